@@ -0,0 +1,204 @@
+package api
+
+import (
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apiv2"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/cache"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/fee"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/graph"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/hold"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/interest"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/ledger"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/reconcile"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/report"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/schedule"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/statement"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/stream"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transfer"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/user"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/webhook"
+	"github.com/labstack/echo/v4"
+)
+
+// Store is everything the HTTP API reads and writes, which postgres.Postgres
+// implements.
+type Store interface {
+	wallet.Storer
+	transaction.Storer
+	statement.Storer
+	hold.Storer
+	limit.Storer
+	transfer.Storer
+	schedule.Storer
+	creditcard.Storer
+	interest.Storer
+	user.Storer
+	audit.Storer
+	report.Storer
+	ledger.Storer
+	reconcile.Storer
+	fee.Storer
+	webhook.Storer
+	stream.Storer
+	graph.Storer
+}
+
+type Options struct {
+	// AdminToken is the token auth.Admin asks of admin routes.
+	AdminToken string
+	// Deprecation is announced on the v1 routes with a v2 successor.
+	Deprecation apiv2.Deprecation
+	// Currency is the currency of v2 amounts.
+	Currency      string
+	Heartbeat     time.Duration
+	MaxComplexity int
+	MaxDepth      int
+}
+
+// Register routes every handler of the API on e. Wallet lists and user
+// summaries are read through cached, whose LastModified also answers
+// conditional requests, and what moves balances writes through it; the
+// other handlers use db.
+func Register(e *echo.Echo, db Store, cached *cache.Store, broker *stream.Broker, o Options) {
+	// v1 routes with a v2 successor announce their deprecation.
+	deprecated := apiv2.Deprecated(o.Deprecation)
+	// List endpoints answer 304 Not Modified to an If-None-Match naming
+	// their current ETag.
+	conditional := cache.Conditional(cached.LastModified)
+	walletHandler := wallet.New(cached)
+	walletGroup := e.Group("/api/v1/wallets")
+	walletGroup.GET("", walletHandler.GetWallet, deprecated, conditional)
+	walletGroup.POST("", walletHandler.CreateWallet, deprecated)
+	walletGroup.POST("/import", walletHandler.ImportWallets)
+	walletGroup.GET("/export", walletHandler.ExportWallets)
+	walletGroup.PUT("/:id", walletHandler.UpdateWallet, deprecated)
+	walletGroup.DELETE("/:id", walletHandler.DeleteWallet, deprecated)
+
+	movements := cache.NewMovements(db, cached)
+	transactionHandler := transaction.New(movements)
+	walletGroup.GET("/:id/transactions", transactionHandler.GetTransactions)
+	walletGroup.POST("/:id/deposits", transactionHandler.Deposit)
+	walletGroup.POST("/:id/withdrawals", transactionHandler.Withdraw)
+
+	statementHandler := statement.New(db)
+	walletGroup.GET("/:id/statements", statementHandler.GetStatement)
+
+	holdHandler := hold.New(movements)
+	walletGroup.GET("/:id/holds", holdHandler.GetHolds)
+	walletGroup.POST("/:id/holds", holdHandler.CreateHold)
+	walletGroup.POST("/:id/holds/:holdId/capture", holdHandler.CaptureHold)
+	walletGroup.POST("/:id/holds/:holdId/void", holdHandler.VoidHold)
+
+	limitHandler := limit.New(db)
+	walletGroup.GET("/:id/limits", limitHandler.GetWalletLimits)
+	walletGroup.PUT("/:id/limits", limitHandler.SaveWalletLimits)
+
+	transferHandler := transfer.New(movements)
+	transferGroup := e.Group("/api/v1/transfers")
+	transferGroup.POST("", transferHandler.CreateTransfer)
+	transferGroup.POST("/quote", transferHandler.QuoteTransfer)
+	transferGroup.GET("/:id", transferHandler.GetTransfer)
+
+	scheduleHandler := schedule.New(db)
+	walletGroup.GET("/:id/scheduled-transfers", scheduleHandler.GetWalletSchedules)
+	scheduleGroup := e.Group("/api/v1/scheduled-transfers")
+	scheduleGroup.POST("", scheduleHandler.CreateSchedule)
+	scheduleGroup.GET("/:id", scheduleHandler.GetSchedule)
+	scheduleGroup.PUT("/:id", scheduleHandler.UpdateSchedule)
+	scheduleGroup.DELETE("/:id", scheduleHandler.DeleteSchedule)
+	scheduleGroup.POST("/:id/pause", scheduleHandler.PauseSchedule)
+	scheduleGroup.POST("/:id/resume", scheduleHandler.ResumeSchedule)
+	scheduleGroup.GET("/:id/executions", scheduleHandler.GetExecutions)
+
+	creditCardHandler := creditcard.New(movements)
+	walletGroup.GET("/:id/credit-card", creditCardHandler.GetCreditCard)
+	walletGroup.PUT("/:id/credit-card", creditCardHandler.SaveCreditCard)
+	walletGroup.POST("/:id/credit-card/spend", creditCardHandler.Spend)
+	walletGroup.POST("/:id/credit-card/payments", creditCardHandler.Pay)
+	walletGroup.GET("/:id/credit-card/statements", creditCardHandler.GetStatements)
+
+	interestHandler := interest.New(db)
+	walletGroup.GET("/:id/interest", interestHandler.GetHistory)
+
+	userHandler := user.New(cached)
+	userGroup := e.Group("/api/v1/users")
+	userGroup.GET("/:id/wallets", userHandler.WalletByUserId, deprecated, conditional)
+	userGroup.GET("/:id/summary", userHandler.Summary)
+
+	streamHandler := stream.New(db, broker, o.Heartbeat)
+	userGroup.GET("/:id/wallets/stream", streamHandler.WalletStream)
+
+	v2Handler := apiv2.New(cached, o.Currency)
+	v2Group := e.Group("/api/v2")
+	v2Group.GET("/wallets", v2Handler.GetWallets, conditional)
+	v2Group.POST("/wallets", v2Handler.CreateWallet)
+	v2Group.PUT("/wallets/:id", v2Handler.UpdateWallet)
+	v2Group.DELETE("/wallets/:id", v2Handler.DeleteWallet)
+	v2Group.GET("/users/:id/wallets", v2Handler.GetUserWallets, conditional)
+
+	graphHandler := graph.New(cached, o.MaxComplexity, o.MaxDepth)
+	e.GET("/graphql", graphHandler.Serve)
+	e.POST("/graphql", graphHandler.Serve)
+
+	adminAuth := auth.Admin(o.AdminToken)
+
+	auditHandler := audit.New(db)
+	auditGroup := e.Group("/api/v1/audit", adminAuth)
+	auditGroup.GET("", auditHandler.GetAuditLogs)
+
+	reportHandler := report.New(db)
+	reportGroup := e.Group("/api/v1/reports", adminAuth)
+	reportGroup.GET("/balances", reportHandler.Balances)
+
+	ledgerHandler := ledger.New(db)
+	ledgerGroup := e.Group("/api/v1/ledger", adminAuth)
+	ledgerGroup.GET("/trial-balance", ledgerHandler.GetTrialBalance)
+	ledgerGroup.GET("/invariant", ledgerHandler.CheckInvariant)
+
+	reconcileHandler := reconcile.New(db)
+	reconcileGroup := e.Group("/api/v1/reconciliations", adminAuth)
+	reconcileGroup.POST("", reconcileHandler.ImportFile)
+	reconcileGroup.GET("", reconcileHandler.GetFiles)
+	reconcileGroup.GET("/:id", reconcileHandler.GetReport)
+	reconcileGroup.POST("/:id/items/:itemId/resolve", reconcileHandler.ResolveItem)
+
+	walletTypeGroup := e.Group("/api/v1/wallet-types")
+	walletTypeGroup.GET("", walletHandler.GetWalletTypes)
+	walletTypeGroup.POST("", walletHandler.CreateWalletType, adminAuth)
+	walletTypeGroup.PUT("/:key", walletHandler.UpdateWalletType, adminAuth)
+	walletTypeGroup.DELETE("/:key", walletHandler.DeleteWalletType, adminAuth)
+	walletTypeGroup.GET("/:key/limits", limitHandler.GetTypeLimits, adminAuth)
+	walletTypeGroup.PUT("/:key/limits", limitHandler.SaveTypeLimits, adminAuth)
+	feeHandler := fee.New(db)
+	walletTypeGroup.GET("/:key/fees", feeHandler.GetTypeFees, adminAuth)
+	walletTypeGroup.PUT("/:key/fees/:operation", feeHandler.SaveTypeFee, adminAuth)
+	walletTypeGroup.DELETE("/:key/fees/:operation", feeHandler.DeleteTypeFee, adminAuth)
+
+	walletGroup.PUT("/:id/interest/rate", interestHandler.SaveWalletRate, adminAuth)
+	walletGroup.POST("/:id/freeze", walletHandler.FreezeWallet, adminAuth)
+	walletGroup.POST("/:id/unfreeze", walletHandler.UnfreezeWallet, adminAuth)
+	walletGroup.POST("/:id/close", walletHandler.CloseWallet, adminAuth)
+	walletGroup.GET("/:id/status-history", walletHandler.GetStatusHistory, adminAuth)
+	transactionGroup := e.Group("/api/v1/transactions", adminAuth)
+	transactionGroup.POST("/:id/reverse", transactionHandler.Reverse)
+	interestGroup := e.Group("/api/v1/interest", adminAuth)
+	interestGroup.GET("/products", interestHandler.GetProductRates)
+	interestGroup.PUT("/products/:wallet_type", interestHandler.SaveProductRate)
+
+	webhookHandler := webhook.New(db)
+	webhookGroup := e.Group("/api/v1/webhooks", adminAuth)
+	webhookGroup.GET("", webhookHandler.GetSubscriptions)
+	webhookGroup.POST("", webhookHandler.CreateSubscription)
+	webhookGroup.GET("/:id", webhookHandler.GetSubscription)
+	webhookGroup.PUT("/:id", webhookHandler.UpdateSubscription)
+	webhookGroup.DELETE("/:id", webhookHandler.DeleteSubscription)
+	webhookGroup.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+	webhookGroup.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+}
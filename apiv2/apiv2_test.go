@@ -0,0 +1,232 @@
+package apiv2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type StubV2 struct {
+	wallets []wallet.Wallet
+	created *wallet.Wallet
+	err     error
+}
+
+func (s *StubV2) Wallets(walletType string) ([]wallet.Wallet, error) {
+	var wallets []wallet.Wallet
+	for _, w := range s.wallets {
+		if walletType == "" || w.WalletType == walletType {
+			wallets = append(wallets, w)
+		}
+	}
+	return wallets, s.err
+}
+
+func (s *StubV2) WalletTypes() ([]wallet.Type, error) {
+	return []wallet.Type{{Key: "Savings", Name: "Savings"}, {Key: "CreditCard", Name: "Credit Card", AllowNegative: true}}, nil
+}
+
+func (s *StubV2) CreateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	w.ID, w.Status, w.AvailableBalance = 7, wallet.StatusActive, w.Balance
+	s.created = &w
+	return &w, s.err
+}
+
+func (s *StubV2) UpdateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &w, nil
+}
+
+func (s *StubV2) DeleteWallet(id int, actor audit.Actor) error {
+	return s.err
+}
+
+func (s *StubV2) WalletsByUserID(userId int) ([]wallet.Wallet, error) {
+	var wallets []wallet.Wallet
+	for _, w := range s.wallets {
+		if w.UserID == userId {
+			wallets = append(wallets, w)
+		}
+	}
+	return wallets, s.err
+}
+
+func stubWallets() []wallet.Wallet {
+	created := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	return []wallet.Wallet{
+		{ID: 1, UserID: 1, UserName: "John Doe", WalletName: "John's Savings", WalletType: "Savings",
+			Balance: 100.5, AvailableBalance: 75, Status: wallet.StatusActive, CreatedAt: created},
+		{ID: 2, UserID: 1, UserName: "John Doe", WalletName: "John's Card", WalletType: "Credit Card",
+			Balance: -0.1 - 0.2, AvailableBalance: -0.3, Status: wallet.StatusFrozen, CreatedAt: created},
+	}
+}
+
+func serve(h echo.HandlerFunc, method, target, body string, params ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if len(params) > 0 {
+		c.SetParamNames(params[0])
+		c.SetParamValues(params[1])
+	}
+	h(c)
+	return rec
+}
+
+func TestMoney(t *testing.T) {
+	for amount, want := range map[float64]Money{100: "100.00", 100.5: "100.50", -0.1 - 0.2: "-0.30", -0.001: "0.00", 1234567.891: "1234567.89"} {
+		if got := NewMoney(amount); got != want {
+			t.Errorf("expected %v as %q but got %q", amount, want, got)
+		}
+	}
+	for _, bad := range []Money{"", "1.234", "1e3", "12,50", " 1", "NaN"} {
+		if _, err := bad.Float(); err != ErrInvalidMoney {
+			t.Errorf("expected %q to be refused but got %v", bad, err)
+		}
+	}
+	if f, err := Money("-20.5").Float(); err != nil || f != -20.5 {
+		t.Errorf("unexpected %v, %v", f, err)
+	}
+}
+
+func TestGetWallets(t *testing.T) {
+	t.Run("should map wallets to the v2 representation", func(t *testing.T) {
+		rec := serve(New(&StubV2{wallets: stubWallets()}, "").GetWallets, http.MethodGet, "/api/v2/wallets", "")
+
+		want := `{"data":[` +
+			`{"id":1,"name":"John's Savings","type":"Savings","status":"active","currency":"THB","balance":"100.50","available_balance":"75.00","user":{"id":1,"name":"John Doe"},"created_at":"2026-09-01T10:00:00Z"},` +
+			`{"id":2,"name":"John's Card","type":"Credit Card","status":"frozen","currency":"THB","balance":"-0.30","available_balance":"-0.30","user":{"id":1,"name":"John Doe"},"created_at":"2026-09-01T10:00:00Z"}` +
+			`]}` + "\n"
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("unexpected response %d\n%s\nwant:\n%s", rec.Code, rec.Body, want)
+		}
+	})
+
+	t.Run("given no wallets should return an empty list", func(t *testing.T) {
+		rec := serve(New(&StubV2{}, "USD").GetUserWallets, http.MethodGet, "/api/v2/users/9/wallets", "", "id", "9")
+
+		if rec.Body.String() != `{"data":[]}`+"\n" {
+			t.Errorf("unexpected body %s", rec.Body)
+		}
+	})
+
+	t.Run("given an unknown type should return 400", func(t *testing.T) {
+		rec := serve(New(&StubV2{}, "").GetWallets, http.MethodGet, "/api/v2/wallets?type=Gold", "")
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestCreateWallet(t *testing.T) {
+	t.Run("given a v2 body should create the wallet", func(t *testing.T) {
+		stub := &StubV2{}
+		body := `{"name": "Jane's Card", "type": "CreditCard", "balance": "-12.50", "user": {"id": 2, "name": "Jane Doe"}}`
+
+		rec := serve(New(stub, "").CreateWallet, http.MethodPost, "/api/v2/wallets", body)
+
+		if rec.Code != http.StatusCreated || stub.created == nil {
+			t.Fatalf("unexpected response %d %s", rec.Code, rec.Body)
+		}
+		if stub.created.UserName != "Jane Doe" || stub.created.WalletType != "Credit Card" || stub.created.Balance != -12.5 {
+			t.Errorf("unexpected wallet %+v", stub.created)
+		}
+		var got Wallet
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		if got.Balance != "-12.50" || got.User.ID != 2 {
+			t.Errorf("unexpected response %+v", got)
+		}
+	})
+
+	t.Run("given a numeric balance should return 400", func(t *testing.T) {
+		stub := &StubV2{}
+		body := `{"name": "Jane", "type": "Savings", "balance": 12.5, "user": {"id": 2, "name": "Jane Doe"}}`
+
+		rec := serve(New(stub, "").CreateWallet, http.MethodPost, "/api/v2/wallets", body)
+
+		if rec.Code != http.StatusBadRequest || stub.created != nil {
+			t.Errorf("unexpected response %d %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("given a balance the type forbids should return 422", func(t *testing.T) {
+		body := `{"name": "Jane", "type": "Savings", "balance": "-1.00", "user": {"id": 2, "name": "Jane Doe"}}`
+
+		rec := serve(New(&StubV2{}, "").CreateWallet, http.MethodPost, "/api/v2/wallets", body)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+	})
+}
+
+func TestUpdateWallet(t *testing.T) {
+	body := `{"name": "John", "type": "Savings", "balance": "5.00", "user": {"id": 1, "name": "John Doe"}}`
+	stub := &StubV2{err: &wallet.StatusError{WalletID: 1, Status: wallet.StatusClosed}}
+
+	rec := serve(New(stub, "").UpdateWallet, http.MethodPut, "/api/v2/wallets/1", body, "id", "1")
+
+	var got Err
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if rec.Code != http.StatusConflict || got.Code != wallet.CodeWalletClosed {
+		t.Errorf("unexpected response %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestDeleteWallet(t *testing.T) {
+	rec := serve(New(&StubV2{err: wallet.ErrWalletNotFound}, "").DeleteWallet, http.MethodDelete, "/api/v2/wallets/9", "", "id", "9")
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	v1 := func(c echo.Context) error {
+		return c.JSON(http.StatusOK, stubWallets())
+	}
+
+	t.Run("should mark v1 responses and keep their body", func(t *testing.T) {
+		plain := serve(v1, http.MethodGet, "/api/v1/users/1/wallets", "")
+		d := Deprecation{
+			Since:  time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			Sunset: time.Date(2027, 4, 30, 0, 0, 0, 0, time.FixedZone("ICT", 7*60*60)),
+			Link:   "https://example.com/docs/v2-migration",
+		}
+
+		rec := serve(Deprecated(d)(v1), http.MethodGet, "/api/v1/users/1/wallets", "")
+
+		if got := rec.Header().Get(HeaderDeprecation); got != "@1792368000" {
+			t.Errorf("unexpected Deprecation %q", got)
+		}
+		if got := rec.Header().Get(HeaderSunset); got != "Thu, 29 Apr 2027 17:00:00 GMT" {
+			t.Errorf("unexpected Sunset %q", got)
+		}
+		links := rec.Header().Values("Link")
+		if len(links) != 2 || links[0] != `</api/v2/users/1/wallets>; rel="successor-version"` || !strings.Contains(links[1], `rel="deprecation"`) {
+			t.Errorf("unexpected Link %q", links)
+		}
+		if rec.Body.String() != plain.Body.String() {
+			t.Errorf("expected the v1 body untouched but got\n%s\nwant:\n%s", rec.Body, plain.Body)
+		}
+	})
+
+	t.Run("given no date should not mark responses", func(t *testing.T) {
+		rec := serve(Deprecated(Deprecation{})(v1), http.MethodGet, "/api/v1/wallets", "")
+
+		if rec.Header().Get(HeaderDeprecation) != "" || rec.Header().Get("Link") != "" {
+			t.Errorf("unexpected headers %v", rec.Header())
+		}
+	})
+}
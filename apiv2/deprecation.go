@@ -0,0 +1,56 @@
+package apiv2
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+)
+
+// Released is the date /api/v2 was released, from which the v1 routes it
+// replaces are deprecated unless api.v1.deprecated_at says otherwise. It
+// moves only if the release is announced again.
+const Released = "2026-10-19"
+
+// Deprecation is when a route was, or will be, deprecated and removed.
+type Deprecation struct {
+	// Since is the date of deprecation. Routes are not marked when zero.
+	Since time.Time
+	// Sunset is when the route stops answering, if announced.
+	Sunset time.Time
+	// Link is a page that explains the deprecation, if any.
+	Link string
+}
+
+// Deprecated marks the responses of v1 routes with a Deprecation header
+// (RFC 9745), a Sunset header (RFC 8594) once announced, and a Link to the
+// same path under /api/v2. Bodies are untouched.
+func Deprecated(d Deprecation) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if d.Since.IsZero() {
+			return next
+		}
+		return func(c echo.Context) error {
+			h := c.Response().Header()
+			h.Set(HeaderDeprecation, fmt.Sprintf("@%d", d.Since.Unix()))
+			if !d.Sunset.IsZero() {
+				h.Set(HeaderSunset, d.Sunset.UTC().Format(http.TimeFormat))
+			}
+			path := c.Request().URL.Path
+			if strings.HasPrefix(path, "/api/v1/") {
+				h.Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, "/api/v2/"+strings.TrimPrefix(path, "/api/v1/")))
+			}
+			if d.Link != "" {
+				h.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, d.Link))
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package apiv2
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultCurrency is the currency of every balance until wallets carry
+// their own.
+const DefaultCurrency = "THB"
+
+type Handler struct {
+	store    Storer
+	currency string
+}
+
+// Storer is the part of wallet.Storer and user.Storer that v2 serves.
+type Storer interface {
+	Wallets(walletType string) ([]wallet.Wallet, error)
+	WalletTypes() ([]wallet.Type, error)
+	CreateWallet(wallet wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error)
+	UpdateWallet(wallet wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error)
+	DeleteWallet(id int, actor audit.Actor) error
+	WalletsByUserID(userId int) ([]wallet.Wallet, error)
+}
+
+// New returns a handler over db reporting balances in currency, or in
+// DefaultCurrency when it is empty.
+func New(db Storer, currency string) *Handler {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return &Handler{store: db, currency: currency}
+}
+
+type Err struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+}
+
+// GetWallets
+//
+//	@Summary		Get all wallets
+//	@Description	Get all wallets, with string money, the currency and the owner as a nested user
+//	@Tags			v2
+//	@Produce		json
+//	@Router			/api/v2/wallets [get]
+//	@Success		200	{object}	WalletList
+//	@Success		304	"If-None-Match names the current ETag"
+//	@Header			200	{string}	ETag	"Hash of the body"
+//	@Header			200	{string}	Last-Modified	"When the wallets last changed"
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   type  query	string	false	"Wallet type key or name, see /api/v1/wallet-types"
+//	@Param   If-None-Match  header	string	false	"ETag of an earlier answer"
+func (h *Handler) GetWallets(c echo.Context) error {
+	walletType := c.QueryParam("type")
+	if walletType != "" {
+		t, err := h.resolveType(walletType)
+		if err != nil {
+			return storeError(c, err)
+		}
+		walletType = t.Name
+	}
+
+	wallets, err := h.store.Wallets(walletType)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, FromWallets(wallets, h.currency))
+}
+
+// CreateWallet
+//
+//	@Summary		Create new wallet
+//	@Description	Create new wallet. Crypto wallets must carry crypto details, as in /api/v1/wallets.
+//	@Tags			v2
+//	@Accept			json
+//	@Produce		json
+//	@Router			/api/v2/wallets [post]
+//	@Success		201	{object}	Wallet
+//	@Failure		400	{object}	Err
+//	@Failure		422	{object}	Err	"balance breaks the rules of the wallet type"
+//	@Failure		500	{object}	Err
+//	@Param   wallet  body		WalletInput	true	"Wallet"
+func (h *Handler) CreateWallet(c echo.Context) error {
+	w, err := h.bind(c, true)
+	if err != nil {
+		return storeError(c, err)
+	}
+
+	created, err := h.store.CreateWallet(w, audit.ActorFrom(c))
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, FromWallet(*created, h.currency))
+}
+
+// UpdateWallet
+//
+//	@Summary		Update wallet
+//	@Description	Update wallet. The crypto details of a crypto wallet are kept when omitted.
+//	@Tags			v2
+//	@Accept			json
+//	@Produce		json
+//	@Router			/api/v2/wallets/{id} [put]
+//	@Success		200	{object}	Wallet
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code wallet_frozen or wallet_closed when the wallet is not active"
+//	@Failure		422	{object}	Err	"balance breaks the rules of the wallet type"
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   wallet  body		WalletInput	true	"Wallet"
+func (h *Handler) UpdateWallet(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+	w, err := h.bind(c, false)
+	if err != nil {
+		return storeError(c, err)
+	}
+	w.ID = id
+
+	updated, err := h.store.UpdateWallet(w, audit.ActorFrom(c))
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, FromWallet(*updated, h.currency))
+}
+
+// DeleteWallet
+//
+//	@Summary		Delete wallet
+//	@Description	Delete wallet
+//	@Tags			v2
+//	@Router			/api/v2/wallets/{id} [delete]
+//	@Success		204
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code wallet_frozen or wallet_closed when the wallet is not active"
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+func (h *Handler) DeleteWallet(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+
+	if err := h.store.DeleteWallet(id, audit.ActorFrom(c)); err != nil {
+		return storeError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetUserWallets
+//
+//	@Summary		Get all wallets by user id
+//	@Description	Get all wallets of a user
+//	@Tags			v2
+//	@Produce		json
+//	@Router			/api/v2/users/{id}/wallets [get]
+//	@Success		200	{object}	WalletList
+//	@Success		304	"If-None-Match names the current ETag"
+//	@Header			200	{string}	ETag	"Hash of the body"
+//	@Header			200	{string}	Last-Modified	"When the wallets last changed"
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path	int	true "User id"
+//	@Param   If-None-Match  header	string	false	"ETag of an earlier answer"
+func (h *Handler) GetUserWallets(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid user id"})
+	}
+
+	wallets, err := h.store.WalletsByUserID(userID)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, FromWallets(wallets, h.currency))
+}
+
+func (h *Handler) resolveType(name string) (wallet.Type, error) {
+	types, err := h.store.WalletTypes()
+	if err != nil {
+		return wallet.Type{}, err
+	}
+	t, ok := wallet.ResolveType(types, name)
+	if !ok {
+		return wallet.Type{}, wallet.ErrTypeNotFound
+	}
+	return t, nil
+}
+
+// inputError is a body that cannot be a wallet.
+type inputError struct {
+	err error
+}
+
+func (e *inputError) Error() string {
+	return e.err.Error()
+}
+
+// bind reads the body and checks it the way /api/v1/wallets does.
+func (h *Handler) bind(c echo.Context, create bool) (wallet.Wallet, error) {
+	var in WalletInput
+	if err := c.Bind(&in); err != nil {
+		return wallet.Wallet{}, &inputError{err}
+	}
+	w, err := in.Wallet()
+	if err != nil {
+		return w, &inputError{err}
+	}
+	t, err := h.resolveType(w.WalletType)
+	if err != nil {
+		return w, err
+	}
+	w.WalletType = t.Name
+	if err := t.CheckBalance(w.Balance); err != nil {
+		return w, err
+	}
+	if err := wallet.ValidateCrypto(&w, create); err != nil {
+		return w, &inputError{err}
+	}
+	return w, nil
+}
+
+// storeError answers with the status /api/v1 uses for err.
+func storeError(c echo.Context, err error) error {
+	var ie *inputError
+	var be *wallet.BalanceError
+	var se *wallet.StatusError
+	var te *wallet.TransitionError
+	switch {
+	case errors.As(err, &ie), errors.Is(err, wallet.ErrMissingCrypto):
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	case errors.Is(err, wallet.ErrTypeNotFound):
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet type"})
+	case errors.As(err, &be):
+		return c.JSON(http.StatusUnprocessableEntity, Err{Message: err.Error()})
+	case errors.As(err, &se):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error(), Code: se.Code()})
+	case errors.As(err, &te):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error(), Code: wallet.CodeInvalidTransition})
+	case errors.Is(err, wallet.ErrWalletNotFound):
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
@@ -0,0 +1,135 @@
+// Package apiv2 serves the wallets under /api/v2, in a representation
+// mapped from the same wallet.Wallet as /api/v1.
+package apiv2
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+// Money is an amount in the currency of the wallet, as a decimal string
+// with two decimals so clients never round through a float.
+type Money string
+
+var moneyPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]{1,2})?$`)
+
+var ErrInvalidMoney = errors.New("amount must be a decimal string with at most two decimals")
+
+// NewMoney formats amount with two decimals, never as negative zero.
+func NewMoney(amount float64) Money {
+	amount = math.Round(amount*100) / 100
+	if amount == 0 {
+		amount = 0
+	}
+	return Money(strconv.FormatFloat(amount, 'f', 2, 64))
+}
+
+// Float parses m.
+func (m Money) Float() (float64, error) {
+	if !moneyPattern.MatchString(string(m)) {
+		return 0, ErrInvalidMoney
+	}
+	return strconv.ParseFloat(string(m), 64)
+}
+
+type User struct {
+	ID   int    `json:"id" example:"1"`
+	Name string `json:"name" example:"John Doe"`
+}
+
+type Crypto struct {
+	Asset    string `json:"asset" example:"BTC"`
+	Address  string `json:"address" example:"bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"`
+	Decimals int    `json:"decimals" example:"8"`
+	Amount   string `json:"amount" example:"0.00000001"`
+}
+
+// Wallet is the v2 representation of a wallet. Balance is the ledger
+// balance and AvailableBalance what can still be spent, both in Currency;
+// the balance of a crypto wallet is its book value.
+type Wallet struct {
+	ID               int       `json:"id" example:"1"`
+	Name             string    `json:"name" example:"John's Wallet"`
+	Type             string    `json:"type" example:"Savings"`
+	Status           string    `json:"status" example:"active"`
+	Currency         string    `json:"currency" example:"THB"`
+	Balance          Money     `json:"balance" swaggertype:"string" example:"100.00"`
+	AvailableBalance Money     `json:"available_balance" swaggertype:"string" example:"75.00"`
+	User             User      `json:"user"`
+	Crypto           *Crypto   `json:"crypto,omitempty"`
+	CreatedAt        time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+// WalletList is a page of wallets. It is an object rather than an array
+// so that paging can be added without a new version.
+type WalletList struct {
+	Data []Wallet `json:"data"`
+}
+
+// WalletInput is the body of a create or update. The user is given by id
+// and name, the type by key or name, the balance as Money.
+type WalletInput struct {
+	Name    string       `json:"name" example:"John's Wallet"`
+	Type    string       `json:"type" example:"Savings"`
+	Balance Money        `json:"balance" swaggertype:"string" example:"100.00"`
+	User    User         `json:"user"`
+	Crypto  *CryptoInput `json:"crypto,omitempty"`
+}
+
+type CryptoInput struct {
+	Asset   string `json:"asset" example:"BTC"`
+	Address string `json:"address" example:"bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"`
+	Amount  string `json:"amount" example:"0.00000001"`
+}
+
+// FromWallet maps a wallet to its v2 representation.
+func FromWallet(w wallet.Wallet, currency string) Wallet {
+	v := Wallet{
+		ID:               w.ID,
+		Name:             w.WalletName,
+		Type:             w.WalletType,
+		Status:           w.Status,
+		Currency:         currency,
+		Balance:          NewMoney(w.Balance),
+		AvailableBalance: NewMoney(w.AvailableBalance),
+		User:             User{ID: w.UserID, Name: w.UserName},
+		CreatedAt:        w.CreatedAt,
+	}
+	if c := w.Crypto; c != nil {
+		v.Crypto = &Crypto{Asset: c.Asset, Address: c.Address, Decimals: c.Decimals, Amount: string(c.Amount)}
+	}
+	return v
+}
+
+// FromWallets maps wallets to a list, empty rather than null.
+func FromWallets(wallets []wallet.Wallet, currency string) WalletList {
+	list := WalletList{Data: make([]Wallet, len(wallets))}
+	for i, w := range wallets {
+		list.Data[i] = FromWallet(w, currency)
+	}
+	return list
+}
+
+// Wallet maps the input to a wallet, before its type is checked.
+func (in WalletInput) Wallet() (wallet.Wallet, error) {
+	balance, err := in.Balance.Float()
+	if err != nil {
+		return wallet.Wallet{}, err
+	}
+	w := wallet.Wallet{
+		UserID:     in.User.ID,
+		UserName:   in.User.Name,
+		WalletName: in.Name,
+		WalletType: in.Type,
+		Balance:    balance,
+	}
+	if c := in.Crypto; c != nil {
+		w.Crypto = &wallet.Crypto{Asset: c.Asset, Address: c.Address, Amount: wallet.Amount(c.Amount)}
+	}
+	return w, nil
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	// Status changes of a wallet.
+	ActionFreeze   = "freeze"
+	ActionUnfreeze = "unfreeze"
+	ActionClose    = "close"
+	// ActionReverse is a reversal of a transaction of the wallet.
+	ActionReverse = "reverse"
+	// ActionImport is a bulk import. Its one record has wallet id 0 and an
+	// Import after.
+	ActionImport = "import"
+)
+
+// Import is the after of an ActionImport record.
+type Import struct {
+	Count     int   `json:"count" example:"2"`
+	WalletIDs []int `json:"wallet_ids" example:"5,6"`
+}
+
+// HeaderActor carries the identity the caller claims, which nothing checks,
+// so it is only ever kept as what the caller claimed. The actor is the
+// identity authenticated on routes behind authentication, and
+// AnonymousActor everywhere else.
+const (
+	HeaderActor    = "X-Actor"
+	AnonymousActor = "anonymous"
+)
+
+// authenticatedKey is the context key of the identity set by Authenticate.
+const authenticatedKey = "audit.authenticated"
+
+type Actor struct {
+	ID string `json:"actor"`
+	// Claimed is the HeaderActor of the request.
+	Claimed   string `json:"claimed,omitempty"`
+	IP        string `json:"ip"`
+	RequestID string `json:"request_id"`
+}
+
+// Authenticate makes id, proven by a credential of the request, the actor
+// ActorFrom builds for c.
+func Authenticate(c echo.Context, id string) {
+	c.Set(authenticatedKey, id)
+}
+
+type Record struct {
+	ID        int             `json:"id" example:"1"`
+	Actor     string          `json:"actor" example:"admin"`
+	Claimed   string          `json:"claimed,omitempty" example:"ops@example.com"`
+	Action    string          `json:"action" example:"update"`
+	WalletID  int             `json:"wallet_id" example:"1"`
+	Before    json.RawMessage `json:"before" swaggertype:"object"`
+	After     json.RawMessage `json:"after" swaggertype:"object"`
+	Diff      json.RawMessage `json:"diff" swaggertype:"object"`
+	IP        string          `json:"ip" example:"127.0.0.1"`
+	RequestID string          `json:"request_id" example:"kTRhvBdvRbSSL1gNNRrbxTGYHDAKDCyB"`
+	CreatedAt time.Time       `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+type Filter struct {
+	Actor    string
+	WalletID int
+	From     time.Time
+	To       time.Time
+}
+
+// ActorFrom builds the actor of the current request: the authenticated
+// identity if there is one, else AnonymousActor, with the header as claimed.
+// The request id is the one assigned by the RequestID middleware, falling
+// back to the one sent by the client.
+func ActorFrom(c echo.Context) Actor {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	if requestID == "" {
+		requestID = c.Request().Header.Get(echo.HeaderXRequestID)
+	}
+	actor := Actor{
+		ID:        AnonymousActor,
+		Claimed:   c.Request().Header.Get(HeaderActor),
+		IP:        c.RealIP(),
+		RequestID: requestID,
+	}
+	if id, ok := c.Get(authenticatedKey).(string); ok {
+		actor.ID = id
+	}
+	return actor
+}
+
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Diff returns the fields that differ between the JSON representations of
+// before and after, keyed by JSON field name. Either side may be nil.
+func Diff(before, after any) (json.RawMessage, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]Change{}
+	for k, v := range b {
+		if !reflect.DeepEqual(v, a[k]) {
+			changes[k] = Change{From: v, To: a[k]}
+		}
+	}
+	for k, v := range a {
+		if _, ok := b[k]; !ok {
+			changes[k] = Change{From: nil, To: v}
+		}
+	}
+	return json.Marshal(changes)
+}
+
+func toMap(v any) (map[string]any, error) {
+	m := map[string]any{}
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return m, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
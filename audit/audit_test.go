@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StubAuditHandler struct {
+	records []Record
+	filter  Filter
+	err     error
+}
+
+func (s *StubAuditHandler) AuditLogs(filter Filter) ([]Record, error) {
+	s.filter = filter
+	return s.records, s.err
+}
+
+func TestAudit(t *testing.T) {
+
+	t.Run("given filters should query audit logs with them", func(t *testing.T) {
+		q := make(url.Values)
+		q.Set("actor", "ops@example.com")
+		q.Set("wallet_id", "7")
+		q.Set("from", "2024-03-01T00:00:00Z")
+		q.Set("to", "2024-04-01T00:00:00Z")
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/?"+q.Encode(), nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		stub := &StubAuditHandler{records: []Record{{ID: 1, Actor: "ops@example.com", WalletID: 7}}}
+		New(stub).GetAuditLogs(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+		want := Filter{
+			Actor:    "ops@example.com",
+			WalletID: 7,
+			From:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		}
+		if !reflect.DeepEqual(stub.filter, want) {
+			t.Errorf("expected filter %v but got %v", want, stub.filter)
+		}
+		resp := []Record{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if len(resp) != 1 {
+			t.Errorf("expected records length %d but got %d", 1, len(resp))
+		}
+	})
+
+	t.Run("given invalid time should return 400 and error message", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/?from=yesterday", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		New(&StubAuditHandler{}).GetAuditLogs(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+		resp := &Err{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		if resp.Message != "Invalid from time" {
+			t.Errorf("expected message %s but got %s", "Invalid from time", resp.Message)
+		}
+	})
+
+	t.Run("given request headers should build an anonymous actor claiming the header", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(HeaderActor, "ops@example.com")
+		req.Header.Set(echo.HeaderXRequestID, "req-1")
+		req.Header.Set(echo.HeaderXRealIP, "10.0.0.1")
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		got := ActorFrom(c)
+		want := Actor{ID: AnonymousActor, Claimed: "ops@example.com", IP: "10.0.0.1", RequestID: "req-1"}
+		if got != want {
+			t.Errorf("expected actor %v but got %v", want, got)
+		}
+	})
+
+	t.Run("given an authenticated request should keep the header as claimed", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(HeaderActor, "ceo@example.com")
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		Authenticate(c, "admin")
+
+		got := ActorFrom(c)
+		if got.ID != "admin" || got.Claimed != "ceo@example.com" {
+			t.Errorf("expected actor admin claiming ceo@example.com but got %+v", got)
+		}
+	})
+
+	t.Run("given an unauthenticated request claiming admin should be anonymous", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(HeaderActor, "admin")
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		got := ActorFrom(c)
+		if got.ID != AnonymousActor || got.Claimed != "admin" {
+			t.Errorf("expected an anonymous actor claiming admin but got %+v", got)
+		}
+	})
+
+	t.Run("given no actor header should be anonymous", func(t *testing.T) {
+		e := echo.New()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+
+		if got := ActorFrom(c).ID; got != AnonymousActor {
+			t.Errorf("expected actor %s but got %s", AnonymousActor, got)
+		}
+	})
+}
+
+func TestDiff(t *testing.T) {
+	type wallet struct {
+		Name    string  `json:"name"`
+		Balance float64 `json:"balance"`
+	}
+
+	t.Run("given changed fields should only include them", func(t *testing.T) {
+		got, err := Diff(&wallet{Name: "a", Balance: 1}, &wallet{Name: "a", Balance: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"balance":{"from":1,"to":2}}`
+		if string(got) != want {
+			t.Errorf("expected diff %s but got %s", want, got)
+		}
+	})
+
+	t.Run("given no before should include every field", func(t *testing.T) {
+		var before *wallet
+		got, err := Diff(before, &wallet{Name: "a", Balance: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"balance":{"from":null,"to":1},"name":{"from":null,"to":"a"}}`
+		if string(got) != want {
+			t.Errorf("expected diff %s but got %s", want, got)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	AuditLogs(filter Filter) ([]Record, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// GetAuditLogs
+//
+//	@Summary		Get audit logs
+//	@Description	Get audit logs of wallet mutations, newest first. A bulk import is one record with action import and wallet id 0.
+//	@Tags			audit
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Record
+//	@Router			/api/v1/audit [get]
+//	@Failure		500	{object}	Err
+//	@Failure		400	{object}	Err
+//	@Param   actor  query	string	false	"Actor, authenticated or claimed"
+//	@Param   wallet_id  query	int	false	"Wallet id"
+//	@Param   from  query	string	false	"From time (RFC 3339)"
+//	@Param   to  query	string	false	"To time (RFC 3339)"
+//	@Security	AdminToken
+func (h *Handler) GetAuditLogs(c echo.Context) error {
+	filter := Filter{Actor: c.QueryParam("actor")}
+
+	if pWalletId := c.QueryParam("wallet_id"); pWalletId != "" {
+		walletId, err := strconv.Atoi(pWalletId)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+		}
+		filter.WalletID = walletId
+	}
+
+	var err error
+	if filter.From, err = parseTime(c.QueryParam("from")); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid from time"})
+	}
+	if filter.To, err = parseTime(c.QueryParam("to")); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid to time"})
+	}
+
+	records, err := h.store.AuditLogs(filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, records)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache holds encoded values by key. Memory is the one in the process; one
+// shared between instances, such as Redis, plugs in behind the same methods.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	// DeletePrefix drops every key starting with prefix, all with "".
+	DeletePrefix(prefix string)
+}
+
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+// Memory is a Cache in the process. Entries expire after a TTL, which bounds
+// how stale a value can get when an invalidation is missed; past size
+// entries, Set drops expired ones first, then any.
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]entry
+	ttl     time.Duration
+	size    int
+	now     func() time.Time
+}
+
+func NewMemory(ttl time.Duration, size int) *Memory {
+	return &Memory{entries: map[string]entry{}, ttl: ttl, size: size, now: time.Now}
+}
+
+func (m *Memory) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || !m.now().Before(e.expires) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (m *Memory) Set(key string, value []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if _, ok := m.entries[key]; !ok && m.size > 0 && len(m.entries) >= m.size {
+		for k, e := range m.entries {
+			if !now.Before(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+		for k := range m.entries {
+			if len(m.entries) < m.size {
+				break
+			}
+			delete(m.entries, k)
+		}
+	}
+	m.entries[key] = entry{value: value, expires: now.Add(m.ttl)}
+}
+
+func (m *Memory) DeletePrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k := range m.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(m.entries, k)
+		}
+	}
+}
+
+// None caches nothing, for when caching is turned off.
+type None struct{}
+
+func (None) Get(key string) ([]byte, bool) { return nil, false }
+
+func (None) Set(key string, value []byte) {}
+
+func (None) DeletePrefix(prefix string) {}
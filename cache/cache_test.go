@@ -0,0 +1,314 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/internal/stubstore"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transfer"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/user"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+// StubCacheStore counts the reads that reach it.
+type StubCacheStore struct {
+	wallet.Storer
+	wallets []wallet.Wallet
+	reads   int
+	// during runs inside a read, as a concurrent mutation would.
+	during func()
+}
+
+func (s *StubCacheStore) Wallets(walletType string) ([]wallet.Wallet, error) {
+	s.reads++
+	if s.during != nil {
+		s.during()
+	}
+	return s.wallets, nil
+}
+
+func (s *StubCacheStore) WalletsByUserID(userId int) ([]wallet.Wallet, error) {
+	s.reads++
+	var wallets []wallet.Wallet
+	for _, w := range s.wallets {
+		if w.UserID == userId {
+			wallets = append(wallets, w)
+		}
+	}
+	return wallets, nil
+}
+
+func (s *StubCacheStore) WalletsByIDs(ids []int) ([]wallet.Wallet, error) {
+	s.reads++
+	return nil, nil
+}
+
+func (s *StubCacheStore) WalletsByUserIDs(userIDs []int) ([]wallet.Wallet, error) {
+	s.reads++
+	return nil, nil
+}
+
+func (s *StubCacheStore) SummaryByUserID(userId int) (*user.Summary, error) {
+	s.reads++
+	return &user.Summary{UserID: userId}, nil
+}
+
+func (s *StubCacheStore) CreateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	w.ID = len(s.wallets) + 1
+	s.wallets = append(s.wallets, w)
+	return &w, nil
+}
+
+func (s *StubCacheStore) DeleteWallet(id int, actor audit.Actor) error {
+	s.wallets = s.wallets[:0]
+	return nil
+}
+
+func newStubStore() *StubCacheStore {
+	return &StubCacheStore{wallets: []wallet.Wallet{
+		{ID: 1, UserID: 1, WalletName: "John Savings", WalletType: wallet.TypeSavings, Balance: 100},
+		{ID: 2, UserID: 2, WalletName: "Jane Card", WalletType: wallet.TypeCreditCard, Balance: -20},
+	}}
+}
+
+func TestMemory(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory(time.Minute, 2)
+	m.now = func() time.Time { return now }
+
+	m.Set("users/1/wallets", []byte("a"))
+	m.Set("users/10/wallets", []byte("b"))
+	if v, ok := m.Get("users/1/wallets"); !ok || string(v) != "a" {
+		t.Errorf("expected a hit but got %q, %v", v, ok)
+	}
+
+	m.DeletePrefix("users/1/")
+	if _, ok := m.Get("users/1/wallets"); ok {
+		t.Error("expected users/1/wallets dropped")
+	}
+	if _, ok := m.Get("users/10/wallets"); !ok {
+		t.Error("expected users/10/wallets kept")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := m.Get("users/10/wallets"); ok {
+		t.Error("expected users/10/wallets expired")
+	}
+
+	m.Set("a", nil)
+	m.Set("b", nil)
+	m.Set("c", nil)
+	if len(m.entries) != 2 {
+		t.Errorf("expected at most 2 entries but got %d", len(m.entries))
+	}
+}
+
+func TestStore(t *testing.T) {
+
+	t.Run("should read through once", func(t *testing.T) {
+		stub := newStubStore()
+		s := NewStore(stub, NewMemory(time.Minute, 0))
+
+		s.Wallets("")
+		got, _ := s.Wallets("")
+		s.SummaryByUserID(1)
+		s.SummaryByUserID(1)
+
+		if stub.reads != 2 || len(got) != 2 || got[1].Balance != -20 {
+			t.Errorf("expected 2 reads and the wallets but got %d, %v", stub.reads, got)
+		}
+	})
+
+	t.Run("given a created wallet should drop the lists and its user only", func(t *testing.T) {
+		stub := newStubStore()
+		s := NewStore(stub, NewMemory(time.Minute, 0))
+		s.Wallets("")
+		s.WalletsByUserID(1)
+		s.WalletsByUserID(2)
+		stub.reads = 0
+
+		s.CreateWallet(wallet.Wallet{UserID: 1, WalletName: "John Card"}, audit.Actor{})
+		all, _ := s.Wallets("")
+		johns, _ := s.WalletsByUserID(1)
+		s.WalletsByUserID(2)
+
+		if stub.reads != 2 || len(all) != 3 || len(johns) != 2 {
+			t.Errorf("expected 2 reads and the new wallet but got %d, %v, %v", stub.reads, all, johns)
+		}
+	})
+
+	t.Run("given a deleted wallet should drop everything", func(t *testing.T) {
+		stub := newStubStore()
+		s := NewStore(stub, NewMemory(time.Minute, 0))
+		s.WalletsByUserID(2)
+
+		s.DeleteWallet(2, audit.Actor{})
+		got, _ := s.WalletsByUserID(2)
+
+		if len(got) != 0 {
+			t.Errorf("expected no wallets but got %v", got)
+		}
+	})
+
+	t.Run("given a notification should drop the user", func(t *testing.T) {
+		stub := newStubStore()
+		s := NewStore(stub, NewMemory(time.Minute, 0))
+		s.WalletsByUserID(1)
+		stub.wallets[0].Balance = 150
+
+		s.Notify(1)
+		got, _ := s.WalletsByUserID(1)
+
+		if got[0].Balance != 150 {
+			t.Errorf("expected the new balance but got %v", got)
+		}
+	})
+
+	t.Run("given an invalidation during a read should not keep what it read", func(t *testing.T) {
+		stub := newStubStore()
+		s := NewStore(stub, NewMemory(time.Minute, 0))
+		stub.during = func() { s.NotifyAll() }
+
+		s.Wallets("")
+		stub.during = nil
+		s.Wallets("")
+
+		if stub.reads != 2 {
+			t.Errorf("expected 2 reads but got %d", stub.reads)
+		}
+	})
+
+	t.Run("should track when the wallets of each user changed", func(t *testing.T) {
+		s := NewStore(newStubStore(), None{})
+		started := s.Modified(1)
+		now := started.Add(time.Hour)
+		s.now = func() time.Time { return now }
+
+		s.Notify(1)
+
+		if got := s.Modified(1); !got.Equal(now) {
+			t.Errorf("expected user 1 modified at %v but got %v", now, got)
+		}
+		if got := s.Modified(0); !got.Equal(now) {
+			t.Errorf("expected all modified at %v but got %v", now, got)
+		}
+		if got := s.Modified(2); !got.Equal(started) {
+			t.Errorf("expected user 2 modified at start %v but got %v", started, got)
+		}
+	})
+}
+
+func TestMovements(t *testing.T) {
+
+	t.Run("given a deposit should answer the new balance to the next GET", func(t *testing.T) {
+		stub := stubstore.New()
+		s := NewStore(stub, NewMemory(time.Minute, 0))
+		e := echo.New()
+		e.POST("/wallets/:id/deposits", transaction.New(NewMovements(stub, s)).Deposit)
+		e.GET("/users/:id/wallets", user.New(s).WalletByUserId, Conditional(s.LastModified))
+		get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/users/1/wallets", nil)
+			req.Header.Set("If-None-Match", ifNoneMatch)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			return rec
+		}
+		etag := get("").Header().Get("ETag")
+
+		req := httptest.NewRequest(http.MethodPost, "/wallets/1/deposits", strings.NewReader(`{"amount": 50, "description": "Salary"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("unexpected deposit %d %s", rec.Code, rec.Body)
+		}
+
+		rec = get(etag)
+		var got []wallet.Wallet
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		if rec.Code != http.StatusOK || len(got) == 0 || got[0].Balance != 150 || rec.Header().Get("ETag") == etag {
+			t.Errorf("expected the new balance under a new ETag but got %d %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("given a transfer should drop both users", func(t *testing.T) {
+		stub := stubstore.New()
+		s := NewStore(stub, None{})
+		started := s.Modified(2)
+		now := started.Add(time.Hour)
+		s.now = func() time.Time { return now }
+
+		if _, err := NewMovements(stub, s).CreateTransfer(transfer.Request{FromWalletID: stubstore.SavingsID, ToWalletID: stubstore.CardID, Amount: 10}); err != nil {
+			t.Fatal(err)
+		}
+
+		if !s.Modified(1).Equal(now) || !s.Modified(2).Equal(now) {
+			t.Errorf("expected users 1 and 2 modified at %v but got %v, %v", now, s.Modified(1), s.Modified(2))
+		}
+	})
+
+	t.Run("given a failed write should drop nothing", func(t *testing.T) {
+		stub := stubstore.New()
+		s := NewStore(stub, None{})
+		started := s.Modified(2)
+		s.now = func() time.Time { return started.Add(time.Hour) }
+
+		if _, err := NewMovements(stub, s).Deposit(stubstore.CardID, 10, "Refund"); err == nil {
+			t.Fatal("expected the frozen wallet to refuse the deposit")
+		}
+
+		if !s.Modified(2).Equal(started) {
+			t.Errorf("expected user 2 unchanged but got %v", s.Modified(2))
+		}
+	})
+}
+
+func TestConditional(t *testing.T) {
+	modified := time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)
+	e := echo.New()
+	e.GET("/wallets", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []string{"John Savings"})
+	}, Conditional(func(echo.Context) time.Time { return modified }))
+	e.GET("/fail", func(c echo.Context) error {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "down"})
+	}, Conditional(func(echo.Context) time.Time { return modified }))
+	get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("/wallets", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Body.String() != "[\"John Savings\"]\n" || etag == "" {
+		t.Fatalf("unexpected response %d %q %s", first.Code, etag, first.Body)
+	}
+	if got := first.Header().Get(echo.HeaderLastModified); got != "Thu, 01 Oct 2026 08:30:00 GMT" {
+		t.Errorf("unexpected Last-Modified %s", got)
+	}
+
+	for _, ifNoneMatch := range []string{etag, `"other", W/` + etag, "*"} {
+		rec := get("/wallets", ifNoneMatch)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %s: expected 304 but got %d %s", ifNoneMatch, rec.Code, rec.Body)
+		}
+	}
+
+	if rec := get("/wallets", `"other"`); rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+		t.Errorf("expected the body for another ETag but got %d", rec.Code)
+	}
+	if rec := get("/fail", "*"); rec.Code != http.StatusInternalServerError || rec.Header().Get("ETag") != "" {
+		t.Errorf("expected the error untouched but got %d %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/hold"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/schedule"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transfer"
+)
+
+// MovementStorer is what moves money in and between wallets outside
+// wallet.Storer.
+type MovementStorer interface {
+	transaction.Storer
+	hold.Storer
+	transfer.Storer
+	creditcard.Storer
+}
+
+// Movements invalidates the wallets whose balances its writes move before
+// returning, as Store does for its own mutations. A failed write is one
+// rolled back transaction, so it invalidates nothing.
+type Movements struct {
+	MovementStorer
+	cached *Store
+}
+
+func NewMovements(db MovementStorer, cached *Store) *Movements {
+	return &Movements{MovementStorer: db, cached: cached}
+}
+
+func (m *Movements) Deposit(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	t, err := m.MovementStorer.Deposit(walletID, amount, description)
+	if err == nil {
+		m.cached.invalidateWallets(walletID)
+	}
+	return t, err
+}
+
+func (m *Movements) Withdraw(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	t, err := m.MovementStorer.Withdraw(walletID, amount, description)
+	if err == nil {
+		m.cached.invalidateWallets(walletID)
+	}
+	return t, err
+}
+
+func (m *Movements) Reverse(id int64, amount *float64, reason string, actor audit.Actor) (*transaction.Reversal, error) {
+	r, err := m.MovementStorer.Reverse(id, amount, reason, actor)
+	if err == nil {
+		var ids []int
+		for _, e := range r.Entries {
+			ids = append(ids, e.WalletID)
+		}
+		m.cached.invalidateWallets(ids...)
+	}
+	return r, err
+}
+
+// CreateHold, CaptureHold and VoidHold move the available balance.
+func (m *Movements) CreateHold(walletID int, r hold.Request) (*hold.Hold, error) {
+	h, err := m.MovementStorer.CreateHold(walletID, r)
+	if err == nil {
+		m.cached.invalidateWallets(walletID)
+	}
+	return h, err
+}
+
+func (m *Movements) CaptureHold(walletID int, id int64, c hold.Capture) (*hold.Hold, error) {
+	h, err := m.MovementStorer.CaptureHold(walletID, id, c)
+	if err == nil {
+		m.cached.invalidateWallets(walletID)
+	}
+	return h, err
+}
+
+func (m *Movements) VoidHold(walletID int, id int64) (*hold.Hold, error) {
+	h, err := m.MovementStorer.VoidHold(walletID, id)
+	if err == nil {
+		m.cached.invalidateWallets(walletID)
+	}
+	return h, err
+}
+
+func (m *Movements) CreateTransfer(r transfer.Request) (*transfer.Transfer, error) {
+	t, err := m.MovementStorer.CreateTransfer(r)
+	if err == nil {
+		m.cached.invalidateWallets(r.FromWalletID, r.ToWalletID)
+	}
+	return t, err
+}
+
+func (m *Movements) Spend(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	t, err := m.MovementStorer.Spend(walletID, amount, description)
+	if err == nil {
+		m.cached.invalidateWallets(walletID)
+	}
+	return t, err
+}
+
+func (m *Movements) Pay(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	t, err := m.MovementStorer.Pay(walletID, amount, description)
+	if err == nil {
+		m.cached.invalidateWallets(walletID)
+	}
+	return t, err
+}
+
+// RunStorer is what a schedule.Scheduler runs, and Transfer to find the
+// wallets of the transfer a run made.
+type RunStorer interface {
+	schedule.SchedulerStorer
+	Transfer(id int64) (*transfer.Transfer, error)
+}
+
+// Runs invalidates the wallets of the transfers the scheduled runs make.
+type Runs struct {
+	RunStorer
+	cached *Store
+}
+
+func NewRuns(db RunStorer, cached *Store) *Runs {
+	return &Runs{RunStorer: db, cached: cached}
+}
+
+func (r *Runs) RunSchedule(id int64, now time.Time) (*schedule.Execution, error) {
+	e, err := r.RunStorer.RunSchedule(id, now)
+	if err != nil || e == nil || e.TransferID == nil {
+		return e, err
+	}
+	t, terr := r.RunStorer.Transfer(*e.TransferID)
+	if terr != nil {
+		r.cached.invalidate(0)
+		return e, nil
+	}
+	r.cached.invalidateWallets(t.FromWalletID, t.ToWalletID)
+	return e, nil
+}
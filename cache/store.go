@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/user"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+type Storer interface {
+	wallet.Storer
+	user.Storer
+	// WalletsByIDs and WalletsByUserIDs read wallets for the gRPC and
+	// GraphQL services; they are not cached.
+	WalletsByIDs(ids []int) ([]wallet.Wallet, error)
+	WalletsByUserIDs(userIDs []int) ([]wallet.Wallet, error)
+}
+
+// Store caches the wallet lists and user summaries of a Storer. Its own
+// mutations, and the deposits, transfers and other movements made through
+// Movements and Runs, invalidate what they touch before returning;
+// mutations made by other replicas reach it through Notify and NotifyAll
+// like they reach the stream.Broker.
+type Store struct {
+	Storer
+	cache Cache
+	now   func() time.Time
+
+	// mu orders invalidations with fills: a fill started before an
+	// invalidation, seen by gen, must not store what it read.
+	mu       sync.Mutex
+	gen      uint64
+	modified time.Time
+	purged   time.Time
+	users    map[int]time.Time
+}
+
+func NewStore(db Storer, c Cache) *Store {
+	now := time.Now().UTC()
+	return &Store{Storer: db, cache: c, now: time.Now, modified: now, purged: now, users: map[int]time.Time{}}
+}
+
+const walletsPrefix = "wallets?"
+
+func walletsKey(walletType string) string {
+	return walletsPrefix + "type=" + walletType
+}
+
+func userPrefix(userID int) string {
+	return "users/" + strconv.Itoa(userID) + "/"
+}
+
+func (s *Store) Wallets(walletType string) ([]wallet.Wallet, error) {
+	return load(s, walletsKey(walletType), func() ([]wallet.Wallet, error) {
+		return s.Storer.Wallets(walletType)
+	})
+}
+
+func (s *Store) WalletsByUserID(userId int) ([]wallet.Wallet, error) {
+	return load(s, userPrefix(userId)+"wallets", func() ([]wallet.Wallet, error) {
+		return s.Storer.WalletsByUserID(userId)
+	})
+}
+
+func (s *Store) SummaryByUserID(userId int) (*user.Summary, error) {
+	return load(s, userPrefix(userId)+"summary", func() (*user.Summary, error) {
+		return s.Storer.SummaryByUserID(userId)
+	})
+}
+
+func (s *Store) CreateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	defer s.invalidate(w.UserID)
+	return s.Storer.CreateWallet(w, actor)
+}
+
+// UpdateWallet may move the wallet to another user, so it drops everything.
+func (s *Store) UpdateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	defer s.invalidate(0)
+	return s.Storer.UpdateWallet(w, actor)
+}
+
+func (s *Store) DeleteWallet(id int, actor audit.Actor) error {
+	defer s.invalidate(0)
+	return s.Storer.DeleteWallet(id, actor)
+}
+
+// ImportWallets invalidates even when it fails: a best-effort import may
+// have written some rows.
+func (s *Store) ImportWallets(wallets []wallet.Wallet, actor audit.Actor) (int, error) {
+	defer s.invalidate(0)
+	return s.Storer.ImportWallets(wallets, actor)
+}
+
+func (s *Store) ChangeStatus(id int, status, reason string, actor audit.Actor) (*wallet.Wallet, error) {
+	w, err := s.Storer.ChangeStatus(id, status, reason, actor)
+	if err != nil {
+		s.invalidate(0)
+		return nil, err
+	}
+	s.invalidate(w.UserID)
+	return w, nil
+}
+
+// UpdateWalletType renames the type of its wallets along with it.
+func (s *Store) UpdateWalletType(t wallet.Type) (*wallet.Type, error) {
+	defer s.invalidate(0)
+	return s.Storer.UpdateWalletType(t)
+}
+
+// Notify drops what is cached about the wallets of userID.
+func (s *Store) Notify(userID int) {
+	s.invalidate(userID)
+}
+
+// NotifyAll drops everything, for when notifications may have been lost.
+func (s *Store) NotifyAll() {
+	s.invalidate(0)
+}
+
+// Modified returns when the wallets of userID last changed, or of anyone
+// when userID is 0, as far as the store has seen: changes before it started
+// count as made when it started.
+func (s *Store) Modified(userID int) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if userID == 0 {
+		return s.modified
+	}
+	if t := s.users[userID]; t.After(s.purged) {
+		return t
+	}
+	return s.purged
+}
+
+// invalidate drops the lists of all wallets with what is cached about
+// userID, or everything when userID is 0.
+func (s *Store) invalidate(userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	s.modified = s.now().UTC()
+	if userID == 0 {
+		s.purged = s.modified
+		clear(s.users)
+		s.cache.DeletePrefix("")
+		return
+	}
+	s.users[userID] = s.modified
+	s.cache.DeletePrefix(walletsPrefix)
+	s.cache.DeletePrefix(userPrefix(userID))
+}
+
+// invalidateWallets drops what is cached about the users of the wallets
+// ids, or everything when they cannot be read.
+func (s *Store) invalidateWallets(ids ...int) {
+	wallets, err := s.Storer.WalletsByIDs(ids)
+	if err != nil {
+		s.invalidate(0)
+		return
+	}
+	if len(wallets) == 0 {
+		// The wallets are gone, but the lists may still hold them.
+		s.invalidate(0)
+		return
+	}
+	seen := map[int]bool{}
+	for _, w := range wallets {
+		if !seen[w.UserID] {
+			seen[w.UserID] = true
+			s.invalidate(w.UserID)
+		}
+	}
+}
+
+// load returns the value cached as key, or fills it from fill.
+func load[T any](s *Store, key string, fill func() (T, error)) (T, error) {
+	if b, ok := s.cache.Get(key); ok {
+		var v T
+		if err := json.Unmarshal(b, &v); err == nil {
+			return v, nil
+		}
+	}
+
+	s.mu.Lock()
+	gen := s.gen
+	s.mu.Unlock()
+	v, err := fill()
+	if err != nil {
+		return v, err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v, nil
+	}
+	s.mu.Lock()
+	if s.gen == gen {
+		s.cache.Set(key, b)
+	}
+	s.mu.Unlock()
+	return v, nil
+}
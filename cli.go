@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/interest"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/reconcile"
+)
+
+// runCommand runs a one-off subcommand instead of the server and returns the
+// process exit code.
+func runCommand(p *postgres.Postgres, args []string) int {
+	switch args[0] {
+	case "interest":
+		return runInterest(p, args[1:])
+	case "reconcile":
+		return runReconcile(p, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		return 2
+	}
+}
+
+// runInterest accrues interest for a range of days on the balance each of
+// them ended with, capitalizing every month of the range that is over. It is
+// used to backfill days the engine missed.
+//
+//	go run . interest -from 2024-03-01 -to 2024-03-31
+func runInterest(p *postgres.Postgres, args []string) int {
+	engine := interest.NewEngine(p, interest.SystemClock{})
+	yesterday := engine.Yesterday().Format(time.DateOnly)
+
+	fs := flag.NewFlagSet("interest", flag.ContinueOnError)
+	from := fs.String("from", yesterday, "first day to accrue (YYYY-MM-DD)")
+	to := fs.String("to", yesterday, "last day to accrue (YYYY-MM-DD)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	start, err := time.Parse(time.DateOnly, *from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -from: %v\n", err)
+		return 2
+	}
+	end, err := time.Parse(time.DateOnly, *to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -to: %v\n", err)
+		return 2
+	}
+	if end.Before(start) {
+		fmt.Fprintln(os.Stderr, "-to must not be before -from")
+		return 2
+	}
+
+	if err := engine.RunRange(start, end); err != nil {
+		fmt.Fprintf(os.Stderr, "interest: %v\n", err)
+		return 1
+	}
+	fmt.Printf("accrued interest from %s to %s\n", *from, *to)
+	return 0
+}
+
+// runReconcile imports a settlement file of the bank and prints what did not
+// match. It exits with 1 unless every line and posting matched.
+//
+//	go run . reconcile -format camt053 settlement-2026-09-01.xml
+func runReconcile(p *postgres.Postgres, args []string) int {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	format := fs.String("format", "", "file format, csv or camt053 (defaults to the file extension)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: reconcile [-format csv|camt053] FILE")
+		return 2
+	}
+
+	path := fs.Arg(0)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reconcile: %v\n", err)
+		return 2
+	}
+	name := filepath.Base(path)
+	if *format == "" {
+		*format = reconcile.FormatOf("", name)
+	}
+
+	r, err := reconcile.Import(p, name, *format, content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reconcile: %v\n", err)
+		return 1
+	}
+	fmt.Printf("file %d %s: %d matched, %d unmatched, %d mismatched, %d postings unmatched\n",
+		r.ID, r.Name, r.Matched, r.Unmatched, r.Mismatched, len(r.UnmatchedEntries))
+	for _, it := range r.Items {
+		if it.Status == reconcile.StatusUnmatched || it.Status == reconcile.StatusMismatched {
+			fmt.Printf("  %s item %d, line %d, %s %.2f %s: %s\n",
+				it.Status, it.ID, it.LineNo, it.BookedOn.Format(time.DateOnly), it.Amount, it.Reference, it.Reason)
+		}
+	}
+	for _, e := range r.UnmatchedEntries {
+		fmt.Printf("  unmatched posting entry %d, transaction %d, wallet %d, %s %.2f\n",
+			e.EntryID, e.TransactionID, e.WalletID, e.PostedAt.Format(time.DateOnly), e.Amount)
+	}
+	if !r.Clean() {
+		return 1
+	}
+	return 0
+}
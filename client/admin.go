@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/fee"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/interest"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/ledger"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/reconcile"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/report"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/webhook"
+)
+
+// The calls below need AdminToken.
+
+func (c *Client) AuditLogs(ctx context.Context, f audit.Filter) ([]audit.Record, error) {
+	q := queryOf("actor", f.Actor)
+	if f.WalletID != 0 {
+		q.Set("wallet_id", strconv.Itoa(f.WalletID))
+	}
+	if !f.From.IsZero() {
+		q.Set("from", f.From.Format(time.RFC3339))
+	}
+	if !f.To.IsZero() {
+		q.Set("to", f.To.Format(time.RFC3339))
+	}
+	var records []audit.Record
+	err := c.get(ctx, q, &records, "/api/v1/audit")
+	return records, err
+}
+
+func (c *Client) BalanceReport(ctx context.Context) ([]report.BalanceRow, error) {
+	var rows []report.BalanceRow
+	err := c.get(ctx, nil, &rows, "/api/v1/reports/balances")
+	return rows, err
+}
+
+func (c *Client) TrialBalance(ctx context.Context) (*ledger.TrialBalance, error) {
+	var tb ledger.TrialBalance
+	if err := c.get(ctx, nil, &tb, "/api/v1/ledger/trial-balance"); err != nil {
+		return nil, err
+	}
+	return &tb, nil
+}
+
+// CheckInvariant returns the report with a 500 *Error when the invariant
+// is violated.
+func (c *Client) CheckInvariant(ctx context.Context) (*ledger.Report, error) {
+	var r ledger.Report
+	err := c.get(ctx, nil, &r, "/api/v1/ledger/invariant")
+	var e *Error
+	if errors.As(err, &e) && e.StatusCode == http.StatusInternalServerError && e.Decode(&r) == nil && !r.OK() {
+		return &r, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ImportSettlement imports the settlement file r named name, in
+// reconcile.FormatCSV or reconcile.FormatCamt053, and returns its report.
+func (c *Client) ImportSettlement(ctx context.Context, r io.Reader, name, format string) (*reconcile.Report, error) {
+	contentType := "text/csv"
+	if format == reconcile.FormatCamt053 {
+		contentType = "application/xml"
+	}
+	var report reconcile.Report
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/reconciliations", query: queryOf("name", name, "format", format), body: r, contentType: contentType}, &report)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (c *Client) SettlementFiles(ctx context.Context) ([]reconcile.File, error) {
+	var files []reconcile.File
+	err := c.get(ctx, nil, &files, "/api/v1/reconciliations")
+	return files, err
+}
+
+func (c *Client) ReconciliationReport(ctx context.Context, fileID int64) (*reconcile.Report, error) {
+	var report reconcile.Report
+	if err := c.get(ctx, nil, &report, "/api/v1/reconciliations/%v", fileID); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (c *Client) ResolveItem(ctx context.Context, fileID, itemID int64, r reconcile.Resolution) (*reconcile.Item, error) {
+	var item reconcile.Item
+	if err := c.post(ctx, r, &item, "/api/v1/reconciliations/%v/items/%v/resolve", fileID, itemID); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (c *Client) Subscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	var subs []webhook.Subscription
+	err := c.get(ctx, nil, &subs, "/api/v1/webhooks")
+	return subs, err
+}
+
+func (c *Client) Subscription(ctx context.Context, id int) (*webhook.Subscription, error) {
+	var sub webhook.Subscription
+	if err := c.get(ctx, nil, &sub, "/api/v1/webhooks/%v", id); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (c *Client) CreateSubscription(ctx context.Context, s webhook.Subscription) (*webhook.Subscription, error) {
+	var created webhook.Subscription
+	if err := c.post(ctx, s, &created, "/api/v1/webhooks"); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateSubscription(ctx context.Context, id int, s webhook.Subscription) (*webhook.Subscription, error) {
+	var updated webhook.Subscription
+	if err := c.put(ctx, s, &updated, "/api/v1/webhooks/%v", id); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteSubscription(ctx context.Context, id int) error {
+	return c.del(ctx, "/api/v1/webhooks/%v", id)
+}
+
+func (c *Client) Deliveries(ctx context.Context, subscriptionID int) ([]webhook.Delivery, error) {
+	var deliveries []webhook.Delivery
+	err := c.get(ctx, nil, &deliveries, "/api/v1/webhooks/%v/deliveries", subscriptionID)
+	return deliveries, err
+}
+
+func (c *Client) Redeliver(ctx context.Context, subscriptionID, deliveryID int) (*webhook.Delivery, error) {
+	var d webhook.Delivery
+	if err := c.post(ctx, nil, &d, "/api/v1/webhooks/%v/deliveries/%v/redeliver", subscriptionID, deliveryID); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (c *Client) ProductRates(ctx context.Context) ([]interest.Rate, error) {
+	var rates []interest.Rate
+	err := c.get(ctx, nil, &rates, "/api/v1/interest/products")
+	return rates, err
+}
+
+func (c *Client) SaveProductRate(ctx context.Context, walletType string, r interest.Rate) (*interest.Rate, error) {
+	var saved interest.Rate
+	if err := c.put(ctx, r, &saved, "/api/v1/interest/products/%v", walletType); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (c *Client) TypeLimits(ctx context.Context, key string) (*limit.Limits, error) {
+	var l limit.Limits
+	if err := c.get(ctx, nil, &l, "/api/v1/wallet-types/%v/limits", key); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (c *Client) SaveTypeLimits(ctx context.Context, key string, l limit.Limits) (*limit.Limits, error) {
+	var saved limit.Limits
+	if err := c.put(ctx, l, &saved, "/api/v1/wallet-types/%v/limits", key); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (c *Client) TypeFees(ctx context.Context, key string) ([]fee.Rule, error) {
+	var rules []fee.Rule
+	err := c.get(ctx, nil, &rules, "/api/v1/wallet-types/%v/fees", key)
+	return rules, err
+}
+
+// SaveTypeFee saves the fee of operation, fee.OperationTransfer or
+// fee.OperationWithdrawal, on wallets of type key.
+func (c *Client) SaveTypeFee(ctx context.Context, key, operation string, r fee.Rule) (*fee.Rule, error) {
+	var saved fee.Rule
+	if err := c.put(ctx, r, &saved, "/api/v1/wallet-types/%v/fees/%v", key, operation); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (c *Client) DeleteTypeFee(ctx context.Context, key, operation string) error {
+	return c.del(ctx, "/api/v1/wallet-types/%v/fees/%v", key, operation)
+}
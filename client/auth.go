@@ -0,0 +1,51 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+)
+
+// Auth adds credentials to a request before it is sent, once per attempt.
+type Auth interface {
+	Apply(req *http.Request) error
+}
+
+// AuthFunc adapts a function to Auth, for schemes the package lacks.
+type AuthFunc func(req *http.Request) error
+
+func (f AuthFunc) Apply(req *http.Request) error {
+	return f(req)
+}
+
+// AdminToken authenticates to the admin routes.
+func AdminToken(token string) Auth {
+	return header(auth.HeaderAdminToken, token)
+}
+
+// Actor names the caller in the audit log. Nothing checks it, so it is
+// recorded as claimed, next to the admin the token authenticates or
+// anonymous.
+func Actor(id string) Auth {
+	return header(audit.HeaderActor, id)
+}
+
+// Chain applies each of auths in turn.
+func Chain(auths ...Auth) Auth {
+	return AuthFunc(func(req *http.Request) error {
+		for _, a := range auths {
+			if err := a.Apply(req); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func header(name, value string) Auth {
+	return AuthFunc(func(req *http.Request) error {
+		req.Header.Set(name, value)
+		return nil
+	})
+}
@@ -0,0 +1,271 @@
+// Package client is a typed client of the wallet API. Every endpoint has a
+// method taking a context and returning the types the handlers answer with.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the API at BaseURL. Its fields may be changed before the
+// first call.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Auth adds credentials to every request, if set.
+	Auth  Auth
+	Retry Retry
+}
+
+// New returns a client of the API at baseURL, such as
+// "http://localhost:1323", retrying with DefaultRetry.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Retry:      DefaultRetry,
+	}
+}
+
+// Retry is how idempotent calls, reads, updates and deletes, are retried
+// after a network error or a 429, 502, 503 or 504. Creates are never
+// retried, so a lost answer cannot create twice.
+type Retry struct {
+	// Attempts is the most calls made; 1 or less disables retries.
+	Attempts int
+	// Backoff is the wait before the second attempt, doubled for each
+	// attempt after it up to MaxBackoff, with jitter. A Retry-After
+	// header overrides it, within MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+var DefaultRetry = Retry{Attempts: 3, Backoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}
+
+// wait is the pause before attempt n, counting from 1, after res.
+func (r Retry) wait(n int, res *http.Response) time.Duration {
+	if res != nil {
+		if s, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && s >= 0 {
+			return r.cap(time.Duration(s) * time.Second)
+		}
+	}
+	d := r.cap(r.Backoff << (n - 1))
+	// Equal jitter: half fixed, half random, so clients spread out.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (r Retry) cap(d time.Duration) time.Duration {
+	if r.MaxBackoff > 0 && (d > r.MaxBackoff || d < 0) {
+		return r.MaxBackoff
+	}
+	return max(d, 0)
+}
+
+// Error is an answer outside 2xx. Message and Code are read from the
+// {"message", "code"} body every handler answers with; Body holds the
+// whole body for the errors that carry more, such as limit.Error.
+type Error struct {
+	StatusCode int
+	Message    string
+	Code       string
+	Body       []byte
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%d: %s", e.StatusCode, e.Message)
+}
+
+// Decode reads the body of e into v.
+func (e *Error) Decode(v any) error {
+	return json.Unmarshal(e.Body, v)
+}
+
+// StatusCode returns the status of an *Error in err's chain, or 0.
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+func decodeError(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	e := &Error{StatusCode: res.StatusCode, Body: body}
+	var msg struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	}
+	if json.Unmarshal(body, &msg) == nil && msg.Message != "" {
+		e.Message, e.Code = msg.Message, msg.Code
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+		if e.Message == "" {
+			e.Message = http.StatusText(res.StatusCode)
+		}
+	}
+	return e
+}
+
+// request is one call. A body is sent as JSON, or as is with contentType
+// when it is an io.Reader, which is never retried as it cannot be replayed.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        any
+	contentType string
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// send makes the call, retrying it when allowed, and returns a 2xx
+// response for the caller to read and close.
+func (c *Client) send(ctx context.Context, r request) (*http.Response, error) {
+	var payload []byte
+	var stream io.Reader
+	contentType := r.contentType
+	switch body := r.body.(type) {
+	case nil:
+	case io.Reader:
+		stream = body
+	default:
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+		contentType = "application/json"
+	}
+
+	attempts := 1
+	if idempotent(r.method) && stream == nil {
+		attempts = max(c.Retry.Attempts, 1)
+	}
+	target := c.BaseURL + r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	for n := 1; ; n++ {
+		var body io.Reader = stream
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, r.method, target, body)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range r.header {
+			req.Header[k] = v
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if req.Header.Get("Accept") == "" {
+			req.Header.Set("Accept", "application/json")
+		}
+		if c.Auth != nil {
+			if err := c.Auth.Apply(req); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := c.HTTPClient.Do(req)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return nil, ctx.Err()
+		case err == nil && res.StatusCode < 300:
+			return res, nil
+		case n >= attempts || (err == nil && !retryable(res.StatusCode)):
+			if err != nil {
+				return nil, err
+			}
+			defer res.Body.Close()
+			return nil, decodeError(res)
+		}
+
+		wait := c.Retry.wait(n, res)
+		if res != nil {
+			io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+			res.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// do makes the call and decodes the answer into out, unless out is nil.
+func (c *Client) do(ctx context.Context, r request, out any) error {
+	res, err := c.send(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s %s: %w", r.method, r.path, err)
+	}
+	return nil
+}
+
+// get, post, put and del are do for a method, with a path formatted from
+// format and args, each arg escaped as a path segment.
+func (c *Client) get(ctx context.Context, query url.Values, out any, format string, args ...any) error {
+	return c.do(ctx, request{method: http.MethodGet, path: path(format, args...), query: query}, out)
+}
+
+func (c *Client) post(ctx context.Context, body, out any, format string, args ...any) error {
+	return c.do(ctx, request{method: http.MethodPost, path: path(format, args...), body: body}, out)
+}
+
+func (c *Client) put(ctx context.Context, body, out any, format string, args ...any) error {
+	return c.do(ctx, request{method: http.MethodPut, path: path(format, args...), body: body}, out)
+}
+
+func (c *Client) del(ctx context.Context, format string, args ...any) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: path(format, args...)}, nil)
+}
+
+func path(format string, args ...any) string {
+	escaped := make([]any, len(args))
+	for i, a := range args {
+		escaped[i] = url.PathEscape(fmt.Sprint(a))
+	}
+	return fmt.Sprintf(format, escaped...)
+}
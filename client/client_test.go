@@ -0,0 +1,725 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/api"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apiv2"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/cache"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/fee"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/graph"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/hold"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/interest"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/internal/stubstore"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/openapi"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/reconcile"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/schedule"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/statement"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/stream"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transfer"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/webhook"
+	"github.com/labstack/echo/v4"
+)
+
+const adminToken = "secret"
+
+// routes records the routes requests reached, as echo routes them.
+type routes struct {
+	mu  sync.Mutex
+	hit map[string]bool
+}
+
+func (r *routes) add(route string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hit[route] = true
+}
+
+// serve runs every handler over stub, routed as main does and checked
+// against the OpenAPI document, so that a call the document does not allow
+// fails. The routes requests reached are added to hit, when not nil.
+func serve(t *testing.T, stub *stubstore.Store, hit *routes) *Client {
+	t.Helper()
+	doc, err := openapi.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := openapi.Validator(doc, openapi.Options{Responses: true, Drift: func(c echo.Context, err error) {
+		t.Errorf("drift from the OpenAPI document: %v", err)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if hit != nil {
+				hit.add(c.Request().Method + " " + c.Path())
+			}
+			return next(c)
+		}
+	})
+	e.Use(validator)
+	e.GET("/openapi.json", openapi.Serve)
+	api.Register(e, stub, cache.NewStore(stub, cache.None{}), stream.NewBroker(), api.Options{
+		AdminToken:    adminToken,
+		Currency:      apiv2.DefaultCurrency,
+		Heartbeat:     time.Second,
+		MaxComplexity: 500,
+		MaxDepth:      10,
+	})
+
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	c := New(srv.URL)
+	c.Retry = Retry{Attempts: 3}
+	return c
+}
+
+func TestWallets(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should list, create, update and delete wallets", func(t *testing.T) {
+		stub := stubstore.New()
+		c := serve(t, stub, nil)
+		c.Auth = Actor("ops@example.com")
+
+		created, err := c.CreateWallet(ctx, wallet.Wallet{UserID: 3, UserName: "Jim Doe", WalletName: "Jim Card", WalletType: "CreditCard", Balance: -5})
+		if err != nil || created.ID != 4 || created.WalletType != wallet.TypeCreditCard {
+			t.Fatalf("unexpected wallet %+v, %v", created, err)
+		}
+		if stub.Actor.ID != audit.AnonymousActor || stub.Actor.Claimed != "ops@example.com" {
+			t.Errorf("expected the actor to be sent as claimed but got %+v", stub.Actor)
+		}
+
+		created.Balance = 10
+		updated, err := c.UpdateWallet(ctx, created.ID, *created)
+		if err != nil || updated.Balance != 10 {
+			t.Fatalf("unexpected wallet %+v, %v", updated, err)
+		}
+
+		cards, err := c.Wallets(ctx, "CreditCard")
+		if err != nil || len(cards) != 2 {
+			t.Fatalf("expected 2 credit cards but got %v, %v", cards, err)
+		}
+
+		if err := c.DeleteWallet(ctx, created.ID); err != nil {
+			t.Fatal(err)
+		}
+		if wallets, _ := c.UserWallets(ctx, 3); len(wallets) != 0 {
+			t.Errorf("expected the wallet deleted but got %v", wallets)
+		}
+	})
+
+	t.Run("given a frozen wallet should decode the error", func(t *testing.T) {
+		c := serve(t, stubstore.New(), nil)
+
+		_, err := c.UpdateWallet(ctx, 2, wallet.Wallet{UserID: 2, UserName: "Jane Doe", WalletName: "Jane Card", WalletType: "CreditCard", Balance: 5})
+
+		var e *Error
+		if !errors.As(err, &e) || e.StatusCode != http.StatusConflict || e.Code != wallet.CodeWalletFrozen || e.Message == "" {
+			t.Errorf("unexpected error %#v", err)
+		}
+	})
+
+	t.Run("given an unknown wallet should return 404", func(t *testing.T) {
+		c := serve(t, stubstore.New(), nil)
+
+		err := c.DeleteWallet(ctx, 9)
+
+		if StatusCode(err) != http.StatusNotFound {
+			t.Errorf("expected 404 but got %v", err)
+		}
+		if _, err := c.UpdateWallet(ctx, 9, wallet.Wallet{WalletType: "Savings"}); StatusCode(err) != http.StatusNotFound {
+			t.Errorf("expected 404 but got %v", err)
+		}
+	})
+
+	t.Run("should import and export", func(t *testing.T) {
+		c := serve(t, stubstore.New(), nil)
+
+		report, err := c.ImportWallets(ctx, strings.NewReader("user_id,user_name,wallet_name,wallet_type,balance\n1,John Doe,Extra,Gold,5\n"), wallet.FormatCSV, wallet.ImportAtomic)
+		if StatusCode(err) != http.StatusUnprocessableEntity || report == nil || len(report.Errors) != 1 {
+			t.Errorf("expected the rejected report but got %+v, %v", report, err)
+		}
+
+		var buf bytes.Buffer
+		if err := c.ExportWallets(ctx, &buf, wallet.FormatNDJSON, ""); err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != 3 {
+			t.Errorf("expected 3 wallets but got %q", buf.String())
+		}
+	})
+
+	t.Run("should read v2", func(t *testing.T) {
+		c := serve(t, stubstore.New(), nil)
+
+		wallets, err := c.WalletsV2(ctx, "")
+		if err != nil || len(wallets) != 3 || wallets[1].Balance != "-20.00" || wallets[1].User.Name != "Jane Doe" {
+			t.Errorf("unexpected wallets %+v, %v", wallets, err)
+		}
+		_, err = c.CreateWalletV2(ctx, apiv2.WalletInput{Name: "Jim", Type: "Savings", Balance: "1.005"})
+		if StatusCode(err) != http.StatusBadRequest {
+			t.Errorf("expected 400 but got %v", err)
+		}
+	})
+}
+
+func TestAuth(t *testing.T) {
+	ctx := context.Background()
+	stub := stubstore.New()
+	c := serve(t, stub, nil)
+
+	if _, err := c.FreezeWallet(ctx, 1, "Suspected card testing"); StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("expected the call refused without a token but got %v", err)
+	}
+
+	c.Auth = Chain(AdminToken(adminToken), Actor("ops@example.com"))
+	w, err := c.FreezeWallet(ctx, 1, "Suspected card testing")
+	if err != nil || w.Status != wallet.StatusFrozen {
+		t.Errorf("unexpected wallet %+v, %v", w, err)
+	}
+	if stub.Actor.ID != auth.AdminActor || stub.Actor.Claimed != "ops@example.com" {
+		t.Errorf("expected the admin claiming to be ops@example.com but got %+v", stub.Actor)
+	}
+
+	c.Auth = AuthFunc(func(req *http.Request) error { return errors.New("no credentials") })
+	if _, err := c.WalletTypes(ctx); err == nil || err.Error() != "no credentials" {
+		t.Errorf("expected the auth error but got %v", err)
+	}
+}
+
+// check is err, or an error showing got when it is not what was expected.
+func check(err error, expected bool, got any) error {
+	if err != nil {
+		return err
+	}
+	if !expected {
+		return fmt.Errorf("unexpected %+v", got)
+	}
+	return nil
+}
+
+// TestOperations calls every method of the client, in order over one stub,
+// and checks that together they call every operation of the OpenAPI
+// document: a route added to the API without a client method fails here.
+func TestOperations(t *testing.T) {
+	ctx := context.Background()
+	stub := stubstore.New()
+	hit := &routes{hit: map[string]bool{}}
+	c := serve(t, stub, hit)
+	c.Auth = Chain(AdminToken(adminToken), Actor("ops@example.com"))
+
+	savings, crypto, card := stubstore.SavingsID, stubstore.CryptoID, stubstore.CardID
+	const spare = 4
+	john := wallet.Wallet{UserID: 1, UserName: "John Doe", WalletName: "John Savings", WalletType: "Savings", Balance: 100}
+	plan := schedule.Request{FromWalletID: savings, ToWalletID: crypto, Amount: 50, Description: "Savings plan",
+		Recurrence: "0 9 1 * *", OnInsufficientFunds: schedule.PolicyRetry, MaxRetries: 3, RetryDelay: "6h"}
+	rent := transfer.Request{FromWalletID: savings, ToWalletID: crypto, Amount: 50, Description: "Rent share"}
+	movement := transaction.Movement{Amount: 20, Description: "Cash"}
+	twenty := 20.0
+	called := map[string]bool{}
+
+	for _, tc := range []struct {
+		method string
+		// status is the status code of the error the call expects, if any.
+		status int
+		call   func() error
+	}{
+		{"OpenAPI", 0, func() error {
+			doc, err := c.OpenAPI(ctx)
+			return check(err, bytes.Contains(doc, []byte(`"openapi"`)), string(doc))
+		}},
+
+		{"Wallets", 0, func() error {
+			wallets, err := c.Wallets(ctx, "Savings")
+			return check(err, len(wallets) == 1 && wallets[0].ID == savings, wallets)
+		}},
+		{"CreateWallet", 0, func() error {
+			w, err := c.CreateWallet(ctx, wallet.Wallet{UserID: 2, UserName: "Jane Doe", WalletName: "Jane Spare Card", WalletType: "CreditCard", Balance: -5})
+			return check(err, w != nil && w.ID == spare, w)
+		}},
+		{"UpdateWallet", 0, func() error {
+			w, err := c.UpdateWallet(ctx, savings, john)
+			return check(err, w != nil && w.Balance == 100 && w.AvailableBalance == 75, w)
+		}},
+		{"ImportWallets", 0, func() error {
+			report, err := c.ImportWallets(ctx, strings.NewReader("user_id,user_name,wallet_name,wallet_type,balance\n5,Jim Doe,Jim Savings,Savings,10\n"), wallet.FormatCSV, wallet.ImportAtomic)
+			return check(err, report != nil && report.Inserted == 1, report)
+		}},
+		{"ExportWallets", 0, func() error {
+			var buf bytes.Buffer
+			err := c.ExportWallets(ctx, &buf, wallet.FormatCSV, "Savings")
+			return check(err, strings.Contains(buf.String(), "John Savings"), buf.String())
+		}},
+		{"WalletTypes", 0, func() error {
+			types, err := c.WalletTypes(ctx)
+			return check(err, len(types) == 3, types)
+		}},
+		{"CreateWalletType", 0, func() error {
+			wt, err := c.CreateWalletType(ctx, wallet.Type{Key: "Gold", Name: "Gold"})
+			return check(err, wt != nil && wt.Key == "Gold", wt)
+		}},
+		{"UpdateWalletType", 0, func() error {
+			maxBalance := 100000.0
+			wt, err := c.UpdateWalletType(ctx, "Savings", wallet.Type{Name: "Savings", MaxBalance: &maxBalance})
+			return check(err, wt != nil && wt.Key == "Savings", wt)
+		}},
+		{"FreezeWallet", 0, func() error {
+			w, err := c.FreezeWallet(ctx, crypto, "Suspected card testing")
+			return check(err, w != nil && w.Status == wallet.StatusFrozen, w)
+		}},
+		{"UnfreezeWallet", 0, func() error {
+			w, err := c.UnfreezeWallet(ctx, crypto, "Cleared by the customer")
+			return check(err, w != nil && w.Status == wallet.StatusActive, w)
+		}},
+		{"CloseWallet", 0, func() error {
+			w, err := c.CloseWallet(ctx, card, "Customer request")
+			return check(err, w != nil && w.Status == wallet.StatusClosed, w)
+		}},
+		{"StatusHistory", 0, func() error {
+			_, err := c.StatusHistory(ctx, card)
+			return err
+		}},
+
+		{"Transactions", 0, func() error {
+			txs, err := c.Transactions(ctx, savings)
+			return check(err, len(txs) > 0, txs)
+		}},
+		{"Deposit", 0, func() error {
+			tx, err := c.Deposit(ctx, savings, transaction.Movement{Amount: 50, Description: "Salary"})
+			return check(err, tx != nil && tx.Kind == transaction.KindDeposit && tx.BalanceAfter == 150, tx)
+		}},
+		{"Withdraw", 0, func() error {
+			tx, err := c.Withdraw(ctx, savings, movement)
+			return check(err, tx != nil && tx.Kind == transaction.KindWithdrawal && tx.BalanceAfter == 130, tx)
+		}},
+		{"ReverseTransaction", 0, func() error {
+			r, err := c.ReverseTransaction(ctx, stubstore.TransactionID, transaction.ReverseRequest{Amount: &twenty, Reason: "Deposit posted to the wrong wallet"})
+			return check(err, r != nil && len(r.Entries) == 1 && r.Entries[0].Amount == -20, r)
+		}},
+		{"Statement", 0, func() error {
+			var buf bytes.Buffer
+			err := c.Statement(ctx, &buf, savings, "2026-09", statement.FormatCSV)
+			return check(err, buf.Len() > 0, buf.String())
+		}},
+
+		{"Holds", 0, func() error {
+			holds, err := c.Holds(ctx, savings)
+			return check(err, len(holds) == 1, holds)
+		}},
+		{"CreateHold", 0, func() error {
+			h, err := c.CreateHold(ctx, savings, hold.Request{Amount: 25, Description: "Hotel booking"})
+			return check(err, h != nil && h.Amount == 25, h)
+		}},
+		{"CaptureHold", 0, func() error {
+			h, err := c.CaptureHold(ctx, savings, stubstore.HoldID, hold.Capture{Amount: &twenty, Description: "Hotel stay"})
+			return check(err, h != nil && h.Status == hold.StatusCaptured, h)
+		}},
+		{"VoidHold", 0, func() error {
+			h, err := c.VoidHold(ctx, savings, stubstore.HoldID)
+			return check(err, h != nil && h.Status == hold.StatusVoided, h)
+		}},
+
+		{"WalletLimits", 0, func() error {
+			_, err := c.WalletLimits(ctx, savings)
+			return err
+		}},
+		{"SaveWalletLimits", 0, func() error {
+			max := 500.0
+			l, err := c.SaveWalletLimits(ctx, savings, limit.Limits{MaxWithdrawal: &max})
+			return check(err, l != nil && l.Wallet.MaxWithdrawal != nil && *l.Wallet.MaxWithdrawal == 500, l)
+		}},
+		{"TypeLimits", 0, func() error {
+			_, err := c.TypeLimits(ctx, "Savings")
+			return err
+		}},
+		{"SaveTypeLimits", 0, func() error {
+			perHour := 5
+			l, err := c.SaveTypeLimits(ctx, "Savings", limit.Limits{MaxTransfersPerHour: &perHour})
+			return check(err, l != nil && l.MaxTransfersPerHour != nil && *l.MaxTransfersPerHour == 5, l)
+		}},
+
+		{"QuoteTransfer", 0, func() error {
+			q, err := c.QuoteTransfer(ctx, rent)
+			return check(err, q != nil && q.Amount == 50, q)
+		}},
+		{"CreateTransfer", 0, func() error {
+			tr, err := c.CreateTransfer(ctx, rent)
+			return check(err, tr != nil && tr.ToWalletID == crypto, tr)
+		}},
+		{"Transfer", 0, func() error {
+			tr, err := c.Transfer(ctx, stubstore.TransferID)
+			return check(err, tr != nil && tr.ID == stubstore.TransferID, tr)
+		}},
+
+		{"WalletSchedules", 0, func() error {
+			schedules, err := c.WalletSchedules(ctx, savings)
+			return check(err, len(schedules) == 1, schedules)
+		}},
+		{"CreateSchedule", 0, func() error {
+			sc, err := c.CreateSchedule(ctx, plan)
+			return check(err, sc != nil && sc.ToWalletID == crypto, sc)
+		}},
+		{"Schedule", 0, func() error {
+			sc, err := c.Schedule(ctx, stubstore.ScheduleID)
+			return check(err, sc != nil && sc.ID == stubstore.ScheduleID, sc)
+		}},
+		{"UpdateSchedule", 0, func() error {
+			sc, err := c.UpdateSchedule(ctx, stubstore.ScheduleID, plan)
+			return check(err, sc != nil && sc.Amount == 50, sc)
+		}},
+		{"PauseSchedule", 0, func() error {
+			sc, err := c.PauseSchedule(ctx, stubstore.ScheduleID)
+			return check(err, sc != nil && sc.Status == schedule.StatusPaused, sc)
+		}},
+		// The stub does not keep the pause, so the schedule is still active.
+		{"ResumeSchedule", http.StatusConflict, func() error {
+			_, err := c.ResumeSchedule(ctx, stubstore.ScheduleID)
+			return err
+		}},
+		{"ScheduleExecutions", 0, func() error {
+			executions, err := c.ScheduleExecutions(ctx, stubstore.ScheduleID)
+			return check(err, len(executions) > 0, executions)
+		}},
+		{"DeleteSchedule", 0, func() error {
+			return c.DeleteSchedule(ctx, stubstore.ScheduleID)
+		}},
+
+		{"CreditCard", 0, func() error {
+			a, err := c.CreditCard(ctx, spare)
+			return check(err, a != nil && a.Balance == 5, a)
+		}},
+		{"SaveCreditCard", 0, func() error {
+			a, err := c.SaveCreditCard(ctx, spare, creditcard.Terms{CreditLimit: 1000, APR: 18.99, StatementDay: 25, DueDay: 10})
+			return check(err, a != nil && a.AvailableCredit == 995, a)
+		}},
+		{"Spend", 0, func() error {
+			tx, err := c.Spend(ctx, spare, creditcard.Movement{Amount: 25.5, Description: "Coffee"})
+			return check(err, tx != nil && tx.Kind == transaction.KindSpend && tx.BalanceAfter == -30.5, tx)
+		}},
+		{"PayCreditCard", 0, func() error {
+			tx, err := c.PayCreditCard(ctx, spare, creditcard.Movement{Amount: 20, Description: "Repayment"})
+			return check(err, tx != nil && tx.Kind == transaction.KindPayment && tx.BalanceAfter == -10.5, tx)
+		}},
+		{"CreditCardStatements", 0, func() error {
+			_, err := c.CreditCardStatements(ctx, spare)
+			return err
+		}},
+
+		{"InterestHistory", 0, func() error {
+			h, err := c.InterestHistory(ctx, savings)
+			return check(err, h != nil, h)
+		}},
+		{"SaveWalletRate", 0, func() error {
+			r, err := c.SaveWalletRate(ctx, savings, interest.Rate{AnnualRate: 1.5, DayCount: "ACT/365"})
+			return check(err, r != nil && r.WalletID == savings, r)
+		}},
+		{"ProductRates", 0, func() error {
+			_, err := c.ProductRates(ctx)
+			return err
+		}},
+		{"SaveProductRate", 0, func() error {
+			r, err := c.SaveProductRate(ctx, "Savings", interest.Rate{AnnualRate: 1.25, DayCount: "30/360"})
+			return check(err, r != nil && r.WalletType == "Savings", r)
+		}},
+
+		{"UserWallets", 0, func() error {
+			wallets, err := c.UserWallets(ctx, 1)
+			return check(err, len(wallets) == 2, wallets)
+		}},
+		{"UserSummary", 0, func() error {
+			s, err := c.UserSummary(ctx, 1)
+			return check(err, s != nil && s.UserID == 1, s)
+		}},
+		{"StreamUserWallets", 0, func() error {
+			// The stream answers until the client leaves.
+			ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			err := c.StreamUserWallets(ctx, 1, 0, func(stream.Change) error { return nil })
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+			return fmt.Errorf("expected the stream to last but got %v", err)
+		}},
+
+		{"WalletsV2", 0, func() error {
+			wallets, err := c.WalletsV2(ctx, "")
+			return check(err, len(wallets) == 5, wallets)
+		}},
+		{"CreateWalletV2", 0, func() error {
+			w, err := c.CreateWalletV2(ctx, apiv2.WalletInput{Name: "Jane Savings", Type: "Savings", Balance: "5.00", User: apiv2.User{ID: 2, Name: "Jane Doe"}})
+			return check(err, w != nil && w.Balance == "5.00", w)
+		}},
+		{"UpdateWalletV2", 0, func() error {
+			w, err := c.UpdateWalletV2(ctx, savings, apiv2.WalletInput{Name: "John Savings", Type: "Savings", Balance: "130.00", User: apiv2.User{ID: 1, Name: "John Doe"}})
+			return check(err, w != nil && w.Balance == "130.00", w)
+		}},
+		{"UserWalletsV2", 0, func() error {
+			wallets, err := c.UserWalletsV2(ctx, 1)
+			return check(err, len(wallets) == 2, wallets)
+		}},
+		{"GraphQL", 0, func() error {
+			var data struct {
+				User struct {
+					Name string `json:"name"`
+				} `json:"user"`
+			}
+			_, err := c.GraphQL(ctx, graph.Request{Query: "{ user(id: 1) { name wallets { id } } }"}, &data)
+			return check(err, data.User.Name == "John Doe", data)
+		}},
+
+		{"AuditLogs", 0, func() error {
+			_, err := c.AuditLogs(ctx, audit.Filter{Actor: "ops@example.com", WalletID: savings})
+			return err
+		}},
+		{"BalanceReport", 0, func() error {
+			rows, err := c.BalanceReport(ctx)
+			return check(err, len(rows) > 0, rows)
+		}},
+		{"TrialBalance", 0, func() error {
+			_, err := c.TrialBalance(ctx)
+			return err
+		}},
+		{"CheckInvariant", 0, func() error {
+			_, err := c.CheckInvariant(ctx)
+			return err
+		}},
+
+		{"ImportSettlement", 0, func() error {
+			r, err := c.ImportSettlement(ctx, strings.NewReader("date,amount,reference,description\n2026-09-02,250.00,TX42,Top-up John Doe\n"), "settlement-2026-09-02.csv", reconcile.FormatCSV)
+			return check(err, r != nil && len(r.Items) == 1 && r.File.Unmatched == 1, r)
+		}},
+		{"SettlementFiles", 0, func() error {
+			files, err := c.SettlementFiles(ctx)
+			return check(err, len(files) == 1, files)
+		}},
+		{"ReconciliationReport", 0, func() error {
+			r, err := c.ReconciliationReport(ctx, stubstore.FileID)
+			return check(err, r != nil && r.File.ID == stubstore.FileID, r)
+		}},
+		{"ResolveItem", 0, func() error {
+			item, err := c.ResolveItem(ctx, stubstore.FileID, stubstore.ItemID, reconcile.Resolution{Note: "Bank fee, booked by hand"})
+			return check(err, item != nil && item.Status == reconcile.StatusResolved && item.ResolvedBy != nil && *item.ResolvedBy == auth.AdminActor, item)
+		}},
+
+		{"Subscriptions", 0, func() error {
+			_, err := c.Subscriptions(ctx)
+			return err
+		}},
+		{"CreateSubscription", 0, func() error {
+			s, err := c.CreateSubscription(ctx, webhook.Subscription{TargetURL: "https://partner.example.com/hooks/wallet", Events: []string{"WalletCreated"}})
+			return check(err, s != nil && s.Secret != "", s)
+		}},
+		{"Subscription", 0, func() error {
+			s, err := c.Subscription(ctx, stubstore.WebhookID)
+			return check(err, s != nil && s.ID == stubstore.WebhookID, s)
+		}},
+		{"UpdateSubscription", 0, func() error {
+			s, err := c.UpdateSubscription(ctx, stubstore.WebhookID, webhook.Subscription{TargetURL: "https://partner.example.com/hooks/v2", Events: []string{"BalanceChanged"}, Active: true})
+			return check(err, s != nil && s.TargetURL == "https://partner.example.com/hooks/v2", s)
+		}},
+		{"Deliveries", 0, func() error {
+			deliveries, err := c.Deliveries(ctx, stubstore.WebhookID)
+			return check(err, len(deliveries) > 0, deliveries)
+		}},
+		{"Redeliver", 0, func() error {
+			_, err := c.Redeliver(ctx, stubstore.WebhookID, stubstore.DeliveryID)
+			return err
+		}},
+		{"DeleteSubscription", 0, func() error {
+			return c.DeleteSubscription(ctx, stubstore.WebhookID)
+		}},
+
+		{"TypeFees", 0, func() error {
+			_, err := c.TypeFees(ctx, "Savings")
+			return err
+		}},
+		{"SaveTypeFee", 0, func() error {
+			min := 1.0
+			r, err := c.SaveTypeFee(ctx, "Savings", "transfer", fee.Rule{Flat: 0.5, Percent: 0.1, Min: &min})
+			return check(err, r != nil && r.WalletType == "Savings" && r.Operation == "transfer", r)
+		}},
+		{"DeleteTypeFee", 0, func() error {
+			return c.DeleteTypeFee(ctx, "Savings", "transfer")
+		}},
+
+		{"DeleteWallet", 0, func() error {
+			return c.DeleteWallet(ctx, crypto)
+		}},
+		// Built-in types cannot be deleted.
+		{"DeleteWalletType", http.StatusConflict, func() error {
+			return c.DeleteWalletType(ctx, "CryptoWallet")
+		}},
+		{"DeleteWalletV2", 0, func() error {
+			return c.DeleteWalletV2(ctx, spare)
+		}},
+	} {
+		err := tc.call()
+		if tc.status != 0 {
+			if StatusCode(err) != tc.status {
+				t.Errorf("%s: expected status code %d but got %v", tc.method, tc.status, err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: %v", tc.method, err)
+		}
+		called[tc.method] = true
+	}
+
+	client := reflect.TypeOf(c)
+	for i := 0; i < client.NumMethod(); i++ {
+		if name := client.Method(i).Name; !called[name] {
+			t.Errorf("%s is not called", name)
+		}
+	}
+
+	doc, err := openapi.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			route := method + " " + pathParam.ReplaceAllString(path, ":$1")
+			if !hit.hit[route] {
+				t.Errorf("%s is documented but no client method calls it", route)
+			}
+			delete(hit.hit, route)
+		}
+	}
+	for route := range hit.hit {
+		t.Errorf("%s is called but not documented", route)
+	}
+}
+
+// pathParam is a parameter of a documented path, {id}, which echo routes
+// as :id.
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// flaky answers 503 to the first failures calls, then calls next.
+func flaky(failures int32, next http.HandlerFunc) (http.HandlerFunc, *atomic.Int32) {
+	var calls atomic.Int32
+	return func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"message":"try again"}`, http.StatusServiceUnavailable)
+			return
+		}
+		next(w, r)
+	}, &calls
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[]`)
+	}
+
+	t.Run("given an idempotent call should retry until it succeeds", func(t *testing.T) {
+		h, calls := flaky(2, ok)
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+
+		_, err := New(srv.URL).Wallets(ctx, "")
+
+		if err != nil || calls.Load() != 3 {
+			t.Errorf("expected 3 calls and success but got %d, %v", calls.Load(), err)
+		}
+	})
+
+	t.Run("given attempts run out should return the last error", func(t *testing.T) {
+		h, calls := flaky(5, ok)
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+
+		_, err := New(srv.URL).WalletTypes(ctx)
+
+		var e *Error
+		if !errors.As(err, &e) || e.StatusCode != http.StatusServiceUnavailable || e.Message != "try again" || calls.Load() != 3 {
+			t.Errorf("unexpected %d calls, %v", calls.Load(), err)
+		}
+	})
+
+	t.Run("given a create should not retry", func(t *testing.T) {
+		h, calls := flaky(1, ok)
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+
+		_, err := New(srv.URL).CreateWallet(ctx, wallet.Wallet{})
+
+		if StatusCode(err) != http.StatusServiceUnavailable || calls.Load() != 1 {
+			t.Errorf("expected one call but got %d, %v", calls.Load(), err)
+		}
+	})
+
+	t.Run("given a cancelled context should stop waiting", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+		c := New(srv.URL)
+		c.Retry = Retry{Attempts: 5, Backoff: time.Hour, MaxBackoff: time.Hour}
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.Wallets(ctx, "")
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the deadline but got %v", err)
+		}
+	})
+
+	t.Run("should back off exponentially within the maximum", func(t *testing.T) {
+		r := Retry{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+		for n, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 40: 300 * time.Millisecond} {
+			if got := r.wait(n, nil); got < want/2 || got > want {
+				t.Errorf("attempt %d: expected a wait in [%v, %v] but got %v", n, want/2, want, got)
+			}
+		}
+	})
+}
+
+func TestStreamUserWallets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/users/1/wallets/stream" || r.Header.Get("Last-Event-ID") != "4" {
+			http.Error(w, `{"message":"unexpected request"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 5\nevent: BalanceChanged\ndata: {\"id\":1}\n\n: heartbeat\n\nid: 6\nevent: WalletDeleted\ndata: {\"id\":2}\n\n")
+	}))
+	defer srv.Close()
+
+	var changes []stream.Change
+	err := New(srv.URL).StreamUserWallets(context.Background(), 1, 4, func(ch stream.Change) error {
+		changes = append(changes, ch)
+		return nil
+	})
+
+	if err != nil || len(changes) != 2 {
+		t.Fatalf("expected 2 changes but got %+v, %v", changes, err)
+	}
+	if ch := changes[1]; ch.ID != 6 || ch.Type != "WalletDeleted" || string(ch.Wallet) != `{"id":2}` || ch.UserID != 1 {
+		t.Errorf("unexpected change %+v", ch)
+	}
+}
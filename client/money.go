@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"io"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/hold"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/interest"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/schedule"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transfer"
+)
+
+func (c *Client) Transactions(ctx context.Context, walletID int) ([]transaction.Transaction, error) {
+	var txs []transaction.Transaction
+	err := c.get(ctx, nil, &txs, "/api/v1/wallets/%v/transactions", walletID)
+	return txs, err
+}
+
+func (c *Client) Deposit(ctx context.Context, walletID int, m transaction.Movement) (*transaction.Transaction, error) {
+	return c.move(ctx, m, "/api/v1/wallets/%v/deposits", walletID)
+}
+
+// Withdraw refuses with a 422 *Error when a limit is exceeded; its Decode
+// reads the limit.Error.
+func (c *Client) Withdraw(ctx context.Context, walletID int, m transaction.Movement) (*transaction.Transaction, error) {
+	return c.move(ctx, m, "/api/v1/wallets/%v/withdrawals", walletID)
+}
+
+func (c *Client) move(ctx context.Context, body any, format string, args ...any) (*transaction.Transaction, error) {
+	var tx transaction.Transaction
+	if err := c.post(ctx, body, &tx, format, args...); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// ReverseTransaction needs AdminToken.
+func (c *Client) ReverseTransaction(ctx context.Context, id int64, r transaction.ReverseRequest) (*transaction.Reversal, error) {
+	var reversal transaction.Reversal
+	if err := c.post(ctx, r, &reversal, "/api/v1/transactions/%v/reverse", id); err != nil {
+		return nil, err
+	}
+	return &reversal, nil
+}
+
+func (c *Client) CreateTransfer(ctx context.Context, r transfer.Request) (*transfer.Transfer, error) {
+	var t transfer.Transfer
+	if err := c.post(ctx, r, &t, "/api/v1/transfers"); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (c *Client) QuoteTransfer(ctx context.Context, r transfer.Request) (*transfer.Quote, error) {
+	var q transfer.Quote
+	if err := c.post(ctx, r, &q, "/api/v1/transfers/quote"); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (c *Client) Transfer(ctx context.Context, id int64) (*transfer.Transfer, error) {
+	var t transfer.Transfer
+	if err := c.get(ctx, nil, &t, "/api/v1/transfers/%v", id); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (c *Client) Holds(ctx context.Context, walletID int) ([]hold.Hold, error) {
+	var holds []hold.Hold
+	err := c.get(ctx, nil, &holds, "/api/v1/wallets/%v/holds", walletID)
+	return holds, err
+}
+
+func (c *Client) CreateHold(ctx context.Context, walletID int, r hold.Request) (*hold.Hold, error) {
+	return c.hold(ctx, r, "/api/v1/wallets/%v/holds", walletID)
+}
+
+func (c *Client) CaptureHold(ctx context.Context, walletID int, holdID int64, r hold.Capture) (*hold.Hold, error) {
+	return c.hold(ctx, r, "/api/v1/wallets/%v/holds/%v/capture", walletID, holdID)
+}
+
+func (c *Client) VoidHold(ctx context.Context, walletID int, holdID int64) (*hold.Hold, error) {
+	return c.hold(ctx, nil, "/api/v1/wallets/%v/holds/%v/void", walletID, holdID)
+}
+
+func (c *Client) hold(ctx context.Context, body any, format string, args ...any) (*hold.Hold, error) {
+	var h hold.Hold
+	if err := c.post(ctx, body, &h, format, args...); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (c *Client) WalletSchedules(ctx context.Context, walletID int) ([]schedule.Schedule, error) {
+	var schedules []schedule.Schedule
+	err := c.get(ctx, nil, &schedules, "/api/v1/wallets/%v/scheduled-transfers", walletID)
+	return schedules, err
+}
+
+func (c *Client) CreateSchedule(ctx context.Context, r schedule.Request) (*schedule.Schedule, error) {
+	return c.schedule(ctx, c.post, r, "/api/v1/scheduled-transfers")
+}
+
+func (c *Client) Schedule(ctx context.Context, id int64) (*schedule.Schedule, error) {
+	var s schedule.Schedule
+	if err := c.get(ctx, nil, &s, "/api/v1/scheduled-transfers/%v", id); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (c *Client) UpdateSchedule(ctx context.Context, id int64, r schedule.Request) (*schedule.Schedule, error) {
+	return c.schedule(ctx, c.put, r, "/api/v1/scheduled-transfers/%v", id)
+}
+
+func (c *Client) DeleteSchedule(ctx context.Context, id int64) error {
+	return c.del(ctx, "/api/v1/scheduled-transfers/%v", id)
+}
+
+func (c *Client) PauseSchedule(ctx context.Context, id int64) (*schedule.Schedule, error) {
+	return c.schedule(ctx, c.post, nil, "/api/v1/scheduled-transfers/%v/pause", id)
+}
+
+func (c *Client) ResumeSchedule(ctx context.Context, id int64) (*schedule.Schedule, error) {
+	return c.schedule(ctx, c.post, nil, "/api/v1/scheduled-transfers/%v/resume", id)
+}
+
+func (c *Client) ScheduleExecutions(ctx context.Context, id int64) ([]schedule.Execution, error) {
+	var executions []schedule.Execution
+	err := c.get(ctx, nil, &executions, "/api/v1/scheduled-transfers/%v/executions", id)
+	return executions, err
+}
+
+type call func(ctx context.Context, body, out any, format string, args ...any) error
+
+func (c *Client) schedule(ctx context.Context, method call, body any, format string, args ...any) (*schedule.Schedule, error) {
+	var s schedule.Schedule
+	if err := method(ctx, body, &s, format, args...); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (c *Client) CreditCard(ctx context.Context, walletID int) (*creditcard.Account, error) {
+	var a creditcard.Account
+	if err := c.get(ctx, nil, &a, "/api/v1/wallets/%v/credit-card", walletID); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (c *Client) SaveCreditCard(ctx context.Context, walletID int, t creditcard.Terms) (*creditcard.Account, error) {
+	var a creditcard.Account
+	if err := c.put(ctx, t, &a, "/api/v1/wallets/%v/credit-card", walletID); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (c *Client) Spend(ctx context.Context, walletID int, m creditcard.Movement) (*transaction.Transaction, error) {
+	return c.move(ctx, m, "/api/v1/wallets/%v/credit-card/spend", walletID)
+}
+
+func (c *Client) PayCreditCard(ctx context.Context, walletID int, m creditcard.Movement) (*transaction.Transaction, error) {
+	return c.move(ctx, m, "/api/v1/wallets/%v/credit-card/payments", walletID)
+}
+
+func (c *Client) CreditCardStatements(ctx context.Context, walletID int) ([]creditcard.Statement, error) {
+	var statements []creditcard.Statement
+	err := c.get(ctx, nil, &statements, "/api/v1/wallets/%v/credit-card/statements", walletID)
+	return statements, err
+}
+
+func (c *Client) InterestHistory(ctx context.Context, walletID int) (*interest.History, error) {
+	var h interest.History
+	if err := c.get(ctx, nil, &h, "/api/v1/wallets/%v/interest", walletID); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// SaveWalletRate needs AdminToken.
+func (c *Client) SaveWalletRate(ctx context.Context, walletID int, r interest.Rate) (*interest.Rate, error) {
+	var saved interest.Rate
+	if err := c.put(ctx, r, &saved, "/api/v1/wallets/%v/interest/rate", walletID); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (c *Client) WalletLimits(ctx context.Context, walletID int) (*limit.WalletLimits, error) {
+	var l limit.WalletLimits
+	if err := c.get(ctx, nil, &l, "/api/v1/wallets/%v/limits", walletID); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (c *Client) SaveWalletLimits(ctx context.Context, walletID int, l limit.Limits) (*limit.WalletLimits, error) {
+	var saved limit.WalletLimits
+	if err := c.put(ctx, l, &saved, "/api/v1/wallets/%v/limits", walletID); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// Statement writes the statement of month, as YYYY-MM, to w in
+// statement.FormatPDF or statement.FormatCSV.
+func (c *Client) Statement(ctx context.Context, w io.Writer, walletID int, month, format string) error {
+	return c.download(ctx, w, queryOf("month", month, "format", format), "/api/v1/wallets/%v/statements", walletID)
+}
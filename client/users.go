@@ -0,0 +1,74 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/stream"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/user"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+func (c *Client) UserWallets(ctx context.Context, userID int) ([]wallet.Wallet, error) {
+	var wallets []wallet.Wallet
+	err := c.get(ctx, nil, &wallets, "/api/v1/users/%v/wallets", userID)
+	return wallets, err
+}
+
+func (c *Client) UserSummary(ctx context.Context, userID int) (*user.Summary, error) {
+	var summary user.Summary
+	if err := c.get(ctx, nil, &summary, "/api/v1/users/%v/summary", userID); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// StreamUserWallets calls fn with each change to the wallets of a user
+// after lastID, or after the latest when lastID is 0, until ctx is done,
+// the server ends the stream or fn returns an error, which it returns.
+// Resuming from the ID of the last change seen loses none.
+func (c *Client) StreamUserWallets(ctx context.Context, userID int, lastID int64, fn func(stream.Change) error) error {
+	header := http.Header{"Accept": {"text/event-stream"}}
+	if lastID > 0 {
+		header.Set("Last-Event-ID", strconv.FormatInt(lastID, 10))
+	}
+	res, err := c.send(ctx, request{method: http.MethodGet, path: path("/api/v1/users/%v/wallets/stream", userID), header: header})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// Events are "id", "event" and "data" lines ended by a blank line;
+	// lines starting with a colon are heartbeats.
+	ch := stream.Change{UserID: userID}
+	sc := bufio.NewScanner(res.Body)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		field, value, _ := strings.Cut(sc.Text(), ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ch.ID, _ = strconv.ParseInt(value, 10, 64)
+		case "event":
+			ch.Type = value
+		case "data":
+			ch.Wallet = json.RawMessage(value)
+		case "":
+			if ch.Type == "" && ch.Wallet == nil {
+				continue
+			}
+			if err := fn(ch); err != nil {
+				return err
+			}
+			ch = stream.Change{UserID: userID}
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return sc.Err()
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apiv2"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/graph"
+)
+
+// WalletsV2 lists the wallets in the v2 representation, of walletType only
+// unless it is empty.
+func (c *Client) WalletsV2(ctx context.Context, walletType string) ([]apiv2.Wallet, error) {
+	var list apiv2.WalletList
+	err := c.get(ctx, queryOf("type", walletType), &list, "/api/v2/wallets")
+	return list.Data, err
+}
+
+func (c *Client) CreateWalletV2(ctx context.Context, in apiv2.WalletInput) (*apiv2.Wallet, error) {
+	var created apiv2.Wallet
+	if err := c.post(ctx, in, &created, "/api/v2/wallets"); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateWalletV2(ctx context.Context, id int, in apiv2.WalletInput) (*apiv2.Wallet, error) {
+	var updated apiv2.Wallet
+	if err := c.put(ctx, in, &updated, "/api/v2/wallets/%v", id); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteWalletV2(ctx context.Context, id int) error {
+	return c.del(ctx, "/api/v2/wallets/%v", id)
+}
+
+func (c *Client) UserWalletsV2(ctx context.Context, userID int) ([]apiv2.Wallet, error) {
+	var list apiv2.WalletList
+	err := c.get(ctx, nil, &list, "/api/v2/users/%v/wallets", userID)
+	return list.Data, err
+}
+
+// GraphQL runs req and decodes its data into data, which may be nil. The
+// errors of a refused or partly failed operation are in the response,
+// which a refused one returns with its *Error.
+func (c *Client) GraphQL(ctx context.Context, req graph.Request, data any) (*graph.Response, error) {
+	res := graph.Response{Data: data}
+	err := c.post(ctx, req, &res, "/graphql")
+	var e *Error
+	if errors.As(err, &e) && (e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusMethodNotAllowed) && e.Decode(&res) == nil {
+		return &res, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// OpenAPI returns the OpenAPI document of the API.
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	var doc json.RawMessage
+	err := c.get(ctx, nil, &doc, "/openapi.json")
+	return doc, err
+}
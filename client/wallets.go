@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+// Wallets lists the wallets, of walletType only unless it is empty.
+func (c *Client) Wallets(ctx context.Context, walletType string) ([]wallet.Wallet, error) {
+	var wallets []wallet.Wallet
+	err := c.get(ctx, queryOf("wallet_type", walletType), &wallets, "/api/v1/wallets")
+	return wallets, err
+}
+
+func (c *Client) CreateWallet(ctx context.Context, w wallet.Wallet) (*wallet.Wallet, error) {
+	var created wallet.Wallet
+	if err := c.post(ctx, w, &created, "/api/v1/wallets"); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateWallet(ctx context.Context, id int, w wallet.Wallet) (*wallet.Wallet, error) {
+	var updated wallet.Wallet
+	if err := c.put(ctx, w, &updated, "/api/v1/wallets/%v", id); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteWallet(ctx context.Context, id int) error {
+	return c.del(ctx, "/api/v1/wallets/%v", id)
+}
+
+// ImportWallets imports the rows of r, in wallet.FormatCSV or
+// wallet.FormatNDJSON, in wallet.ImportAtomic or wallet.ImportBestEffort
+// mode. A rejected atomic import returns its report with the *Error.
+func (c *Client) ImportWallets(ctx context.Context, r io.Reader, format, mode string) (*wallet.ImportReport, error) {
+	contentType := "text/csv"
+	if format == wallet.FormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	q := queryOf("format", format, "mode", mode)
+	var report wallet.ImportReport
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/wallets/import", query: q, body: r, contentType: contentType}, &report)
+	var e *Error
+	if errors.As(err, &e) && e.StatusCode == http.StatusUnprocessableEntity && e.Decode(&report) == nil {
+		return &report, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ExportWallets writes the wallets, of walletType only unless it is empty,
+// to w in wallet.FormatCSV or wallet.FormatNDJSON.
+func (c *Client) ExportWallets(ctx context.Context, w io.Writer, format, walletType string) error {
+	return c.download(ctx, w, queryOf("format", format, "wallet_type", walletType), "/api/v1/wallets/export")
+}
+
+func (c *Client) FreezeWallet(ctx context.Context, id int, reason string) (*wallet.Wallet, error) {
+	return c.changeStatus(ctx, id, "freeze", reason)
+}
+
+func (c *Client) UnfreezeWallet(ctx context.Context, id int, reason string) (*wallet.Wallet, error) {
+	return c.changeStatus(ctx, id, "unfreeze", reason)
+}
+
+func (c *Client) CloseWallet(ctx context.Context, id int, reason string) (*wallet.Wallet, error) {
+	return c.changeStatus(ctx, id, "close", reason)
+}
+
+func (c *Client) changeStatus(ctx context.Context, id int, action, reason string) (*wallet.Wallet, error) {
+	var w wallet.Wallet
+	if err := c.post(ctx, wallet.StatusRequest{Reason: reason}, &w, "/api/v1/wallets/%v/%v", id, action); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (c *Client) StatusHistory(ctx context.Context, id int) ([]wallet.StatusChange, error) {
+	var history []wallet.StatusChange
+	err := c.get(ctx, nil, &history, "/api/v1/wallets/%v/status-history", id)
+	return history, err
+}
+
+func (c *Client) WalletTypes(ctx context.Context) ([]wallet.Type, error) {
+	var types []wallet.Type
+	err := c.get(ctx, nil, &types, "/api/v1/wallet-types")
+	return types, err
+}
+
+func (c *Client) CreateWalletType(ctx context.Context, t wallet.Type) (*wallet.Type, error) {
+	var created wallet.Type
+	if err := c.post(ctx, t, &created, "/api/v1/wallet-types"); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateWalletType(ctx context.Context, key string, t wallet.Type) (*wallet.Type, error) {
+	var updated wallet.Type
+	if err := c.put(ctx, t, &updated, "/api/v1/wallet-types/%v", key); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteWalletType(ctx context.Context, key string) error {
+	return c.del(ctx, "/api/v1/wallet-types/%v", key)
+}
+
+// download copies a GET answer that is not JSON to w.
+func (c *Client) download(ctx context.Context, w io.Writer, query url.Values, format string, args ...any) error {
+	res, err := c.send(ctx, request{method: http.MethodGet, path: path(format, args...), query: query, header: http.Header{"Accept": {"*/*"}}})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
+// queryOf builds a query from name and value pairs, leaving out empty
+// values.
+func queryOf(pairs ...string) url.Values {
+	q := url.Values{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			q.Set(pairs[i], pairs[i+1])
+		}
+	}
+	return q
+}
@@ -0,0 +1,131 @@
+package creditcard
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	ErrNotFound            = errors.New("credit card not found")
+	ErrNotCreditCard       = errors.New("wallet is not a credit card")
+	ErrCreditLimitExceeded = errors.New("credit limit exceeded")
+	ErrOverpayment         = errors.New("payment exceeds outstanding balance")
+)
+
+// MinimumPaymentFloor is the smallest minimum payment asked for, unless the
+// closing balance itself is lower.
+const MinimumPaymentFloor = 25.0
+
+// Account holds the credit card terms of a wallet of type Credit Card. The
+// wallet balance is the outstanding balance: what the holder owes.
+type Account struct {
+	WalletID        int       `json:"wallet_id" example:"2"`
+	CreditLimit     float64   `json:"credit_limit" example:"5000.00"`
+	Balance         float64   `json:"balance" example:"500.00"`
+	AvailableCredit float64   `json:"available_credit" example:"4500.00"`
+	APR             float64   `json:"apr" example:"18.99"`
+	StatementDay    int       `json:"statement_day" example:"25"`
+	DueDay          int       `json:"due_day" example:"10"`
+	CreatedAt       time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+type Statement struct {
+	ID             int       `json:"id" example:"1"`
+	WalletID       int       `json:"wallet_id" example:"2"`
+	PeriodStart    time.Time `json:"period_start" example:"2024-02-25T00:00:00Z"`
+	PeriodEnd      time.Time `json:"period_end" example:"2024-03-25T00:00:00Z"`
+	OpeningBalance float64   `json:"opening_balance" example:"400.00"`
+	Spend          float64   `json:"spend" example:"300.00"`
+	Payments       float64   `json:"payments" example:"200.00"`
+	Adjustments    float64   `json:"adjustments" example:"0.00"`
+	Interest       float64   `json:"interest" example:"3.17"`
+	ClosingBalance float64   `json:"closing_balance" example:"503.17"`
+	MinimumPayment float64   `json:"minimum_payment" example:"25.00"`
+	DueDate        time.Time `json:"due_date" example:"2024-04-10T00:00:00Z"`
+	CreatedAt      time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+// Activity sums the transactions of a statement period. Opening is the
+// balance when the period starts; Payments is positive.
+type Activity struct {
+	Opening     float64
+	Spend       float64
+	Payments    float64
+	Adjustments float64
+}
+
+// Terms are the fields of an Account a client may set.
+type Terms struct {
+	CreditLimit  float64 `json:"credit_limit" example:"5000.00"`
+	APR          float64 `json:"apr" example:"18.99"`
+	StatementDay int     `json:"statement_day" example:"25"`
+	DueDay       int     `json:"due_day" example:"10"`
+}
+
+func (t Terms) validate() string {
+	switch {
+	case t.CreditLimit <= 0:
+		return "Invalid credit limit"
+	case t.APR < 0 || t.APR > 100:
+		return "Invalid APR"
+	case t.StatementDay < 1 || t.StatementDay > 28:
+		return "Statement day must be between 1 and 28"
+	case t.DueDay < 1 || t.DueDay > 28:
+		return "Due day must be between 1 and 28"
+	}
+	return ""
+}
+
+// WithAvailableCredit fills in AvailableCredit from the limit and balance.
+func (a Account) WithAvailableCredit() Account {
+	a.AvailableCredit = math.Max(0, round(a.CreditLimit-a.Balance))
+	return a
+}
+
+// NewStatement closes the period [start, end). Interest is charged when the
+// previous statement was not paid off during this period, at APR/12 on what
+// is left of it. The minimum payment is 1% of the closing balance plus the
+// interest, but at least MinimumPaymentFloor.
+func NewStatement(a Account, prev *Statement, act Activity, start, end time.Time) Statement {
+	s := Statement{
+		WalletID:       a.WalletID,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		OpeningBalance: round(act.Opening),
+		Spend:          round(act.Spend),
+		Payments:       round(act.Payments),
+		Adjustments:    round(act.Adjustments),
+		DueDate:        DueDate(end, a.DueDay),
+	}
+
+	if prev != nil {
+		if unpaid := prev.ClosingBalance - act.Payments; unpaid > 0 {
+			s.Interest = round(unpaid * a.APR / 100 / 12)
+		}
+	}
+	s.ClosingBalance = round(s.OpeningBalance + s.Spend - s.Payments + s.Adjustments + s.Interest)
+
+	switch {
+	case s.ClosingBalance <= 0:
+		s.MinimumPayment = 0
+	case s.ClosingBalance <= MinimumPaymentFloor:
+		s.MinimumPayment = s.ClosingBalance
+	default:
+		s.MinimumPayment = math.Max(MinimumPaymentFloor, round(s.ClosingBalance*0.01+s.Interest))
+	}
+	return s
+}
+
+// DueDate is the first dueDay strictly after the statement date.
+func DueDate(statementDate time.Time, dueDay int) time.Time {
+	y, m, d := statementDate.Date()
+	if dueDay <= d {
+		m++
+	}
+	return time.Date(y, m, dueDay, 0, 0, 0, 0, statementDate.Location())
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
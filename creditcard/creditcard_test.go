@@ -0,0 +1,312 @@
+package creditcard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/labstack/echo/v4"
+)
+
+type StubCreditCardHandler struct {
+	accounts   map[int]*Account
+	statements []Statement
+	activity   Activity
+	closed     []Statement
+}
+
+func (s *StubCreditCardHandler) CreditCard(walletID int) (*Account, error) {
+	a, ok := s.accounts[walletID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return a, nil
+}
+
+func (s *StubCreditCardHandler) SaveCreditCard(walletID int, terms Terms) (*Account, error) {
+	a, err := s.CreditCard(walletID)
+	if err != nil {
+		return nil, ErrNotCreditCard
+	}
+	a.CreditLimit, a.APR, a.StatementDay, a.DueDay = terms.CreditLimit, terms.APR, terms.StatementDay, terms.DueDay
+	return a, nil
+}
+
+func (s *StubCreditCardHandler) Spend(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	a, err := s.CreditCard(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Balance+amount > a.CreditLimit {
+		return nil, ErrCreditLimitExceeded
+	}
+	a.Balance += amount
+	return &transaction.Transaction{WalletID: walletID, Kind: transaction.KindSpend, Amount: amount, BalanceAfter: a.Balance}, nil
+}
+
+func (s *StubCreditCardHandler) Pay(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	a, err := s.CreditCard(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if amount > a.Balance {
+		return nil, ErrOverpayment
+	}
+	a.Balance -= amount
+	return &transaction.Transaction{WalletID: walletID, Kind: transaction.KindPayment, Amount: -amount, BalanceAfter: a.Balance}, nil
+}
+
+func (s *StubCreditCardHandler) Statements(walletID int) ([]Statement, error) {
+	return s.statements, nil
+}
+
+func (s *StubCreditCardHandler) ActiveCreditCards() ([]Account, error) {
+	var accounts []Account
+	for _, a := range s.accounts {
+		accounts = append(accounts, *a)
+	}
+	return accounts, nil
+}
+
+func (s *StubCreditCardHandler) LastStatement(walletID int) (*Statement, error) {
+	for i := len(s.closed) - 1; i >= 0; i-- {
+		if s.closed[i].WalletID == walletID {
+			return &s.closed[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// closedOf returns the statements closed for walletID, oldest first.
+func (s *StubCreditCardHandler) closedOf(walletID int) []Statement {
+	var closed []Statement
+	for _, st := range s.closed {
+		if st.WalletID == walletID {
+			closed = append(closed, st)
+		}
+	}
+	return closed
+}
+
+func (s *StubCreditCardHandler) Activity(walletID int, from, to time.Time) (Activity, error) {
+	return s.activity, nil
+}
+
+func (s *StubCreditCardHandler) CloseStatement(st Statement) (*Statement, error) {
+	s.closed = append(s.closed, st)
+	return &st, nil
+}
+
+func request(body string, walletId string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(walletId)
+	return c, rec
+}
+
+func TestCreditCard(t *testing.T) {
+
+	t.Run("given spend within limit should increase outstanding balance", func(t *testing.T) {
+		c, rec := request(`{"amount": 200, "description": "Groceries"}`, "2")
+		stub := &StubCreditCardHandler{accounts: map[int]*Account{2: {WalletID: 2, CreditLimit: 1000, Balance: 500}}}
+
+		New(stub).Spend(c)
+
+		if rec.Code != http.StatusCreated {
+			t.Errorf("expected status code %d but got %d", http.StatusCreated, rec.Code)
+		}
+		resp := &transaction.Transaction{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		if resp.BalanceAfter != 700 {
+			t.Errorf("expected balance after %v but got %v", 700, resp.BalanceAfter)
+		}
+	})
+
+	t.Run("given spend over limit should return 422", func(t *testing.T) {
+		c, rec := request(`{"amount": 600}`, "2")
+		stub := &StubCreditCardHandler{accounts: map[int]*Account{2: {WalletID: 2, CreditLimit: 1000, Balance: 500}}}
+
+		New(stub).Spend(c)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+		resp := &Err{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		if resp.Message != ErrCreditLimitExceeded.Error() {
+			t.Errorf("expected message %s but got %s", ErrCreditLimitExceeded, resp.Message)
+		}
+	})
+
+	t.Run("given payment over outstanding balance should return 422", func(t *testing.T) {
+		c, rec := request(`{"amount": 600}`, "2")
+		stub := &StubCreditCardHandler{accounts: map[int]*Account{2: {WalletID: 2, CreditLimit: 1000, Balance: 500}}}
+
+		New(stub).Pay(c)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+	})
+
+	t.Run("given non positive amount should return 400", func(t *testing.T) {
+		c, rec := request(`{"amount": -5}`, "2")
+
+		New(&StubCreditCardHandler{}).Spend(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given terms for wallet that is not a credit card should return 422", func(t *testing.T) {
+		c, rec := request(`{"credit_limit": 1000, "apr": 20, "statement_day": 25, "due_day": 10}`, "1")
+
+		New(&StubCreditCardHandler{}).SaveCreditCard(c)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+	})
+
+	t.Run("given statement day out of range should return 400", func(t *testing.T) {
+		c, rec := request(`{"credit_limit": 1000, "apr": 20, "statement_day": 31, "due_day": 10}`, "2")
+
+		New(&StubCreditCardHandler{}).SaveCreditCard(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given credit card should return available credit", func(t *testing.T) {
+		c, rec := request("", "2")
+		stub := &StubCreditCardHandler{accounts: map[int]*Account{2: {WalletID: 2, CreditLimit: 1000, Balance: 1200}}}
+
+		New(stub).GetCreditCard(c)
+
+		resp := &Account{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		if resp.AvailableCredit != 0 {
+			t.Errorf("expected available credit %v but got %v", 0, resp.AvailableCredit)
+		}
+	})
+}
+
+func TestStatement(t *testing.T) {
+	account := Account{WalletID: 2, CreditLimit: 5000, APR: 24, StatementDay: 25, DueDay: 10}
+	start := time.Date(2024, 2, 25, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC)
+
+	t.Run("given first statement should not charge interest", func(t *testing.T) {
+		s := NewStatement(account, nil, Activity{Opening: 100, Spend: 400, Payments: 50}, start, end)
+
+		if s.Interest != 0 || s.ClosingBalance != 450 {
+			t.Errorf("expected closing %v without interest but got %+v", 450, s)
+		}
+		if s.MinimumPayment != MinimumPaymentFloor {
+			t.Errorf("expected minimum payment %v but got %v", MinimumPaymentFloor, s.MinimumPayment)
+		}
+		if want := time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC); !s.DueDate.Equal(want) {
+			t.Errorf("expected due date %v but got %v", want, s.DueDate)
+		}
+	})
+
+	t.Run("given previous statement not paid off should charge monthly interest on the rest", func(t *testing.T) {
+		prev := &Statement{ClosingBalance: 3000}
+		s := NewStatement(account, prev, Activity{Opening: 3000, Spend: 0, Payments: 1000}, start, end)
+
+		// 2000 * 24% / 12
+		if s.Interest != 40 {
+			t.Errorf("expected interest %v but got %v", 40, s.Interest)
+		}
+		if s.ClosingBalance != 2040 {
+			t.Errorf("expected closing balance %v but got %v", 2040, s.ClosingBalance)
+		}
+		// 1% of 2040 + 40
+		if s.MinimumPayment != 60.4 {
+			t.Errorf("expected minimum payment %v but got %v", 60.4, s.MinimumPayment)
+		}
+	})
+
+	t.Run("given previous statement paid in full should not charge interest", func(t *testing.T) {
+		prev := &Statement{ClosingBalance: 300}
+		s := NewStatement(account, prev, Activity{Opening: 300, Spend: 10, Payments: 300}, start, end)
+
+		if s.Interest != 0 || s.MinimumPayment != 10 {
+			t.Errorf("expected no interest and minimum %v but got %+v", 10, s)
+		}
+	})
+
+	t.Run("given due day before statement day should be due next month", func(t *testing.T) {
+		got := DueDate(time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), 10)
+		if want := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("expected due date %v but got %v", want, got)
+		}
+	})
+}
+
+func TestJob(t *testing.T) {
+	created := time.Date(2024, 1, 3, 8, 0, 0, 0, time.UTC)
+	stub := &StubCreditCardHandler{
+		accounts: map[int]*Account{
+			2: {WalletID: 2, CreditLimit: 5000, APR: 24, StatementDay: 25, DueDay: 10, CreatedAt: created},
+			5: {WalletID: 5, CreditLimit: 5000, APR: 24, StatementDay: 1, DueDay: 20, CreatedAt: created},
+		},
+		activity: Activity{Opening: 0, Spend: 100},
+	}
+	job := NewJob(stub)
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+
+	t.Run("given missed statement days should close every period that ended", func(t *testing.T) {
+		if err := job.RunDay(time.Date(2024, 3, 25, 13, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatal(err)
+		}
+
+		for walletID, ends := range map[int][]time.Time{
+			2: {day(1, 25), day(2, 25), day(3, 25)},
+			5: {day(2, 1), day(3, 1)},
+		} {
+			closed := stub.closedOf(walletID)
+			if len(closed) != len(ends) {
+				t.Fatalf("wallet %d: expected %d statements but got %v", walletID, len(ends), closed)
+			}
+			start := created
+			for i, s := range closed {
+				if !s.PeriodStart.Equal(start) || !s.PeriodEnd.Equal(ends[i]) {
+					t.Errorf("wallet %d: expected period [%v, %v) but got [%v, %v)", walletID, start, ends[i], s.PeriodStart, s.PeriodEnd)
+				}
+				start = s.PeriodEnd
+			}
+		}
+		// The second statement charges interest on the unpaid first one.
+		if got := stub.closedOf(2)[1].Interest; got != 2 {
+			t.Errorf("expected interest %v but got %v", 2, got)
+		}
+	})
+
+	t.Run("given a rerun on the same day should be a no-op", func(t *testing.T) {
+		job.RunDay(time.Date(2024, 3, 25, 20, 0, 0, 0, time.UTC))
+
+		if len(stub.closed) != 5 {
+			t.Errorf("expected 5 statements but got %d", len(stub.closed))
+		}
+	})
+
+	t.Run("given the next statement day should close only its period", func(t *testing.T) {
+		job.RunDay(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+
+		closed := stub.closedOf(5)
+		if len(stub.closed) != 6 || !closed[len(closed)-1].PeriodEnd.Equal(day(4, 1)) {
+			t.Errorf("expected one more statement for wallet 5 but got %v", stub.closed)
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package creditcard
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	CreditCard(walletID int) (*Account, error)
+	SaveCreditCard(walletID int, terms Terms) (*Account, error)
+	Spend(walletID int, amount float64, description string) (*transaction.Transaction, error)
+	Pay(walletID int, amount float64, description string) (*transaction.Transaction, error)
+	Statements(walletID int) ([]Statement, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+}
+
+type Movement struct {
+	Amount      float64 `json:"amount" example:"25.50"`
+	Description string  `json:"description" example:"Coffee"`
+}
+
+// GetCreditCard
+//
+//	@Summary		Get credit card
+//	@Description	Get credit card terms, outstanding balance and available credit of a wallet
+//	@Tags			credit card
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Account
+//	@Router			/api/v1/wallets/{id}/credit-card [get]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+func (h *Handler) GetCreditCard(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+
+	account, err := h.store.CreditCard(walletId)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, account.WithAvailableCredit())
+}
+
+// SaveCreditCard
+//
+//	@Summary		Set credit card terms
+//	@Description	Set credit limit, APR (percent), statement day and due day of a Credit Card wallet
+//	@Tags			credit card
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Account
+//	@Router			/api/v1/wallets/{id}/credit-card [put]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		422	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   terms  body		Terms	true	"Terms"
+func (h *Handler) SaveCreditCard(c echo.Context) error {
+	var t Terms
+	if err := c.Bind(&t); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+	if msg := t.validate(); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	account, err := h.store.SaveCreditCard(walletId, t)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, account.WithAvailableCredit())
+}
+
+// Spend
+//
+//	@Summary		Spend on credit card
+//	@Description	Increase the outstanding balance, up to the credit limit
+//	@Tags			credit card
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	transaction.Transaction
+//	@Router			/api/v1/wallets/{id}/credit-card/spend [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code wallet_frozen or wallet_closed"
+//	@Failure		422	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   spend  body		Movement	true	"Spend"
+func (h *Handler) Spend(c echo.Context) error {
+	return h.move(c, h.store.Spend)
+}
+
+// Pay
+//
+//	@Summary		Pay credit card
+//	@Description	Decrease the outstanding balance, at most down to zero
+//	@Tags			credit card
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	transaction.Transaction
+//	@Router			/api/v1/wallets/{id}/credit-card/payments [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code wallet_frozen or wallet_closed"
+//	@Failure		422	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   payment  body		Movement	true	"Payment"
+func (h *Handler) Pay(c echo.Context) error {
+	return h.move(c, h.store.Pay)
+}
+
+func (h *Handler) move(c echo.Context, post func(walletID int, amount float64, description string) (*transaction.Transaction, error)) error {
+	var m Movement
+	if err := c.Bind(&m); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+	amount := round(m.Amount)
+	if amount <= 0 || math.IsInf(amount, 0) {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid amount"})
+	}
+
+	t, err := post(walletId, amount, m.Description)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, t)
+}
+
+// GetStatements
+//
+//	@Summary		Get credit card statements
+//	@Description	Get the monthly statements of a credit card, newest first
+//	@Tags			credit card
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Statement
+//	@Router			/api/v1/wallets/{id}/credit-card/statements [get]
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+func (h *Handler) GetStatements(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+
+	statements, err := h.store.Statements(walletId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, statements)
+}
+
+func storeError(c echo.Context, err error) error {
+	var se *wallet.StatusError
+	var le *limit.Error
+	switch {
+	case errors.As(err, &le):
+		return c.JSON(http.StatusUnprocessableEntity, le)
+	case errors.As(err, &se):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error(), Code: se.Code()})
+	case errors.Is(err, ErrNotFound):
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	case errors.Is(err, ErrNotCreditCard), errors.Is(err, ErrCreditLimitExceeded), errors.Is(err, ErrOverpayment):
+		return c.JSON(http.StatusUnprocessableEntity, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
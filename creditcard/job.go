@@ -0,0 +1,90 @@
+package creditcard
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type JobStorer interface {
+	// ActiveCreditCards returns the accounts of active wallets.
+	ActiveCreditCards() ([]Account, error)
+	LastStatement(walletID int) (*Statement, error)
+	Activity(walletID int, from, to time.Time) (Activity, error)
+	// CloseStatement posts the statement interest to the wallet and saves
+	// the statement in one transaction.
+	CloseStatement(s Statement) (*Statement, error)
+}
+
+// Job generates the statements due each day and charges interest on them.
+// Running it again for a day that is already done is a no-op, and a run
+// after days the job missed closes every period that ended meanwhile.
+type Job struct {
+	store JobStorer
+	now   func() time.Time
+}
+
+func NewJob(store JobStorer) *Job {
+	return &Job{store: store, now: time.Now}
+}
+
+// Run runs the job for the current day every interval until ctx is done.
+func (j *Job) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := j.RunDay(j.now()); err != nil {
+			log.Printf("credit card job: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDay closes, oldest first, every period of every account that ended by
+// the day of t. Periods end at midnight UTC starting the statement day.
+func (j *Job) RunDay(t time.Time) error {
+	y, m, d := t.UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	accounts, err := j.store.ActiveCreditCards()
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		prev, err := j.store.LastStatement(a.WalletID)
+		if err != nil {
+			return err
+		}
+		start := a.CreatedAt
+		if prev != nil {
+			start = prev.PeriodEnd
+		}
+
+		for end := periodEnd(start, a.StatementDay); !end.After(today); end = periodEnd(start, a.StatementDay) {
+			act, err := j.store.Activity(a.WalletID, start, end)
+			if err != nil {
+				return err
+			}
+			if prev, err = j.store.CloseStatement(NewStatement(a, prev, act, start, end)); err != nil {
+				return err
+			}
+			start = end
+		}
+	}
+	return nil
+}
+
+// periodEnd is the first statementDay strictly after start, at midnight
+// UTC, when the period starting at start ends.
+func periodEnd(start time.Time, statementDay int) time.Time {
+	y, m, _ := start.UTC().Date()
+	end := time.Date(y, m, statementDay, 0, 0, 0, 0, time.UTC)
+	if !end.After(start) {
+		end = end.AddDate(0, 1, 0)
+	}
+	return end
+}
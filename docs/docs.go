@@ -15,9 +15,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/users/{user_id}/wallets": {
+        "/api/v1/audit": {
             "get": {
-                "description": "Get all wallets by user id",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get audit logs of wallet mutations, newest first. A bulk import is one record with action import and wallet id 0.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,43 +30,68 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "audit"
                 ],
-                "summary": "Get all wallets by user id",
+                "summary": "Get audit logs",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User id",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Actor, authenticated or claimed",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "wallet_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "From time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "To time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Wallet"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/audit.Record"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/user.Err"
+                            "$ref": "#/definitions/audit.Err"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/user.Err"
+                            "$ref": "#/definitions/audit.Err"
                         }
                     }
                 }
             }
         },
-        "/api/v1/wallets": {
+        "/api/v1/interest/products": {
             "get": {
-                "description": "Get all wallets",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get the interest rate of every wallet type earning interest",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,91 +99,271 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallet"
+                    "interest"
                 ],
-                "summary": "Get all wallets",
+                "summary": "Get product interest rates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/interest.Rate"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/interest.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/interest/products/{wallet_type}": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Set the interest rate of a wallet type",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "interest"
+                ],
+                "summary": "Set product interest rate",
                 "parameters": [
                     {
-                        "enum": [
-                            "Savings",
-                            "CreditCard",
-                            "CryptoWallet"
-                        ],
                         "type": "string",
-                        "description": "Wallet type",
+                        "description": "Wallet type key, see /api/v1/wallet-types",
                         "name": "wallet_type",
-                        "in": "query"
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rate",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/interest.Rate"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Wallet"
+                            "$ref": "#/definitions/interest.Rate"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Err"
+                            "$ref": "#/definitions/interest.Err"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Err"
+                            "$ref": "#/definitions/interest.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ledger/invariant": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Check that every wallet balance equals the sum of the postings to its account and that every entry balances",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ledger"
+                ],
+                "summary": "Check ledger invariant",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ledger.Report"
+                        }
+                    },
+                    "500": {
+                        "description": "the invariant is violated",
+                        "schema": {
+                            "$ref": "#/definitions/ledger.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ledger/trial-balance": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get the net of every ledger account on its debit or credit side. Wallet accounts are rolled up into the wallets and card_receivables control accounts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ledger"
+                ],
+                "summary": "Get trial balance",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ledger.TrialBalance"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ledger.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reconciliations": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get the imported settlement files with their item counts, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reconciliation"
+                ],
+                "summary": "Get settlement files",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/reconcile.File"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/reconcile.Err"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create new wallet",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Import a settlement file of the bank, as CSV (with a header row of date, amount and optionally reference, description) or ISO 20022 camt.053 XML, and match its lines against the postings to the settlement account by reference, or by amount and date.",
                 "consumes": [
-                    "application/json"
+                    "text/csv",
+                    "application/xml"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "wallet"
+                    "reconciliation"
                 ],
-                "summary": "Create new wallet",
+                "summary": "Import settlement file",
                 "parameters": [
                     {
-                        "description": "Wallet",
-                        "name": "wallet",
+                        "enum": [
+                            "csv",
+                            "camt053"
+                        ],
+                        "type": "string",
+                        "description": "File format, defaults to the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Settlement file",
+                        "name": "file",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/wallet.Wallet"
+                            "type": "string"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Wallet"
+                            "$ref": "#/definitions/reconcile.Report"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Err"
+                            "$ref": "#/definitions/reconcile.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/reconcile.Err"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Err"
+                            "$ref": "#/definitions/reconcile.Err"
                         }
                     }
                 }
             }
         },
-        "/api/v1/wallets/{id}": {
-            "put": {
-                "description": "Update wallet",
+        "/api/v1/reconciliations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get the matched, unmatched and mismatched items of a settlement file, and the postings of its period that no item matches",
                 "consumes": [
                     "application/json"
                 ],
@@ -161,50 +371,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallet"
+                    "reconciliation"
                 ],
-                "summary": "Update wallet",
+                "summary": "Get reconciliation report",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Wallet id",
+                        "description": "Settlement file ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Wallet",
-                        "name": "wallet",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/wallet.Wallet"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Wallet"
+                            "$ref": "#/definitions/reconcile.Report"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Err"
+                            "$ref": "#/definitions/reconcile.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/reconcile.Err"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Err"
+                            "$ref": "#/definitions/reconcile.Err"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete wallet",
+            }
+        },
+        "/api/v1/reconciliations/{id}/items/{itemId}/resolve": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Settle an unmatched or mismatched item by hand, against a posting to the settlement account or, without one, with a note. A mismatched item resolved without an entry keeps the posting its reference names.",
                 "consumes": [
                     "application/json"
                 ],
@@ -212,58 +426,5473 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallet"
+                    "reconciliation"
                 ],
-                "summary": "Delete wallet",
+                "summary": "Resolve settlement item",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Wallet id",
+                        "description": "Settlement file ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Settlement item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resolution",
+                        "name": "resolution",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reconcile.Resolution"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reconcile.Item"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Err"
+                            "$ref": "#/definitions/reconcile.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/reconcile.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/reconcile.Err"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Err"
+                            "$ref": "#/definitions/reconcile.Err"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "user.Err": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
         },
-        "wallet.Err": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
+        "/api/v1/reports/balances": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get wallet count and total balance grouped by wallet type and creation month",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report"
+                ],
+                "summary": "Get balance report",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/report.BalanceRow"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/report.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduled-transfers": {
+            "post": {
+                "description": "Create a standing order repeating a transfer on a cron expression (\"0 9 1 * *\") or an RRULE (\"FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9\"), in UTC",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Create scheduled transfer",
+                "parameters": [
+                    {
+                        "description": "Scheduled transfer",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduled-transfers/{id}": {
+            "get": {
+                "description": "Get a scheduled transfer and its next run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Get scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled transfer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace a scheduled transfer. Its next run is computed again from now; a paused schedule stays paused.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Update scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled transfer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Scheduled transfer",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a scheduled transfer and its execution history. Transfers it made are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Delete scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled transfer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduled-transfers/{id}/executions": {
+            "get": {
+                "description": "Get every run of a scheduled transfer, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Get execution history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled transfer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schedule.Execution"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduled-transfers/{id}/pause": {
+            "post": {
+                "description": "Stop running a scheduled transfer until it is resumed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Pause scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled transfer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduled-transfers/{id}/resume": {
+            "post": {
+                "description": "Run a paused scheduled transfer again from its next occurrence. Occurrences missed while paused are not made up.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Resume scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled transfer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/transactions/{id}/reverse": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Undo some or all of a posted transaction with compensating reversal entries, within the status, balance rules and spending limits of the wallets. Reversing a transfer reverses both legs. A transaction can be reversed in parts until nothing is left.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Reverse transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reversal",
+                        "name": "reversal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transaction.ReverseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Reversal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code already_reversed, wallet_frozen or wallet_closed",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "not reversible, more than is left, a spending limit was hit, or the balance would break the rules of the wallet type",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/transfers": {
+            "post": {
+                "description": "Move money from one wallet to another atomically, within the balance and spending limits of the source. The fee of the source wallet type is charged to the source on top of the amount. A transfer into a credit card pays it off.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Transfer between wallets",
+                "parameters": [
+                    {
+                        "description": "Transfer",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code wallet_frozen or wallet_closed",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "a spending limit was hit, or the balance would break the rules of the wallet type",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/transfers/quote": {
+            "post": {
+                "description": "Preview the fee a transfer would be charged now and the total leaving the source, without moving money",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Quote transfer",
+                "parameters": [
+                    {
+                        "description": "Transfer to quote",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Quote"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "the source is a credit card",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/transfers/{id}": {
+            "get": {
+                "description": "Get a transfer by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Get transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transfer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/summary": {
+            "get": {
+                "description": "Get total balance, wallet count and balance per wallet type of a user. Net worth counts credit card balances as liabilities.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get balance summary of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.Summary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/user.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/user.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/wallets": {
+            "get": {
+                "description": "Get all wallets by user id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get all wallets by user id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier answer",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wallet.Wallet"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the body"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the wallets last changed"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "If-None-Match names the current ETag"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/user.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/user.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/wallets/stream": {
+            "get": {
+                "description": "Server-Sent Events stream of wallet and balance changes of a user. Send Last-Event-ID to resume after a disconnect, within the retention of the change log.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Stream wallet changes of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Id of the last change received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stream.Change"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/stream.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/stream.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallet-types": {
+            "get": {
+                "description": "Get the wallet types of the registry and their balance rules",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet type"
+                ],
+                "summary": "Get wallet types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wallet.Type"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Add a wallet type to the registry. Wallets can use it right away.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet type"
+                ],
+                "summary": "Create wallet type",
+                "parameters": [
+                    {
+                        "description": "Wallet type",
+                        "name": "type",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Type"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Type"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallet-types/{key}": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Update the name and balance rules of a wallet type. Renaming it renames it on its wallets.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet type"
+                ],
+                "summary": "Update wallet type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet type key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Wallet type",
+                        "name": "type",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Type"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Type"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Delete a wallet type no wallet uses",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet type"
+                ],
+                "summary": "Delete wallet type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet type key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallet-types/{key}/fees": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get the fee rules of the wallets of a type, by operation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fee"
+                ],
+                "summary": "Get wallet type fees",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet type key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/fee.Rule"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/fee.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/fee.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallet-types/{key}/fees/{operation}": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Replace the fee charged on an operation of the wallets of a type. Tiers, when given, replace flat and percent by the amount moved.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fee"
+                ],
+                "summary": "Set wallet type fee",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet type key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "transfer or withdrawal",
+                        "name": "operation",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fee rule",
+                        "name": "fee",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/fee.Rule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/fee.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/fee.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/fee.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/fee.Err"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Stop charging a fee on an operation of the wallets of a type",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fee"
+                ],
+                "summary": "Delete wallet type fee",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet type key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "transfer or withdrawal",
+                        "name": "operation",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/fee.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/fee.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallet-types/{key}/limits": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get the default spending limits of the wallets of a type",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limit"
+                ],
+                "summary": "Get wallet type limits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet type key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Limits"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Replace the default spending limits of the wallets of a type",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limit"
+                ],
+                "summary": "Set wallet type limits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet type key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Limits",
+                        "name": "limits",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/limit.Limits"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Limits"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets": {
+            "get": {
+                "description": "Get all wallets",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Get all wallets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet type key, see /api/v1/wallet-types",
+                        "name": "wallet_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier answer",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wallet.Wallet"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the body"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the wallets last changed"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "If-None-Match names the current ETag"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create new wallet. Crypto wallets must carry crypto details: a supported asset, a deposit address valid for its chain\n(Bitcoin bech32 or base58, Ethereum with an EIP-55 checksum) and an amount within the decimals of the asset.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Create new wallet",
+                "parameters": [
+                    {
+                        "description": "Wallet",
+                        "name": "wallet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Wallet"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "balance breaks the rules of the wallet type",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/export": {
+            "get": {
+                "description": "Stream all wallets as CSV or NDJSON",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Export wallets",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Wallet type key, see /api/v1/wallet-types",
+                        "name": "wallet_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/import": {
+            "post": {
+                "description": "Import wallets from CSV (with a header row of user_id, user_name, wallet_name, wallet_type, balance, and asset, address, amount for crypto wallets) or NDJSON.\nCrypto wallets must carry crypto details, checked as on create, so they can be imported from NDJSON only.\nEvery row is validated first. In atomic mode nothing is inserted unless every row is valid; in best_effort mode valid rows are inserted and the others reported.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Import wallets",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Input format, defaults to the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "best_effort"
+                        ],
+                        "type": "string",
+                        "default": "atomic",
+                        "description": "Import mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Rows of wallets in the format",
+                        "name": "wallets",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "best_effort with rejected rows",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.ImportReport"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.ImportReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}": {
+            "put": {
+                "description": "Update wallet. The crypto details of a crypto wallet are kept when omitted, and required of a wallet becoming a crypto wallet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Update wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Wallet",
+                        "name": "wallet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Wallet"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code wallet_frozen or wallet_closed when the wallet is not active",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "balance breaks the rules of the wallet type",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete wallet. Frozen and closed wallets cannot be deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Delete wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code wallet_frozen or wallet_closed when the wallet is not active",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/close": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Close an active or frozen wallet for good",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Close wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.StatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code invalid_status_transition",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/credit-card": {
+            "get": {
+                "description": "Get credit card terms, outstanding balance and available credit of a wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "credit card"
+                ],
+                "summary": "Get credit card",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Set credit limit, APR (percent), statement day and due day of a Credit Card wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "credit card"
+                ],
+                "summary": "Set credit card terms",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Terms",
+                        "name": "terms",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Terms"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/credit-card/payments": {
+            "post": {
+                "description": "Decrease the outstanding balance, at most down to zero",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "credit card"
+                ],
+                "summary": "Pay credit card",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Movement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code wallet_frozen or wallet_closed",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/credit-card/spend": {
+            "post": {
+                "description": "Increase the outstanding balance, up to the credit limit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "credit card"
+                ],
+                "summary": "Spend on credit card",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Spend",
+                        "name": "spend",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Movement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code wallet_frozen or wallet_closed",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/credit-card/statements": {
+            "get": {
+                "description": "Get the monthly statements of a credit card, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "credit card"
+                ],
+                "summary": "Get credit card statements",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/creditcard.Statement"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/creditcard.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/deposits": {
+            "post": {
+                "description": "Add money to a wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Deposit into wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Deposit",
+                        "name": "deposit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Movement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code wallet_frozen or wallet_closed",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/freeze": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Freeze an active wallet. Its balance cannot change until it is unfrozen.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Freeze wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.StatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code invalid_status_transition",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/holds": {
+            "get": {
+                "description": "Get the holds of a wallet, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hold"
+                ],
+                "summary": "Get holds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hold.Hold"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Reserve funds of a wallet until captured, voided or expired. The amount comes off the available balance and counts against the spending limits now.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hold"
+                ],
+                "summary": "Place hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Hold, expiring in 7 days unless expires_at is given",
+                        "name": "hold",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hold.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code wallet_frozen or wallet_closed",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "insufficient available balance, or a spending limit was hit",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/holds/{holdId}/capture": {
+            "post": {
+                "description": "Take some or all of the held funds off the ledger balance as a capture transaction and release the rest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hold"
+                ],
+                "summary": "Capture hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Hold id",
+                        "name": "holdId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Capture, everything held by default",
+                        "name": "capture",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Capture"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code hold_captured, hold_voided or hold_expired, or wallet_frozen or wallet_closed",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "capture exceeds the held amount",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/holds/{holdId}/void": {
+            "post": {
+                "description": "Release the held funds without taking them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hold"
+                ],
+                "summary": "Void hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Hold id",
+                        "name": "holdId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code hold_captured, hold_voided or hold_expired",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/interest": {
+            "get": {
+                "description": "Get the effective rate, daily accruals and monthly postings of a wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "interest"
+                ],
+                "summary": "Get interest history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/interest.History"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/interest.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/interest.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/interest/rate": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Override the product interest rate of one wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "interest"
+                ],
+                "summary": "Set wallet interest rate",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rate",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/interest.Rate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/interest.Rate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/interest.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/interest.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/interest.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/limits": {
+            "get": {
+                "description": "Get the spending limits of a wallet, those of its type, the effective ones and the current usage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limit"
+                ],
+                "summary": "Get wallet limits",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/limit.WalletLimits"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the limits of a wallet. Omitted limits fall back to those of its type.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limit"
+                ],
+                "summary": "Set wallet limits",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Limits",
+                        "name": "limits",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/limit.Limits"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/limit.WalletLimits"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/scheduled-transfers": {
+            "get": {
+                "description": "Get the scheduled transfers from or to a wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Get scheduled transfers of a wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schedule.Schedule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/statements": {
+            "get": {
+                "description": "Get the monthly statement of a wallet, with opening balance, itemized transactions and closing balance, as a PDF or CSV download. The current month gives a statement to date.",
+                "produces": [
+                    "application/pdf",
+                    "text/csv",
+                    "application/json"
+                ],
+                "tags": [
+                    "statement"
+                ],
+                "summary": "Get wallet statement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Month, YYYY-MM",
+                        "name": "month",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pdf (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/statement.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/statement.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/statement.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/status-history": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get every status change of a wallet with its reason and actor, latest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Get wallet status history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wallet.StatusChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/transactions": {
+            "get": {
+                "description": "Get every transaction posted to a wallet, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Get wallet transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/transaction.Transaction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/unfreeze": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Make a frozen wallet active again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Unfreeze wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.StatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code invalid_status_transition",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallets/{id}/withdrawals": {
+            "post": {
+                "description": "Take money out of a wallet, within its balance and spending limits. The withdrawal fee of its wallet type is posted as a separate fee transaction.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Withdraw from wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Withdrawal",
+                        "name": "withdrawal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Movement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code wallet_frozen or wallet_closed",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "a spending limit was hit, or the balance would break the rules of the wallet type",
+                        "schema": {
+                            "$ref": "#/definitions/limit.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transaction.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get all webhook subscriptions. Secrets are never returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Get all webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhook.Subscription"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Create webhook subscription. A secret is generated when none is given and is only returned here.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Create webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Subscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get webhook subscription. The secret is never returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Get webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Update webhook subscription. An empty secret keeps the current one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Update webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Subscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Delete webhook subscription and its delivery history",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Delete webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get delivery history of a webhook subscription, newest first, each with its attempts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Get delivery history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhook.Delivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}/deliveries/{deliveryId}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Queue a delivery to be sent again right away, including dead ones, with a new round of retries. Earlier attempts stay in its history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Redeliver webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery id",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Delivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/users/{id}/wallets": {
+            "get": {
+                "description": "Get all wallets of a user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Get all wallets by user id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier answer",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.WalletList"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the body"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the wallets last changed"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "If-None-Match names the current ETag"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/wallets": {
+            "get": {
+                "description": "Get all wallets, with string money, the currency and the owner as a nested user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Get all wallets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet type key or name, see /api/v1/wallet-types",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier answer",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.WalletList"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the body"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the wallets last changed"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "If-None-Match names the current ETag"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create new wallet. Crypto wallets must carry crypto details, as in /api/v1/wallets.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Create new wallet",
+                "parameters": [
+                    {
+                        "description": "Wallet",
+                        "name": "wallet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.WalletInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "balance breaks the rules of the wallet type",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/wallets/{id}": {
+            "put": {
+                "description": "Update wallet. The crypto details of a crypto wallet are kept when omitted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Update wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Wallet",
+                        "name": "wallet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.WalletInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code wallet_frozen or wallet_closed when the wallet is not active",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "422": {
+                        "description": "balance breaks the rules of the wallet type",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete wallet",
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Delete wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "409": {
+                        "description": "code wallet_frozen or wallet_closed when the wallet is not active",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiv2.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "description": "Query users and wallets and change wallets with GraphQL. Queries may also be sent with GET and the request fields as query parameters; mutations may not.\nOperations costing more than the configured complexity are refused: every field costs one, and fields under a list ten times as much.\nSo are operations nesting fields deeper than the configured depth.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "GraphQL",
+                "parameters": [
+                    {
+                        "description": "GraphQL request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/graph.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/graph.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/graph.Response"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/graph.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/openapi.json": {
+            "get": {
+                "description": "Get the API as an OpenAPI 3.1 document",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meta"
+                ],
+                "summary": "Get the OpenAPI document",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/openapi.Err"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apiv2.Crypto": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+                },
+                "amount": {
+                    "type": "string",
+                    "example": "0.00000001"
+                },
+                "asset": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "decimals": {
+                    "type": "integer",
+                    "example": 8
+                }
+            }
+        },
+        "apiv2.CryptoInput": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+                },
+                "amount": {
+                    "type": "string",
+                    "example": "0.00000001"
+                },
+                "asset": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "apiv2.Err": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "apiv2.User": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                }
+            }
+        },
+        "apiv2.Wallet": {
+            "type": "object",
+            "properties": {
+                "available_balance": {
+                    "type": "string",
+                    "example": "75.00"
+                },
+                "balance": {
+                    "type": "string",
+                    "example": "100.00"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "crypto": {
+                    "$ref": "#/definitions/apiv2.Crypto"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "THB"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John's Wallet"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Savings"
+                },
+                "user": {
+                    "$ref": "#/definitions/apiv2.User"
+                }
+            }
+        },
+        "apiv2.WalletInput": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "string",
+                    "example": "100.00"
+                },
+                "crypto": {
+                    "$ref": "#/definitions/apiv2.CryptoInput"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John's Wallet"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Savings"
+                },
+                "user": {
+                    "$ref": "#/definitions/apiv2.User"
+                }
+            }
+        },
+        "apiv2.WalletList": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apiv2.Wallet"
+                    }
+                }
+            }
+        },
+        "audit.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "audit.Record": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "claimed": {
+                    "type": "string",
+                    "example": "ops@example.com"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "diff": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "127.0.0.1"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "kTRhvBdvRbSSL1gNNRrbxTGYHDAKDCyB"
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "creditcard.Account": {
+            "type": "object",
+            "properties": {
+                "apr": {
+                    "type": "number",
+                    "example": 18.99
+                },
+                "available_credit": {
+                    "type": "number",
+                    "example": 4500
+                },
+                "balance": {
+                    "type": "number",
+                    "example": 500
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "credit_limit": {
+                    "type": "number",
+                    "example": 5000
+                },
+                "due_day": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "statement_day": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "creditcard.Err": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "creditcard.Movement": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 25.5
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Coffee"
+                }
+            }
+        },
+        "creditcard.Statement": {
+            "type": "object",
+            "properties": {
+                "adjustments": {
+                    "type": "number",
+                    "example": 0
+                },
+                "closing_balance": {
+                    "type": "number",
+                    "example": 503.17
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "due_date": {
+                    "type": "string",
+                    "example": "2024-04-10T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "interest": {
+                    "type": "number",
+                    "example": 3.17
+                },
+                "minimum_payment": {
+                    "type": "number",
+                    "example": 25
+                },
+                "opening_balance": {
+                    "type": "number",
+                    "example": 400
+                },
+                "payments": {
+                    "type": "number",
+                    "example": 200
+                },
+                "period_end": {
+                    "type": "string",
+                    "example": "2024-03-25T00:00:00Z"
+                },
+                "period_start": {
+                    "type": "string",
+                    "example": "2024-02-25T00:00:00Z"
+                },
+                "spend": {
+                    "type": "number",
+                    "example": 300
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "creditcard.Terms": {
+            "type": "object",
+            "properties": {
+                "apr": {
+                    "type": "number",
+                    "example": 18.99
+                },
+                "credit_limit": {
+                    "type": "number",
+                    "example": 5000
+                },
+                "due_day": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "statement_day": {
+                    "type": "integer",
+                    "example": 25
+                }
+            }
+        },
+        "fee.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "fee.Rule": {
+            "type": "object",
+            "properties": {
+                "flat": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "max": {
+                    "type": "number",
+                    "example": 25
+                },
+                "min": {
+                    "type": "number",
+                    "example": 1
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "transfer"
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 0.1
+                },
+                "tiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fee.Tier"
+                    }
+                },
+                "wallet_type": {
+                    "type": "string",
+                    "example": "Savings"
+                }
+            }
+        },
+        "fee.Tier": {
+            "type": "object",
+            "properties": {
+                "flat": {
+                    "type": "number",
+                    "example": 1
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "up_to": {
+                    "type": "number",
+                    "example": 1000
+                }
+            }
+        },
+        "graph.Request": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "{ user(id: 1) { name wallets { id walletName balance } } }"
+                },
+                "variables": {
+                    "type": "object"
+                }
+            }
+        },
+        "graph.Response": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "hold.Capture": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 20
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Hotel stay"
+                }
+            }
+        },
+        "hold.Err": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "hold.Hold": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 25
+                },
+                "captured_amount": {
+                    "type": "number",
+                    "example": 20
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Hotel booking"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-04-01T14:19:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "resolved_at": {
+                    "type": "string",
+                    "example": "2024-03-26T09:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "transaction_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "hold.Request": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 25
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Hotel booking"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-04-01T14:19:00Z"
+                }
+            }
+        },
+        "interest.Accrual": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 0.034247
+                },
+                "annual_rate": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "balance": {
+                    "type": "number",
+                    "example": 1000
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-03-25T00:00:00Z"
+                },
+                "day_count": {
+                    "type": "string",
+                    "example": "ACT/365"
+                },
+                "posted_at": {
+                    "type": "string",
+                    "example": "2024-04-01T00:00:00Z"
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "interest.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "interest.History": {
+            "type": "object",
+            "properties": {
+                "accruals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/interest.Accrual"
+                    }
+                },
+                "accrued": {
+                    "type": "number",
+                    "example": 0.82
+                },
+                "postings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/interest.Posting"
+                    }
+                },
+                "rate": {
+                    "$ref": "#/definitions/interest.Rate"
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "interest.Posting": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 1.06
+                },
+                "month": {
+                    "type": "string",
+                    "example": "2024-03"
+                },
+                "posted_at": {
+                    "type": "string",
+                    "example": "2024-04-01T00:00:00Z"
+                },
+                "transaction_id": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "interest.Rate": {
+            "type": "object",
+            "properties": {
+                "annual_rate": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "day_count": {
+                    "type": "string",
+                    "example": "ACT/365"
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "wallet_type": {
+                    "type": "string",
+                    "example": "Savings"
+                }
+            }
+        },
+        "ledger.AccountBalance": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "settlement"
+                },
+                "credit": {
+                    "type": "number",
+                    "example": 0
+                },
+                "debit": {
+                    "type": "number",
+                    "example": 3500
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Settlement"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "asset"
+                }
+            }
+        },
+        "ledger.Divergence": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number",
+                    "example": 1000
+                },
+                "ledger": {
+                    "type": "number",
+                    "example": 900
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "ledger.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "ledger.Report": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00Z"
+                },
+                "divergences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ledger.Divergence"
+                    }
+                },
+                "unbalanced_entries": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "ledger.TrialBalance": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ledger.AccountBalance"
+                    }
+                },
+                "as_of": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00Z"
+                },
+                "balanced": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "total_credit": {
+                    "type": "number",
+                    "example": 3500
+                },
+                "total_debit": {
+                    "type": "number",
+                    "example": 3500
+                }
+            }
+        },
+        "limit.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "limit.Error": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "number",
+                    "example": 1000
+                },
+                "code": {
+                    "type": "string",
+                    "example": "limit_exceeded"
+                },
+                "limit": {
+                    "type": "string",
+                    "example": "daily_outflow"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Daily outflow limit of 1000.00 reached: 900.00 already sent today"
+                },
+                "resets_at": {
+                    "type": "string",
+                    "example": "2024-03-26T00:00:00Z"
+                },
+                "used": {
+                    "type": "number",
+                    "example": 900
+                }
+            }
+        },
+        "limit.Limits": {
+            "type": "object",
+            "properties": {
+                "daily_outflow": {
+                    "type": "number",
+                    "example": 1000
+                },
+                "max_transfers_per_hour": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "max_withdrawal": {
+                    "type": "number",
+                    "example": 500
+                },
+                "monthly_outflow": {
+                    "type": "number",
+                    "example": 10000
+                }
+            }
+        },
+        "limit.Usage": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "type": "number",
+                    "example": 120
+                },
+                "monthly": {
+                    "type": "number",
+                    "example": 1520
+                },
+                "transfers_last_hour": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "limit.WalletLimits": {
+            "type": "object",
+            "properties": {
+                "effective": {
+                    "$ref": "#/definitions/limit.Limits"
+                },
+                "type": {
+                    "$ref": "#/definitions/limit.Limits"
+                },
+                "usage": {
+                    "$ref": "#/definitions/limit.Usage"
+                },
+                "wallet": {
+                    "$ref": "#/definitions/limit.Limits"
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "openapi.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "reconcile.Candidate": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 250
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Top-up"
+                },
+                "entry_id": {
+                    "type": "integer",
+                    "example": 17
+                },
+                "posted_at": {
+                    "type": "string",
+                    "example": "2026-09-01T10:15:00Z"
+                },
+                "transaction_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "reconcile.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "reconcile.File": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "camt053"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2026-09-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "imported_at": {
+                    "type": "string",
+                    "example": "2026-09-02T06:00:00Z"
+                },
+                "matched": {
+                    "type": "integer",
+                    "example": 41
+                },
+                "mismatched": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "name": {
+                    "type": "string",
+                    "example": "settlement-2026-09-01.xml"
+                },
+                "resolved": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2026-09-01T00:00:00Z"
+                },
+                "unmatched": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "reconcile.Item": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 250
+                },
+                "booked_on": {
+                    "type": "string",
+                    "example": "2026-09-01T00:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Top-up John Doe"
+                },
+                "entry_id": {
+                    "type": "integer",
+                    "example": 17
+                },
+                "file_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "line_no": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Bank fee, booked by hand"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "amount 250.00 but posted 205.00"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "TX42"
+                },
+                "resolved_at": {
+                    "type": "string",
+                    "example": "2026-09-02T08:00:00Z"
+                },
+                "resolved_by": {
+                    "type": "string",
+                    "example": "ops@example.com"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "matched"
+                }
+            }
+        },
+        "reconcile.Report": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "camt053"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2026-09-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "imported_at": {
+                    "type": "string",
+                    "example": "2026-09-02T06:00:00Z"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reconcile.Item"
+                    }
+                },
+                "matched": {
+                    "type": "integer",
+                    "example": 41
+                },
+                "mismatched": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "name": {
+                    "type": "string",
+                    "example": "settlement-2026-09-01.xml"
+                },
+                "resolved": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2026-09-01T00:00:00Z"
+                },
+                "unmatched": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "unmatched_entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reconcile.Candidate"
+                    }
+                }
+            }
+        },
+        "reconcile.Resolution": {
+            "type": "object",
+            "properties": {
+                "entry_id": {
+                    "type": "integer",
+                    "example": 17
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Bank fee, booked by hand"
+                }
+            }
+        },
+        "report.BalanceRow": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "type": "string",
+                    "example": "2024-03"
+                },
+                "total_balance": {
+                    "type": "number",
+                    "example": 3000
+                },
+                "wallet_count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "wallet_type": {
+                    "type": "string",
+                    "example": "Savings"
+                }
+            }
+        },
+        "report.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "schedule.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "schedule.Execution": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "error": {
+                    "type": "string",
+                    "example": "balance cannot be negative for Savings"
+                },
+                "executed_at": {
+                    "type": "string",
+                    "example": "2024-04-01T09:00:03Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "schedule_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "scheduled_for": {
+                    "type": "string",
+                    "example": "2024-04-01T09:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "succeeded"
+                },
+                "transfer_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "schedule.Request": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 500
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Credit card repayment"
+                },
+                "end_at": {
+                    "type": "string",
+                    "example": "2025-04-01T00:00:00Z"
+                },
+                "from_wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "max_retries": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "on_insufficient_funds": {
+                    "type": "string",
+                    "example": "retry"
+                },
+                "recurrence": {
+                    "type": "string",
+                    "example": "0 9 1 * *"
+                },
+                "retry_delay": {
+                    "type": "string",
+                    "example": "6h"
+                },
+                "start_at": {
+                    "type": "string",
+                    "example": "2024-04-01T00:00:00Z"
+                },
+                "to_wallet_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "schedule.Schedule": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 500
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Credit card repayment"
+                },
+                "end_at": {
+                    "type": "string",
+                    "example": "2025-04-01T00:00:00Z"
+                },
+                "from_wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "max_retries": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "next_run_at": {
+                    "type": "string",
+                    "example": "2024-04-01T09:00:00Z"
+                },
+                "occurrence_at": {
+                    "description": "OccurrenceAt is the occurrence being worked on and NextRunAt when it\nis next tried, later than OccurrenceAt while it is retried.",
+                    "type": "string",
+                    "example": "2024-04-01T09:00:00Z"
+                },
+                "on_insufficient_funds": {
+                    "type": "string",
+                    "example": "retry"
+                },
+                "recurrence": {
+                    "type": "string",
+                    "example": "FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9;BYMINUTE=0"
+                },
+                "retries": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "retry_delay": {
+                    "type": "string",
+                    "example": "6h"
+                },
+                "start_at": {
+                    "type": "string",
+                    "example": "2024-04-01T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "to_wallet_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                }
+            }
+        },
+        "statement.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "stream.Change": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "type": {
+                    "type": "string",
+                    "example": "BalanceChanged"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "wallet": {
+                    "type": "object"
+                }
+            }
+        },
+        "stream.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "transaction.Err": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "transaction.Movement": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 25.5
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Cash"
+                }
+            }
+        },
+        "transaction.Reversal": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/transaction.Transaction"
+                    }
+                },
+                "reversed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/transaction.Transaction"
+                    }
+                }
+            }
+        },
+        "transaction.ReverseRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 10
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Deposit posted to the wrong wallet"
+                }
+            }
+        },
+        "transaction.Transaction": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 25.5
+                },
+                "balance_after": {
+                    "type": "number",
+                    "example": 525.5
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Coffee"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "spend"
+                },
+                "reversal_of": {
+                    "description": "ReversalOf links a reversal to the transaction it undoes, and\nReversed is how much of a transaction was reversed so far.",
+                    "type": "integer",
+                    "example": 7
+                },
+                "reversed": {
+                    "type": "number",
+                    "example": 10
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "transfer.Err": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "transfer.Quote": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 50
+                },
+                "fee": {
+                    "type": "number",
+                    "example": 0.55
+                },
+                "from_wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "to_wallet_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "total": {
+                    "type": "number",
+                    "example": 50.55
+                }
+            }
+        },
+        "transfer.Request": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 50
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Rent share"
+                },
+                "from_wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "to_wallet_id": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "transfer.Transfer": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 50
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "credit_transaction_id": {
+                    "type": "integer",
+                    "example": 11
+                },
+                "debit_transaction_id": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Rent share"
+                },
+                "fee": {
+                    "type": "number",
+                    "example": 0.55
+                },
+                "fee_transaction_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "from_wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "to_wallet_id": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "user.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.Summary": {
+            "type": "object",
+            "properties": {
+                "by_type": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user.TypeBalance"
+                    }
+                },
+                "net_worth": {
+                    "type": "number",
+                    "example": 600
+                },
+                "total_balance": {
+                    "type": "number",
+                    "example": 1600
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "wallet_count": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "user.TypeBalance": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number",
+                    "example": 500
+                },
+                "wallet_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "wallet_type": {
+                    "type": "string",
+                    "example": "Credit Card"
+                }
+            }
+        },
+        "wallet.Crypto": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+                },
+                "amount": {
+                    "type": "string",
+                    "example": "0.00000001"
+                },
+                "asset": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "decimals": {
+                    "type": "integer",
+                    "example": 8
+                }
+            }
+        },
+        "wallet.Err": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "wallet.ImportReport": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/wallet.RowError"
+                    }
+                },
+                "inserted": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "atomic"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "wallet.RowError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Invalid wallet type"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "wallet.StatusChange": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "ops@example.com"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Suspected card testing"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "frozen"
+                },
+                "wallet_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "wallet.StatusRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Suspected card testing"
+                }
+            }
+        },
+        "wallet.Type": {
+            "type": "object",
+            "properties": {
+                "allow_negative": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "key": {
+                    "type": "string",
+                    "example": "CreditCard"
+                },
+                "max_balance": {
+                    "type": "number",
+                    "example": 100000
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Credit Card"
+                }
+            }
         },
         "wallet.Wallet": {
             "type": "object",
             "properties": {
+                "available_balance": {
+                    "type": "number",
+                    "example": 75
+                },
                 "balance": {
                     "type": "number",
                     "example": 100
@@ -272,10 +5901,17 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2024-03-25T14:19:00.729237Z"
                 },
+                "crypto": {
+                    "$ref": "#/definitions/wallet.Crypto"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
                 "user_id": {
                     "type": "integer",
                     "example": 1
@@ -293,6 +5929,139 @@ const docTemplate = `{
                     "example": "CreditCard"
                 }
             }
+        },
+        "webhook.Attempt": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "attempt": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "error": {
+                    "type": "string",
+                    "example": "unexpected status 500"
+                },
+                "response_status": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "status": {
+                    "type": "string",
+                    "example": "failed"
+                }
+            }
+        },
+        "webhook.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "delivered_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "event_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "WalletCreated"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Attempt"
+                    }
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "unexpected status 500"
+                },
+                "next_attempt_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "webhook.Err": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.Subscription": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-25T14:19:00.729237Z"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "WalletCreated",
+                        "BalanceChanged"
+                    ]
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "5f2b0c..."
+                },
+                "target_url": {
+                    "type": "string",
+                    "example": "https://partner.example.com/hooks/wallet"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "type": "apiKey",
+            "name": "X-Admin-Token",
+            "in": "header"
         }
     }
 }`
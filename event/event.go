@@ -0,0 +1,30 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WalletCreated  = "WalletCreated"
+	WalletUpdated  = "WalletUpdated"
+	WalletDeleted  = "WalletDeleted"
+	BalanceChanged = "BalanceChanged"
+)
+
+var Types = []string{WalletCreated, WalletUpdated, WalletDeleted, BalanceChanged}
+
+type Event struct {
+	ID        int64           `json:"id" example:"1"`
+	Type      string          `json:"type" example:"WalletCreated"`
+	WalletID  int             `json:"wallet_id" example:"1"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+// Balance is the payload of a BalanceChanged event.
+type Balance struct {
+	WalletID int     `json:"wallet_id"`
+	From     float64 `json:"from"`
+	To       float64 `json:"to"`
+}
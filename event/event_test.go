@@ -0,0 +1,237 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type StubOutbox struct {
+	events    []Event
+	published []int64
+	attempts  map[int64]int
+}
+
+func (s *StubOutbox) PendingEvents(limit int) ([]Event, error) {
+	var pending []Event
+	for _, e := range s.events {
+		if !s.isPublished(e.ID) && !s.isDead(e.ID) {
+			pending = append(pending, e)
+		}
+	}
+	return pending, nil
+}
+
+func (s *StubOutbox) MarkPublished(id int64) error {
+	s.published = append(s.published, id)
+	return nil
+}
+
+func (s *StubOutbox) MarkFailed(id int64, reason string, maxAttempts int) (bool, error) {
+	if s.attempts == nil {
+		s.attempts = map[int64]int{}
+	}
+	s.attempts[id]++
+	return s.attempts[id] >= maxAttempts, nil
+}
+
+// isDead uses the max attempts of the tests, 3.
+func (s *StubOutbox) isDead(id int64) bool {
+	return s.attempts[id] >= 3
+}
+
+func (s *StubOutbox) isPublished(id int64) bool {
+	for _, p := range s.published {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
+
+type StubLeader struct {
+	lead bool
+}
+
+func (l *StubLeader) TryLead(ctx context.Context) (bool, error) {
+	return l.lead, nil
+}
+
+func (l *StubLeader) Resign() error {
+	return nil
+}
+
+func newRelay(outbox Outbox, sinks ...Sink) *Relay {
+	return NewRelay(outbox, &StubLeader{lead: true}, 0, 3, sinks...)
+}
+
+type StubSink struct {
+	fail      map[int64]bool
+	published []int64
+}
+
+func (s *StubSink) Publish(ctx context.Context, e Event) error {
+	if s.fail[e.ID] {
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, e.ID)
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+
+	t.Run("given pending events should publish and mark them in order", func(t *testing.T) {
+		outbox := &StubOutbox{events: []Event{
+			{ID: 1, Type: WalletCreated, WalletID: 1},
+			{ID: 2, Type: WalletCreated, WalletID: 2},
+			{ID: 3, Type: BalanceChanged, WalletID: 1},
+		}}
+		sink := &StubSink{}
+
+		if err := newRelay(outbox, sink).Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []int64{1, 2, 3}
+		if !reflect.DeepEqual(sink.published, want) {
+			t.Errorf("expected published %v but got %v", want, sink.published)
+		}
+		if !reflect.DeepEqual(outbox.published, want) {
+			t.Errorf("expected marked %v but got %v", want, outbox.published)
+		}
+	})
+
+	t.Run("given failed event should hold back later events of the same wallet only", func(t *testing.T) {
+		outbox := &StubOutbox{events: []Event{
+			{ID: 1, Type: WalletCreated, WalletID: 1},
+			{ID: 2, Type: WalletCreated, WalletID: 2},
+			{ID: 3, Type: BalanceChanged, WalletID: 1},
+		}}
+		sink := &StubSink{fail: map[int64]bool{1: true}}
+		relay := newRelay(outbox, sink)
+
+		relay.Flush(context.Background())
+		if want := []int64{2}; !reflect.DeepEqual(outbox.published, want) {
+			t.Errorf("expected marked %v but got %v", want, outbox.published)
+		}
+
+		sink.fail = nil
+		relay.Flush(context.Background())
+		if want := []int64{2, 1, 3}; !reflect.DeepEqual(outbox.published, want) {
+			t.Errorf("expected marked %v but got %v", want, outbox.published)
+		}
+	})
+
+	t.Run("given one sink fails should not mark event published", func(t *testing.T) {
+		outbox := &StubOutbox{events: []Event{{ID: 1, Type: WalletCreated, WalletID: 1}}}
+		ok := &StubSink{}
+		failing := &StubSink{fail: map[int64]bool{1: true}}
+
+		newRelay(outbox, ok, failing).Flush(context.Background())
+
+		if len(outbox.published) != 0 {
+			t.Errorf("expected no marked events but got %v", outbox.published)
+		}
+	})
+
+	t.Run("given an event fails max attempts times should dead letter it and release its wallet", func(t *testing.T) {
+		outbox := &StubOutbox{events: []Event{
+			{ID: 1, Type: WalletCreated, WalletID: 1},
+			{ID: 2, Type: BalanceChanged, WalletID: 1},
+		}}
+		sink := &StubSink{fail: map[int64]bool{1: true}}
+		relay := newRelay(outbox, sink)
+
+		relay.Flush(context.Background())
+		relay.Flush(context.Background())
+		if len(outbox.published) != 0 {
+			t.Fatalf("expected event 2 held back but got %v", outbox.published)
+		}
+		relay.Flush(context.Background())
+		relay.Flush(context.Background())
+
+		if want := []int64{2}; !reflect.DeepEqual(outbox.published, want) || outbox.attempts[1] != 3 {
+			t.Errorf("expected event 1 dead after 3 attempts and %v marked but got %d, %v", want, outbox.attempts[1], outbox.published)
+		}
+	})
+
+	t.Run("given another replica leads should not relay", func(t *testing.T) {
+		outbox := &StubOutbox{events: []Event{{ID: 1, Type: WalletCreated, WalletID: 1}}}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		NewRelay(outbox, &StubLeader{}, time.Millisecond, 3, &StubSink{}).Run(ctx)
+
+		if len(outbox.published) != 0 {
+			t.Errorf("expected no marked events but got %v", outbox.published)
+		}
+	})
+}
+
+func TestSinks(t *testing.T) {
+	e := Event{ID: 1, Type: WalletCreated, WalletID: 1, Payload: json.RawMessage(`{"id":1}`)}
+
+	t.Run("given writer sink should write one json line per event", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewWriterSink(&buf)
+		s.Publish(context.Background(), e)
+		s.Publish(context.Background(), e)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Errorf("expected lines %d but got %d", 2, len(lines))
+		}
+	})
+
+	t.Run("given file sink should append to file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.ndjson")
+		s := NewFileSink(path)
+		s.Publish(context.Background(), e)
+		s.Publish(context.Background(), e)
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(string(b), "\n"); n != 2 {
+			t.Errorf("expected lines %d but got %d", 2, n)
+		}
+	})
+
+	t.Run("given webhook sink should post event", func(t *testing.T) {
+		var got Event
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &got)
+		}))
+		defer srv.Close()
+
+		if err := NewWebhookSink(srv.URL, nil).Publish(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != e.ID || got.Type != e.Type {
+			t.Errorf("expected event %v but got %v", e, got)
+		}
+	})
+
+	t.Run("given webhook responds with error should fail", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		if err := NewWebhookSink(srv.URL, nil).Publish(context.Background(), e); err == nil {
+			t.Error("expected error but got nil")
+		}
+	})
+}
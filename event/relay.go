@@ -0,0 +1,115 @@
+package event
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Outbox is the transactional outbox the relay drains. PendingEvents must
+// return the events neither published nor dead in the order they were
+// written.
+type Outbox interface {
+	PendingEvents(limit int) ([]Event, error)
+	MarkPublished(id int64) error
+	// MarkFailed records a failed attempt at publishing an event and
+	// reports whether it was the last of maxAttempts, the event being dead
+	// from then on.
+	MarkFailed(id int64, reason string, maxAttempts int) (dead bool, err error)
+}
+
+// Leader elects the one replica relaying the outbox.
+type Leader interface {
+	// TryLead reports whether this replica leads, trying to become the
+	// leader when it does not.
+	TryLead(ctx context.Context) (bool, error)
+	Resign() error
+}
+
+// Relay moves events from the outbox to its sinks. Delivery is at least
+// once: an event is marked published only after every sink accepted it, and
+// is retried on every sink otherwise. Events of a wallet are published in
+// order; a failure holds back the rest of that wallet's events until the
+// next flush, while other wallets carry on. After maxAttempts failures an
+// event is dead: it stays in the outbox for inspection and no longer holds
+// back its wallet.
+//
+// Every replica runs a relay but only the leader flushes, as two relays
+// draining the outbox at once would break the order per wallet.
+type Relay struct {
+	outbox      Outbox
+	leader      Leader
+	sinks       []Sink
+	interval    time.Duration
+	maxAttempts int
+	batchSize   int
+}
+
+func NewRelay(outbox Outbox, leader Leader, interval time.Duration, maxAttempts int, sinks ...Sink) *Relay {
+	return &Relay{outbox: outbox, leader: leader, sinks: sinks, interval: interval, maxAttempts: maxAttempts, batchSize: 100}
+}
+
+// Run flushes the outbox every interval while this replica leads, until
+// ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	defer r.leader.Resign()
+	for {
+		lead, err := r.leader.TryLead(ctx)
+		if err != nil {
+			log.Printf("outbox relay: %v", err)
+		}
+		if lead {
+			if err := r.Flush(ctx); err != nil {
+				log.Printf("outbox relay: %v", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush publishes one batch of pending events.
+func (r *Relay) Flush(ctx context.Context) error {
+	events, err := r.outbox.PendingEvents(r.batchSize)
+	if err != nil {
+		return err
+	}
+
+	blocked := map[int]bool{}
+	for _, e := range events {
+		if blocked[e.WalletID] {
+			continue
+		}
+		if err := r.publish(ctx, e); err != nil {
+			log.Printf("outbox relay: publish event %d: %v", e.ID, err)
+			dead, err := r.outbox.MarkFailed(e.ID, err.Error(), r.maxAttempts)
+			if err != nil {
+				return err
+			}
+			if dead {
+				log.Printf("outbox relay: event %d is dead after %d attempts", e.ID, r.maxAttempts)
+			} else {
+				blocked[e.WalletID] = true
+			}
+			continue
+		}
+		if err := r.outbox.MarkPublished(e.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Relay) publish(ctx context.Context, e Event) error {
+	for _, s := range r.sinks {
+		if err := s.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
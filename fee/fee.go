@@ -0,0 +1,104 @@
+package fee
+
+import (
+	"math"
+	"sort"
+)
+
+// Operations a fee can be charged on. The fee is taken from the wallet the
+// money leaves, on top of the amount.
+const (
+	OperationTransfer   = "transfer"
+	OperationWithdrawal = "withdrawal"
+)
+
+// Operations are the operations in the order they are listed.
+var Operations = []string{OperationTransfer, OperationWithdrawal}
+
+// ValidOperation reports whether op is an operation fees can be set on.
+func ValidOperation(op string) bool {
+	for _, o := range Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Tier is the fee of amounts up to UpTo, or of every larger amount when
+// UpTo is nil.
+type Tier struct {
+	UpTo    *float64 `json:"up_to,omitempty" example:"1000"`
+	Flat    float64  `json:"flat" example:"1.00"`
+	Percent float64  `json:"percent" example:"0.5"`
+}
+
+// Rule is the fee of an operation on the wallets of a type: Flat plus
+// Percent of the amount, or those of the tier the amount falls in when
+// tiers are set, kept within Min and Max.
+type Rule struct {
+	WalletType string   `json:"wallet_type" example:"Savings"`
+	Operation  string   `json:"operation" example:"transfer"`
+	Flat       float64  `json:"flat" example:"0.50"`
+	Percent    float64  `json:"percent" example:"0.1"`
+	Tiers      []Tier   `json:"tiers,omitempty"`
+	Min        *float64 `json:"min,omitempty" example:"1.00"`
+	Max        *float64 `json:"max,omitempty" example:"25.00"`
+}
+
+// Compute returns the fee of amount, in cents.
+func (r Rule) Compute(amount float64) float64 {
+	flat, percent := r.Flat, r.Percent
+	if t, ok := r.tier(amount); ok {
+		flat, percent = t.Flat, t.Percent
+	}
+	fee := flat + amount*percent/100
+	if r.Min != nil && fee < *r.Min {
+		fee = *r.Min
+	}
+	if r.Max != nil && fee > *r.Max {
+		fee = *r.Max
+	}
+	return math.Round(fee*100) / 100
+}
+
+// tier returns the first tier amount falls in. Tiers are sorted by Validate.
+func (r Rule) tier(amount float64) (Tier, bool) {
+	for _, t := range r.Tiers {
+		if t.UpTo == nil || amount <= *t.UpTo {
+			return t, true
+		}
+	}
+	return Tier{}, false
+}
+
+// Validate sorts the tiers by UpTo and returns a message for the first
+// problem: negative amounts, a minimum above the maximum, or tiers with the
+// same bound or more than one unbounded tier.
+func (r *Rule) Validate() string {
+	if r.Flat < 0 || r.Percent < 0 || r.Percent > 100 {
+		return "Invalid flat or percent"
+	}
+	if r.Min != nil && *r.Min < 0 || r.Max != nil && *r.Max < 0 {
+		return "Invalid min or max"
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return "min cannot be above max"
+	}
+	sort.SliceStable(r.Tiers, func(i, j int) bool {
+		a, b := r.Tiers[i].UpTo, r.Tiers[j].UpTo
+		return b == nil && a != nil || a != nil && b != nil && *a < *b
+	})
+	for i, t := range r.Tiers {
+		if t.Flat < 0 || t.Percent < 0 || t.Percent > 100 || t.UpTo != nil && *t.UpTo <= 0 {
+			return "Invalid tier"
+		}
+		if i > 0 {
+			prev := r.Tiers[i-1].UpTo
+			if prev == nil || t.UpTo != nil && *t.UpTo == *prev {
+				return "Tiers must have different bounds and at most one without up_to"
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package fee
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func ptr(f float64) *float64 {
+	return &f
+}
+
+type StubFee struct {
+	rules []Rule
+	err   error
+}
+
+func (s *StubFee) TypeFees(key string) ([]Rule, error) {
+	return s.rules, s.err
+}
+
+func (s *StubFee) SaveTypeFee(r Rule) (*Rule, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.rules = append(s.rules, r)
+	return &r, nil
+}
+
+func (s *StubFee) DeleteTypeFee(key, operation string) error {
+	for i, r := range s.rules {
+		if r.WalletType == key && r.Operation == operation {
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func TestCompute(t *testing.T) {
+	tiered := Rule{Tiers: []Tier{
+		{UpTo: ptr(100), Flat: 1},
+		{UpTo: ptr(1000), Flat: 0.5, Percent: 0.5},
+		{Percent: 0.25},
+	}, Max: ptr(10)}
+
+	tests := []struct {
+		name   string
+		rule   Rule
+		amount float64
+		want   float64
+	}{
+		{"no fee", Rule{}, 100, 0},
+		{"flat", Rule{Flat: 2}, 100, 2},
+		{"percent rounded to cents", Rule{Percent: 0.1}, 123.45, 0.12},
+		{"flat plus percent", Rule{Flat: 0.5, Percent: 1}, 50, 1},
+		{"raised to the minimum", Rule{Percent: 0.1, Min: ptr(1)}, 50, 1},
+		{"capped at the maximum", Rule{Percent: 1, Max: ptr(25)}, 10000, 25},
+		{"first tier", tiered, 100, 1},
+		{"middle tier", tiered, 500, 3},
+		{"open tier", tiered, 2000, 5},
+		{"open tier capped", tiered, 8000, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Compute(tt.amount); got != tt.want {
+				t.Errorf("expected fee %v but got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("should sort tiers with the open one last", func(t *testing.T) {
+		r := Rule{Tiers: []Tier{{Percent: 1}, {UpTo: ptr(1000)}, {UpTo: ptr(100)}}}
+
+		if msg := r.Validate(); msg != "" {
+			t.Fatal(msg)
+		}
+		if *r.Tiers[0].UpTo != 100 || *r.Tiers[1].UpTo != 1000 || r.Tiers[2].UpTo != nil {
+			t.Errorf("unexpected order %+v", r.Tiers)
+		}
+	})
+
+	for name, r := range map[string]Rule{
+		"negative flat":      {Flat: -1},
+		"percent above 100":  {Percent: 101},
+		"min above max":      {Min: ptr(5), Max: ptr(1)},
+		"two open tiers":     {Tiers: []Tier{{Flat: 1}, {Flat: 2}}},
+		"same bound twice":   {Tiers: []Tier{{UpTo: ptr(10)}, {UpTo: ptr(10)}}},
+		"non-positive bound": {Tiers: []Tier{{UpTo: ptr(0)}}},
+	} {
+		t.Run("given "+name+" should fail", func(t *testing.T) {
+			if msg := r.Validate(); msg == "" {
+				t.Errorf("expected a message for %+v", r)
+			}
+		})
+	}
+}
+
+func request(method, body string, params ...string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("key", "operation")
+	c.SetParamValues(params...)
+	return c, rec
+}
+
+func TestSaveTypeFee(t *testing.T) {
+	t.Run("given a valid rule should save it for the type and operation", func(t *testing.T) {
+		c, rec := request(http.MethodPut, `{"percent": 0.1, "min": 1}`, "Savings", OperationTransfer)
+
+		New(&StubFee{}).SaveTypeFee(c)
+
+		var resp Rule
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusOK || resp.WalletType != "Savings" || resp.Operation != OperationTransfer {
+			t.Errorf("unexpected response %d %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("given an unknown operation should return 400", func(t *testing.T) {
+		c, rec := request(http.MethodPut, `{"flat": 1}`, "Savings", "deposit")
+
+		New(&StubFee{}).SaveTypeFee(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given an unknown type should return 404", func(t *testing.T) {
+		c, rec := request(http.MethodPut, `{"flat": 1}`, "Nope", OperationTransfer)
+
+		New(&StubFee{err: ErrTypeNotFound}).SaveTypeFee(c)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestDeleteTypeFee(t *testing.T) {
+	c, rec := request(http.MethodDelete, "", "Savings", OperationWithdrawal)
+
+	New(&StubFee{}).DeleteTypeFee(c)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,116 @@
+package fee
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	ErrNotFound     = errors.New("fee rule not found")
+	ErrTypeNotFound = errors.New("wallet type not found")
+)
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	TypeFees(key string) ([]Rule, error)
+	SaveTypeFee(r Rule) (*Rule, error)
+	DeleteTypeFee(key, operation string) error
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// GetTypeFees
+//
+//	@Summary		Get wallet type fees
+//	@Description	Get the fee rules of the wallets of a type, by operation
+//	@Tags			fee
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Rule
+//	@Router			/api/v1/wallet-types/{key}/fees [get]
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   key  path		string	true	"Wallet type key"
+//	@Security	AdminToken
+func (h *Handler) GetTypeFees(c echo.Context) error {
+	rules, err := h.store.TypeFees(c.Param("key"))
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, rules)
+}
+
+// SaveTypeFee
+//
+//	@Summary		Set wallet type fee
+//	@Description	Replace the fee charged on an operation of the wallets of a type. Tiers, when given, replace flat and percent by the amount moved.
+//	@Tags			fee
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Rule
+//	@Router			/api/v1/wallet-types/{key}/fees/{operation} [put]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   key  path		string	true	"Wallet type key"
+//	@Param   operation  path		string	true	"transfer or withdrawal"
+//	@Param   fee  body		Rule	true	"Fee rule"
+//	@Security	AdminToken
+func (h *Handler) SaveTypeFee(c echo.Context) error {
+	var r Rule
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	r.WalletType, r.Operation = c.Param("key"), c.Param("operation")
+	if !ValidOperation(r.Operation) {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid operation"})
+	}
+	if msg := r.Validate(); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	saved, err := h.store.SaveTypeFee(r)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, saved)
+}
+
+// DeleteTypeFee
+//
+//	@Summary		Delete wallet type fee
+//	@Description	Stop charging a fee on an operation of the wallets of a type
+//	@Tags			fee
+//	@Accept			json
+//	@Produce		json
+//	@Success		204
+//	@Router			/api/v1/wallet-types/{key}/fees/{operation} [delete]
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   key  path		string	true	"Wallet type key"
+//	@Param   operation  path		string	true	"transfer or withdrawal"
+//	@Security	AdminToken
+func (h *Handler) DeleteTypeFee(c echo.Context) error {
+	if err := h.store.DeleteTypeFee(c.Param("key"), c.Param("operation")); err != nil {
+		return storeError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func storeError(c echo.Context, err error) error {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrTypeNotFound) {
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// listFactor is how many items a list field is assumed to return when
+// costing a query.
+const listFactor = 10
+
+// complexity is the cost of the operation of doc that operation picks: one
+// per field, with the fields selected under a list counted listFactor
+// times. Introspection fields cost one, so tooling is never refused.
+//
+// The schema is cyclic (Wallet.user, User.wallets), so the cost grows
+// exponentially with nesting: counting stops as soon as it passes limit,
+// returning limit+1, and fields nested deeper than maxDepth are an error.
+func complexity(schema graphql.Schema, doc *ast.Document, operationName string, limit, maxDepth int) (int, error) {
+	op := operation(doc, operationName)
+	if op == nil {
+		return 0, nil
+	}
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, d := range doc.Definitions {
+		if f, ok := d.(*ast.FragmentDefinition); ok {
+			fragments[f.Name.Value] = f
+		}
+	}
+
+	root := schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	c := &coster{schema: schema, fragments: fragments, visiting: map[string]bool{}, limit: limit, maxDepth: maxDepth}
+	cost := c.cost(op.SelectionSet, root, 1)
+	if c.tooDeep {
+		return cost, fmt.Errorf("query is nested deeper than the limit of %d", maxDepth)
+	}
+	return cost, nil
+}
+
+// operation returns the operation of doc named name, or the first when
+// name is empty or unknown, which execution then reports.
+func operation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var first *ast.OperationDefinition
+	for _, d := range doc.Definitions {
+		op, ok := d.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if op.Name != nil && op.Name.Value == name {
+			return op
+		}
+		if first == nil {
+			first = op
+		}
+	}
+	return first
+}
+
+type coster struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	// visiting guards against fragment cycles, which validation reports.
+	visiting map[string]bool
+	limit    int
+	maxDepth int
+	tooDeep  bool
+}
+
+// cost is the cost of set at depth, saturated at limit+1.
+func (c *coster) cost(set *ast.SelectionSet, parent graphql.Type, depth int) int {
+	if set == nil {
+		return 0
+	}
+	if depth > c.maxDepth {
+		c.tooDeep = true
+		return c.limit + 1
+	}
+	total := 0
+	for _, s := range set.Selections {
+		var sub, factor int
+		switch s := s.(type) {
+		case *ast.Field:
+			total++
+			if strings.HasPrefix(s.Name.Value, "__") {
+				break
+			}
+			obj, ok := parent.(*graphql.Object)
+			if !ok {
+				break
+			}
+			def, ok := obj.Fields()[s.Name.Value]
+			if !ok {
+				break
+			}
+			var typ graphql.Type
+			typ, factor = unwrap(def.Type)
+			sub = c.cost(s.SelectionSet, typ, depth+1)
+		case *ast.InlineFragment:
+			typ := parent
+			if s.TypeCondition != nil {
+				typ = c.schema.Type(s.TypeCondition.Name.Value)
+			}
+			sub, factor = c.cost(s.SelectionSet, typ, depth), 1
+		case *ast.FragmentSpread:
+			f, ok := c.fragments[s.Name.Value]
+			if !ok || c.visiting[f.Name.Value] {
+				break
+			}
+			c.visiting[f.Name.Value] = true
+			sub, factor = c.cost(f.SelectionSet, c.schema.Type(f.TypeCondition.Name.Value), depth), 1
+			c.visiting[f.Name.Value] = false
+		}
+		// factor*sub > limit-total, without computing the product.
+		if total > c.limit || sub > 0 && sub > (c.limit-total)/factor {
+			return c.limit + 1
+		}
+		total += factor * sub
+	}
+	return total
+}
+
+// unwrap returns the named type of t, and listFactor when t is a list.
+func unwrap(t graphql.Type) (graphql.Type, int) {
+	factor := 1
+	for {
+		switch w := t.(type) {
+		case *graphql.NonNull:
+			t = w.OfType
+		case *graphql.List:
+			factor *= listFactor
+			t = w.OfType
+		default:
+			return t, factor
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/user"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/labstack/echo/v4"
+)
+
+type StubGraph struct {
+	mu       sync.Mutex
+	wallets  []wallet.Wallet
+	batches  map[string]int
+	created  *wallet.Wallet
+	createAs audit.Actor
+}
+
+func newStub() *StubGraph {
+	created := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	return &StubGraph{
+		batches: map[string]int{},
+		wallets: []wallet.Wallet{
+			{ID: 1, UserID: 1, UserName: "John Doe", WalletName: "John Savings", WalletType: "Savings", Balance: 100, AvailableBalance: 100, Status: wallet.StatusActive, CreatedAt: created},
+			{ID: 2, UserID: 1, UserName: "John Doe", WalletName: "John Card", WalletType: "Credit Card", Balance: -20, AvailableBalance: -20, Status: wallet.StatusActive, CreatedAt: created},
+			{ID: 3, UserID: 2, UserName: "Jane Doe", WalletName: "Jane Savings", WalletType: "Savings", Balance: 50, AvailableBalance: 50, Status: wallet.StatusActive, CreatedAt: created},
+		},
+	}
+}
+
+func (s *StubGraph) count(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches[name]++
+}
+
+func (s *StubGraph) Wallets(walletType string) ([]wallet.Wallet, error) {
+	var wallets []wallet.Wallet
+	for _, w := range s.wallets {
+		if walletType == "" || w.WalletType == walletType {
+			wallets = append(wallets, w)
+		}
+	}
+	return wallets, nil
+}
+
+func (s *StubGraph) WalletTypes() ([]wallet.Type, error) {
+	return []wallet.Type{{Key: "Savings", Name: "Savings"}, {Key: "CreditCard", Name: "Credit Card", AllowNegative: true}}, nil
+}
+
+func (s *StubGraph) CreateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	w.ID, w.Status = 4, wallet.StatusActive
+	s.created, s.createAs = &w, actor
+	return &w, nil
+}
+
+func (s *StubGraph) UpdateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	return nil, wallet.ErrWalletNotFound
+}
+
+func (s *StubGraph) DeleteWallet(id int, actor audit.Actor) error {
+	return nil
+}
+
+func (s *StubGraph) SummaryByUserID(userId int) (*user.Summary, error) {
+	return &user.Summary{UserID: userId, WalletCount: 2, TotalBalance: 80, NetWorth: 80, ByType: []user.TypeBalance{}}, nil
+}
+
+func (s *StubGraph) WalletsByIDs(ids []int) ([]wallet.Wallet, error) {
+	s.count("WalletsByIDs")
+	var wallets []wallet.Wallet
+	for _, w := range s.wallets {
+		for _, id := range ids {
+			if w.ID == id {
+				wallets = append(wallets, w)
+			}
+		}
+	}
+	return wallets, nil
+}
+
+func (s *StubGraph) WalletsByUserIDs(userIDs []int) ([]wallet.Wallet, error) {
+	s.count("WalletsByUserIDs")
+	var wallets []wallet.Wallet
+	for _, w := range s.wallets {
+		for _, id := range userIDs {
+			if w.UserID == id {
+				wallets = append(wallets, w)
+			}
+		}
+	}
+	return wallets, nil
+}
+
+type response struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func post(t *testing.T, h *Handler, query string, variables map[string]any) (int, response) {
+	t.Helper()
+	body, _ := json.Marshal(Request{Query: query, Variables: variables})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	h.Serve(echo.New().NewContext(req, rec))
+
+	var res response
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body.String(), err)
+	}
+	return rec.Code, res
+}
+
+func TestQuery(t *testing.T) {
+	t.Run("given nested wallets should read the wallets of each user once", func(t *testing.T) {
+		stub := newStub()
+		query := `{ wallets { id user { name wallets { walletName user { wallets { id } } } } } }`
+
+		code, res := post(t, New(stub, 10000, 10), query, nil)
+
+		if code != http.StatusOK || len(res.Errors) > 0 {
+			t.Fatalf("unexpected response %d %+v", code, res.Errors)
+		}
+		wallets := res.Data["wallets"].([]any)
+		if len(wallets) != 3 {
+			t.Fatalf("expected 3 wallets but got %d", len(wallets))
+		}
+		owner := wallets[2].(map[string]any)["user"].(map[string]any)
+		if owner["name"] != "Jane Doe" || len(owner["wallets"].([]any)) != 1 {
+			t.Errorf("unexpected owner %v", owner)
+		}
+		if stub.batches["WalletsByUserIDs"] != 1 {
+			t.Errorf("expected one batch for both levels but got %d", stub.batches["WalletsByUserIDs"])
+		}
+	})
+
+	t.Run("given a user should return its wallets and summary", func(t *testing.T) {
+		stub := newStub()
+		query := `query($id: Int!) { user(id: $id) { name summary { walletCount } wallets { id createdAt crypto { asset } } } nobody: user(id: 9) { name } }`
+
+		_, res := post(t, New(stub, 500, 10), query, map[string]any{"id": 1})
+
+		u := res.Data["user"].(map[string]any)
+		if u["name"] != "John Doe" || len(u["wallets"].([]any)) != 2 || res.Data["nobody"] != nil {
+			t.Errorf("unexpected data %v %+v", res.Data, res.Errors)
+		}
+		if stub.batches["WalletsByUserIDs"] != 1 {
+			t.Errorf("expected the two users read in one batch but got %d", stub.batches["WalletsByUserIDs"])
+		}
+	})
+
+	t.Run("given wallet ids should batch them", func(t *testing.T) {
+		stub := newStub()
+
+		_, res := post(t, New(stub, 500, 10), `{ a: wallet(id: 1) { walletName } b: wallet(id: 3) { walletName } c: wallet(id: 9) { id } }`, nil)
+
+		if res.Data["b"].(map[string]any)["walletName"] != "Jane Savings" || res.Data["c"] != nil {
+			t.Errorf("unexpected data %v", res.Data)
+		}
+		if stub.batches["WalletsByIDs"] != 1 {
+			t.Errorf("expected one batch but got %d", stub.batches["WalletsByIDs"])
+		}
+	})
+
+	t.Run("given an unknown wallet type should return BAD_USER_INPUT", func(t *testing.T) {
+		_, res := post(t, New(newStub(), 500, 10), `{ wallets(walletType: "Gold") { id } }`, nil)
+
+		if len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != CodeBadInput {
+			t.Errorf("unexpected errors %+v", res.Errors)
+		}
+	})
+
+	t.Run("given a query over the complexity limit should return 400", func(t *testing.T) {
+		code, res := post(t, New(newStub(), 100, 10), `{ wallets { user { wallets { id } } } }`, nil)
+
+		if code != http.StatusBadRequest || len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "limit of 100") {
+			t.Errorf("unexpected response %d %+v", code, res.Errors)
+		}
+	})
+
+	t.Run("given a query nested deeper than the limit should return 400", func(t *testing.T) {
+		code, res := post(t, New(newStub(), 1<<30, 10), nested(6), nil)
+
+		if code != http.StatusBadRequest || len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "deeper than the limit of 10") {
+			t.Errorf("unexpected response %d %+v", code, res.Errors)
+		}
+	})
+}
+
+func TestMutation(t *testing.T) {
+	t.Run("given a valid wallet should create it", func(t *testing.T) {
+		stub := newStub()
+		query := `mutation { createWallet(input: {userId: 2, userName: "Jane Doe", walletName: "Jane Card", walletType: "CreditCard", balance: -5}) { id walletType user { wallets { id } } } }`
+
+		_, res := post(t, New(stub, 500, 10), query, nil)
+
+		if len(res.Errors) > 0 || stub.created == nil || stub.created.WalletType != "Credit Card" {
+			t.Fatalf("unexpected result %v %+v", res.Data, res.Errors)
+		}
+		if stub.createAs.ID != audit.AnonymousActor {
+			t.Errorf("expected the actor of the request but got %+v", stub.createAs)
+		}
+	})
+
+	t.Run("given a balance the type forbids should not create it", func(t *testing.T) {
+		stub := newStub()
+		query := `mutation { createWallet(input: {userId: 2, userName: "Jane Doe", walletName: "Jane", walletType: "Savings", balance: -5}) { id } }`
+
+		_, res := post(t, New(stub, 500, 10), query, nil)
+
+		if stub.created != nil || len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != CodeBadInput {
+			t.Errorf("unexpected result %+v", res.Errors)
+		}
+	})
+
+	t.Run("given an unknown wallet should return NOT_FOUND", func(t *testing.T) {
+		query := `mutation { updateWallet(id: 9, input: {userId: 2, userName: "Jane Doe", walletName: "Jane", walletType: "Savings", balance: 5}) { id } }`
+
+		_, res := post(t, New(newStub(), 500, 10), query, nil)
+
+		if len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != CodeNotFound {
+			t.Errorf("unexpected errors %+v", res.Errors)
+		}
+	})
+
+	t.Run("given GET should refuse a mutation", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation { deleteWallet(id: 1) }`), nil)
+		rec := httptest.NewRecorder()
+
+		New(newStub(), 500, 10).Serve(echo.New().NewContext(req, rec))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status code %d but got %d", http.StatusMethodNotAllowed, rec.Code)
+		}
+	})
+}
+
+// nested selects wallets { user { ... } } levels times, which costs
+// 10^levels and more.
+func nested(levels int) string {
+	return "{ " + strings.Repeat("wallets { user { ", levels) + "id" + strings.Repeat(" } }", levels) + " }"
+}
+
+func TestComplexity(t *testing.T) {
+	schema := newSchema(newStub())
+	for query, want := range map[string]int{
+		`{ wallet(id: 1) { id balance } }`:                                  3,
+		`{ wallets { id } }`:                                                11,
+		`{ wallets { ...w } } fragment w on Wallet { id user { name } }`:    31,
+		`{ user(id: 1) { wallets { id } summary { byType { balance } } } }`: 24,
+		`{ __schema { types { name fields { name } } } }`:                   1,
+	} {
+		doc, err := parser.Parse(parser.ParseParams{Source: query})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := complexity(schema, doc, "", 10000, 10); err != nil || got != want {
+			t.Errorf("expected %q to cost %d but got %d, %v", query, want, got, err)
+		}
+	}
+}
+
+func TestComplexityOfDeepQueries(t *testing.T) {
+	schema := newSchema(newStub())
+	doc, err := parser.Parse(parser.ParseParams{Source: nested(40)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should saturate instead of overflowing", func(t *testing.T) {
+		got, err := complexity(schema, doc, "", 500, 1000)
+
+		if err != nil || got != 501 {
+			t.Errorf("expected the cost saturated at 501 but got %d, %v", got, err)
+		}
+	})
+
+	t.Run("should refuse a depth over the limit", func(t *testing.T) {
+		if _, err := complexity(schema, doc, "", 500, 10); err == nil {
+			t.Error("expected a depth error")
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/user"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store         Storer
+	schema        graphql.Schema
+	maxComplexity int
+	maxDepth      int
+}
+
+// Storer is what the GraphQL API reads and writes: the methods it shares
+// with wallet.Storer and user.Storer, and two batch reads for its loaders.
+type Storer interface {
+	Wallets(walletType string) ([]wallet.Wallet, error)
+	WalletTypes() ([]wallet.Type, error)
+	CreateWallet(wallet wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error)
+	UpdateWallet(wallet wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error)
+	DeleteWallet(id int, actor audit.Actor) error
+	SummaryByUserID(userId int) (*user.Summary, error)
+	WalletsByIDs(ids []int) ([]wallet.Wallet, error)
+	WalletsByUserIDs(userIDs []int) ([]wallet.Wallet, error)
+}
+
+// New serves the schema over db, refusing operations that cost more than
+// maxComplexity or nest fields deeper than maxDepth, see complexity.
+func New(db Storer, maxComplexity, maxDepth int) *Handler {
+	return &Handler{store: db, schema: newSchema(db), maxComplexity: maxComplexity, maxDepth: maxDepth}
+}
+
+type Request struct {
+	Query         string         `json:"query" example:"{ user(id: 1) { name wallets { id walletName balance } } }"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty" swaggertype:"object"`
+}
+
+// Response is the body of every answer, with data, errors or both.
+type Response struct {
+	Data   any                        `json:"data,omitempty" swaggertype:"object"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty" swaggertype:"array,object"`
+}
+
+func failed(c echo.Context, status int, err error) error {
+	return c.JSON(status, Response{Errors: gqlerrors.FormatErrors(err)})
+}
+
+// Serve
+//
+//	@Summary		GraphQL
+//	@Description	Query users and wallets and change wallets with GraphQL. Queries may also be sent with GET and the request fields as query parameters; mutations may not.
+//	@Description	Operations costing more than the configured complexity are refused: every field costs one, and fields under a list ten times as much.
+//	@Description	So are operations nesting fields deeper than the configured depth.
+//	@Tags			graphql
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Response
+//	@Failure		400	{object}	Response
+//	@Failure		405	{object}	Response
+//	@Router			/graphql [post]
+//	@Param   request  body		Request	true	"GraphQL request"
+func (h *Handler) Serve(c echo.Context) error {
+	var req Request
+	if c.Request().Method == http.MethodGet {
+		req.Query = c.QueryParam("query")
+		req.OperationName = c.QueryParam("operationName")
+		if v := c.QueryParam("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return failed(c, http.StatusBadRequest, fmt.Errorf("invalid variables: %w", err))
+			}
+		}
+	} else if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return failed(c, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+	}
+	if req.Query == "" {
+		return failed(c, http.StatusBadRequest, errors.New("query is required"))
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return failed(c, http.StatusBadRequest, err)
+	}
+	if op := operation(doc, req.OperationName); op != nil && op.Operation != ast.OperationTypeQuery && c.Request().Method == http.MethodGet {
+		return failed(c, http.StatusMethodNotAllowed, fmt.Errorf("%s operations must be sent with POST", op.Operation))
+	}
+	cost, err := complexity(h.schema, doc, req.OperationName, h.maxComplexity, h.maxDepth)
+	if err != nil {
+		return failed(c, http.StatusBadRequest, err)
+	}
+	if cost > h.maxComplexity {
+		return failed(c, http.StatusBadRequest, fmt.Errorf("query costs more than the limit of %d", h.maxComplexity))
+	}
+
+	ctx := context.WithValue(c.Request().Context(), actorKey{}, audit.ActorFrom(c))
+	ctx = withLoaders(ctx, newLoaders(h.store))
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+	return c.JSON(http.StatusOK, Response{Data: result.Data, Errors: result.Errors})
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// loaders batch the reads of one request: every wallet or user wallets a
+// level of the query asks for is read in one query, whatever the number of
+// parents.
+type loaders struct {
+	wallet      *dataloader.Loader[int, *wallet.Wallet]
+	userWallets *dataloader.Loader[int, []wallet.Wallet]
+}
+
+type loadersKey struct{}
+
+func newLoaders(store Storer) *loaders {
+	return &loaders{
+		wallet:      dataloader.NewBatchedLoader(walletBatch(store)),
+		userWallets: dataloader.NewBatchedLoader(userWalletsBatch(store)),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// walletBatch reads wallets by id, nil for an unknown id.
+func walletBatch(store Storer) dataloader.BatchFunc[int, *wallet.Wallet] {
+	return func(ctx context.Context, ids []int) []*dataloader.Result[*wallet.Wallet] {
+		wallets, err := store.WalletsByIDs(ids)
+		byID := make(map[int]*wallet.Wallet, len(wallets))
+		for i := range wallets {
+			byID[wallets[i].ID] = &wallets[i]
+		}
+		results := make([]*dataloader.Result[*wallet.Wallet], len(ids))
+		for i, id := range ids {
+			results[i] = &dataloader.Result[*wallet.Wallet]{Data: byID[id], Error: err}
+		}
+		return results
+	}
+}
+
+// userWalletsBatch reads the wallets of users by user id, oldest first.
+func userWalletsBatch(store Storer) dataloader.BatchFunc[int, []wallet.Wallet] {
+	return func(ctx context.Context, userIDs []int) []*dataloader.Result[[]wallet.Wallet] {
+		wallets, err := store.WalletsByUserIDs(userIDs)
+		byUser := make(map[int][]wallet.Wallet, len(userIDs))
+		for _, w := range wallets {
+			byUser[w.UserID] = append(byUser[w.UserID], w)
+		}
+		results := make([]*dataloader.Result[[]wallet.Wallet], len(userIDs))
+		for i, id := range userIDs {
+			ws := byUser[id]
+			if ws == nil {
+				ws = []wallet.Wallet{}
+			}
+			results[i] = &dataloader.Result[[]wallet.Wallet]{Data: ws, Error: err}
+		}
+		return results
+	}
+}
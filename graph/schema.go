@@ -0,0 +1,345 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/user"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/graphql-go/graphql"
+)
+
+// User is the owner of wallets. Users have no table of their own: a user
+// exists while it has a wallet, and its name is the one on its first.
+type User struct {
+	ID   int
+	Name string
+}
+
+// Error is a GraphQL error with a code in its extensions. A frozen or
+// closed wallet gives the code of the REST API.
+type Error struct {
+	Message string
+	Code    string
+}
+
+// Codes of Error.
+const (
+	CodeBadInput = "BAD_USER_INPUT"
+	CodeNotFound = "NOT_FOUND"
+)
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]any {
+	return map[string]any{"code": e.Code}
+}
+
+// storeError gives a failure of the store its code.
+func storeError(err error) error {
+	var be *wallet.BalanceError
+	var se *wallet.StatusError
+	switch {
+	case errors.As(err, &be), errors.Is(err, wallet.ErrMissingCrypto):
+		return &Error{Message: err.Error(), Code: CodeBadInput}
+	case errors.As(err, &se):
+		return &Error{Message: err.Error(), Code: se.Code()}
+	case errors.Is(err, wallet.ErrWalletNotFound):
+		return &Error{Message: err.Error(), Code: CodeNotFound}
+	}
+	return err
+}
+
+type actorKey struct{}
+
+func actorFrom(ctx context.Context) audit.Actor {
+	actor, _ := ctx.Value(actorKey{}).(audit.Actor)
+	return actor
+}
+
+// field resolves a field of a source of type T with get.
+func field[T any](typ graphql.Output, get func(T) any) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return get(p.Source.(T)), nil
+		},
+	}
+}
+
+// thunk lets the executor resolve the siblings of a field before the
+// loader runs, so they are read in one batch.
+func thunk[V any](load func() (V, error)) func() (any, error) {
+	return func() (any, error) {
+		v, err := load()
+		return v, err
+	}
+}
+
+func newSchema(store Storer) graphql.Schema {
+	str := graphql.NewNonNull(graphql.String)
+	integer := graphql.NewNonNull(graphql.Int)
+	float := graphql.NewNonNull(graphql.Float)
+
+	crypto := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Crypto",
+		Description: "On-chain side of a crypto wallet",
+		Fields: graphql.Fields{
+			"asset":    field(str, func(c *wallet.Crypto) any { return c.Asset }),
+			"address":  field(str, func(c *wallet.Crypto) any { return c.Address }),
+			"decimals": field(integer, func(c *wallet.Crypto) any { return c.Decimals }),
+			"amount":   field(str, func(c *wallet.Crypto) any { return string(c.Amount) }),
+		},
+	})
+
+	typeBalance := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TypeBalance",
+		Fields: graphql.Fields{
+			"walletType":  field(str, func(t user.TypeBalance) any { return t.WalletType }),
+			"walletCount": field(integer, func(t user.TypeBalance) any { return t.WalletCount }),
+			"balance":     field(float, func(t user.TypeBalance) any { return t.Balance }),
+		},
+	})
+
+	summary := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Summary",
+		Description: "Balances of a user. Net worth counts credit card balances as liabilities.",
+		Fields: graphql.Fields{
+			"walletCount":  field(integer, func(s *user.Summary) any { return s.WalletCount }),
+			"totalBalance": field(float, func(s *user.Summary) any { return s.TotalBalance }),
+			"netWorth":     field(float, func(s *user.Summary) any { return s.NetWorth }),
+			"byType":       field(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(typeBalance))), func(s *user.Summary) any { return s.ByType }),
+		},
+	})
+
+	var walletType *graphql.Object
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":   field(integer, func(u User) any { return u.ID }),
+				"name": field(str, func(u User) any { return u.Name }),
+				"wallets": {
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(walletType))),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						u := p.Source.(User)
+						return thunk(loadersFrom(p.Context).userWallets.Load(p.Context, u.ID)), nil
+					},
+				},
+				"summary": {
+					Type: graphql.NewNonNull(summary),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return store.SummaryByUserID(p.Source.(User).ID)
+					},
+				},
+			}
+		}),
+	})
+
+	walletType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Wallet",
+		Fields: graphql.Fields{
+			"id":               field(integer, func(w wallet.Wallet) any { return w.ID }),
+			"userId":           field(integer, func(w wallet.Wallet) any { return w.UserID }),
+			"userName":         field(str, func(w wallet.Wallet) any { return w.UserName }),
+			"walletName":       field(str, func(w wallet.Wallet) any { return w.WalletName }),
+			"walletType":       field(str, func(w wallet.Wallet) any { return w.WalletType }),
+			"balance":          field(float, func(w wallet.Wallet) any { return w.Balance }),
+			"availableBalance": field(float, func(w wallet.Wallet) any { return w.AvailableBalance }),
+			"status":           field(str, func(w wallet.Wallet) any { return w.Status }),
+			"createdAt":        field(graphql.NewNonNull(graphql.DateTime), func(w wallet.Wallet) any { return w.CreatedAt }),
+			"crypto":           field(crypto, func(w wallet.Wallet) any { return w.Crypto }),
+			"user":             field(graphql.NewNonNull(userType), func(w wallet.Wallet) any { return User{ID: w.UserID, Name: w.UserName} }),
+		},
+	})
+
+	cryptoInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CryptoInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"asset":   {Type: str},
+			"address": {Type: str},
+			"amount":  {Type: str, Description: "Quantity of the asset, as a decimal string"},
+		},
+	})
+
+	walletInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "WalletInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"userId":     {Type: integer},
+			"userName":   {Type: str},
+			"walletName": {Type: str},
+			"walletType": {Type: str, Description: "Wallet type key or name"},
+			"balance":    {Type: float},
+			"crypto":     {Type: cryptoInput},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"wallets": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(walletType))),
+				Args: graphql.FieldConfigArgument{
+					"walletType": {Type: graphql.String, Description: "Wallet type key or name"},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					name, _ := p.Args["walletType"].(string)
+					if name != "" {
+						t, err := resolveType(store, name)
+						if err != nil {
+							return nil, err
+						}
+						name = t.Name
+					}
+					wallets, err := store.Wallets(name)
+					if err != nil {
+						return nil, err
+					}
+					if wallets == nil {
+						wallets = []wallet.Wallet{}
+					}
+					return wallets, nil
+				},
+			},
+			"wallet": {
+				Type: walletType,
+				Args: graphql.FieldConfigArgument{"id": {Type: integer}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					load := loadersFrom(p.Context).wallet.Load(p.Context, p.Args["id"].(int))
+					return func() (any, error) {
+						w, err := load()
+						if err != nil || w == nil {
+							return nil, err
+						}
+						return *w, nil
+					}, nil
+				},
+			},
+			"user": {
+				Type: userType,
+				Args: graphql.FieldConfigArgument{"id": {Type: integer}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id := p.Args["id"].(int)
+					load := loadersFrom(p.Context).userWallets.Load(p.Context, id)
+					return func() (any, error) {
+						wallets, err := load()
+						if err != nil || len(wallets) == 0 {
+							return nil, err
+						}
+						return User{ID: id, Name: wallets[0].UserName}, nil
+					}, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createWallet": {
+				Type: graphql.NewNonNull(walletType),
+				Args: graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(walletInput)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					w, err := walletFromInput(store, p.Args["input"].(map[string]any), true)
+					if err != nil {
+						return nil, err
+					}
+					created, err := store.CreateWallet(w, actorFrom(p.Context))
+					if err != nil {
+						return nil, storeError(err)
+					}
+					loadersFrom(p.Context).userWallets.Clear(p.Context, created.UserID)
+					return *created, nil
+				},
+			},
+			"updateWallet": {
+				Type: graphql.NewNonNull(walletType),
+				Args: graphql.FieldConfigArgument{
+					"id":    {Type: integer},
+					"input": {Type: graphql.NewNonNull(walletInput)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					w, err := walletFromInput(store, p.Args["input"].(map[string]any), false)
+					if err != nil {
+						return nil, err
+					}
+					w.ID = p.Args["id"].(int)
+					updated, err := store.UpdateWallet(w, actorFrom(p.Context))
+					if err != nil {
+						return nil, storeError(err)
+					}
+					l := loadersFrom(p.Context)
+					l.wallet.Clear(p.Context, updated.ID)
+					l.userWallets.ClearAll()
+					return *updated, nil
+				},
+			},
+			"deleteWallet": {
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{"id": {Type: integer}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id := p.Args["id"].(int)
+					if err := store.DeleteWallet(id, actorFrom(p.Context)); err != nil {
+						return nil, storeError(err)
+					}
+					l := loadersFrom(p.Context)
+					l.wallet.Clear(p.Context, id)
+					l.userWallets.ClearAll()
+					return true, nil
+				},
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+	if err != nil {
+		// The schema is fixed at compile time, so this is a programming error.
+		panic(err)
+	}
+	return schema
+}
+
+func resolveType(store Storer, s string) (wallet.Type, error) {
+	types, err := store.WalletTypes()
+	if err != nil {
+		return wallet.Type{}, err
+	}
+	t, ok := wallet.ResolveType(types, s)
+	if !ok {
+		return wallet.Type{}, &Error{Message: "Invalid wallet type", Code: CodeBadInput}
+	}
+	return t, nil
+}
+
+// walletFromInput checks a WalletInput the way the REST API checks a wallet
+// body.
+func walletFromInput(store Storer, in map[string]any, create bool) (wallet.Wallet, error) {
+	w := wallet.Wallet{
+		UserID:     in["userId"].(int),
+		UserName:   in["userName"].(string),
+		WalletName: in["walletName"].(string),
+		Balance:    in["balance"].(float64),
+	}
+	t, err := resolveType(store, in["walletType"].(string))
+	if err != nil {
+		return w, err
+	}
+	w.WalletType = t.Name
+	if err := t.CheckBalance(w.Balance); err != nil {
+		return w, &Error{Message: err.Error(), Code: CodeBadInput}
+	}
+	if c, ok := in["crypto"].(map[string]any); ok {
+		w.Crypto = &wallet.Crypto{
+			Asset:   c["asset"].(string),
+			Address: c["address"].(string),
+			Amount:  wallet.Amount(c["amount"].(string)),
+		}
+	}
+	if err := wallet.ValidateCrypto(&w, create); err != nil {
+		return w, &Error{Message: err.Error(), Code: CodeBadInput}
+	}
+	return w, nil
+}
@@ -0,0 +1,210 @@
+package hold
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+	now   func() time.Time
+}
+
+type Storer interface {
+	CreateHold(walletID int, r Request) (*Hold, error)
+	Holds(walletID int) ([]Hold, error)
+	// CaptureHold posts the captured amount and releases the hold in one
+	// transaction.
+	CaptureHold(walletID int, id int64, c Capture) (*Hold, error)
+	VoidHold(walletID int, id int64) (*Hold, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db, now: time.Now}
+}
+
+type Err struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+}
+
+// CreateHold
+//
+//	@Summary		Place hold
+//	@Description	Reserve funds of a wallet until captured, voided or expired. The amount comes off the available balance and counts against the spending limits now.
+//	@Tags			hold
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	Hold
+//	@Router			/api/v1/wallets/{id}/holds [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code wallet_frozen or wallet_closed"
+//	@Failure		422	{object}	limit.Error	"insufficient available balance, or a spending limit was hit"
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   hold  body		Request	true	"Hold, expiring in 7 days unless expires_at is given"
+func (h *Handler) CreateHold(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+	var r Request
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	r.Amount = math.Round(r.Amount*100) / 100
+	if r.Amount <= 0 || math.IsInf(r.Amount, 0) {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid amount"})
+	}
+	now := h.now().UTC()
+	if r.ExpiresAt == nil {
+		expires := now.Add(DefaultExpiry)
+		r.ExpiresAt = &expires
+	}
+	if !r.ExpiresAt.After(now) || r.ExpiresAt.Sub(now) > MaxExpiry {
+		return c.JSON(http.StatusBadRequest, Err{Message: "expires_at must be in the next 30 days"})
+	}
+	expires := r.ExpiresAt.UTC()
+	r.ExpiresAt = &expires
+
+	created, err := h.store.CreateHold(walletId, r)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// GetHolds
+//
+//	@Summary		Get holds
+//	@Description	Get the holds of a wallet, newest first
+//	@Tags			hold
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Hold
+//	@Router			/api/v1/wallets/{id}/holds [get]
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+func (h *Handler) GetHolds(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+
+	holds, err := h.store.Holds(walletId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, holds)
+}
+
+// CaptureHold
+//
+//	@Summary		Capture hold
+//	@Description	Take some or all of the held funds off the ledger balance as a capture transaction and release the rest
+//	@Tags			hold
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Hold
+//	@Router			/api/v1/wallets/{id}/holds/{holdId}/capture [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code hold_captured, hold_voided or hold_expired, or wallet_frozen or wallet_closed"
+//	@Failure		422	{object}	Err	"capture exceeds the held amount"
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   holdId  path		int	true	"Hold id"
+//	@Param   capture  body		Capture	false	"Capture, everything held by default"
+func (h *Handler) CaptureHold(c echo.Context) error {
+	walletId, id, err := ids(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	var capture Capture
+	if err := c.Bind(&capture); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	if capture.Amount != nil {
+		amount := math.Round(*capture.Amount*100) / 100
+		if amount <= 0 || math.IsInf(amount, 0) {
+			return c.JSON(http.StatusBadRequest, Err{Message: "Invalid amount"})
+		}
+		capture.Amount = &amount
+	}
+
+	captured, err := h.store.CaptureHold(walletId, id, capture)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, captured)
+}
+
+// VoidHold
+//
+//	@Summary		Void hold
+//	@Description	Release the held funds without taking them
+//	@Tags			hold
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Hold
+//	@Router			/api/v1/wallets/{id}/holds/{holdId}/void [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code hold_captured, hold_voided or hold_expired"
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   holdId  path		int	true	"Hold id"
+func (h *Handler) VoidHold(c echo.Context) error {
+	walletId, id, err := ids(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+
+	voided, err := h.store.VoidHold(walletId, id)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, voided)
+}
+
+func ids(c echo.Context) (int, int64, error) {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, errors.New("Invalid wallet id")
+	}
+	id, err := strconv.ParseInt(c.Param("holdId"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("Invalid hold id")
+	}
+	return walletId, id, nil
+}
+
+func storeError(c echo.Context, err error) error {
+	var le *limit.Error
+	var be *wallet.BalanceError
+	var se *wallet.StatusError
+	var he *StateError
+	switch {
+	case errors.As(err, &le):
+		return c.JSON(http.StatusUnprocessableEntity, le)
+	case errors.As(err, &be), errors.Is(err, transaction.ErrCreditCard), errors.Is(err, ErrOverCapture):
+		return c.JSON(http.StatusUnprocessableEntity, Err{Message: err.Error()})
+	case errors.As(err, &se):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error(), Code: se.Code()})
+	case errors.As(err, &he):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error(), Code: he.Code()})
+	case errors.Is(err, ErrNotFound), errors.Is(err, wallet.ErrWalletNotFound):
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
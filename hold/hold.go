@@ -0,0 +1,71 @@
+package hold
+
+import (
+	"errors"
+	"time"
+)
+
+// Statuses of a hold. Only an active hold reserves funds.
+const (
+	StatusActive   = "active"
+	StatusCaptured = "captured"
+	StatusVoided   = "voided"
+	StatusExpired  = "expired"
+)
+
+const (
+	// DefaultExpiry is how long a hold lasts when no expiry is given.
+	DefaultExpiry = 7 * 24 * time.Hour
+	// MaxExpiry is the longest a hold may last.
+	MaxExpiry = 30 * 24 * time.Hour
+)
+
+var (
+	ErrNotFound = errors.New("hold not found")
+	// ErrOverCapture is returned when capturing more than was held.
+	ErrOverCapture = errors.New("capture exceeds the held amount")
+)
+
+// Hold reserves funds of a wallet for a later capture. While active the
+// amount is taken off the available balance but not the ledger balance.
+type Hold struct {
+	ID             int64      `json:"id" example:"1"`
+	WalletID       int        `json:"wallet_id" example:"1"`
+	Amount         float64    `json:"amount" example:"25.00"`
+	Description    string     `json:"description" example:"Hotel booking"`
+	Status         string     `json:"status" example:"active"`
+	CapturedAmount *float64   `json:"captured_amount,omitempty" example:"20.00"`
+	TransactionID  *int64     `json:"transaction_id,omitempty" example:"42"`
+	ExpiresAt      time.Time  `json:"expires_at" example:"2024-04-01T14:19:00Z"`
+	CreatedAt      time.Time  `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" example:"2024-03-26T09:00:00Z"`
+}
+
+type Request struct {
+	Amount      float64    `json:"amount" example:"25.00"`
+	Description string     `json:"description" example:"Hotel booking"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" example:"2024-04-01T14:19:00Z"`
+}
+
+// Capture takes some or all of the held funds; the rest is released. A
+// missing amount captures everything.
+type Capture struct {
+	Amount      *float64 `json:"amount,omitempty" example:"20.00"`
+	Description string   `json:"description" example:"Hotel stay"`
+}
+
+// StateError is returned when capturing or voiding a hold that is no
+// longer active, or that has expired but was not swept yet.
+type StateError struct {
+	HoldID int64
+	Status string
+}
+
+func (e *StateError) Error() string {
+	return "hold is " + e.Status
+}
+
+// Code is the error code of the response, such as hold_expired.
+func (e *StateError) Code() string {
+	return "hold_" + e.Status
+}
@@ -0,0 +1,228 @@
+package hold
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+var now = time.Date(2024, 3, 25, 14, 0, 0, 0, time.UTC)
+
+type StubHold struct {
+	holds []Hold
+	err   error
+}
+
+func (s *StubHold) CreateHold(walletID int, r Request) (*Hold, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	h := Hold{ID: int64(len(s.holds) + 1), WalletID: walletID, Amount: r.Amount, Description: r.Description, Status: StatusActive, ExpiresAt: *r.ExpiresAt}
+	s.holds = append(s.holds, h)
+	return &h, nil
+}
+
+func (s *StubHold) Holds(walletID int) ([]Hold, error) {
+	return s.holds, s.err
+}
+
+func (s *StubHold) CaptureHold(walletID int, id int64, c Capture) (*Hold, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	for _, h := range s.holds {
+		if h.ID == id && h.WalletID == walletID {
+			amount := h.Amount
+			if c.Amount != nil {
+				amount = *c.Amount
+			}
+			if amount > h.Amount {
+				return nil, ErrOverCapture
+			}
+			h.Status, h.CapturedAmount = StatusCaptured, &amount
+			return &h, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *StubHold) VoidHold(walletID int, id int64) (*Hold, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	for _, h := range s.holds {
+		if h.ID == id && h.WalletID == walletID {
+			h.Status = StatusVoided
+			return &h, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *StubHold) ExpireHolds(at time.Time) (int, error) {
+	n := 0
+	for i, h := range s.holds {
+		if h.Status == StatusActive && !h.ExpiresAt.After(at) {
+			s.holds[i].Status = StatusExpired
+			n++
+		}
+	}
+	return n, nil
+}
+
+func request(body string, params ...string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id", "holdId")
+	c.SetParamValues(params...)
+	return c, rec
+}
+
+func handler(store Storer) *Handler {
+	h := New(store)
+	h.now = func() time.Time { return now }
+	return h
+}
+
+func TestCreateHold(t *testing.T) {
+	t.Run("given no expiry should hold for the default expiry", func(t *testing.T) {
+		c, rec := request(`{"amount": 25.004, "description": "Hotel booking"}`, "1", "")
+
+		handler(&StubHold{}).CreateHold(c)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d but got %d", http.StatusCreated, rec.Code)
+		}
+		var resp Hold
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if resp.Amount != 25 || !resp.ExpiresAt.Equal(now.Add(DefaultExpiry)) {
+			t.Errorf("unexpected hold %+v", resp)
+		}
+	})
+
+	t.Run("given an invalid amount or expiry should return 400", func(t *testing.T) {
+		for _, body := range []string{
+			`{"amount": 0}`,
+			`{"amount": 10, "expires_at": "2024-03-25T13:00:00Z"}`,
+			`{"amount": 10, "expires_at": "2024-05-25T13:00:00Z"}`,
+		} {
+			c, rec := request(body, "1", "")
+
+			handler(&StubHold{}).CreateHold(c)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status code %d for %s but got %d", http.StatusBadRequest, body, rec.Code)
+			}
+		}
+	})
+
+	t.Run("given the available balance is too low should return 422", func(t *testing.T) {
+		c, rec := request(`{"amount": 5000}`, "1", "")
+
+		handler(&StubHold{err: &wallet.BalanceError{Message: "balance cannot be negative for Savings"}}).CreateHold(c)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+	})
+
+	t.Run("given a spending limit is hit should return 422 naming it", func(t *testing.T) {
+		c, rec := request(`{"amount": 500}`, "1", "")
+
+		handler(&StubHold{err: &limit.Error{Code: limit.CodeLimitExceeded, Limit: limit.DailyOutflow}}).CreateHold(c)
+
+		var resp limit.Error
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusUnprocessableEntity || resp.Limit != limit.DailyOutflow {
+			t.Errorf("expected %d %s but got %d %s", http.StatusUnprocessableEntity, limit.DailyOutflow, rec.Code, resp.Limit)
+		}
+	})
+}
+
+func TestCaptureHold(t *testing.T) {
+	active := func() *StubHold {
+		return &StubHold{holds: []Hold{{ID: 1, WalletID: 1, Amount: 25, Status: StatusActive, ExpiresAt: now.Add(time.Hour)}}}
+	}
+
+	t.Run("given a partial amount should capture it", func(t *testing.T) {
+		c, rec := request(`{"amount": 20}`, "1", "1")
+
+		handler(active()).CaptureHold(c)
+
+		var resp Hold
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusOK || resp.Status != StatusCaptured || *resp.CapturedAmount != 20 {
+			t.Errorf("unexpected response %d %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("given no body should capture everything", func(t *testing.T) {
+		c, rec := request("", "1", "1")
+
+		handler(active()).CaptureHold(c)
+
+		var resp Hold
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusOK || *resp.CapturedAmount != 25 {
+			t.Errorf("unexpected response %d %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("given more than held should return 422", func(t *testing.T) {
+		c, rec := request(`{"amount": 30}`, "1", "1")
+
+		handler(active()).CaptureHold(c)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+	})
+
+	t.Run("given an expired hold should return 409 with its code", func(t *testing.T) {
+		c, rec := request("", "1", "1")
+
+		handler(&StubHold{err: &StateError{HoldID: 1, Status: StatusExpired}}).CaptureHold(c)
+
+		var resp Err
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusConflict || resp.Code != "hold_expired" {
+			t.Errorf("expected %d hold_expired but got %d %s", http.StatusConflict, rec.Code, resp.Code)
+		}
+	})
+}
+
+func TestVoidHold(t *testing.T) {
+	c, rec := request("", "1", "7")
+
+	handler(&StubHold{}).VoidHold(c)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestSweeper(t *testing.T) {
+	store := &StubHold{holds: []Hold{
+		{ID: 1, Status: StatusActive, ExpiresAt: now.Add(-time.Minute)},
+		{ID: 2, Status: StatusActive, ExpiresAt: now.Add(time.Minute)},
+	}}
+	s := NewSweeper(store)
+	s.now = func() time.Time { return now }
+
+	if err := s.Sweep(); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.holds[0].Status != StatusExpired || store.holds[1].Status != StatusActive {
+		t.Errorf("expected only the first hold to expire but got %+v", store.holds)
+	}
+}
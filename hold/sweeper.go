@@ -0,0 +1,49 @@
+package hold
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type SweeperStorer interface {
+	// ExpireHolds releases the active holds expired at now and returns how
+	// many were released.
+	ExpireHolds(now time.Time) (int, error)
+}
+
+// Sweeper releases expired holds. Each hold is expired under the lock of its
+// wallet, so several replicas can sweep at once.
+type Sweeper struct {
+	store SweeperStorer
+	now   func() time.Time
+}
+
+func NewSweeper(store SweeperStorer) *Sweeper {
+	return &Sweeper{store: store, now: time.Now}
+}
+
+// Run sweeps every interval until ctx is done.
+func (s *Sweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Sweep(); err != nil {
+			log.Printf("hold sweeper: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep releases every hold expired by now.
+func (s *Sweeper) Sweep() error {
+	n, err := s.store.ExpireHolds(s.now().UTC())
+	if n > 0 {
+		log.Printf("hold sweeper: released %d expired holds", n)
+	}
+	return err
+}
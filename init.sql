@@ -1,16 +1,493 @@
 -- Creation of product table
-CREATE TYPE wallet_type AS ENUM ('Savings', 'Credit Card', 'Crypto Wallet');
+-- Registry of wallet types. Wallets refer to a type by its name; the key is
+-- what clients use in query strings and paths. A max_balance of NULL means
+-- no limit.
+CREATE TABLE IF NOT EXISTS wallet_types (
+	key VARCHAR(32) PRIMARY KEY,
+	name VARCHAR(64) NOT NULL UNIQUE,
+	allow_negative BOOLEAN NOT NULL DEFAULT FALSE,
+	max_balance DECIMAL(10, 2) CHECK (max_balance >= 0),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO wallet_types (key, name) VALUES
+('Savings', 'Savings'),
+('CreditCard', 'Credit Card'),
+('CryptoWallet', 'Crypto Wallet');
 
 CREATE TABLE IF NOT EXISTS user_wallet (
 	id SERIAL PRIMARY KEY,
 	user_id INT NOT NULL,
 	user_name VARCHAR(255) NOT NULL,
 	wallet_name VARCHAR(255) NOT NULL,
-	wallet_type wallet_type NOT NULL,
+	wallet_type VARCHAR(64) NOT NULL REFERENCES wallet_types (name) ON UPDATE CASCADE,
+	balance DECIMAL(10, 2) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	status VARCHAR(8) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'frozen', 'closed')),
+	-- Sum of the active holds, kept with the balance so the available
+	-- balance is checked under the same row lock.
+	held DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (held >= 0)
+);
+
+-- Who froze, unfroze or closed a wallet, and why.
+CREATE TABLE IF NOT EXISTS wallet_status_change (
+	id SERIAL PRIMARY KEY,
+	wallet_id INT NOT NULL REFERENCES user_wallet (id) ON DELETE CASCADE,
+	from_status VARCHAR(8) NOT NULL,
+	to_status VARCHAR(8) NOT NULL,
+	reason VARCHAR(255) NOT NULL,
+	actor VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS wallet_status_change_wallet_id_idx ON wallet_status_change (wallet_id);
+
+CREATE TABLE IF NOT EXISTS wallet_transaction (
+	id BIGSERIAL PRIMARY KEY,
+	wallet_id INT NOT NULL,
+	kind VARCHAR(16) NOT NULL,
+	amount DECIMAL(12, 2) NOT NULL,
+	balance_after DECIMAL(10, 2) NOT NULL,
+	description VARCHAR(255) NOT NULL DEFAULT '',
+	-- A reversal links to the transaction it undoes, which keeps how much
+	-- of it was reversed so far.
+	reversal_of BIGINT REFERENCES wallet_transaction (id),
+	reversed DECIMAL(12, 2) NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS wallet_transaction_wallet_id_idx ON wallet_transaction (wallet_id, created_at);
+
+-- Double-entry ledger. Every wallet has an account whose balance is the
+-- wallet balance, and every transaction posts an entry whose lines, debits
+-- positive, add up to zero. System accounts take the other side; wallet
+-- accounts roll up to a control account in the trial balance.
+CREATE TABLE IF NOT EXISTS ledger_account (
+	id SERIAL PRIMARY KEY,
+	code VARCHAR(64) NOT NULL UNIQUE,
+	name VARCHAR(255) NOT NULL,
+	type VARCHAR(16) NOT NULL CHECK (type IN ('asset', 'liability', 'income', 'expense')),
+	control VARCHAR(64),
+	wallet_id INT UNIQUE REFERENCES user_wallet (id) ON DELETE SET NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO ledger_account (code, name, type) VALUES
+('settlement', 'Settlement', 'asset'),
+('clearing', 'Transfer clearing', 'asset'),
+('fees', 'House fees', 'income'),
+('interest_expense', 'Interest expense', 'expense'),
+('interest_income', 'Interest income', 'income');
+
+CREATE TABLE IF NOT EXISTS ledger_entry (
+	id BIGSERIAL PRIMARY KEY,
+	transaction_id BIGINT REFERENCES wallet_transaction (id),
+	description VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entry_transaction_id_idx ON ledger_entry (transaction_id);
+
+CREATE TABLE IF NOT EXISTS ledger_line (
+	id BIGSERIAL PRIMARY KEY,
+	entry_id BIGINT NOT NULL REFERENCES ledger_entry (id),
+	account_id INT NOT NULL REFERENCES ledger_account (id),
+	amount DECIMAL(14, 2) NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_line_account_id_idx ON ledger_line (account_id);
+CREATE INDEX IF NOT EXISTS ledger_line_entry_id_idx ON ledger_line (entry_id);
+
+-- Settlement files of the bank and their lines, each matched to at most one
+-- ledger entry posted to the settlement account and each entry to at most
+-- one line.
+CREATE TABLE IF NOT EXISTS settlement_file (
+	id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	format VARCHAR(16) NOT NULL CHECK (format IN ('csv', 'camt053')),
+	checksum CHAR(64) NOT NULL UNIQUE,
+	period_from DATE NOT NULL,
+	period_to DATE NOT NULL,
+	imported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS settlement_item (
+	id BIGSERIAL PRIMARY KEY,
+	file_id BIGINT NOT NULL REFERENCES settlement_file (id) ON DELETE CASCADE,
+	line_no INT NOT NULL,
+	reference VARCHAR(255) NOT NULL DEFAULT '',
+	amount DECIMAL(14, 2) NOT NULL,
+	booked_on DATE NOT NULL,
+	description VARCHAR(255) NOT NULL DEFAULT '',
+	status VARCHAR(16) NOT NULL CHECK (status IN ('matched', 'unmatched', 'mismatched', 'resolved')),
+	entry_id BIGINT REFERENCES ledger_entry (id),
+	reason VARCHAR(255) NOT NULL DEFAULT '',
+	note VARCHAR(255) NOT NULL DEFAULT '',
+	resolved_by VARCHAR(255),
+	resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS settlement_item_file_id_idx ON settlement_item (file_id, line_no);
+CREATE UNIQUE INDEX IF NOT EXISTS settlement_item_entry_id_idx ON settlement_item (entry_id) WHERE entry_id IS NOT NULL;
+
+-- On-chain side of crypto wallets. The amount is kept exactly, at the
+-- precision of the asset, next to the book value in user_wallet.balance.
+CREATE TABLE IF NOT EXISTS crypto_wallet (
+	wallet_id INT PRIMARY KEY REFERENCES user_wallet (id) ON DELETE CASCADE,
+	asset VARCHAR(16) NOT NULL,
+	address VARCHAR(90) NOT NULL,
+	decimals SMALLINT NOT NULL CHECK (decimals BETWEEN 0 AND 18),
+	amount NUMERIC(38, 18) NOT NULL DEFAULT 0 CHECK (amount >= 0)
+);
+
+-- Spending limits per wallet type and per wallet. NULL is no limit; a wallet
+-- limit overrides the limit of its type field by field.
+CREATE TABLE IF NOT EXISTS wallet_type_limit (
+	wallet_type VARCHAR(32) PRIMARY KEY REFERENCES wallet_types (key) ON DELETE CASCADE,
+	max_withdrawal DECIMAL(10, 2) CHECK (max_withdrawal >= 0),
+	daily_outflow DECIMAL(10, 2) CHECK (daily_outflow >= 0),
+	monthly_outflow DECIMAL(12, 2) CHECK (monthly_outflow >= 0),
+	max_transfers_per_hour INT CHECK (max_transfers_per_hour >= 0),
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS wallet_limit (
+	wallet_id INT PRIMARY KEY REFERENCES user_wallet (id) ON DELETE CASCADE,
+	max_withdrawal DECIMAL(10, 2) CHECK (max_withdrawal >= 0),
+	daily_outflow DECIMAL(10, 2) CHECK (daily_outflow >= 0),
+	monthly_outflow DECIMAL(12, 2) CHECK (monthly_outflow >= 0),
+	max_transfers_per_hour INT CHECK (max_transfers_per_hour >= 0),
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Fees charged on an operation of the wallets of a type: flat plus percent
+-- of the amount, or those of the first tier the amount is up to, kept
+-- between min_fee and max_fee.
+CREATE TABLE IF NOT EXISTS fee_rule (
+	wallet_type VARCHAR(32) NOT NULL REFERENCES wallet_types (key) ON DELETE CASCADE,
+	operation VARCHAR(16) NOT NULL CHECK (operation IN ('transfer', 'withdrawal')),
+	flat DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (flat >= 0),
+	percent DECIMAL(6, 3) NOT NULL DEFAULT 0 CHECK (percent >= 0 AND percent <= 100),
+	tiers JSONB NOT NULL DEFAULT '[]',
+	min_fee DECIMAL(10, 2) CHECK (min_fee >= 0),
+	max_fee DECIMAL(10, 2) CHECK (max_fee >= 0),
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (wallet_type, operation)
+);
+
+-- A transfer outlives its wallets, like their transactions do: deleting
+-- a wallet only clears its side.
+CREATE TABLE IF NOT EXISTS transfer (
+	id BIGSERIAL PRIMARY KEY,
+	from_wallet_id INT REFERENCES user_wallet (id) ON DELETE SET NULL,
+	to_wallet_id INT REFERENCES user_wallet (id) ON DELETE SET NULL,
+	amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+	description VARCHAR(255) NOT NULL DEFAULT '',
+	debit_transaction_id BIGINT NOT NULL REFERENCES wallet_transaction (id),
+	credit_transaction_id BIGINT NOT NULL REFERENCES wallet_transaction (id),
+	fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+	fee_transaction_id BIGINT REFERENCES wallet_transaction (id),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Funds reserved on a wallet until captured, voided or expired. The amounts
+-- of the active holds of a wallet add up to user_wallet.held.
+CREATE TABLE IF NOT EXISTS wallet_hold (
+	id BIGSERIAL PRIMARY KEY,
+	wallet_id INT NOT NULL REFERENCES user_wallet (id) ON DELETE CASCADE,
+	amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+	description VARCHAR(255) NOT NULL DEFAULT '',
+	status VARCHAR(8) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'captured', 'voided', 'expired')),
+	captured_amount DECIMAL(10, 2),
+	transaction_id BIGINT REFERENCES wallet_transaction (id),
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS wallet_hold_wallet_id_idx ON wallet_hold (wallet_id, status);
+CREATE INDEX IF NOT EXISTS wallet_hold_expiry_idx ON wallet_hold (expires_at) WHERE status = 'active';
+
+-- Standing orders. occurrence_at is the occurrence being worked on and
+-- next_run_at when it is next tried, later while a declined run is retried.
+CREATE TABLE IF NOT EXISTS scheduled_transfer (
+	id BIGSERIAL PRIMARY KEY,
+	from_wallet_id INT NOT NULL REFERENCES user_wallet (id) ON DELETE CASCADE,
+	to_wallet_id INT NOT NULL REFERENCES user_wallet (id) ON DELETE CASCADE,
+	amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+	description VARCHAR(255) NOT NULL DEFAULT '',
+	recurrence VARCHAR(255) NOT NULL,
+	start_at TIMESTAMP NOT NULL,
+	end_at TIMESTAMP,
+	on_insufficient_funds VARCHAR(8) NOT NULL DEFAULT 'skip',
+	max_retries INT NOT NULL DEFAULT 0 CHECK (max_retries >= 0),
+	retry_delay VARCHAR(32) NOT NULL DEFAULT '',
+	status VARCHAR(16) NOT NULL DEFAULT 'active',
+	occurrence_at TIMESTAMP,
+	next_run_at TIMESTAMP,
+	retries INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_transfer_due_idx ON scheduled_transfer (next_run_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS scheduled_transfer_execution (
+	id BIGSERIAL PRIMARY KEY,
+	schedule_id BIGINT NOT NULL REFERENCES scheduled_transfer (id) ON DELETE CASCADE,
+	scheduled_for TIMESTAMP NOT NULL,
+	attempt INT NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	transfer_id BIGINT REFERENCES transfer (id),
+	error TEXT,
+	executed_at TIMESTAMP NOT NULL,
+	UNIQUE (schedule_id, scheduled_for, attempt)
+);
+
+CREATE TABLE IF NOT EXISTS credit_card (
+	wallet_id INT PRIMARY KEY REFERENCES user_wallet (id) ON DELETE CASCADE,
+	credit_limit DECIMAL(10, 2) NOT NULL,
+	apr DECIMAL(5, 2) NOT NULL,
+	statement_day SMALLINT NOT NULL CHECK (statement_day BETWEEN 1 AND 28),
+	due_day SMALLINT NOT NULL CHECK (due_day BETWEEN 1 AND 28),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS credit_card_statement (
+	id SERIAL PRIMARY KEY,
+	wallet_id INT NOT NULL REFERENCES user_wallet (id) ON DELETE CASCADE,
+	period_start TIMESTAMP NOT NULL,
+	period_end TIMESTAMP NOT NULL,
+	opening_balance DECIMAL(10, 2) NOT NULL,
+	spend DECIMAL(10, 2) NOT NULL,
+	payments DECIMAL(10, 2) NOT NULL,
+	adjustments DECIMAL(10, 2) NOT NULL,
+	interest DECIMAL(10, 2) NOT NULL,
+	closing_balance DECIMAL(10, 2) NOT NULL,
+	minimum_payment DECIMAL(10, 2) NOT NULL,
+	due_date DATE NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (wallet_id, period_end)
+);
+
+-- Interest is earned at the rate of the wallet, falling back to the rate of
+-- its product. Accruals are kept per day at full precision and capitalized
+-- into one transaction per wallet each month.
+CREATE TABLE IF NOT EXISTS interest_product (
+	wallet_type VARCHAR(64) PRIMARY KEY REFERENCES wallet_types (name) ON UPDATE CASCADE ON DELETE CASCADE,
+	annual_rate DECIMAL(7, 4) NOT NULL CHECK (annual_rate BETWEEN 0 AND 100),
+	day_count VARCHAR(8) NOT NULL DEFAULT 'ACT/365'
+);
+
+CREATE TABLE IF NOT EXISTS wallet_interest_rate (
+	wallet_id INT PRIMARY KEY REFERENCES user_wallet (id) ON DELETE CASCADE,
+	annual_rate DECIMAL(7, 4) NOT NULL CHECK (annual_rate BETWEEN 0 AND 100),
+	day_count VARCHAR(8) NOT NULL DEFAULT 'ACT/365'
+);
+
+CREATE TABLE IF NOT EXISTS interest_accrual (
+	wallet_id INT NOT NULL REFERENCES user_wallet (id) ON DELETE CASCADE,
+	accrual_date DATE NOT NULL,
 	balance DECIMAL(10, 2) NOT NULL,
+	annual_rate DECIMAL(7, 4) NOT NULL,
+	day_count VARCHAR(8) NOT NULL,
+	amount DECIMAL(14, 6) NOT NULL,
+	posted_at TIMESTAMP,
+	transaction_id BIGINT REFERENCES wallet_transaction (id),
+	PRIMARY KEY (wallet_id, accrual_date)
+);
+
+-- Days the interest engine ran, so it can catch up on the days it missed.
+CREATE TABLE IF NOT EXISTS interest_run (
+	day DATE PRIMARY KEY,
+	ran_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+	id SERIAL PRIMARY KEY,
+	actor VARCHAR(255) NOT NULL,
+	-- the X-Actor of the request, which nothing checks
+	claimed VARCHAR(255) NOT NULL DEFAULT '',
+	action VARCHAR(16) NOT NULL,
+	wallet_id INT NOT NULL,
+	before JSONB,
+	after JSONB,
+	diff JSONB NOT NULL,
+	ip VARCHAR(64) NOT NULL,
+	request_id VARCHAR(64) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor, created_at);
+CREATE INDEX IF NOT EXISTS audit_log_claimed_idx ON audit_log (claimed, created_at);
+CREATE INDEX IF NOT EXISTS audit_log_wallet_id_idx ON audit_log (wallet_id, created_at);
+
+CREATE TABLE IF NOT EXISTS outbox (
+	id BIGSERIAL PRIMARY KEY,
+	event_type VARCHAR(32) NOT NULL,
+	wallet_id INT NOT NULL,
+	payload JSONB NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	published_at TIMESTAMP,
+	-- Failed attempts at publishing; past outbox.max_attempts the event is
+	-- dead and left for inspection.
+	attempts INT NOT NULL DEFAULT 0,
+	last_error TEXT,
+	dead_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (id) WHERE published_at IS NULL AND dead_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhook_subscription (
+	id SERIAL PRIMARY KEY,
+	target_url TEXT NOT NULL,
+	events TEXT[] NOT NULL DEFAULT '{}',
+	secret VARCHAR(255) NOT NULL,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+	id SERIAL PRIMARY KEY,
+	subscription_id INT NOT NULL REFERENCES webhook_subscription (id) ON DELETE CASCADE,
+	event_id BIGINT NOT NULL,
+	event_type VARCHAR(32) NOT NULL,
+	payload JSONB NOT NULL,
+	status VARCHAR(16) NOT NULL DEFAULT 'pending',
+	attempts INT NOT NULL DEFAULT 0,
+	response_status INT,
+	last_error TEXT,
+	next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	delivered_at TIMESTAMP,
+	-- attempts when last redelivered, where the retries start counting.
+	attempts_before INT NOT NULL DEFAULT 0,
+	UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_due_idx ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS webhook_attempt (
+	id BIGSERIAL PRIMARY KEY,
+	delivery_id INT NOT NULL REFERENCES webhook_delivery (id) ON DELETE CASCADE,
+	attempt INT NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	response_status INT,
+	error TEXT,
 	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE INDEX IF NOT EXISTS webhook_attempt_delivery_id_idx ON webhook_attempt (delivery_id);
+
+-- Change log of user_wallet feeding GET /api/v1/users/:id/wallets/stream.
+-- Changes are kept for stream.retention so streams can resume from
+-- Last-Event-ID, and a NOTIFY wakes up the streams of the owner.
+CREATE TABLE IF NOT EXISTS wallet_change (
+	id BIGSERIAL PRIMARY KEY,
+	user_id INT NOT NULL,
+	change_type VARCHAR(32) NOT NULL,
+	wallet JSONB NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS wallet_change_user_id_idx ON wallet_change (user_id, id);
+CREATE INDEX IF NOT EXISTS wallet_change_created_at_idx ON wallet_change (created_at);
+
+-- wallet_json is a wallet as the API returns it, with the crypto details of
+-- a crypto wallet and without the columns kept for bookkeeping such as held.
+CREATE OR REPLACE FUNCTION wallet_json(w user_wallet) RETURNS JSONB AS $$
+	SELECT jsonb_build_object(
+		'id', w.id,
+		'user_id', w.user_id,
+		'user_name', w.user_name,
+		'wallet_name', w.wallet_name,
+		'wallet_type', w.wallet_type,
+		'balance', w.balance,
+		'available_balance', w.balance - w.held,
+		'created_at', w.created_at AT TIME ZONE 'UTC',
+		'status', w.status
+	) || COALESCE((
+		SELECT jsonb_build_object('crypto', jsonb_build_object(
+			'asset', c.asset,
+			'address', c.address,
+			'decimals', c.decimals,
+			'amount', trim_scale(c.amount)::text
+		))
+		FROM crypto_wallet c WHERE c.wallet_id = w.id
+	), '{}');
+$$ LANGUAGE sql STABLE;
+
+-- log_wallet_change appends a change to the log and wakes up the streams
+-- of its user.
+CREATE OR REPLACE FUNCTION log_wallet_change(owner_id INT, change_type VARCHAR(32), w user_wallet) RETURNS VOID AS $$
+DECLARE
+	change_id BIGINT;
+BEGIN
+	INSERT INTO wallet_change (user_id, change_type, wallet)
+	VALUES (owner_id, change_type, wallet_json(w))
+	RETURNING id INTO change_id;
+
+	PERFORM pg_notify('wallet_changes', json_build_object('id', change_id, 'user_id', owner_id)::text);
+END;
+$$ LANGUAGE plpgsql;
+
+-- A wallet moving to another user leaves the log of its old owner as
+-- WalletDeleted and enters the log of the new one as WalletCreated.
+CREATE OR REPLACE FUNCTION notify_wallet_change() RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		PERFORM log_wallet_change(NEW.user_id, 'WalletCreated', NEW);
+	ELSIF TG_OP = 'DELETE' THEN
+		PERFORM log_wallet_change(OLD.user_id, 'WalletDeleted', OLD);
+	ELSIF wallet_json(NEW) = wallet_json(OLD) THEN
+		RETURN NULL;
+	ELSIF NEW.user_id <> OLD.user_id THEN
+		PERFORM log_wallet_change(OLD.user_id, 'WalletDeleted', OLD);
+		PERFORM log_wallet_change(NEW.user_id, 'WalletCreated', NEW);
+	ELSIF (wallet_json(NEW) - 'balance' - 'available_balance') = (wallet_json(OLD) - 'balance' - 'available_balance') THEN
+		PERFORM log_wallet_change(NEW.user_id, 'BalanceChanged', NEW);
+	ELSE
+		PERFORM log_wallet_change(NEW.user_id, 'WalletUpdated', NEW);
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER user_wallet_change
+AFTER INSERT OR UPDATE OR DELETE ON user_wallet
+FOR EACH ROW EXECUTE FUNCTION notify_wallet_change();
+
+-- A change to the crypto details of a wallet is a WalletUpdated of the
+-- wallet, unless the wallet is gone, which its own trigger logs, or is
+-- logged as it is already, by the trigger of the wallet written with them.
+CREATE OR REPLACE FUNCTION notify_crypto_change() RETURNS TRIGGER AS $$
+DECLARE
+	w user_wallet;
+	changed_id INT;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		changed_id := OLD.wallet_id;
+	ELSE
+		changed_id := NEW.wallet_id;
+	END IF;
+	SELECT * INTO w FROM user_wallet WHERE id = changed_id;
+	IF NOT FOUND THEN
+		RETURN NULL;
+	END IF;
+	IF (SELECT c.wallet FROM wallet_change c
+		WHERE c.user_id = w.user_id AND (c.wallet->>'id')::INT = w.id
+		ORDER BY c.id DESC LIMIT 1) = wallet_json(w) THEN
+		RETURN NULL;
+	END IF;
+	PERFORM log_wallet_change(w.user_id, 'WalletUpdated', w);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER crypto_wallet_change
+AFTER INSERT OR UPDATE OR DELETE ON crypto_wallet
+FOR EACH ROW EXECUTE FUNCTION notify_crypto_change();
+
 INSERT INTO user_wallet (user_id, user_name, wallet_name, wallet_type, balance) VALUES
 (1, 'John Doe', 'John Savings', 'Savings', 1000.00),
 (1, 'John Doe', 'John Credit Card', 'Credit Card', 500.00),
@@ -19,3 +496,38 @@ INSERT INTO user_wallet (user_id, user_name, wallet_name, wallet_type, balance)
 (2, 'Jane Doe', 'Jane Credit Card', 'Credit Card', 1000.00),
 (2, 'Jane Doe', 'Jane Crypto Wallet', 'Crypto Wallet', 200.00);
 
+INSERT INTO wallet_transaction (wallet_id, kind, amount, balance_after, description)
+SELECT id, 'opening', balance, balance, 'Opening balance' FROM user_wallet;
+
+INSERT INTO ledger_account (code, name, type, control, wallet_id)
+SELECT 'wallet:' || id, wallet_name,
+	CASE WHEN wallet_type = 'Credit Card' THEN 'asset' ELSE 'liability' END,
+	CASE WHEN wallet_type = 'Credit Card' THEN 'card_receivables' ELSE 'wallets' END,
+	id
+FROM user_wallet;
+
+INSERT INTO ledger_entry (transaction_id, description)
+SELECT id, description FROM wallet_transaction WHERE amount <> 0;
+
+INSERT INTO ledger_line (entry_id, account_id, amount)
+SELECT e.id, a.id, CASE WHEN a.type = 'asset' THEN t.amount ELSE -t.amount END
+FROM ledger_entry e
+JOIN wallet_transaction t ON t.id = e.transaction_id
+JOIN ledger_account a ON a.wallet_id = t.wallet_id
+UNION ALL
+SELECT e.id, s.id, CASE WHEN a.type = 'asset' THEN -t.amount ELSE t.amount END
+FROM ledger_entry e
+JOIN wallet_transaction t ON t.id = e.transaction_id
+JOIN ledger_account a ON a.wallet_id = t.wallet_id
+JOIN ledger_account s ON s.code = 'settlement';
+
+INSERT INTO crypto_wallet (wallet_id, asset, address, decimals, amount)
+SELECT id, 'BTC', 'bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq', 8, 0.00150000 FROM user_wallet WHERE wallet_name = 'John Crypto Wallet'
+UNION ALL
+SELECT id, 'ETH', '0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed', 18, 0.052000000000000001 FROM user_wallet WHERE wallet_name = 'Jane Crypto Wallet';
+
+INSERT INTO credit_card (wallet_id, credit_limit, apr, statement_day, due_day)
+SELECT id, 5000.00, 18.99, 25, 10 FROM user_wallet WHERE wallet_type = 'Credit Card';
+
+INSERT INTO interest_product (wallet_type, annual_rate, day_count) VALUES
+('Savings', 1.25, 'ACT/365');
@@ -0,0 +1,138 @@
+package interest
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always returns the same time, for tests and reruns.
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+type EngineStorer interface {
+	// InterestTargets returns the wallets earning interest that existed on
+	// day and had a positive balance at its end, with that balance and
+	// their effective rate.
+	InterestTargets(day time.Time) ([]Target, error)
+	// SaveAccruals stores accruals, skipping wallets already accrued that day.
+	SaveAccruals(accruals []Accrual) error
+	// CapitalizeInterest posts the unposted accruals dated in [from, to) to
+	// the balance of each wallet, one transaction per wallet.
+	CapitalizeInterest(from, to time.Time) error
+	// LastInterestDay returns the last day run, zero before the first.
+	LastInterestDay() (time.Time, error)
+	SaveInterestDay(day time.Time) error
+}
+
+// Engine accrues savings interest daily and capitalizes it monthly. Every
+// step is idempotent, so a day can safely be run again.
+type Engine struct {
+	store EngineStorer
+	clock Clock
+}
+
+func NewEngine(store EngineStorer, clock Clock) *Engine {
+	return &Engine{store: store, clock: clock}
+}
+
+// Run accrues every day since the last one run up to the previous day,
+// every interval until ctx is done, so days missed while the engine was
+// down are caught up, month ends included.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := e.catchUp(); err != nil {
+			log.Printf("interest engine: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Yesterday is the last full day according to the engine clock, in UTC.
+func (e *Engine) Yesterday() time.Time {
+	return truncate(e.clock.Now().UTC()).AddDate(0, 0, -1)
+}
+
+// catchUp runs the days after the last one run up to yesterday, or only
+// yesterday on the first run.
+func (e *Engine) catchUp() error {
+	yesterday := e.Yesterday()
+	last, err := e.store.LastInterestDay()
+	if err != nil {
+		return err
+	}
+	from := yesterday
+	if !last.IsZero() && last.Before(yesterday) {
+		from = truncate(last).AddDate(0, 0, 1)
+	}
+	return e.RunRange(from, yesterday)
+}
+
+// RunDay accrues interest for day on the balances at its end and, when its
+// month is over, capitalizes the month.
+func (e *Engine) RunDay(day time.Time) error {
+	return e.RunRange(day, day)
+}
+
+// RunRange accrues every day from from to to, both included, then
+// capitalizes every month of the range that is over by the engine clock,
+// so a backfill posts the interest of the months it completes.
+func (e *Engine) RunRange(from, to time.Time) error {
+	from, to = truncate(from), truncate(to)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if err := e.accrue(day); err != nil {
+			return err
+		}
+	}
+
+	today := e.Yesterday().AddDate(0, 0, 1)
+	for month := monthOf(from); !month.After(to); month = month.AddDate(0, 1, 0) {
+		next := month.AddDate(0, 1, 0)
+		if next.After(today) {
+			continue
+		}
+		if err := e.store.CapitalizeInterest(month, next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Engine) accrue(day time.Time) error {
+	targets, err := e.store.InterestTargets(day)
+	if err != nil {
+		return err
+	}
+
+	accruals := make([]Accrual, 0, len(targets))
+	for _, t := range targets {
+		accruals = append(accruals, Accrue(t, day))
+	}
+	if err := e.store.SaveAccruals(accruals); err != nil {
+		return err
+	}
+	return e.store.SaveInterestDay(day)
+}
+
+func monthOf(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
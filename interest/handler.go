@@ -0,0 +1,172 @@
+package interest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	InterestHistory(walletID int) (*History, error)
+	SaveWalletRate(r Rate) (*Rate, error)
+	ProductRates() ([]Rate, error)
+	SaveProductRate(r Rate) (*Rate, error)
+	WalletTypes() ([]wallet.Type, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// GetHistory
+//
+//	@Summary		Get interest history
+//	@Description	Get the effective rate, daily accruals and monthly postings of a wallet
+//	@Tags			interest
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	History
+//	@Router			/api/v1/wallets/{id}/interest [get]
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+func (h *Handler) GetHistory(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+
+	history, err := h.store.InterestHistory(walletId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, history)
+}
+
+// SaveWalletRate
+//
+//	@Summary		Set wallet interest rate
+//	@Description	Override the product interest rate of one wallet
+//	@Tags			interest
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Rate
+//	@Router			/api/v1/wallets/{id}/interest/rate [put]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   rate  body		Rate	true	"Rate"
+//	@Security	AdminToken
+func (h *Handler) SaveWalletRate(c echo.Context) error {
+	var r Rate
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+	r.WalletID, r.WalletType = walletId, ""
+	if r.DayCount == "" {
+		r.DayCount = ACT365
+	}
+	if msg := validate(r); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	rate, err := h.store.SaveWalletRate(r)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, rate)
+}
+
+// GetProductRates
+//
+//	@Summary		Get product interest rates
+//	@Description	Get the interest rate of every wallet type earning interest
+//	@Tags			interest
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Rate
+//	@Router			/api/v1/interest/products [get]
+//	@Failure		500	{object}	Err
+//	@Security	AdminToken
+func (h *Handler) GetProductRates(c echo.Context) error {
+	rates, err := h.store.ProductRates()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, rates)
+}
+
+// SaveProductRate
+//
+//	@Summary		Set product interest rate
+//	@Description	Set the interest rate of a wallet type
+//	@Tags			interest
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Rate
+//	@Router			/api/v1/interest/products/{wallet_type} [put]
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   wallet_type  path		string	true	"Wallet type key, see /api/v1/wallet-types"
+//	@Param   rate  body		Rate	true	"Rate"
+//	@Security	AdminToken
+func (h *Handler) SaveProductRate(c echo.Context) error {
+	var r Rate
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	types, err := h.store.WalletTypes()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	walletType, ok := wallet.ResolveType(types, c.Param("wallet_type"))
+	if !ok {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet type"})
+	}
+	r.WalletID, r.WalletType = 0, walletType.Name
+	if r.DayCount == "" {
+		r.DayCount = ACT365
+	}
+	if msg := validate(r); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	rate, err := h.store.SaveProductRate(r)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, rate)
+}
+
+func validate(r Rate) string {
+	if r.AnnualRate < 0 || r.AnnualRate > 100 {
+		return "Invalid annual rate"
+	}
+	if !ValidDayCount(r.DayCount) {
+		return "Invalid day count convention"
+	}
+	return ""
+}
+
+func storeError(c echo.Context, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
@@ -0,0 +1,120 @@
+package interest
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Day count conventions deciding how much of a year one day is.
+const (
+	// ACT365 counts actual days over a 365 day year.
+	ACT365 = "ACT/365"
+	// Thirty360 counts every month as 30 days over a 360 day year (US 30/360),
+	// so a month always earns the same, whatever its length.
+	Thirty360 = "30/360"
+)
+
+var ErrNotFound = errors.New("interest rate not found")
+
+// Rate is an annual interest rate in percent. It is set per product, which
+// is a wallet type, and may be overridden per wallet.
+type Rate struct {
+	WalletID   int     `json:"wallet_id,omitempty" example:"1"`
+	WalletType string  `json:"wallet_type,omitempty" example:"Savings"`
+	AnnualRate float64 `json:"annual_rate" example:"1.25"`
+	DayCount   string  `json:"day_count" example:"ACT/365"`
+}
+
+// Target is a wallet that earns interest, with its effective rate.
+type Target struct {
+	WalletID   int
+	Balance    float64
+	AnnualRate float64
+	DayCount   string
+}
+
+type Accrual struct {
+	WalletID   int        `json:"wallet_id" example:"1"`
+	Date       time.Time  `json:"date" example:"2024-03-25T00:00:00Z"`
+	Balance    float64    `json:"balance" example:"1000.00"`
+	AnnualRate float64    `json:"annual_rate" example:"1.25"`
+	DayCount   string     `json:"day_count" example:"ACT/365"`
+	Amount     float64    `json:"amount" example:"0.034247"`
+	PostedAt   *time.Time `json:"posted_at,omitempty" example:"2024-04-01T00:00:00Z"`
+}
+
+type Posting struct {
+	TransactionID int64     `json:"transaction_id" example:"42"`
+	Month         string    `json:"month" example:"2024-03"`
+	Amount        float64   `json:"amount" example:"1.06"`
+	PostedAt      time.Time `json:"posted_at" example:"2024-04-01T00:00:00Z"`
+}
+
+type History struct {
+	WalletID int       `json:"wallet_id" example:"1"`
+	Rate     *Rate     `json:"rate"`
+	Accrued  float64   `json:"accrued" example:"0.82"`
+	Accruals []Accrual `json:"accruals"`
+	Postings []Posting `json:"postings"`
+}
+
+func ValidDayCount(dc string) bool {
+	return dc == ACT365 || dc == Thirty360
+}
+
+// YearFraction returns the part of a year between from and to under the
+// given day count convention. Only the dates matter, not the times.
+func YearFraction(dayCount string, from, to time.Time) float64 {
+	switch dayCount {
+	case Thirty360:
+		return float64(days360(from, to)) / 360
+	default:
+		return float64(actualDays(from, to)) / 365
+	}
+}
+
+func actualDays(from, to time.Time) int {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	a := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	b := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func days360(from, to time.Time) int {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+	return 360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1)
+}
+
+// Accrue returns the interest earned by t over the day date. Amounts are
+// kept to six decimals and only rounded to cents when posted.
+func Accrue(t Target, date time.Time) Accrual {
+	day := truncate(date)
+	fraction := YearFraction(t.DayCount, day, day.AddDate(0, 0, 1))
+	amount := t.Balance * t.AnnualRate / 100 * fraction
+	return Accrual{
+		WalletID:   t.WalletID,
+		Date:       day,
+		Balance:    t.Balance,
+		AnnualRate: t.AnnualRate,
+		DayCount:   t.DayCount,
+		Amount:     roundTo(amount, 1e6),
+	}
+}
+
+func truncate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func roundTo(v, unit float64) float64 {
+	return math.Round(v*unit) / unit
+}
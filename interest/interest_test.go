@@ -0,0 +1,319 @@
+package interest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type StubInterest struct {
+	targets []Target
+	// balances, when set, gives the balance of every target at the end of
+	// a day.
+	balances    map[string]float64
+	accruals    map[string]Accrual
+	capitalized [][2]time.Time
+	lastDay     time.Time
+	saved       []Rate
+}
+
+func (s *StubInterest) InterestTargets(day time.Time) ([]Target, error) {
+	if s.balances == nil {
+		return s.targets, nil
+	}
+	var targets []Target
+	for _, t := range s.targets {
+		if b, ok := s.balances[day.Format(time.DateOnly)]; ok {
+			t.Balance = b
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
+}
+
+func (s *StubInterest) LastInterestDay() (time.Time, error) {
+	return s.lastDay, nil
+}
+
+func (s *StubInterest) SaveInterestDay(day time.Time) error {
+	if day.After(s.lastDay) {
+		s.lastDay = day
+	}
+	return nil
+}
+
+func (s *StubInterest) SaveAccruals(accruals []Accrual) error {
+	if s.accruals == nil {
+		s.accruals = map[string]Accrual{}
+	}
+	for _, a := range accruals {
+		key := fmt.Sprintf("%d/%s", a.WalletID, a.Date.Format(time.DateOnly))
+		if _, ok := s.accruals[key]; !ok {
+			s.accruals[key] = a
+		}
+	}
+	return nil
+}
+
+func (s *StubInterest) CapitalizeInterest(from, to time.Time) error {
+	s.capitalized = append(s.capitalized, [2]time.Time{from, to})
+	return nil
+}
+
+func (s *StubInterest) InterestHistory(walletID int) (*History, error) {
+	return &History{WalletID: walletID, Accruals: []Accrual{}, Postings: []Posting{}}, nil
+}
+
+func (s *StubInterest) SaveWalletRate(r Rate) (*Rate, error) {
+	if r.WalletID != 1 {
+		return nil, ErrNotFound
+	}
+	s.saved = append(s.saved, r)
+	return &r, nil
+}
+
+func (s *StubInterest) ProductRates() ([]Rate, error) {
+	return s.saved, nil
+}
+
+func (s *StubInterest) SaveProductRate(r Rate) (*Rate, error) {
+	s.saved = append(s.saved, r)
+	return &r, nil
+}
+
+func (s *StubInterest) WalletTypes() ([]wallet.Type, error) {
+	return []wallet.Type{{Key: "Savings", Name: wallet.TypeSavings}, {Key: "CreditCard", Name: wallet.TypeCreditCard}}, nil
+}
+
+func date(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+func TestYearFraction(t *testing.T) {
+	tests := []struct {
+		dayCount string
+		from, to string
+		want     float64
+	}{
+		{ACT365, "2024-01-01", "2024-01-02", 1.0 / 365},
+		{ACT365, "2024-02-28", "2024-03-01", 2.0 / 365},
+		{ACT365, "2024-01-01", "2025-01-01", 366.0 / 365},
+		{Thirty360, "2024-01-30", "2024-01-31", 0},
+		{Thirty360, "2024-01-31", "2024-02-01", 1.0 / 360},
+		{Thirty360, "2023-02-28", "2023-03-01", 3.0 / 360},
+		{Thirty360, "2024-01-01", "2025-01-01", 1},
+	}
+	for _, tt := range tests {
+		got := YearFraction(tt.dayCount, date(tt.from), date(tt.to))
+		if got != tt.want {
+			t.Errorf("YearFraction(%s, %s, %s) = %v, want %v", tt.dayCount, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestThirty360MonthsEarnTheSame(t *testing.T) {
+	for _, month := range []string{"2023-02-01", "2024-02-01", "2024-03-01", "2024-04-01"} {
+		start := date(month)
+		total := 0.0
+		for day := start; day.Before(start.AddDate(0, 1, 0)); day = day.AddDate(0, 0, 1) {
+			total += YearFraction(Thirty360, day, day.AddDate(0, 0, 1))
+		}
+		if roundTo(total, 1e9) != roundTo(30.0/360, 1e9) {
+			t.Errorf("month %s accrued %v of a year, want %v", month, total, 30.0/360)
+		}
+	}
+}
+
+func TestAccrue(t *testing.T) {
+	a := Accrue(Target{WalletID: 1, Balance: 1000, AnnualRate: 1.25, DayCount: ACT365}, time.Date(2024, 3, 25, 13, 0, 0, 0, time.UTC))
+
+	if !a.Date.Equal(date("2024-03-25")) {
+		t.Errorf("expected accrual on 2024-03-25 but got %v", a.Date)
+	}
+	if a.Amount != 0.034247 {
+		t.Errorf("expected 0.034247 but got %v", a.Amount)
+	}
+}
+
+func TestEngine(t *testing.T) {
+	t.Run("given a day in the month should accrue without capitalizing", func(t *testing.T) {
+		stub := &StubInterest{targets: []Target{{WalletID: 1, Balance: 1000, AnnualRate: 1.25, DayCount: ACT365}}}
+		engine := NewEngine(stub, FixedClock(time.Date(2024, 3, 16, 1, 0, 0, 0, time.UTC)))
+
+		if err := engine.RunDay(engine.Yesterday()); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(stub.accruals) != 1 {
+			t.Errorf("expected 1 accrual but got %d", len(stub.accruals))
+		}
+		if len(stub.capitalized) != 0 {
+			t.Errorf("expected no capitalization but got %v", stub.capitalized)
+		}
+	})
+
+	t.Run("given the last day of the month should capitalize the month", func(t *testing.T) {
+		stub := &StubInterest{targets: []Target{{WalletID: 1, Balance: 1000, AnnualRate: 1.25, DayCount: ACT365}}}
+		engine := NewEngine(stub, FixedClock(time.Date(2024, 3, 1, 0, 30, 0, 0, time.UTC)))
+
+		if err := engine.RunDay(engine.Yesterday()); err != nil {
+			t.Fatal(err)
+		}
+
+		want := [2]time.Time{date("2024-02-01"), date("2024-03-01")}
+		if len(stub.capitalized) != 1 || stub.capitalized[0] != want {
+			t.Errorf("expected capitalization of %v but got %v", want, stub.capitalized)
+		}
+	})
+
+	t.Run("given a range should accrue every day once", func(t *testing.T) {
+		stub := &StubInterest{targets: []Target{{WalletID: 1, Balance: 1000, AnnualRate: 1.25, DayCount: ACT365}}}
+		engine := NewEngine(stub, FixedClock(time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC)))
+
+		if err := engine.RunRange(date("2024-03-30"), date("2024-04-02")); err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.RunRange(date("2024-03-30"), date("2024-04-02")); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(stub.accruals) != 4 {
+			t.Errorf("expected 4 accruals but got %d", len(stub.accruals))
+		}
+		want := [2]time.Time{date("2024-03-01"), date("2024-04-01")}
+		if len(stub.capitalized) != 2 || stub.capitalized[0] != want || stub.capitalized[1] != want {
+			t.Errorf("expected March capitalized on each run but got %v", stub.capitalized)
+		}
+	})
+
+	t.Run("given a backfill should accrue on the balance of each day", func(t *testing.T) {
+		stub := &StubInterest{
+			targets:  []Target{{WalletID: 1, AnnualRate: 1.25, DayCount: ACT365}},
+			balances: map[string]float64{"2024-03-02": 1000, "2024-03-03": 2000},
+		}
+		engine := NewEngine(stub, FixedClock(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)))
+
+		if err := engine.RunRange(date("2024-03-01"), date("2024-03-03")); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(stub.accruals) != 2 {
+			t.Errorf("expected no accrual before the wallet existed but got %v", stub.accruals)
+		}
+		if a := stub.accruals["1/2024-03-03"]; a.Balance != 2000 {
+			t.Errorf("expected the balance of 2024-03-03 but got %v", a.Balance)
+		}
+		want := [2]time.Time{date("2024-03-01"), date("2024-04-01")}
+		if len(stub.capitalized) != 1 || stub.capitalized[0] != want {
+			t.Errorf("expected the past month capitalized but got %v", stub.capitalized)
+		}
+	})
+
+	t.Run("given missed days should catch up through the month end", func(t *testing.T) {
+		stub := &StubInterest{
+			targets: []Target{{WalletID: 1, Balance: 1000, AnnualRate: 1.25, DayCount: ACT365}},
+			lastDay: date("2024-03-29"),
+		}
+		engine := NewEngine(stub, FixedClock(time.Date(2024, 4, 3, 1, 0, 0, 0, time.UTC)))
+
+		if err := engine.catchUp(); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(stub.accruals) != 4 || !stub.lastDay.Equal(date("2024-04-02")) {
+			t.Errorf("expected 2024-03-30 to 2024-04-02 accrued but got %v up to %v", stub.accruals, stub.lastDay)
+		}
+		want := [2]time.Time{date("2024-03-01"), date("2024-04-01")}
+		if len(stub.capitalized) != 1 || stub.capitalized[0] != want {
+			t.Errorf("expected the missed month end capitalized but got %v", stub.capitalized)
+		}
+	})
+}
+
+func TestRateHandlers(t *testing.T) {
+	t.Run("given a wallet rate without day count should default to ACT/365", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"annual_rate": 2.5}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		stub := &StubInterest{}
+		h := New(stub)
+
+		h.SaveWalletRate(c)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+		var got Rate
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		if got.WalletID != 1 || got.DayCount != ACT365 {
+			t.Errorf("unexpected rate %+v", got)
+		}
+	})
+
+	t.Run("given an unknown day count should return 400", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"annual_rate": 2.5, "day_count": "ACT/360"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		h := New(&StubInterest{})
+
+		h.SaveWalletRate(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given an unknown wallet should return 404", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"annual_rate": 2.5}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+		h := New(&StubInterest{})
+
+		h.SaveWalletRate(c)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+
+	t.Run("given a product rate should save it under the wallet type name", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"annual_rate": 1.5, "day_count": "30/360"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("wallet_type")
+		c.SetParamValues("Savings")
+		stub := &StubInterest{}
+		h := New(stub)
+
+		h.SaveProductRate(c)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+		if len(stub.saved) != 1 || stub.saved[0].WalletType != "Savings" {
+			t.Errorf("unexpected saved rates %+v", stub.saved)
+		}
+	})
+}
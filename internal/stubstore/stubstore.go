@@ -0,0 +1,780 @@
+// Package stubstore is an api.Store in memory, for tests that run the real
+// handlers. It holds a few wallets, which it changes as asked, and answers
+// every other read with data shaped like what postgres returns.
+package stubstore
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/fee"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/hold"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/interest"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/ledger"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/reconcile"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/report"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/schedule"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/statement"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/stream"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transfer"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/user"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/webhook"
+)
+
+// Created is when every fixture was created.
+var Created = time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+
+// Ids of the fixtures. Other ids are not found.
+const (
+	// SavingsID is John's savings wallet, active, with 25.00 held.
+	SavingsID = 1
+	// CardID is Jane's credit card wallet, frozen.
+	CardID = 2
+	// CryptoID is John's bitcoin wallet, active.
+	CryptoID = 3
+
+	TransactionID = 1
+	HoldID        = 1
+	TransferID    = 1
+	ScheduleID    = 1
+	FileID        = 1
+	ItemID        = 2
+	WebhookID     = 1
+	DeliveryID    = 1
+)
+
+type Store struct {
+	mu      sync.Mutex
+	wallets []wallet.Wallet
+	// Actor is the actor of the last wallet change.
+	Actor audit.Actor
+}
+
+func New() *Store {
+	return &Store{wallets: []wallet.Wallet{
+		{ID: SavingsID, UserID: 1, UserName: "John Doe", WalletName: "John Savings", WalletType: wallet.TypeSavings,
+			Balance: 100, AvailableBalance: 75, Status: wallet.StatusActive, CreatedAt: Created},
+		{ID: CardID, UserID: 2, UserName: "Jane Doe", WalletName: "Jane Card", WalletType: wallet.TypeCreditCard,
+			Balance: -20, AvailableBalance: -20, Status: wallet.StatusFrozen, CreatedAt: Created},
+		{ID: CryptoID, UserID: 1, UserName: "John Doe", WalletName: "John Bitcoin", WalletType: wallet.TypeCryptoWallet,
+			Balance: 10, AvailableBalance: 10, Status: wallet.StatusActive, CreatedAt: Created,
+			Crypto: &wallet.Crypto{Asset: "BTC", Address: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", Decimals: 8, Amount: "0.5"}},
+	}}
+}
+
+func (s *Store) find(id int) (int, error) {
+	for i, w := range s.wallets {
+		if w.ID == id {
+			return i, nil
+		}
+	}
+	return 0, wallet.ErrWalletNotFound
+}
+
+func (s *Store) filter(keep func(wallet.Wallet) bool) []wallet.Wallet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wallets := []wallet.Wallet{}
+	for _, w := range s.wallets {
+		if keep(w) {
+			wallets = append(wallets, w)
+		}
+	}
+	return wallets
+}
+
+func (s *Store) Wallets(walletType string) ([]wallet.Wallet, error) {
+	return s.filter(func(w wallet.Wallet) bool { return walletType == "" || w.WalletType == walletType }), nil
+}
+
+func (s *Store) WalletsByUserID(userId int) ([]wallet.Wallet, error) {
+	return s.filter(func(w wallet.Wallet) bool { return w.UserID == userId }), nil
+}
+
+func (s *Store) WalletsByIDs(ids []int) ([]wallet.Wallet, error) {
+	return s.filter(func(w wallet.Wallet) bool { return contains(ids, w.ID) }), nil
+}
+
+func (s *Store) WalletsByUserIDs(userIDs []int) ([]wallet.Wallet, error) {
+	return s.filter(func(w wallet.Wallet) bool { return contains(userIDs, w.UserID) }), nil
+}
+
+func contains(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) CreateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.ID = 1
+	for _, o := range s.wallets {
+		w.ID = max(w.ID, o.ID+1)
+	}
+	w.Status, w.CreatedAt, w.AvailableBalance = wallet.StatusActive, Created, w.Balance
+	s.wallets, s.Actor = append(s.wallets, w), actor
+	return &w, nil
+}
+
+func (s *Store) UpdateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, err := s.find(w.ID)
+	if err != nil {
+		return nil, err
+	}
+	if status := s.wallets[i].Status; status != wallet.StatusActive {
+		return nil, &wallet.StatusError{WalletID: w.ID, Status: status}
+	}
+	if w.WalletType == wallet.TypeCryptoWallet && w.Crypto == nil {
+		if s.wallets[i].Crypto == nil {
+			return nil, wallet.ErrMissingCrypto
+		}
+		w.Crypto = s.wallets[i].Crypto
+	}
+	w.Status, w.CreatedAt = wallet.StatusActive, s.wallets[i].CreatedAt
+	w.AvailableBalance = w.Balance - (s.wallets[i].Balance - s.wallets[i].AvailableBalance)
+	s.wallets[i], s.Actor = w, actor
+	return &w, nil
+}
+
+func (s *Store) DeleteWallet(id int, actor audit.Actor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if status := s.wallets[i].Status; status != wallet.StatusActive {
+		return &wallet.StatusError{WalletID: id, Status: status}
+	}
+	s.wallets, s.Actor = append(s.wallets[:i], s.wallets[i+1:]...), actor
+	return nil
+}
+
+func (s *Store) ImportWallets(wallets []wallet.Wallet, actor audit.Actor) (int, error) {
+	for _, w := range wallets {
+		s.CreateWallet(w, actor)
+	}
+	return len(wallets), nil
+}
+
+func (s *Store) EachWallet(walletType string, fn func(wallet.Wallet) error) error {
+	wallets, _ := s.Wallets(walletType)
+	for _, w := range wallets {
+		if err := fn(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) WalletTypes() ([]wallet.Type, error) {
+	return []wallet.Type{
+		{Key: "Savings", Name: wallet.TypeSavings},
+		{Key: "CreditCard", Name: wallet.TypeCreditCard, AllowNegative: true},
+		{Key: "CryptoWallet", Name: wallet.TypeCryptoWallet},
+	}, nil
+}
+
+func (s *Store) walletType(key string) (*wallet.Type, error) {
+	types, _ := s.WalletTypes()
+	for _, t := range types {
+		if t.Key == key {
+			return &t, nil
+		}
+	}
+	return nil, wallet.ErrTypeNotFound
+}
+
+func (s *Store) CreateWalletType(t wallet.Type) (*wallet.Type, error) {
+	if _, err := s.walletType(t.Key); err == nil {
+		return nil, wallet.ErrTypeExists
+	}
+	return &t, nil
+}
+
+func (s *Store) UpdateWalletType(t wallet.Type) (*wallet.Type, error) {
+	if _, err := s.walletType(t.Key); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *Store) DeleteWalletType(key string) error {
+	t, err := s.walletType(key)
+	if err != nil {
+		return err
+	}
+	if used, _ := s.Wallets(t.Name); len(used) > 0 {
+		return wallet.ErrTypeInUse
+	}
+	return nil
+}
+
+func (s *Store) ChangeStatus(id int, status, reason string, actor audit.Actor) (*wallet.Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := wallet.CheckTransition(s.wallets[i].Status, status); err != nil {
+		return nil, err
+	}
+	s.wallets[i].Status, s.Actor = status, actor
+	w := s.wallets[i]
+	return &w, nil
+}
+
+func (s *Store) StatusHistory(id int) ([]wallet.StatusChange, error) {
+	if id != CardID {
+		return []wallet.StatusChange{}, nil
+	}
+	return []wallet.StatusChange{{ID: 1, WalletID: id, From: wallet.StatusActive, To: wallet.StatusFrozen,
+		Reason: "Suspected card testing", Actor: "ops@example.com", CreatedAt: Created.Add(time.Hour)}}, nil
+}
+
+func (s *Store) wallet(id int) (*wallet.Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	w := s.wallets[i]
+	return &w, nil
+}
+
+func (s *Store) SummaryByUserID(userId int) (*user.Summary, error) {
+	wallets, _ := s.WalletsByUserID(userId)
+	summary := &user.Summary{UserID: userId, ByType: []user.TypeBalance{}}
+	byType := map[string]int{}
+	for _, w := range wallets {
+		summary.WalletCount++
+		summary.TotalBalance += w.Balance
+		summary.NetWorth += w.Balance
+		i, ok := byType[w.WalletType]
+		if !ok {
+			i = len(summary.ByType)
+			byType[w.WalletType] = i
+			summary.ByType = append(summary.ByType, user.TypeBalance{WalletType: w.WalletType})
+		}
+		summary.ByType[i].WalletCount++
+		summary.ByType[i].Balance += w.Balance
+	}
+	return summary, nil
+}
+
+func (s *Store) Transactions(walletID int) ([]transaction.Transaction, error) {
+	if _, err := s.wallet(walletID); err != nil {
+		return []transaction.Transaction{}, nil
+	}
+	return []transaction.Transaction{{ID: TransactionID, WalletID: walletID, Kind: transaction.KindOpening,
+		Amount: 100, BalanceAfter: 100, Description: "Opening balance", CreatedAt: Created}}, nil
+}
+
+// post moves the balance of an active wallet by amount.
+func (s *Store) post(walletID int, kind string, amount float64, description string) (*transaction.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, err := s.find(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if status := s.wallets[i].Status; status != wallet.StatusActive {
+		return nil, &wallet.StatusError{WalletID: walletID, Status: status}
+	}
+	s.wallets[i].Balance += amount
+	s.wallets[i].AvailableBalance += amount
+	return &transaction.Transaction{ID: 2, WalletID: walletID, Kind: kind, Amount: amount,
+		BalanceAfter: s.wallets[i].Balance, Description: description, CreatedAt: Created.Add(time.Hour)}, nil
+}
+
+func (s *Store) Deposit(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	return s.post(walletID, transaction.KindDeposit, amount, description)
+}
+
+func (s *Store) Withdraw(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	return s.post(walletID, transaction.KindWithdrawal, -amount, description)
+}
+
+func (s *Store) Reverse(id int64, amount *float64, reason string, actor audit.Actor) (*transaction.Reversal, error) {
+	if id != TransactionID {
+		return nil, transaction.ErrNotFound
+	}
+	reversed := 100.0
+	if amount != nil {
+		reversed = *amount
+	}
+	original := TransactionID
+	return &transaction.Reversal{
+		Reversed: []transaction.Transaction{{ID: TransactionID, WalletID: SavingsID, Kind: transaction.KindOpening,
+			Amount: 100, BalanceAfter: 100, Description: "Opening balance", Reversed: reversed, CreatedAt: Created}},
+		Entries: []transaction.Transaction{{ID: 3, WalletID: SavingsID, Kind: transaction.KindReversal,
+			Amount: -reversed, BalanceAfter: 100 - reversed, Description: reason, ReversalOf: ptr(int64(original)), CreatedAt: Created.Add(time.Hour)}},
+	}, nil
+}
+
+func (s *Store) Statement(walletID int, start, end time.Time) (*statement.Statement, error) {
+	w, err := s.wallet(walletID)
+	if err != nil {
+		return nil, err
+	}
+	lines := []statement.Line{
+		{Transaction: transaction.Transaction{ID: 2, WalletID: walletID, Kind: transaction.KindDeposit, Amount: 50, Description: "Salary", CreatedAt: start.Add(24 * time.Hour)}, Balance: 150},
+		{Transaction: transaction.Transaction{ID: 3, WalletID: walletID, Kind: transaction.KindWithdrawal, Amount: -50, Description: "Rent", CreatedAt: start.Add(48 * time.Hour)}, Balance: 100},
+	}
+	return &statement.Statement{WalletID: walletID, WalletName: w.WalletName, WalletType: w.WalletType, UserName: w.UserName,
+		PeriodStart: start, PeriodEnd: end, OpeningBalance: 100, Lines: lines, TotalIn: 50, TotalOut: 50, ClosingBalance: 100}, nil
+}
+
+func (s *Store) hold() hold.Hold {
+	return hold.Hold{ID: HoldID, WalletID: SavingsID, Amount: 25, Description: "Hotel booking", Status: hold.StatusActive,
+		ExpiresAt: Created.Add(7 * 24 * time.Hour), CreatedAt: Created}
+}
+
+func (s *Store) CreateHold(walletID int, r hold.Request) (*hold.Hold, error) {
+	if _, err := s.wallet(walletID); err != nil {
+		return nil, err
+	}
+	h := hold.Hold{ID: 2, WalletID: walletID, Amount: r.Amount, Description: r.Description, Status: hold.StatusActive,
+		ExpiresAt: Created.Add(7 * 24 * time.Hour), CreatedAt: Created}
+	if r.ExpiresAt != nil {
+		h.ExpiresAt = *r.ExpiresAt
+	}
+	return &h, nil
+}
+
+func (s *Store) Holds(walletID int) ([]hold.Hold, error) {
+	if walletID != SavingsID {
+		return []hold.Hold{}, nil
+	}
+	return []hold.Hold{s.hold()}, nil
+}
+
+func (s *Store) CaptureHold(walletID int, id int64, c hold.Capture) (*hold.Hold, error) {
+	if walletID != SavingsID || id != HoldID {
+		return nil, hold.ErrNotFound
+	}
+	h := s.hold()
+	captured := h.Amount
+	if c.Amount != nil {
+		captured = *c.Amount
+	}
+	if captured > h.Amount {
+		return nil, hold.ErrOverCapture
+	}
+	h.Status, h.CapturedAmount, h.TransactionID, h.ResolvedAt = hold.StatusCaptured, &captured, ptr(int64(4)), ptr(Created.Add(time.Hour))
+	return &h, nil
+}
+
+func (s *Store) VoidHold(walletID int, id int64) (*hold.Hold, error) {
+	if walletID != SavingsID || id != HoldID {
+		return nil, hold.ErrNotFound
+	}
+	h := s.hold()
+	h.Status, h.ResolvedAt = hold.StatusVoided, ptr(Created.Add(time.Hour))
+	return &h, nil
+}
+
+func (s *Store) WalletLimits(walletID int) (*limit.WalletLimits, error) {
+	if _, err := s.wallet(walletID); err != nil {
+		return nil, limit.ErrNotFound
+	}
+	l := limit.Limits{DailyOutflow: ptr(1000.0), MaxTransfersPerHour: ptr(5)}
+	return &limit.WalletLimits{WalletID: walletID, Type: l, Effective: l,
+		Usage: limit.Usage{Daily: 120, Monthly: 1520, TransfersHour: 2}}, nil
+}
+
+func (s *Store) SaveWalletLimits(walletID int, l limit.Limits) (*limit.WalletLimits, error) {
+	limits, err := s.WalletLimits(walletID)
+	if err != nil {
+		return nil, err
+	}
+	limits.Wallet = l
+	limits.Effective = limit.Effective(l, limits.Type)
+	return limits, nil
+}
+
+func (s *Store) TypeLimits(key string) (*limit.Limits, error) {
+	if _, err := s.walletType(key); err != nil {
+		return nil, limit.ErrTypeNotFound
+	}
+	return &limit.Limits{DailyOutflow: ptr(1000.0), MaxTransfersPerHour: ptr(5)}, nil
+}
+
+func (s *Store) SaveTypeLimits(key string, l limit.Limits) (*limit.Limits, error) {
+	if _, err := s.walletType(key); err != nil {
+		return nil, limit.ErrTypeNotFound
+	}
+	return &l, nil
+}
+
+func (s *Store) QuoteTransfer(r transfer.Request) (*transfer.Quote, error) {
+	for _, id := range []int{r.FromWalletID, r.ToWalletID} {
+		if _, err := s.wallet(id); err != nil {
+			return nil, err
+		}
+	}
+	return &transfer.Quote{FromWalletID: r.FromWalletID, ToWalletID: r.ToWalletID, Amount: r.Amount, Fee: 0.5, Total: r.Amount + 0.5}, nil
+}
+
+func (s *Store) CreateTransfer(r transfer.Request) (*transfer.Transfer, error) {
+	q, err := s.QuoteTransfer(r)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer.Transfer{ID: 2, FromWalletID: r.FromWalletID, ToWalletID: r.ToWalletID, Amount: r.Amount, Description: r.Description,
+		DebitTransactionID: 5, CreditTransactionID: 6, Fee: q.Fee, FeeTransactionID: ptr(int64(7)), CreatedAt: Created.Add(time.Hour)}, nil
+}
+
+func (s *Store) Transfer(id int64) (*transfer.Transfer, error) {
+	if id != TransferID {
+		return nil, transfer.ErrNotFound
+	}
+	return &transfer.Transfer{ID: TransferID, FromWalletID: SavingsID, ToWalletID: CardID, Amount: 50, Description: "Card repayment",
+		DebitTransactionID: 2, CreditTransactionID: 3, CreatedAt: Created}, nil
+}
+
+func (s *Store) schedule() schedule.Schedule {
+	next := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	return schedule.Schedule{ID: ScheduleID, FromWalletID: SavingsID, ToWalletID: CardID, Amount: 50, Description: "Card repayment",
+		Recurrence: "FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9;BYMINUTE=0", StartAt: Created, OnInsufficientFunds: schedule.PolicyRetry,
+		MaxRetries: 3, RetryDelay: "6h", Status: schedule.StatusActive, OccurrenceAt: &next, NextRunAt: &next, CreatedAt: Created, UpdatedAt: Created}
+}
+
+func (s *Store) CreateSchedule(sc schedule.Schedule) (*schedule.Schedule, error) {
+	for _, id := range []int{sc.FromWalletID, sc.ToWalletID} {
+		if _, err := s.wallet(id); err != nil {
+			return nil, err
+		}
+	}
+	sc.ID, sc.CreatedAt, sc.UpdatedAt = 2, Created, Created
+	return &sc, nil
+}
+
+func (s *Store) Schedule(id int64) (*schedule.Schedule, error) {
+	if id != ScheduleID {
+		return nil, schedule.ErrNotFound
+	}
+	sc := s.schedule()
+	return &sc, nil
+}
+
+func (s *Store) WalletSchedules(walletID int) ([]schedule.Schedule, error) {
+	if walletID != SavingsID {
+		return []schedule.Schedule{}, nil
+	}
+	return []schedule.Schedule{s.schedule()}, nil
+}
+
+func (s *Store) UpdateSchedule(sc schedule.Schedule) (*schedule.Schedule, error) {
+	if sc.ID != ScheduleID {
+		return nil, schedule.ErrNotFound
+	}
+	if !sc.UpdatedAt.Equal(Created) {
+		return nil, schedule.ErrConflict
+	}
+	sc.UpdatedAt = Created.Add(time.Hour)
+	return &sc, nil
+}
+
+func (s *Store) DeleteSchedule(id int64) error {
+	if id != ScheduleID {
+		return schedule.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) Executions(scheduleID int64) ([]schedule.Execution, error) {
+	if scheduleID != ScheduleID {
+		return nil, schedule.ErrNotFound
+	}
+	at := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	return []schedule.Execution{{ID: 1, ScheduleID: scheduleID, ScheduledFor: at, Attempt: 1, Status: schedule.ExecutionSucceeded,
+		TransferID: ptr(int64(TransferID)), ExecutedAt: at.Add(3 * time.Second)}}, nil
+}
+
+func (s *Store) card(walletID int) (*creditcard.Account, error) {
+	w, err := s.wallet(walletID)
+	if err != nil {
+		return nil, creditcard.ErrNotFound
+	}
+	if w.WalletType != wallet.TypeCreditCard {
+		return nil, creditcard.ErrNotCreditCard
+	}
+	return &creditcard.Account{WalletID: walletID, CreditLimit: 5000, Balance: -w.Balance, AvailableCredit: 5000 + w.Balance,
+		APR: 18.99, StatementDay: 25, DueDay: 10, CreatedAt: Created}, nil
+}
+
+// CreditCard finds the account of credit card wallets only, as the terms of
+// other wallets are not found.
+func (s *Store) CreditCard(walletID int) (*creditcard.Account, error) {
+	a, err := s.card(walletID)
+	if errors.Is(err, creditcard.ErrNotCreditCard) {
+		return nil, creditcard.ErrNotFound
+	}
+	return a, err
+}
+
+func (s *Store) SaveCreditCard(walletID int, terms creditcard.Terms) (*creditcard.Account, error) {
+	a, err := s.card(walletID)
+	if err != nil {
+		return nil, err
+	}
+	a.CreditLimit, a.APR, a.StatementDay, a.DueDay = terms.CreditLimit, terms.APR, terms.StatementDay, terms.DueDay
+	a.AvailableCredit = a.CreditLimit - a.Balance
+	return a, nil
+}
+
+func (s *Store) Spend(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	if _, err := s.card(walletID); err != nil {
+		return nil, err
+	}
+	return s.post(walletID, transaction.KindSpend, -amount, description)
+}
+
+func (s *Store) Pay(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	if _, err := s.card(walletID); err != nil {
+		return nil, err
+	}
+	return s.post(walletID, transaction.KindPayment, amount, description)
+}
+
+func (s *Store) Statements(walletID int) ([]creditcard.Statement, error) {
+	if walletID != CardID {
+		return []creditcard.Statement{}, nil
+	}
+	start := time.Date(2026, 8, 25, 0, 0, 0, 0, time.UTC)
+	return []creditcard.Statement{{ID: 1, WalletID: walletID, PeriodStart: start, PeriodEnd: start.AddDate(0, 1, 0),
+		Spend: 20, ClosingBalance: 20, MinimumPayment: 20, DueDate: time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC), CreatedAt: start.AddDate(0, 1, 0)}}, nil
+}
+
+func (s *Store) InterestHistory(walletID int) (*interest.History, error) {
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	posted := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	return &interest.History{WalletID: walletID, Rate: &interest.Rate{WalletType: "Savings", AnnualRate: 1.25, DayCount: interest.ACT365},
+		Accrued: 0.003425,
+		Accruals: []interest.Accrual{{WalletID: walletID, Date: day, Balance: 100, AnnualRate: 1.25, DayCount: interest.ACT365,
+			Amount: 0.003425, PostedAt: &posted}},
+		Postings: []interest.Posting{{TransactionID: 8, Month: "2026-09", Amount: 0.10, PostedAt: posted}},
+	}, nil
+}
+
+func (s *Store) SaveWalletRate(r interest.Rate) (*interest.Rate, error) {
+	if _, err := s.wallet(r.WalletID); err != nil {
+		return nil, interest.ErrNotFound
+	}
+	return &r, nil
+}
+
+func (s *Store) ProductRates() ([]interest.Rate, error) {
+	return []interest.Rate{{WalletType: "Savings", AnnualRate: 1.25, DayCount: interest.ACT365}}, nil
+}
+
+func (s *Store) SaveProductRate(r interest.Rate) (*interest.Rate, error) {
+	return &r, nil
+}
+
+func (s *Store) AuditLogs(filter audit.Filter) ([]audit.Record, error) {
+	return []audit.Record{{
+		ID:        1,
+		Actor:     "admin",
+		Claimed:   "ops@example.com",
+		Action:    "update",
+		WalletID:  SavingsID,
+		Before:    json.RawMessage(`{"wallet_name":"John"}`),
+		After:     json.RawMessage(`{"wallet_name":"John Savings"}`),
+		Diff:      json.RawMessage(`{"wallet_name":{"from":"John","to":"John Savings"}}`),
+		IP:        "127.0.0.1",
+		RequestID: "kTRhvBdvRbSSL1gNNRrbxTGYHDAKDCyB",
+		CreatedAt: Created,
+	}}, nil
+}
+
+func (s *Store) BalanceReport() ([]report.BalanceRow, error) {
+	return []report.BalanceRow{
+		{WalletType: wallet.TypeSavings, Month: "2026-09", WalletCount: 1, TotalBalance: 100},
+		{WalletType: wallet.TypeCreditCard, Month: "2026-09", WalletCount: 1, TotalBalance: -20},
+	}, nil
+}
+
+func (s *Store) TrialBalance() (*ledger.TrialBalance, error) {
+	tb := ledger.NewTrialBalance(Created, []ledger.Account{
+		{Code: "settlement", Name: "Settlement", Type: "asset"},
+		{Code: "wallets", Name: "Wallets", Type: "liability"},
+	}, []float64{90, -90})
+	return &tb, nil
+}
+
+func (s *Store) CheckInvariant() (*ledger.Report, error) {
+	return &ledger.Report{CheckedAt: Created, Divergences: []ledger.Divergence{}, UnbalancedEntries: []int64{}}, nil
+}
+
+func (s *Store) file() reconcile.File {
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	return reconcile.File{ID: FileID, Name: "settlement-2026-09-01.csv", Format: reconcile.FormatCSV, From: day, To: day,
+		Checksum: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", Matched: 1, Unmatched: 1, ImportedAt: day.Add(30 * time.Hour)}
+}
+
+func (s *Store) ImportFile(f reconcile.File, lines []reconcile.Line) (*reconcile.Report, error) {
+	if f.Name == s.file().Name {
+		return nil, reconcile.ErrDuplicateFile
+	}
+	f.ID, f.ImportedAt = 2, Created
+	r := &reconcile.Report{Items: []reconcile.Item{}, UnmatchedEntries: []reconcile.Candidate{}}
+	for i, l := range lines {
+		r.Items = append(r.Items, reconcile.Item{ID: int64(i + 3), FileID: f.ID,
+			Result: reconcile.Result{Line: l, Status: reconcile.StatusUnmatched, Reason: "no posting"}})
+		f.Unmatched++
+	}
+	r.File = f
+	return r, nil
+}
+
+func (s *Store) Files() ([]reconcile.File, error) {
+	return []reconcile.File{s.file()}, nil
+}
+
+func (s *Store) Report(id int64) (*reconcile.Report, error) {
+	if id != FileID {
+		return nil, reconcile.ErrNotFound
+	}
+	f := s.file()
+	booked := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	return &reconcile.Report{File: f, Items: []reconcile.Item{
+		{ID: 1, FileID: f.ID, Result: reconcile.Result{Line: reconcile.Line{LineNo: 1, Reference: "TX2", Amount: 50, BookedOn: booked, Description: "Top-up John Doe"},
+			Status: reconcile.StatusMatched, EntryID: ptr(int64(17))}},
+		{ID: ItemID, FileID: f.ID, Result: reconcile.Result{Line: reconcile.Line{LineNo: 2, Amount: -1.5, BookedOn: booked, Description: "Bank fee"},
+			Status: reconcile.StatusUnmatched, Reason: "no settlement posting of -1.50"}},
+	}, UnmatchedEntries: []reconcile.Candidate{}}, nil
+}
+
+func (s *Store) Resolve(fileID, itemID int64, r reconcile.Resolution, actor audit.Actor) (*reconcile.Item, error) {
+	report, err := s.Report(fileID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range report.Items {
+		if item.ID != itemID {
+			continue
+		}
+		if item.Status != reconcile.StatusUnmatched {
+			return nil, reconcile.ErrSettled
+		}
+		item.Status, item.EntryID, item.Note = reconcile.StatusResolved, r.EntryID, r.Note
+		item.ResolvedBy, item.ResolvedAt = &actor.ID, ptr(Created.Add(time.Hour))
+		return &item, nil
+	}
+	return nil, reconcile.ErrItemNotFound
+}
+
+func (s *Store) TypeFees(key string) ([]fee.Rule, error) {
+	t, err := s.walletType(key)
+	if err != nil {
+		return nil, fee.ErrTypeNotFound
+	}
+	return []fee.Rule{{WalletType: t.Name, Operation: fee.OperationTransfer, Flat: 0.5, Percent: 0.1, Min: ptr(1.0), Max: ptr(25.0)}}, nil
+}
+
+func (s *Store) SaveTypeFee(r fee.Rule) (*fee.Rule, error) {
+	if _, err := s.walletType(r.WalletType); err != nil {
+		return nil, fee.ErrTypeNotFound
+	}
+	return &r, nil
+}
+
+func (s *Store) DeleteTypeFee(key, operation string) error {
+	if _, err := s.walletType(key); err != nil {
+		return fee.ErrTypeNotFound
+	}
+	if operation != fee.OperationTransfer {
+		return fee.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) subscription() webhook.Subscription {
+	return webhook.Subscription{ID: WebhookID, TargetURL: "https://partner.example.com/hooks/wallet",
+		Events: []string{"WalletCreated", "BalanceChanged"}, Active: true, CreatedAt: Created}
+}
+
+func (s *Store) Subscriptions() ([]webhook.Subscription, error) {
+	return []webhook.Subscription{s.subscription()}, nil
+}
+
+func (s *Store) Subscription(id int) (*webhook.Subscription, error) {
+	if id != WebhookID {
+		return nil, webhook.ErrNotFound
+	}
+	sub := s.subscription()
+	return &sub, nil
+}
+
+func (s *Store) CreateSubscription(sub webhook.Subscription) (*webhook.Subscription, error) {
+	sub.ID, sub.CreatedAt = 2, Created
+	return &sub, nil
+}
+
+func (s *Store) UpdateSubscription(sub webhook.Subscription) (*webhook.Subscription, error) {
+	if sub.ID != WebhookID {
+		return nil, webhook.ErrNotFound
+	}
+	sub.CreatedAt = Created
+	return &sub, nil
+}
+
+func (s *Store) DeleteSubscription(id int) error {
+	if id != WebhookID {
+		return webhook.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) delivery() webhook.Delivery {
+	at := Created.Add(time.Minute)
+	return webhook.Delivery{ID: DeliveryID, SubscriptionID: WebhookID, EventID: 1, EventType: "WalletCreated",
+		Payload: json.RawMessage(`{"id":1,"user_id":1,"wallet_name":"John Savings"}`), Status: webhook.StatusFailed,
+		Attempts: 1, ResponseStatus: 500, LastError: "unexpected status 500", NextAttemptAt: at.Add(30 * time.Second), CreatedAt: Created,
+		History: []webhook.Attempt{{Attempt: 1, Status: webhook.StatusFailed, ResponseStatus: 500, Error: "unexpected status 500", At: at}}}
+}
+
+func (s *Store) Deliveries(subscriptionID int) ([]webhook.Delivery, error) {
+	if subscriptionID != WebhookID {
+		return []webhook.Delivery{}, nil
+	}
+	return []webhook.Delivery{s.delivery()}, nil
+}
+
+func (s *Store) Redeliver(subscriptionID, deliveryID int) (*webhook.Delivery, error) {
+	if subscriptionID != WebhookID || deliveryID != DeliveryID {
+		return nil, webhook.ErrNotFound
+	}
+	d := s.delivery()
+	d.Status, d.NextAttemptAt = webhook.StatusPending, Created.Add(time.Hour)
+	return &d, nil
+}
+
+func (s *Store) WalletChanges(userID int, afterID int64, limit int) ([]stream.Change, error) {
+	return []stream.Change{}, nil
+}
+
+func (s *Store) LatestWalletChangeID() (int64, error) {
+	return 0, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
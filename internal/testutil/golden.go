@@ -0,0 +1,37 @@
+// Package testutil holds helpers shared by the tests of several packages.
+package testutil
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Golden compares got with testdata/name of the package under test,
+// rewriting the file instead when the tests run with -update.
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+	if utf8.Valid(got) && utf8.Valid(want) {
+		t.Errorf("%s differs from the golden file; rerun with -update if the change is intended:\n%s\nwant:\n%s", name, got, want)
+		return
+	}
+	t.Errorf("%s differs from the golden file; rerun with -update if the change is intended", name)
+}
@@ -0,0 +1,42 @@
+package ledger
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Checker checks the ledger invariant periodically and logs every
+// violation it finds.
+type Checker struct {
+	store Storer
+}
+
+func NewChecker(store Storer) *Checker {
+	return &Checker{store: store}
+}
+
+// Run checks every interval until ctx is done.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Check(); err != nil {
+			log.Printf("ledger checker: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check returns an InvariantError when the invariant does not hold.
+func (c *Checker) Check() error {
+	r, err := c.store.CheckInvariant()
+	if err != nil {
+		return err
+	}
+	return r.Check()
+}
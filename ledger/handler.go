@@ -0,0 +1,70 @@
+package ledger
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	// TrialBalance nets the postings of every system account, and of the
+	// wallet accounts by control account.
+	TrialBalance() (*TrialBalance, error)
+	// CheckInvariant compares every wallet balance with its account and
+	// looks for entries that do not balance, in one snapshot.
+	CheckInvariant() (*Report, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// GetTrialBalance
+//
+//	@Summary		Get trial balance
+//	@Description	Get the net of every ledger account on its debit or credit side. Wallet accounts are rolled up into the wallets and card_receivables control accounts.
+//	@Tags			ledger
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	TrialBalance
+//	@Router			/api/v1/ledger/trial-balance [get]
+//	@Failure		500	{object}	Err
+//	@Security	AdminToken
+func (h *Handler) GetTrialBalance(c echo.Context) error {
+	tb, err := h.store.TrialBalance()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, tb)
+}
+
+// CheckInvariant
+//
+//	@Summary		Check ledger invariant
+//	@Description	Check that every wallet balance equals the sum of the postings to its account and that every entry balances
+//	@Tags			ledger
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Report
+//	@Router			/api/v1/ledger/invariant [get]
+//	@Failure		500	{object}	Report	"the invariant is violated"
+//	@Security	AdminToken
+func (h *Handler) CheckInvariant(c echo.Context) error {
+	r, err := h.store.CheckInvariant()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	if !r.OK() {
+		c.Logger().Error(r.Check())
+		return c.JSON(http.StatusInternalServerError, r)
+	}
+	return c.JSON(http.StatusOK, r)
+}
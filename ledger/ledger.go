@@ -0,0 +1,235 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+)
+
+// Types of account. Assets and expenses grow with debits, liabilities and
+// income with credits.
+const (
+	TypeAsset     = "asset"
+	TypeLiability = "liability"
+	TypeIncome    = "income"
+	TypeExpense   = "expense"
+)
+
+// System accounts, on the other side of every wallet posting.
+const (
+	// AccountSettlement is money entering or leaving the system: deposits,
+	// withdrawals, card spend and payments, and opening balances.
+	AccountSettlement = "settlement"
+	// AccountClearing holds a transfer between its two legs, so it is back
+	// to zero once both are posted.
+	AccountClearing = "clearing"
+	// AccountFees is the house account collecting the fees charged on
+	// transfers and withdrawals.
+	AccountFees            = "fees"
+	AccountInterestExpense = "interest_expense"
+	AccountInterestIncome  = "interest_income"
+)
+
+// Control accounts grouping the account of every wallet in the trial
+// balance. The wallets of users are owed to them, credit cards are owed by
+// them.
+const (
+	AccountWallets         = "wallets"
+	AccountCardReceivables = "card_receivables"
+)
+
+// Controls are the control accounts by code.
+var Controls = map[string]Account{
+	AccountWallets:         {Code: AccountWallets, Name: "User wallets", Type: TypeLiability},
+	AccountCardReceivables: {Code: AccountCardReceivables, Name: "Credit card receivables", Type: TypeAsset},
+}
+
+var ErrUnbalanced = errors.New("ledger entry does not balance")
+
+type Account struct {
+	Code string `json:"code" example:"settlement"`
+	Name string `json:"name" example:"Settlement"`
+	Type string `json:"type" example:"asset"`
+}
+
+// DebitNormal reports whether debits increase the balance of the account.
+func (a Account) DebitNormal() bool {
+	return a.Type == TypeAsset || a.Type == TypeExpense
+}
+
+// Signed is the line amount, debits positive, that changes the balance of
+// the account by amount.
+func (a Account) Signed(amount float64) float64 {
+	if a.DebitNormal() {
+		return amount
+	}
+	return -amount
+}
+
+// WalletAccount is the account of a wallet: an asset for credit cards and a
+// liability for everything else. Its balance is the wallet balance.
+func WalletAccount(walletID int, walletName string, creditCard bool) Account {
+	a := Account{Code: fmt.Sprintf("wallet:%d", walletID), Name: walletName, Type: TypeLiability}
+	if creditCard {
+		a.Type = TypeAsset
+	}
+	return a
+}
+
+// Control is the control account a wallet account rolls up to.
+func (a Account) Control() string {
+	if a.Type == TypeAsset {
+		return AccountCardReceivables
+	}
+	return AccountWallets
+}
+
+// Contra is the system account on the other side of a wallet transaction of
+// kind. Fees go to the house fee account. Interest is an expense paid on
+// savings and income charged on credit cards.
+func Contra(kind string, creditCard bool) string {
+	switch kind {
+	case transaction.KindTransferIn, transaction.KindTransferOut:
+		return AccountClearing
+	case transaction.KindFee:
+		return AccountFees
+	case transaction.KindInterest:
+		if creditCard {
+			return AccountInterestIncome
+		}
+		return AccountInterestExpense
+	}
+	return AccountSettlement
+}
+
+// Line is one side of an entry. Amount is positive for a debit and negative
+// for a credit.
+type Line struct {
+	Account string  `json:"account" example:"wallet:1"`
+	Amount  float64 `json:"amount" example:"-100.00"`
+}
+
+type Entry struct {
+	Description string
+	Lines       []Line
+}
+
+// Move is the entry changing the balance of account by amount against the
+// system account contra.
+func Move(account Account, contra string, amount float64, description string) Entry {
+	signed := account.Signed(amount)
+	return Entry{Description: description, Lines: []Line{
+		{Account: account.Code, Amount: signed},
+		{Account: contra, Amount: -signed},
+	}}
+}
+
+// Validate checks the entry has at least two lines, none of them zero, and
+// that its debits equal its credits to the cent.
+func (e Entry) Validate() error {
+	if len(e.Lines) < 2 {
+		return fmt.Errorf("%w: needs at least two lines", ErrUnbalanced)
+	}
+	var sum int64
+	for _, l := range e.Lines {
+		cents := toCents(l.Amount)
+		if cents == 0 {
+			return fmt.Errorf("%w: zero line for %s", ErrUnbalanced, l.Account)
+		}
+		sum += cents
+	}
+	if sum != 0 {
+		return fmt.Errorf("%w: off by %.2f", ErrUnbalanced, float64(sum)/100)
+	}
+	return nil
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// AccountBalance is a row of the trial balance: the net of an account on
+// the side it falls.
+type AccountBalance struct {
+	Account
+	Debit  float64 `json:"debit" example:"3500.00"`
+	Credit float64 `json:"credit" example:"0"`
+}
+
+type TrialBalance struct {
+	AsOf        time.Time        `json:"as_of" example:"2024-03-25T14:19:00Z"`
+	Accounts    []AccountBalance `json:"accounts"`
+	TotalDebit  float64          `json:"total_debit" example:"3500.00"`
+	TotalCredit float64          `json:"total_credit" example:"3500.00"`
+	Balanced    bool             `json:"balanced" example:"true"`
+}
+
+// NewTrialBalance puts the net of each account, debits positive, on its
+// side and totals both sides.
+func NewTrialBalance(asOf time.Time, accounts []Account, nets []float64) TrialBalance {
+	tb := TrialBalance{AsOf: asOf, Accounts: make([]AccountBalance, 0, len(accounts))}
+	var debit, credit int64
+	for i, a := range accounts {
+		cents := toCents(nets[i])
+		row := AccountBalance{Account: a}
+		if cents >= 0 {
+			row.Debit = float64(cents) / 100
+			debit += cents
+		} else {
+			row.Credit = float64(-cents) / 100
+			credit -= cents
+		}
+		tb.Accounts = append(tb.Accounts, row)
+	}
+	tb.TotalDebit, tb.TotalCredit = float64(debit)/100, float64(credit)/100
+	tb.Balanced = debit == credit
+	return tb
+}
+
+// Divergence is a wallet whose balance is not the balance of its account.
+type Divergence struct {
+	WalletID int     `json:"wallet_id" example:"1"`
+	Balance  float64 `json:"balance" example:"1000.00"`
+	Ledger   float64 `json:"ledger" example:"900.00"`
+}
+
+// Report is the outcome of checking the ledger invariants: every wallet
+// balance is the sum of the postings to its account, and every entry
+// balances.
+type Report struct {
+	CheckedAt         time.Time    `json:"checked_at" example:"2024-03-25T14:19:00Z"`
+	Divergences       []Divergence `json:"divergences"`
+	UnbalancedEntries []int64      `json:"unbalanced_entries"`
+}
+
+func (r *Report) OK() bool {
+	return len(r.Divergences) == 0 && len(r.UnbalancedEntries) == 0
+}
+
+// InvariantError is returned when the ledger invariants do not hold.
+type InvariantError struct {
+	Report *Report
+}
+
+func (e *InvariantError) Error() string {
+	var parts []string
+	for _, d := range e.Report.Divergences {
+		parts = append(parts, fmt.Sprintf("wallet %d balance %.2f but ledger %.2f", d.WalletID, d.Balance, d.Ledger))
+	}
+	for _, id := range e.Report.UnbalancedEntries {
+		parts = append(parts, fmt.Sprintf("entry %d does not balance", id))
+	}
+	return "ledger invariant violated: " + strings.Join(parts, "; ")
+}
+
+// Check returns an InvariantError unless the report is clean.
+func (r *Report) Check() error {
+	if r.OK() {
+		return nil
+	}
+	return &InvariantError{Report: r}
+}
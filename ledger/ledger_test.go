@@ -0,0 +1,146 @@
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/labstack/echo/v4"
+)
+
+var now = time.Date(2024, 3, 25, 14, 0, 0, 0, time.UTC)
+
+type StubLedger struct {
+	tb     *TrialBalance
+	report *Report
+	err    error
+}
+
+func (s *StubLedger) TrialBalance() (*TrialBalance, error) {
+	return s.tb, s.err
+}
+
+func (s *StubLedger) CheckInvariant() (*Report, error) {
+	return s.report, s.err
+}
+
+func TestMove(t *testing.T) {
+	savings := WalletAccount(1, "John Savings", false)
+	card := WalletAccount(2, "John Credit Card", true)
+
+	tests := []struct {
+		name    string
+		account Account
+		contra  string
+		amount  float64
+		want    []Line
+	}{
+		{"deposit credits the wallet", savings, Contra(transaction.KindDeposit, false), 100,
+			[]Line{{"wallet:1", -100}, {AccountSettlement, 100}}},
+		{"withdrawal debits the wallet", savings, Contra(transaction.KindWithdrawal, false), -40,
+			[]Line{{"wallet:1", 40}, {AccountSettlement, -40}}},
+		{"card spend debits the card", card, Contra(transaction.KindSpend, true), 25,
+			[]Line{{"wallet:2", 25}, {AccountSettlement, -25}}},
+		{"transfer leg goes through clearing", savings, Contra(transaction.KindTransferOut, false), -10,
+			[]Line{{"wallet:1", 10}, {AccountClearing, -10}}},
+		{"fee goes to the house", savings, Contra(transaction.KindFee, false), -0.55,
+			[]Line{{"wallet:1", 0.55}, {AccountFees, -0.55}}},
+		{"savings interest is an expense", savings, Contra(transaction.KindInterest, false), 1.5,
+			[]Line{{"wallet:1", -1.5}, {AccountInterestExpense, 1.5}}},
+		{"card interest is income", card, Contra(transaction.KindInterest, true), 3,
+			[]Line{{"wallet:2", 3}, {AccountInterestIncome, -3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Move(tt.account, tt.contra, tt.amount, "")
+
+			if err := e.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if len(e.Lines) != 2 || e.Lines[0] != tt.want[0] || e.Lines[1] != tt.want[1] {
+				t.Errorf("expected lines %v but got %v", tt.want, e.Lines)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []Line
+		ok    bool
+	}{
+		{"balanced to the cent", []Line{{"a", 0.1}, {"b", 0.2}, {"c", -0.3}}, true},
+		{"off by a cent", []Line{{"a", 10}, {"b", -9.99}}, false},
+		{"single line", []Line{{"a", 10}}, false},
+		{"zero line", []Line{{"a", 10}, {"b", -10}, {"c", 0}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Entry{Lines: tt.lines}.Validate()
+
+			if (err == nil) != tt.ok || (err != nil && !errors.Is(err, ErrUnbalanced)) {
+				t.Errorf("expected ok %v but got %v", tt.ok, err)
+			}
+		})
+	}
+}
+
+func TestNewTrialBalance(t *testing.T) {
+	accounts := []Account{
+		{Code: AccountSettlement, Type: TypeAsset},
+		{Code: AccountInterestExpense, Type: TypeExpense},
+		Controls[AccountWallets],
+	}
+
+	tb := NewTrialBalance(now, accounts, []float64{3000, 1.25, -3001.25})
+
+	if !tb.Balanced || tb.TotalDebit != 3001.25 || tb.TotalCredit != 3001.25 {
+		t.Errorf("expected balanced totals of 3001.25 but got %+v", tb)
+	}
+	if tb.Accounts[2].Credit != 3001.25 || tb.Accounts[2].Debit != 0 {
+		t.Errorf("expected wallets on the credit side but got %+v", tb.Accounts[2])
+	}
+}
+
+func TestCheckInvariant(t *testing.T) {
+	t.Run("given a clean ledger should return 200", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+		New(&StubLedger{report: &Report{CheckedAt: now}}).CheckInvariant(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("given a divergence should return 500 with the report", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		report := &Report{CheckedAt: now, Divergences: []Divergence{{WalletID: 1, Balance: 1000, Ledger: 900}}}
+
+		New(&StubLedger{report: report}).CheckInvariant(c)
+
+		var resp Report
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusInternalServerError || len(resp.Divergences) != 1 {
+			t.Errorf("expected %d with one divergence but got %d %+v", http.StatusInternalServerError, rec.Code, resp)
+		}
+	})
+}
+
+func TestChecker(t *testing.T) {
+	report := &Report{CheckedAt: now, UnbalancedEntries: []int64{7}}
+
+	err := NewChecker(&StubLedger{report: report}).Check()
+
+	var ie *InvariantError
+	if !errors.As(err, &ie) || ie.Error() != "ledger invariant violated: entry 7 does not balance" {
+		t.Errorf("expected an invariant error but got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package limit
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	ErrNotFound     = errors.New("wallet not found")
+	ErrTypeNotFound = errors.New("wallet type not found")
+)
+
+// WalletLimits shows the limits of a wallet: its own, those of its type,
+// the effective ones and how much of them is used.
+type WalletLimits struct {
+	WalletID  int    `json:"wallet_id" example:"1"`
+	Wallet    Limits `json:"wallet"`
+	Type      Limits `json:"type"`
+	Effective Limits `json:"effective"`
+	Usage     Usage  `json:"usage"`
+}
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	WalletLimits(walletID int) (*WalletLimits, error)
+	SaveWalletLimits(walletID int, l Limits) (*WalletLimits, error)
+	TypeLimits(key string) (*Limits, error)
+	SaveTypeLimits(key string, l Limits) (*Limits, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// GetWalletLimits
+//
+//	@Summary		Get wallet limits
+//	@Description	Get the spending limits of a wallet, those of its type, the effective ones and the current usage
+//	@Tags			limit
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	WalletLimits
+//	@Router			/api/v1/wallets/{id}/limits [get]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+func (h *Handler) GetWalletLimits(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+
+	limits, err := h.store.WalletLimits(walletId)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, limits)
+}
+
+// SaveWalletLimits
+//
+//	@Summary		Set wallet limits
+//	@Description	Replace the limits of a wallet. Omitted limits fall back to those of its type.
+//	@Tags			limit
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	WalletLimits
+//	@Router			/api/v1/wallets/{id}/limits [put]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   limits  body		Limits	true	"Limits"
+func (h *Handler) SaveWalletLimits(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+	var l Limits
+	if err := c.Bind(&l); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	if msg := l.Validate(); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	limits, err := h.store.SaveWalletLimits(walletId, l)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, limits)
+}
+
+// GetTypeLimits
+//
+//	@Summary		Get wallet type limits
+//	@Description	Get the default spending limits of the wallets of a type
+//	@Tags			limit
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Limits
+//	@Router			/api/v1/wallet-types/{key}/limits [get]
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   key  path		string	true	"Wallet type key"
+//	@Security	AdminToken
+func (h *Handler) GetTypeLimits(c echo.Context) error {
+	limits, err := h.store.TypeLimits(c.Param("key"))
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, limits)
+}
+
+// SaveTypeLimits
+//
+//	@Summary		Set wallet type limits
+//	@Description	Replace the default spending limits of the wallets of a type
+//	@Tags			limit
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Limits
+//	@Router			/api/v1/wallet-types/{key}/limits [put]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   key  path		string	true	"Wallet type key"
+//	@Param   limits  body		Limits	true	"Limits"
+//	@Security	AdminToken
+func (h *Handler) SaveTypeLimits(c echo.Context) error {
+	var l Limits
+	if err := c.Bind(&l); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	if msg := l.Validate(); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	limits, err := h.store.SaveTypeLimits(c.Param("key"), l)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, limits)
+}
+
+func storeError(c echo.Context, err error) error {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrTypeNotFound) {
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
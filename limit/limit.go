@@ -0,0 +1,152 @@
+package limit
+
+import (
+	"fmt"
+	"time"
+)
+
+// Names of the limits, as reported in Error.Limit.
+const (
+	MaxWithdrawal       = "max_withdrawal"
+	DailyOutflow        = "daily_outflow"
+	MonthlyOutflow      = "monthly_outflow"
+	MaxTransfersPerHour = "max_transfers_per_hour"
+)
+
+// CodeLimitExceeded is the error code of every Error.
+const CodeLimitExceeded = "limit_exceeded"
+
+// Limits caps the money leaving a wallet. A nil field is no limit. They are
+// set per wallet type and may be overridden per wallet, field by field.
+type Limits struct {
+	MaxWithdrawal       *float64 `json:"max_withdrawal,omitempty" example:"500"`
+	DailyOutflow        *float64 `json:"daily_outflow,omitempty" example:"1000"`
+	MonthlyOutflow      *float64 `json:"monthly_outflow,omitempty" example:"10000"`
+	MaxTransfersPerHour *int     `json:"max_transfers_per_hour,omitempty" example:"5"`
+}
+
+// Effective returns the wallet limits with the type limits filling the
+// fields the wallet does not set.
+func Effective(wallet, walletType Limits) Limits {
+	if wallet.MaxWithdrawal == nil {
+		wallet.MaxWithdrawal = walletType.MaxWithdrawal
+	}
+	if wallet.DailyOutflow == nil {
+		wallet.DailyOutflow = walletType.DailyOutflow
+	}
+	if wallet.MonthlyOutflow == nil {
+		wallet.MonthlyOutflow = walletType.MonthlyOutflow
+	}
+	if wallet.MaxTransfersPerHour == nil {
+		wallet.MaxTransfersPerHour = walletType.MaxTransfersPerHour
+	}
+	return wallet
+}
+
+// Empty reports whether no limit is set.
+func (l Limits) Empty() bool {
+	return l.MaxWithdrawal == nil && l.DailyOutflow == nil && l.MonthlyOutflow == nil && l.MaxTransfersPerHour == nil
+}
+
+// Validate returns a message for the first negative limit.
+func (l Limits) Validate() string {
+	amounts := []struct {
+		name  string
+		value *float64
+	}{{MaxWithdrawal, l.MaxWithdrawal}, {DailyOutflow, l.DailyOutflow}, {MonthlyOutflow, l.MonthlyOutflow}}
+	for _, a := range amounts {
+		if a.value != nil && *a.value < 0 {
+			return "Invalid " + a.name
+		}
+	}
+	if l.MaxTransfersPerHour != nil && *l.MaxTransfersPerHour < 0 {
+		return "Invalid " + MaxTransfersPerHour
+	}
+	return ""
+}
+
+// Usage is what a wallet has already sent out in the current windows, as
+// of Now. Days and months are calendar ones, the hour is rolling.
+type Usage struct {
+	Daily          float64    `json:"daily" example:"120.00"`
+	Monthly        float64    `json:"monthly" example:"1520.00"`
+	TransfersHour  int        `json:"transfers_last_hour" example:"2"`
+	OldestTransfer *time.Time `json:"-"`
+	Now            time.Time  `json:"-"`
+}
+
+// Outflow is a movement of money out of a wallet, checked against limits.
+type Outflow struct {
+	Amount   float64
+	Transfer bool
+}
+
+// Error is an outflow refused by a limit, saying which limit and when it
+// allows more again. ResetsAt is nil for limits on a single movement.
+type Error struct {
+	Code     string     `json:"code" example:"limit_exceeded"`
+	Limit    string     `json:"limit" example:"daily_outflow"`
+	Message  string     `json:"message" example:"Daily outflow limit of 1000.00 reached: 900.00 already sent today"`
+	Allowed  float64    `json:"allowed" example:"1000"`
+	Used     float64    `json:"used" example:"900"`
+	ResetsAt *time.Time `json:"resets_at,omitempty" example:"2024-03-26T00:00:00Z"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Check returns an *Error when o would break one of l given u.
+func Check(l Limits, u Usage, o Outflow) error {
+	if l.MaxWithdrawal != nil && o.Amount > *l.MaxWithdrawal {
+		return &Error{
+			Code:    CodeLimitExceeded,
+			Limit:   MaxWithdrawal,
+			Message: fmt.Sprintf("Single withdrawal limit of %.2f exceeded", *l.MaxWithdrawal),
+			Allowed: *l.MaxWithdrawal,
+		}
+	}
+	if l.DailyOutflow != nil && u.Daily+o.Amount > *l.DailyOutflow {
+		resets := startOfDay(u.Now).AddDate(0, 0, 1)
+		return &Error{
+			Code:     CodeLimitExceeded,
+			Limit:    DailyOutflow,
+			Message:  fmt.Sprintf("Daily outflow limit of %.2f reached: %.2f already sent today, resets at %s", *l.DailyOutflow, u.Daily, resets.Format(time.RFC3339)),
+			Allowed:  *l.DailyOutflow,
+			Used:     u.Daily,
+			ResetsAt: &resets,
+		}
+	}
+	if l.MonthlyOutflow != nil && u.Monthly+o.Amount > *l.MonthlyOutflow {
+		d := startOfDay(u.Now)
+		resets := d.AddDate(0, 1, 1-d.Day())
+		return &Error{
+			Code:     CodeLimitExceeded,
+			Limit:    MonthlyOutflow,
+			Message:  fmt.Sprintf("Monthly outflow limit of %.2f reached: %.2f already sent this month, resets at %s", *l.MonthlyOutflow, u.Monthly, resets.Format(time.RFC3339)),
+			Allowed:  *l.MonthlyOutflow,
+			Used:     u.Monthly,
+			ResetsAt: &resets,
+		}
+	}
+	if o.Transfer && l.MaxTransfersPerHour != nil && u.TransfersHour+1 > *l.MaxTransfersPerHour {
+		resets := u.Now.Add(time.Hour)
+		if u.OldestTransfer != nil {
+			resets = u.OldestTransfer.Add(time.Hour)
+		}
+		return &Error{
+			Code:     CodeLimitExceeded,
+			Limit:    MaxTransfersPerHour,
+			Message:  fmt.Sprintf("Limit of %d transfers per hour reached, next transfer allowed at %s", *l.MaxTransfersPerHour, resets.Format(time.RFC3339)),
+			Allowed:  float64(*l.MaxTransfersPerHour),
+			Used:     float64(u.TransfersHour),
+			ResetsAt: &resets,
+		}
+	}
+	return nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
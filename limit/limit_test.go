@@ -0,0 +1,74 @@
+package limit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func amount(v float64) *float64 { return &v }
+
+func count(v int) *int { return &v }
+
+func TestEffective(t *testing.T) {
+	own := Limits{DailyOutflow: amount(100)}
+	ofType := Limits{DailyOutflow: amount(1000), MonthlyOutflow: amount(5000)}
+
+	got := Effective(own, ofType)
+
+	if *got.DailyOutflow != 100 || *got.MonthlyOutflow != 5000 || got.MaxWithdrawal != nil {
+		t.Errorf("unexpected effective limits %+v", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2024, 3, 25, 14, 30, 0, 0, time.UTC)
+	oldest := now.Add(-40 * time.Minute)
+	tests := []struct {
+		name     string
+		limits   Limits
+		usage    Usage
+		outflow  Outflow
+		limit    string
+		resetsAt *time.Time
+	}{
+		{"within every limit", Limits{MaxWithdrawal: amount(500), DailyOutflow: amount(1000)}, Usage{Daily: 400, Now: now}, Outflow{Amount: 500}, "", nil},
+		{"single withdrawal too large", Limits{MaxWithdrawal: amount(500)}, Usage{Now: now}, Outflow{Amount: 500.01}, MaxWithdrawal, nil},
+		{"daily cap reached", Limits{DailyOutflow: amount(1000)}, Usage{Daily: 900, Now: now}, Outflow{Amount: 200}, DailyOutflow, ptr(time.Date(2024, 3, 26, 0, 0, 0, 0, time.UTC))},
+		{"monthly cap reached", Limits{MonthlyOutflow: amount(5000)}, Usage{Monthly: 4990, Now: now}, Outflow{Amount: 20}, MonthlyOutflow, ptr(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))},
+		{"too many transfers", Limits{MaxTransfersPerHour: count(3)}, Usage{TransfersHour: 3, OldestTransfer: &oldest, Now: now}, Outflow{Amount: 1, Transfer: true}, MaxTransfersPerHour, ptr(oldest.Add(time.Hour))},
+		{"transfer count ignores withdrawals", Limits{MaxTransfersPerHour: count(3)}, Usage{TransfersHour: 3, Now: now}, Outflow{Amount: 1}, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.limits, tt.usage, tt.outflow)
+			if tt.limit == "" {
+				if err != nil {
+					t.Fatalf("expected no error but got %v", err)
+				}
+				return
+			}
+			var le *Error
+			if !errors.As(err, &le) {
+				t.Fatalf("expected a limit error but got %v", err)
+			}
+			if le.Limit != tt.limit || le.Code != CodeLimitExceeded {
+				t.Errorf("expected limit %s but got %+v", tt.limit, le)
+			}
+			if (tt.resetsAt == nil) != (le.ResetsAt == nil) || (tt.resetsAt != nil && !tt.resetsAt.Equal(*le.ResetsAt)) {
+				t.Errorf("expected reset at %v but got %v", tt.resetsAt, le.ResetsAt)
+			}
+		})
+	}
+}
+
+func ptr(t time.Time) *time.Time { return &t }
+
+func TestValidate(t *testing.T) {
+	if msg := (Limits{DailyOutflow: amount(-1)}).Validate(); msg != "Invalid daily_outflow" {
+		t.Errorf("unexpected message %q", msg)
+	}
+	if msg := (Limits{MaxTransfersPerHour: count(0)}).Validate(); msg != "" {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
@@ -1,13 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
+	"github.com/KKGo-Software-engineering/fun-exercise-api/api"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apiv2"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/cache"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/event"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/hold"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/interest"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/ledger"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/openapi"
 	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
-	"github.com/KKGo-Software-engineering/fun-exercise-api/user"
-	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/rpc"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/schedule"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/stream"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/webhook"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"github.com/spf13/viper"
 
 	_ "github.com/KKGo-Software-engineering/fun-exercise-api/docs"
@@ -18,10 +36,38 @@ import (
 // @version		1.0
 // @description	Sophisticated Wallet API
 // @host			localhost:1323
+//
+// @securityDefinitions.apikey	AdminToken
+// @in							header
+// @name						X-Admin-Token
 func main() {
 	viper.SetConfigName("config")
 	viper.AddConfigPath(".")
 	viper.AutomaticEnv()
+	viper.SetDefault("outbox.interval", "1s")
+	viper.SetDefault("outbox.max_attempts", 20)
+	viper.SetDefault("webhook.interval", "1s")
+	viper.SetDefault("webhook.max_attempts", 8)
+	viper.SetDefault("webhook.backoff", "30s")
+	viper.SetDefault("stream.heartbeat", "15s")
+	viper.SetDefault("stream.retention", "168h")
+	viper.SetDefault("stream.prune_interval", "1h")
+	viper.SetDefault("credit_card.interval", "1h")
+	viper.SetDefault("interest.interval", "1h")
+	viper.SetDefault("schedule.interval", "1m")
+	viper.SetDefault("hold.interval", "1m")
+	viper.SetDefault("ledger.interval", "1h")
+	viper.SetDefault("graphql.max_complexity", 500)
+	viper.SetDefault("graphql.max_depth", 10)
+	viper.SetDefault("grpc.address", ":50051")
+	viper.SetDefault("shutdown.timeout", "10s")
+	viper.SetDefault("api.currency", apiv2.DefaultCurrency)
+	viper.SetDefault("api.v1.deprecated_at", apiv2.Released)
+	viper.SetDefault("openapi.validate", true)
+	viper.SetDefault("openapi.validate_responses", false)
+	viper.SetDefault("cache.enabled", true)
+	viper.SetDefault("cache.ttl", "1m")
+	viper.SetDefault("cache.size", 10000)
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	err := viper.ReadInConfig()
 	if err != nil {
@@ -33,18 +79,119 @@ func main() {
 		panic(err)
 	}
 
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(p, os.Args[1:]))
+	}
+
+	// ctx is done on SIGINT or SIGTERM, or once a server or the wallet
+	// changes listener stops, and stops the background jobs and the others
+	// with it.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var walletCache cache.Cache = cache.None{}
+	if viper.GetBool("cache.enabled") {
+		walletCache = cache.NewMemory(viper.GetDuration("cache.ttl"), viper.GetInt("cache.size"))
+	}
+	cached := cache.NewStore(p, walletCache)
+
+	sinks := append(outboxSinks(), webhook.NewDispatcher(p))
+	relay := event.NewRelay(p, p.OutboxLock(), viper.GetDuration("outbox.interval"), viper.GetInt("outbox.max_attempts"), sinks...)
+	go relay.Run(ctx)
+
+	sender := webhook.NewSender(p, nil, viper.GetInt("webhook.max_attempts"), viper.GetDuration("webhook.backoff"))
+	go sender.Run(ctx, viper.GetDuration("webhook.interval"))
+
+	go creditcard.NewJob(p).Run(ctx, viper.GetDuration("credit_card.interval"))
+	go interest.NewEngine(p, interest.SystemClock{}).Run(ctx, viper.GetDuration("interest.interval"))
+	go hold.NewSweeper(p).Run(ctx, viper.GetDuration("hold.interval"))
+	go schedule.NewScheduler(cache.NewRuns(p, cached), p.SchedulerLock()).Run(ctx, viper.GetDuration("schedule.interval"))
+	go ledger.NewChecker(p).Run(ctx, viper.GetDuration("ledger.interval"))
+
+	broker := stream.NewBroker()
+	go stream.NewPruner(p, viper.GetDuration("stream.retention")).Run(ctx, viper.GetDuration("stream.prune_interval"))
+
 	e := echo.New()
+	e.Use(middleware.RequestID())
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
-	walletHandler := wallet.New(p)
-	walletGroup := e.Group("/api/v1/wallets")
-	walletGroup.GET("", walletHandler.GetWallet)
-	walletGroup.POST("", walletHandler.CreateWallet)
-	walletGroup.PUT("/:id", walletHandler.UpdateWallet)
-	walletGroup.DELETE("/:id", walletHandler.DeleteWallet)
-
-	userHandler := user.New(p)
-	userGroup := e.Group("/api/v1/users")
-	userGroup.GET("/:id/wallets", userHandler.WalletByUserId)
-
-	e.Logger.Fatal(e.Start(":1323"))
+	e.GET("/openapi.json", openapi.Serve)
+	if viper.GetBool("openapi.validate") {
+		doc, err := openapi.Document()
+		if err != nil {
+			panic(err)
+		}
+		validator, err := openapi.Validator(doc, openapi.Options{Responses: viper.GetBool("openapi.validate_responses")})
+		if err != nil {
+			panic(err)
+		}
+		e.Use(validator)
+	}
+	api.Register(e, p, cached, broker, api.Options{
+		AdminToken: viper.GetString("admin.token"),
+		Deprecation: apiv2.Deprecation{
+			Since:  viper.GetTime("api.v1.deprecated_at"),
+			Sunset: viper.GetTime("api.v1.sunset"),
+			Link:   viper.GetString("api.v1.deprecation_link"),
+		},
+		Currency:      viper.GetString("api.currency"),
+		Heartbeat:     viper.GetDuration("stream.heartbeat"),
+		MaxComplexity: viper.GetInt("graphql.max_complexity"),
+		MaxDepth:      viper.GetInt("graphql.max_depth"),
+	})
+
+	// The listener, like both servers, returns nil once ctx is done.
+	const services = 3
+	errc := make(chan error, services)
+	go func() {
+		errc <- p.ListenWalletChanges(ctx, stream.Notifiers{broker, cached})
+	}()
+	go func() {
+		errc <- rpc.Serve(ctx, viper.GetString("grpc.address"), cached)
+	}()
+	go func() {
+		errc <- e.Start(":1323")
+	}()
+	// Start returns as soon as Shutdown begins; shutdown closes once the
+	// in-flight requests are done.
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("shutdown.timeout"))
+		defer cancel()
+		if err := e.Shutdown(shutdownCtx); err != nil {
+			e.Logger.Error(err)
+		}
+	}()
+
+	failed := false
+	for i := 0; i < services; i++ {
+		err := <-errc
+		stop()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Error(err)
+			failed = true
+		}
+	}
+	<-shutdown
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func outboxSinks() []event.Sink {
+	var sinks []event.Sink
+	for _, name := range viper.GetStringSlice("outbox.sinks") {
+		switch name {
+		case "stdout":
+			sinks = append(sinks, event.NewStdoutSink())
+		case "file":
+			sinks = append(sinks, event.NewFileSink(viper.GetString("outbox.file")))
+		case "webhook":
+			sinks = append(sinks, event.NewWebhookSink(viper.GetString("outbox.webhook_url"), nil))
+		default:
+			panic(fmt.Errorf("fatal error unknown outbox sink: %s", name))
+		}
+	}
+	return sinks
 }
@@ -0,0 +1,147 @@
+package openapi
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// upgrade rewrites the schemas of a converted OpenAPI 3.0 document in the
+// JSON Schema of OpenAPI 3.1: nullable becomes a type list with "null",
+// and example becomes an examples array.
+func upgrade(doc *openapi3.T) {
+	walkSchemas(doc, func(s *openapi3.Schema) {
+		if s.Nullable {
+			s.Nullable = false
+			if s.Type != nil && !s.Type.Includes(openapi3.TypeNull) {
+				types := append(slices.Clone(*s.Type), openapi3.TypeNull)
+				s.Type = &types
+			}
+		}
+		if s.Example != nil {
+			if s.Extensions == nil {
+				s.Extensions = map[string]any{}
+			}
+			s.Extensions["examples"] = []any{s.Example}
+			s.Example = nil
+		}
+	})
+}
+
+// downgrade returns a copy of doc read back as OpenAPI 3.0, which is what
+// kin-openapi checks documents and traffic against. It undoes upgrade.
+func downgrade(doc *openapi3.T) (*openapi3.T, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	v30, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, err
+	}
+	walkSchemas(v30, func(s *openapi3.Schema) {
+		if s.Type.Includes(openapi3.TypeNull) {
+			s.Nullable = true
+			types := slices.DeleteFunc(slices.Clone(*s.Type), func(t string) bool { return t == openapi3.TypeNull })
+			s.Type = &types
+			if len(types) == 0 {
+				s.Type = nil
+			}
+		}
+		if examples, ok := s.Extensions["examples"].([]any); ok {
+			if len(examples) > 0 {
+				s.Example = examples[0]
+			}
+			delete(s.Extensions, "examples")
+		}
+	})
+	v30.OpenAPI = "3.0.3"
+	return v30, nil
+}
+
+// walkSchemas calls fn once on every schema doc defines, in its components
+// and inline in its operations.
+func walkSchemas(doc *openapi3.T, fn func(*openapi3.Schema)) {
+	seen := map[*openapi3.Schema]bool{}
+	var walk func(ref *openapi3.SchemaRef)
+	walk = func(ref *openapi3.SchemaRef) {
+		// A reference is walked where it points, in the components.
+		if ref == nil || ref.Ref != "" || ref.Value == nil || seen[ref.Value] {
+			return
+		}
+		s := ref.Value
+		seen[s] = true
+		fn(s)
+		for _, p := range s.Properties {
+			walk(p)
+		}
+		for _, refs := range []openapi3.SchemaRefs{s.AllOf, s.AnyOf, s.OneOf} {
+			for _, r := range refs {
+				walk(r)
+			}
+		}
+		walk(s.Items)
+		walk(s.Not)
+		walk(s.AdditionalProperties.Schema)
+	}
+	content := func(c openapi3.Content) {
+		for _, mt := range c {
+			walk(mt.Schema)
+		}
+	}
+	parameters := func(params openapi3.Parameters) {
+		for _, p := range params {
+			if p.Ref == "" && p.Value != nil {
+				walk(p.Value.Schema)
+				content(p.Value.Content)
+			}
+		}
+	}
+	headers := func(hs openapi3.Headers) {
+		for _, h := range hs {
+			if h.Ref == "" && h.Value != nil {
+				walk(h.Value.Schema)
+			}
+		}
+	}
+	responses := func(rs map[string]*openapi3.ResponseRef) {
+		for _, r := range rs {
+			if r.Ref == "" && r.Value != nil {
+				content(r.Value.Content)
+				headers(r.Value.Headers)
+			}
+		}
+	}
+
+	if c := doc.Components; c != nil {
+		for _, s := range c.Schemas {
+			walk(s)
+		}
+		for _, p := range c.Parameters {
+			parameters(openapi3.Parameters{p})
+		}
+		for _, b := range c.RequestBodies {
+			if b.Ref == "" && b.Value != nil {
+				content(b.Value.Content)
+			}
+		}
+		responses(c.Responses)
+		headers(c.Headers)
+	}
+	if doc.Paths == nil {
+		return
+	}
+	for _, item := range doc.Paths.Map() {
+		parameters(item.Parameters)
+		for _, op := range item.Operations() {
+			parameters(op.Parameters)
+			if b := op.RequestBody; b != nil && b.Ref == "" && b.Value != nil {
+				content(b.Value.Content)
+			}
+			if op.Responses != nil {
+				responses(op.Responses.Map())
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package openapi describes the API as an OpenAPI 3.1 document, converted
+// from the Swagger 2.0 document swag generates from the handler
+// annotations, and validates traffic against it.
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/docs"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+)
+
+// Version is the OpenAPI version of the document.
+const Version = "3.1.0"
+
+var document = sync.OnceValues(func() (*openapi3.T, error) {
+	return Convert([]byte(docs.SwaggerInfo.ReadDoc()))
+})
+
+// Document returns the document of the API, converted once.
+func Document() (*openapi3.T, error) {
+	return document()
+}
+
+// Convert converts a Swagger 2.0 document to OpenAPI 3.1. The conversion
+// yields OpenAPI 3.0, which is checked before its schemas are upgraded to
+// 3.1. The servers are dropped so that the document holds wherever the API
+// is served.
+func Convert(swagger []byte) (*openapi3.T, error) {
+	var v2 openapi2.T
+	if err := json.Unmarshal(swagger, &v2); err != nil {
+		return nil, fmt.Errorf("reading swagger document: %w", err)
+	}
+	doc, err := openapi2conv.ToV3(&v2)
+	if err != nil {
+		return nil, fmt.Errorf("converting swagger document: %w", err)
+	}
+	doc.Servers = nil
+	if err := doc.Validate(context.Background(), openapi3.DisableExamplesValidation()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	upgrade(doc)
+	doc.OpenAPI = Version
+	return doc, nil
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// Serve
+//
+//	@Summary		Get the OpenAPI document
+//	@Description	Get the API as an OpenAPI 3.1 document
+//	@Tags			meta
+//	@Produce		json
+//	@Router			/openapi.json [get]
+//	@Success		200	{object}	object
+//	@Failure		500	{object}	Err
+func Serve(c echo.Context) error {
+	doc, err := Document()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, doc)
+}
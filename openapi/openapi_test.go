@@ -0,0 +1,404 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/api"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apiv2"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/cache"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/internal/stubstore"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/stream"
+	"github.com/labstack/echo/v4"
+)
+
+const adminToken = "secret"
+
+// server routes the real handlers as main does over a stubstore.Store, with
+// every request and response checked against the document; drift fails t.
+// The routes requests reached are added to hit.
+func server(t *testing.T, hit map[string]bool) *echo.Echo {
+	t.Helper()
+	doc, err := Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := Validator(doc, Options{Responses: true, Drift: func(c echo.Context, err error) {
+		t.Errorf("drift from the OpenAPI document: %v", err)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub := stubstore.New()
+	cached := cache.NewStore(stub, cache.NewMemory(time.Minute, 0))
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if hit != nil {
+				hit[c.Request().Method+" "+c.Path()] = true
+			}
+			return next(c)
+		}
+	})
+	e.Use(validator)
+	e.GET("/openapi.json", Serve)
+	api.Register(e, stub, cached, stream.NewBroker(), api.Options{
+		AdminToken:    adminToken,
+		Currency:      apiv2.DefaultCurrency,
+		Heartbeat:     time.Second,
+		MaxComplexity: 500,
+		MaxDepth:      10,
+	})
+	return e
+}
+
+func do(e *echo.Echo, method, target, body string) *httptest.ResponseRecorder {
+	return send(e, method, target, echo.MIMEApplicationJSON, body)
+}
+
+// send makes a request as an admin, who may call every route.
+func send(e *echo.Echo, method, target, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	req.Header.Set(auth.HeaderAdminToken, adminToken)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDocument(t *testing.T) {
+	rec := do(server(t, nil), http.MethodGet, "/openapi.json", "")
+
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d: %v", rec.Code, err)
+	}
+	if doc.OpenAPI != Version {
+		t.Errorf("expected OpenAPI %s but got %s", Version, doc.OpenAPI)
+	}
+	for _, path := range []string{"/api/v1/wallets", "/api/v1/users/{id}/wallets", "/api/v2/wallets", "/api/v2/users/{id}/wallets"} {
+		if _, ok := doc.Paths[path]; !ok {
+			t.Errorf("expected %s to be described", path)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	t.Run("should write nullable as a type list and example as examples", func(t *testing.T) {
+		doc, err := Convert([]byte(`{"swagger": "2.0", "info": {"title": "Wallet API", "version": "1.0"},
+			"paths": {"/notes": {"post": {"consumes": ["application/json"], "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Note"}}], "responses": {"204": {"description": "No Content"}}}}},
+			"definitions": {"Note": {"type": "object", "required": ["text"], "properties": {"text": {"type": "string", "example": "Rent", "x-nullable": true}}}}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(doc)
+		var got struct {
+			OpenAPI    string `json:"openapi"`
+			Components struct {
+				Schemas map[string]struct {
+					Properties map[string]map[string]any `json:"properties"`
+				} `json:"schemas"`
+			} `json:"components"`
+		}
+		json.Unmarshal(data, &got)
+		text := got.Components.Schemas["Note"].Properties["text"]
+		if got.OpenAPI != "3.1.0" {
+			t.Errorf("expected OpenAPI 3.1.0 but got %s", got.OpenAPI)
+		}
+		if !reflect.DeepEqual(text["type"], []any{"string", "null"}) || !reflect.DeepEqual(text["examples"], []any{"Rent"}) {
+			t.Errorf("expected a type list and examples but got %v", text)
+		}
+		if _, ok := text["nullable"]; ok {
+			t.Errorf("expected no nullable but got %v", text)
+		}
+		if _, ok := text["example"]; ok {
+			t.Errorf("expected no example but got %v", text)
+		}
+
+		validator, err := Validator(doc, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := echo.New()
+		e.POST("/notes", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }, validator)
+		if rec := do(e, http.MethodPost, "/notes", `{"text": null}`); rec.Code != http.StatusNoContent {
+			t.Errorf("expected null to be accepted but got %d %s", rec.Code, rec.Body)
+		}
+		if rec := do(e, http.MethodPost, "/notes", `{"text": 5}`); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected a number to be refused but got %d %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("given a dangling reference should refuse it", func(t *testing.T) {
+		if _, err := Convert([]byte(`{"swagger": "2.0", "paths": {"/x": {"get": {"responses": {"200": {"schema": {"$ref": "#/definitions/missing"}}}}}}}`)); err == nil {
+			t.Error("expected a dangling reference to be refused")
+		}
+	})
+}
+
+// TestContract runs every documented operation through the validator, so
+// that an annotation and the handler it documents cannot drift apart.
+// Requests run in order over one stub: the later ones see what the earlier
+// ones changed.
+func TestContract(t *testing.T) {
+	hit := map[string]bool{}
+	e := server(t, hit)
+	const (
+		csv    = "text/csv"
+		ndjson = "application/x-ndjson"
+	)
+	schedule := `{"from_wallet_id": 1, "to_wallet_id": 3, "amount": 50, "description": "Savings plan", "recurrence": "0 9 1 * *", "on_insufficient_funds": "retry", "max_retries": 3, "retry_delay": "6h"}`
+	for _, tc := range []struct {
+		method, target, contentType, body string
+		status                            int
+	}{
+		{http.MethodGet, "/openapi.json", "", "", http.StatusOK},
+
+		{http.MethodGet, "/api/v1/wallets", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/wallets?wallet_type=Gold", "", "", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/wallets", "", `{"user_id": 2, "user_name": "Jane Doe", "wallet_name": "Jane Spare Card", "wallet_type": "CreditCard", "balance": -5}`, http.StatusCreated},
+		{http.MethodPost, "/api/v1/wallets", "", `{"user_id": 2, "user_name": "Jane Doe", "wallet_name": "Jane", "wallet_type": "Savings", "balance": -5}`, http.StatusUnprocessableEntity},
+		{http.MethodPut, "/api/v1/wallets/1", "", `{"user_id": 1, "user_name": "John Doe", "wallet_name": "John Savings", "wallet_type": "Savings", "balance": 100}`, http.StatusOK},
+		{http.MethodPut, "/api/v1/wallets/9", "", `{"user_id": 1, "user_name": "John Doe", "wallet_name": "John", "wallet_type": "Savings", "balance": 5}`, http.StatusNotFound},
+		{http.MethodPut, "/api/v1/wallets/2", "", `{"user_id": 2, "user_name": "Jane Doe", "wallet_name": "Jane Card", "wallet_type": "CreditCard", "balance": -20}`, http.StatusConflict},
+		{http.MethodPost, "/api/v1/wallets/import", csv, "user_id,user_name,wallet_name,wallet_type,balance\n5,Jim Doe,Jim Savings,Savings,10\n", http.StatusCreated},
+		{http.MethodPost, "/api/v1/wallets/import", ndjson, `{"user_id": 5, "user_name": "Jim Doe", "wallet_name": "Jim Gold", "wallet_type": "Gold", "balance": 10}` + "\n", http.StatusUnprocessableEntity},
+		{http.MethodGet, "/api/v1/wallets/export?format=ndjson", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/wallets/export?format=csv&wallet_type=Savings", "", "", http.StatusOK},
+
+		{http.MethodGet, "/api/v1/wallets/1/transactions", "", "", http.StatusOK},
+		{http.MethodPost, "/api/v1/wallets/1/deposits", "", `{"amount": 50, "description": "Salary"}`, http.StatusCreated},
+		{http.MethodPost, "/api/v1/wallets/1/withdrawals", "", `{"amount": 20, "description": "Cash"}`, http.StatusCreated},
+		{http.MethodPost, "/api/v1/wallets/2/withdrawals", "", `{"amount": 20, "description": "Cash"}`, http.StatusConflict},
+		{http.MethodPost, "/api/v1/transactions/1/reverse", "", `{"amount": 10, "reason": "Deposit posted to the wrong wallet"}`, http.StatusCreated},
+		{http.MethodPost, "/api/v1/transactions/9/reverse", "", `{"reason": "Deposit posted to the wrong wallet"}`, http.StatusNotFound},
+		{http.MethodGet, "/api/v1/wallets/1/statements?month=2026-09", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/wallets/1/statements?month=2026-09&format=csv", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/wallets/9/statements?month=2026-09", "", "", http.StatusNotFound},
+
+		{http.MethodGet, "/api/v1/wallets/1/holds", "", "", http.StatusOK},
+		{http.MethodPost, "/api/v1/wallets/1/holds", "", `{"amount": 25, "description": "Hotel booking"}`, http.StatusCreated},
+		{http.MethodPost, "/api/v1/wallets/1/holds/1/capture", "", `{"amount": 20, "description": "Hotel stay"}`, http.StatusOK},
+		{http.MethodPost, "/api/v1/wallets/1/holds/1/capture", "", `{"amount": 30, "description": "Hotel stay"}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/api/v1/wallets/1/holds/1/void", "", "", http.StatusOK},
+		{http.MethodPost, "/api/v1/wallets/1/holds/9/void", "", "", http.StatusNotFound},
+
+		{http.MethodGet, "/api/v1/wallets/1/limits", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/wallets/9/limits", "", "", http.StatusNotFound},
+		{http.MethodPut, "/api/v1/wallets/1/limits", "", `{"max_withdrawal": 500}`, http.StatusOK},
+		{http.MethodGet, "/api/v1/wallet-types/Savings/limits", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/wallet-types/Gold/limits", "", "", http.StatusNotFound},
+		{http.MethodPut, "/api/v1/wallet-types/Savings/limits", "", `{"daily_outflow": 1000, "max_transfers_per_hour": 5}`, http.StatusOK},
+
+		{http.MethodPost, "/api/v1/transfers/quote", "", `{"from_wallet_id": 1, "to_wallet_id": 3, "amount": 50, "description": "Rent share"}`, http.StatusOK},
+		{http.MethodPost, "/api/v1/transfers", "", `{"from_wallet_id": 1, "to_wallet_id": 3, "amount": 50, "description": "Rent share"}`, http.StatusCreated},
+		{http.MethodPost, "/api/v1/transfers", "", `{"from_wallet_id": 1, "to_wallet_id": 9, "amount": 50, "description": "Rent share"}`, http.StatusNotFound},
+		{http.MethodGet, "/api/v1/transfers/1", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/transfers/9", "", "", http.StatusNotFound},
+
+		{http.MethodGet, "/api/v1/wallets/1/scheduled-transfers", "", "", http.StatusOK},
+		{http.MethodPost, "/api/v1/scheduled-transfers", "", schedule, http.StatusCreated},
+		{http.MethodPost, "/api/v1/scheduled-transfers", "", strings.Replace(schedule, "0 9 1 * *", "now and then", 1), http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/scheduled-transfers/1", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/scheduled-transfers/9", "", "", http.StatusNotFound},
+		{http.MethodPut, "/api/v1/scheduled-transfers/1", "", schedule, http.StatusOK},
+		{http.MethodPost, "/api/v1/scheduled-transfers/1/pause", "", "", http.StatusOK},
+		{http.MethodPost, "/api/v1/scheduled-transfers/1/resume", "", "", http.StatusConflict},
+		{http.MethodGet, "/api/v1/scheduled-transfers/1/executions", "", "", http.StatusOK},
+		{http.MethodDelete, "/api/v1/scheduled-transfers/1", "", "", http.StatusNoContent},
+		{http.MethodDelete, "/api/v1/scheduled-transfers/9", "", "", http.StatusNotFound},
+
+		{http.MethodGet, "/api/v1/wallets/2/credit-card", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/wallets/1/credit-card", "", "", http.StatusNotFound},
+		{http.MethodPut, "/api/v1/wallets/2/credit-card", "", `{"credit_limit": 5000, "apr": 18.99, "statement_day": 25, "due_day": 10}`, http.StatusOK},
+		{http.MethodPut, "/api/v1/wallets/1/credit-card", "", `{"credit_limit": 5000, "apr": 18.99, "statement_day": 25, "due_day": 10}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/api/v1/wallets/4/credit-card/spend", "", `{"amount": 25.5, "description": "Coffee"}`, http.StatusCreated},
+		{http.MethodPost, "/api/v1/wallets/2/credit-card/payments", "", `{"amount": 20, "description": "Repayment"}`, http.StatusConflict},
+		{http.MethodGet, "/api/v1/wallets/2/credit-card/statements", "", "", http.StatusOK},
+
+		{http.MethodGet, "/api/v1/wallets/1/interest", "", "", http.StatusOK},
+		{http.MethodPut, "/api/v1/wallets/1/interest/rate", "", `{"annual_rate": 1.5, "day_count": "ACT/365"}`, http.StatusOK},
+		{http.MethodGet, "/api/v1/interest/products", "", "", http.StatusOK},
+		{http.MethodPut, "/api/v1/interest/products/Savings", "", `{"annual_rate": 1.25, "day_count": "30/360"}`, http.StatusOK},
+
+		{http.MethodGet, "/api/v1/users/1/wallets", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/users/1/summary", "", "", http.StatusOK},
+
+		{http.MethodGet, "/api/v2/wallets", "", "", http.StatusOK},
+		{http.MethodPost, "/api/v2/wallets", "", `{"name": "Jane Savings", "type": "Savings", "balance": "5.00", "user": {"id": 2, "name": "Jane Doe"}}`, http.StatusCreated},
+		{http.MethodPut, "/api/v2/wallets/1", "", `{"name": "John Savings", "type": "Savings", "balance": "130.00", "user": {"id": 1, "name": "John Doe"}}`, http.StatusOK},
+		{http.MethodPut, "/api/v2/wallets/9", "", `{"name": "Jane", "type": "Savings", "balance": "5.00", "user": {"id": 2, "name": "Jane Doe"}}`, http.StatusNotFound},
+		{http.MethodGet, "/api/v2/users/1/wallets", "", "", http.StatusOK},
+		{http.MethodPost, "/graphql", "", `{"query": "{ user(id: 1) { name wallets { id walletName balance } } }"}`, http.StatusOK},
+
+		{http.MethodGet, "/api/v1/audit?actor=ops@example.com&wallet_id=1", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/audit?from=yesterday", "", "", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/reports/balances", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/ledger/trial-balance", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/ledger/invariant", "", "", http.StatusOK},
+
+		{http.MethodPost, "/api/v1/reconciliations?name=settlement-2026-09-02.csv", csv, "date,amount,reference,description\n2026-09-02,250.00,TX42,Top-up John Doe\n", http.StatusCreated},
+		{http.MethodPost, "/api/v1/reconciliations?name=settlement-2026-09-01.csv", csv, "date,amount,reference,description\n2026-09-01,50.00,TX2,Top-up John Doe\n", http.StatusConflict},
+		{http.MethodGet, "/api/v1/reconciliations", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/reconciliations/1", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/reconciliations/9", "", "", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/reconciliations/1/items/2/resolve", "", `{"note": "Bank fee, booked by hand"}`, http.StatusOK},
+		{http.MethodPost, "/api/v1/reconciliations/1/items/1/resolve", "", `{"note": "Bank fee, booked by hand"}`, http.StatusConflict},
+
+		{http.MethodGet, "/api/v1/wallet-types", "", "", http.StatusOK},
+		{http.MethodPost, "/api/v1/wallet-types", "", `{"key": "Gold", "name": "Gold"}`, http.StatusCreated},
+		{http.MethodPost, "/api/v1/wallet-types", "", `{"key": "Savings", "name": "Savings"}`, http.StatusConflict},
+		{http.MethodPut, "/api/v1/wallet-types/Savings", "", `{"name": "Savings", "max_balance": 100000}`, http.StatusOK},
+		{http.MethodDelete, "/api/v1/wallet-types/Savings", "", "", http.StatusConflict},
+		{http.MethodDelete, "/api/v1/wallet-types/Gold", "", "", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/wallet-types/Savings/fees", "", "", http.StatusOK},
+		{http.MethodPut, "/api/v1/wallet-types/Savings/fees/transfer", "", `{"flat": 0.5, "percent": 0.1, "min": 1}`, http.StatusOK},
+		{http.MethodDelete, "/api/v1/wallet-types/Savings/fees/transfer", "", "", http.StatusNoContent},
+		{http.MethodDelete, "/api/v1/wallet-types/Savings/fees/withdrawal", "", "", http.StatusNotFound},
+
+		{http.MethodPost, "/api/v1/wallets/3/freeze", "", `{"reason": "Suspected card testing"}`, http.StatusOK},
+		{http.MethodPost, "/api/v1/wallets/3/unfreeze", "", `{"reason": "Cleared by the customer"}`, http.StatusOK},
+		{http.MethodPost, "/api/v1/wallets/2/close", "", `{"reason": "Customer request"}`, http.StatusOK},
+		{http.MethodPost, "/api/v1/wallets/2/close", "", `{"reason": "Customer request"}`, http.StatusConflict},
+		{http.MethodGet, "/api/v1/wallets/2/status-history", "", "", http.StatusOK},
+
+		{http.MethodGet, "/api/v1/webhooks", "", "", http.StatusOK},
+		{http.MethodPost, "/api/v1/webhooks", "", `{"target_url": "https://partner.example.com/hooks/wallet", "events": ["WalletCreated"]}`, http.StatusCreated},
+		{http.MethodPost, "/api/v1/webhooks", "", `{"target_url": "ftp://partner.example.com", "events": ["WalletCreated"]}`, http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/webhooks/1", "", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/webhooks/9", "", "", http.StatusNotFound},
+		{http.MethodPut, "/api/v1/webhooks/1", "", `{"target_url": "https://partner.example.com/hooks/v2", "events": ["BalanceChanged"], "active": true}`, http.StatusOK},
+		{http.MethodGet, "/api/v1/webhooks/1/deliveries", "", "", http.StatusOK},
+		{http.MethodPost, "/api/v1/webhooks/1/deliveries/1/redeliver", "", "", http.StatusAccepted},
+		{http.MethodPost, "/api/v1/webhooks/1/deliveries/9/redeliver", "", "", http.StatusNotFound},
+		{http.MethodDelete, "/api/v1/webhooks/1", "", "", http.StatusNoContent},
+
+		{http.MethodDelete, "/api/v1/wallets/3", "", "", http.StatusNoContent},
+		{http.MethodDelete, "/api/v1/wallets/2", "", "", http.StatusConflict},
+		{http.MethodDelete, "/api/v2/wallets/4", "", "", http.StatusNoContent},
+		{http.MethodDelete, "/api/v2/wallets/9", "", "", http.StatusNotFound},
+	} {
+		contentType := tc.contentType
+		if contentType == "" {
+			contentType = echo.MIMEApplicationJSON
+		}
+		rec := send(e, tc.method, tc.target, contentType, tc.body)
+
+		if rec.Code != tc.status {
+			t.Errorf("%s %s: expected status code %d but got %d %s", tc.method, tc.target, tc.status, rec.Code, rec.Body)
+		}
+	}
+
+	for _, target := range []string{"/api/v1/wallets", "/api/v1/users/1/wallets", "/api/v2/wallets", "/api/v2/users/1/wallets"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("If-None-Match", "*")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("GET %s: expected status code %d but got %d %s", target, http.StatusNotModified, rec.Code, rec.Body)
+		}
+	}
+
+	// The stream answers until the client leaves.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1/wallets/stream", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), "text/event-stream") {
+		t.Errorf("GET stream: expected an event stream but got %d %v", rec.Code, rec.Header())
+	}
+
+	doc, err := Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	documented := map[string]bool{}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			route := method + " " + pathParam.ReplaceAllString(path, ":$1")
+			documented[route] = true
+			if !hit[route] {
+				t.Errorf("%s is documented but not exercised", route)
+			}
+		}
+	}
+	for _, r := range e.Routes() {
+		if r.Method == echo.RouteNotFound {
+			continue
+		}
+		route := r.Method + " " + r.Path
+		if !documented[route] && !undocumented[route] {
+			t.Errorf("%s is routed but not documented", route)
+		}
+	}
+}
+
+// pathParam is a parameter of a documented path, {id}, which echo routes
+// as :id.
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// undocumented are the routes left out of the document on purpose.
+var undocumented = map[string]bool{
+	// Queries over GET are the POST operation with the request in the
+	// query string.
+	"GET /graphql": true,
+}
+
+func TestValidator(t *testing.T) {
+	e := server(t, nil)
+
+	t.Run("given a path parameter of the wrong type should return 400", func(t *testing.T) {
+		rec := do(e, http.MethodGet, "/api/v1/users/abc/wallets", "")
+
+		var got Err
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(got.Message, `"id"`) {
+			t.Errorf("unexpected response %d %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("given a body that does not match the schema should return 400", func(t *testing.T) {
+		rec := do(e, http.MethodPost, "/api/v2/wallets", `{"name": "Jane", "type": "Savings", "balance": 5, "user": {"id": 2, "name": "Jane Doe"}}`)
+
+		if rec.Code != http.StatusBadRequest || strings.Contains(rec.Body.String(), "\\n") {
+			t.Errorf("unexpected response %d %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("given a route the document does not describe should pass it", func(t *testing.T) {
+		e.GET("/healthz", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+		if rec := do(e, http.MethodGet, "/healthz", ""); rec.Code != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("given a response that drifts should replace it with 500", func(t *testing.T) {
+		doc, _ := Document()
+		validator, _ := Validator(doc, Options{Responses: true})
+		e := echo.New()
+		e.GET("/api/v1/wallet-types", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, map[string]string{"key": "not a list"})
+		}, validator)
+
+		if rec := do(e, http.MethodGet, "/api/v1/wallet-types", ""); rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status code %d but got %d %s", http.StatusInternalServerError, rec.Code, rec.Body)
+		}
+	})
+}
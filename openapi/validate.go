@@ -0,0 +1,149 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	// Bodies the API reads or writes raw are checked for their content
+	// type only.
+	for _, contentType := range []string{"text/csv", "application/x-ndjson", "application/xml", "application/pdf", "text/event-stream"} {
+		openapi3filter.RegisterBodyDecoder(contentType, openapi3filter.FileBodyDecoder)
+	}
+}
+
+type Options struct {
+	// Responses validates what handlers answer as well, which buffers
+	// every response but event streams. It is meant for tests.
+	Responses bool
+	// Drift is told of a response that does not match the document. When
+	// nil the response is replaced with a 500.
+	Drift func(c echo.Context, err error)
+}
+
+// Validator checks requests against doc and answers 400 to those that do
+// not match. Routes doc does not describe pass untouched, and so do
+// credentials, which the auth middleware checks.
+func Validator(doc *openapi3.T, opts Options) (echo.MiddlewareFunc, error) {
+	doc, err := downgrade(doc)
+	if err != nil {
+		return nil, err
+	}
+	router, err := legacy.NewRouter(doc, openapi3.DisableExamplesValidation())
+	if err != nil {
+		return nil, err
+	}
+	filter := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route, params, err := router.FindRoute(req)
+			if err != nil {
+				return next(c)
+			}
+			in := &openapi3filter.RequestValidationInput{Request: req, PathParams: params, Route: route, Options: filter}
+			if err := openapi3filter.ValidateRequest(req.Context(), in); err != nil {
+				return c.JSON(http.StatusBadRequest, Err{Message: requestError(err)})
+			}
+			if !opts.Responses {
+				return next(c)
+			}
+			return validateResponse(c, next, in, opts.Drift)
+		}
+	}, nil
+}
+
+// requestError is the first line of err, without the schema dump that
+// follows it.
+func requestError(err error) string {
+	var re *openapi3filter.RequestError
+	if errors.As(err, &re) {
+		msg := re.Error()
+		if i := strings.Index(msg, "\n"); i >= 0 {
+			msg = msg[:i]
+		}
+		return msg
+	}
+	return err.Error()
+}
+
+func validateResponse(c echo.Context, next echo.HandlerFunc, in *openapi3filter.RequestValidationInput, drift func(echo.Context, error)) error {
+	res := c.Response()
+	real := res.Writer
+	rec := &recorder{ResponseWriter: real}
+	res.Writer = rec
+	err := next(c)
+	res.Writer = real
+	if rec.stream {
+		return err
+	}
+	if rec.status == 0 {
+		// Nothing was written; the error handler answers with the real writer.
+		return err
+	}
+
+	out := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: in,
+		Status:                 rec.status,
+		Header:                 real.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+	if verr := openapi3filter.ValidateResponse(context.Background(), out); verr != nil {
+		verr = fmt.Errorf("%s %s answered %d: %w", in.Request.Method, in.Route.Path, rec.status, verr)
+		if drift != nil {
+			drift(c, verr)
+		} else {
+			real.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+			real.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(real).Encode(Err{Message: "response does not match the OpenAPI document: " + verr.Error()})
+			return err
+		}
+	}
+	real.WriteHeader(rec.status)
+	real.Write(rec.body.Bytes())
+	return err
+}
+
+// recorder holds a response back for validation, except an event stream,
+// which it lets through as it is written.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	stream bool
+}
+
+func (r *recorder) WriteHeader(code int) {
+	r.status = code
+	if strings.HasPrefix(r.Header().Get(echo.HeaderContentType), "text/event-stream") {
+		r.stream = true
+		r.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.stream {
+		return r.ResponseWriter.Write(b)
+	}
+	return r.body.Write(b)
+}
+
+func (r *recorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok && r.stream {
+		f.Flush()
+	}
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+)
+
+// insertAudit records a wallet mutation in the caller's transaction so the
+// audit trail can never disagree with the data it describes.
+func insertAudit(tx *sql.Tx, actor audit.Actor, action string, walletID int, before, after any) error {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+	diff, err := audit.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	stmt := "INSERT INTO audit_log (actor, claimed, action, wallet_id, before, after, diff, ip, request_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	_, err = tx.Exec(stmt, actor.ID, actor.Claimed, action, walletID, beforeJSON, afterJSON, []byte(diff), actor.IP, actor.RequestID)
+	return err
+}
+
+func (p *Postgres) AuditLogs(filter audit.Filter) ([]audit.Record, error) {
+	var conds []string
+	var args []any
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.Actor != "" {
+		// Each side of the OR has its own index.
+		where("(actor = $%[1]d OR claimed = $%[1]d)", filter.Actor)
+	}
+	if filter.WalletID != 0 {
+		where("wallet_id = $%d", filter.WalletID)
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+
+	query := "SELECT id, actor, claimed, action, wallet_id, before, after, diff, ip, request_id, created_at FROM audit_log"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY id DESC"
+
+	rows, err := p.Db.Query(query, args...)
+	if err != nil {
+		return nil, errors.New("failed to get audit logs")
+	}
+	defer rows.Close()
+
+	records := []audit.Record{}
+	for rows.Next() {
+		var r audit.Record
+		var before, after, diff []byte
+		err := rows.Scan(&r.ID, &r.Actor, &r.Claimed, &r.Action, &r.WalletID,
+			&before, &after, &diff,
+			&r.IP, &r.RequestID, &r.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		r.Before, r.After, r.Diff = before, after, diff
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
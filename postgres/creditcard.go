@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+const creditCardQuery = `SELECT c.wallet_id, c.credit_limit, w.balance, c.apr, c.statement_day, c.due_day, c.created_at
+	FROM credit_card c JOIN user_wallet w ON w.id = c.wallet_id`
+
+const statementColumns = "id, wallet_id, period_start, period_end, opening_balance, spend, payments, adjustments, interest, closing_balance, minimum_payment, due_date, created_at"
+
+func scanCreditCard(row scanner) (*creditcard.Account, error) {
+	var a creditcard.Account
+	err := row.Scan(&a.WalletID, &a.CreditLimit, &a.Balance, &a.APR, &a.StatementDay, &a.DueDay, &a.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, creditcard.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func scanStatement(row scanner) (*creditcard.Statement, error) {
+	var s creditcard.Statement
+	err := row.Scan(&s.ID, &s.WalletID, &s.PeriodStart, &s.PeriodEnd,
+		&s.OpeningBalance, &s.Spend, &s.Payments, &s.Adjustments, &s.Interest,
+		&s.ClosingBalance, &s.MinimumPayment, &s.DueDate, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (p *Postgres) CreditCard(walletID int) (*creditcard.Account, error) {
+	return scanCreditCard(p.Db.QueryRow(creditCardQuery+" WHERE c.wallet_id = $1", walletID))
+}
+
+func (p *Postgres) SaveCreditCard(walletID int, terms creditcard.Terms) (*creditcard.Account, error) {
+	var account *creditcard.Account
+	err := p.withTx(func(tx *sql.Tx) error {
+		w, err := walletForUpdate(tx, walletID)
+		if errors.Is(err, wallet.ErrWalletNotFound) {
+			return creditcard.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if w.WalletType != wallet.TypeCreditCard {
+			return creditcard.ErrNotCreditCard
+		}
+
+		stmt := `INSERT INTO credit_card (wallet_id, credit_limit, apr, statement_day, due_day) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (wallet_id) DO UPDATE SET credit_limit = $2, apr = $3, statement_day = $4, due_day = $5`
+		if _, err := tx.Exec(stmt, walletID, terms.CreditLimit, terms.APR, terms.StatementDay, terms.DueDay); err != nil {
+			return err
+		}
+		account, err = scanCreditCard(tx.QueryRow(creditCardQuery+" WHERE c.wallet_id = $1", walletID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Spend adds to the outstanding balance of a credit card. The wallet row is
+// locked while the limit is checked so concurrent spends cannot overshoot.
+func (p *Postgres) Spend(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	var t *transaction.Transaction
+	err := p.withTx(func(tx *sql.Tx) error {
+		a, err := scanCreditCard(tx.QueryRow(creditCardQuery+" WHERE c.wallet_id = $1 FOR UPDATE OF w", walletID))
+		if err != nil {
+			return err
+		}
+		if a.Balance+amount > a.CreditLimit {
+			return creditcard.ErrCreditLimitExceeded
+		}
+		t, err = postTransaction(tx, walletID, transaction.KindSpend, amount, description)
+		return err
+	})
+	return t, err
+}
+
+// Pay reduces the outstanding balance of a credit card.
+func (p *Postgres) Pay(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	var t *transaction.Transaction
+	err := p.withTx(func(tx *sql.Tx) error {
+		a, err := scanCreditCard(tx.QueryRow(creditCardQuery+" WHERE c.wallet_id = $1 FOR UPDATE OF w", walletID))
+		if err != nil {
+			return err
+		}
+		if amount > a.Balance {
+			return creditcard.ErrOverpayment
+		}
+		t, err = postTransaction(tx, walletID, transaction.KindPayment, -amount, description)
+		return err
+	})
+	return t, err
+}
+
+func (p *Postgres) Statements(walletID int) ([]creditcard.Statement, error) {
+	rows, err := p.Db.Query("SELECT "+statementColumns+" FROM credit_card_statement WHERE wallet_id = $1 ORDER BY period_end DESC", walletID)
+	if err != nil {
+		return nil, errors.New("failed to get statements")
+	}
+	defer rows.Close()
+
+	statements := []creditcard.Statement{}
+	for rows.Next() {
+		s, err := scanStatement(rows)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, *s)
+	}
+	return statements, rows.Err()
+}
+
+func (p *Postgres) ActiveCreditCards() ([]creditcard.Account, error) {
+	rows, err := p.Db.Query(creditCardQuery+" WHERE w.status = $1 ORDER BY c.wallet_id", wallet.StatusActive)
+	if err != nil {
+		return nil, errors.New("failed to get credit cards")
+	}
+	defer rows.Close()
+
+	var accounts []creditcard.Account
+	for rows.Next() {
+		a, err := scanCreditCard(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *a)
+	}
+	return accounts, rows.Err()
+}
+
+func (p *Postgres) LastStatement(walletID int) (*creditcard.Statement, error) {
+	s, err := scanStatement(p.Db.QueryRow("SELECT "+statementColumns+" FROM credit_card_statement WHERE wallet_id = $1 ORDER BY period_end DESC LIMIT 1", walletID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return s, err
+}
+
+func (p *Postgres) Activity(walletID int, from, to time.Time) (creditcard.Activity, error) {
+	query := `SELECT
+			COALESCE(SUM(amount) FILTER (WHERE created_at < $2), 0),
+			COALESCE(SUM(amount) FILTER (WHERE created_at >= $2 AND kind = $4), 0),
+			COALESCE(-SUM(amount) FILTER (WHERE created_at >= $2 AND kind = $5), 0),
+			COALESCE(SUM(amount) FILTER (WHERE created_at >= $2 AND kind <> $4 AND kind <> $5), 0)
+		FROM wallet_transaction WHERE wallet_id = $1 AND created_at < $3`
+	var a creditcard.Activity
+	err := p.Db.QueryRow(query, walletID, from, to, transaction.KindSpend, transaction.KindPayment).
+		Scan(&a.Opening, &a.Spend, &a.Payments, &a.Adjustments)
+	return a, err
+}
+
+func (p *Postgres) CloseStatement(s creditcard.Statement) (*creditcard.Statement, error) {
+	var saved *creditcard.Statement
+	err := p.withTx(func(tx *sql.Tx) error {
+		stmt := `INSERT INTO credit_card_statement (wallet_id, period_start, period_end, opening_balance, spend, payments, adjustments, interest, closing_balance, minimum_payment, due_date)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING ` + statementColumns
+		var err error
+		saved, err = scanStatement(tx.QueryRow(stmt, s.WalletID, s.PeriodStart, s.PeriodEnd,
+			s.OpeningBalance, s.Spend, s.Payments, s.Adjustments, s.Interest,
+			s.ClosingBalance, s.MinimumPayment, s.DueDate,
+		))
+		if err != nil {
+			return err
+		}
+		if s.Interest == 0 {
+			return nil
+		}
+		// Date the interest inside the period it was charged for, so the
+		// next statement opens with it included.
+		at := s.PeriodEnd.Add(-time.Microsecond)
+		_, err = postTransactionAt(tx, s.WalletID, transaction.KindInterest, s.Interest, "Interest "+s.PeriodEnd.Format("2006-01-02"), at)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+// walletQuery selects wallets along with their crypto details, in the column
+// order read by scanWalletWithCrypto.
+const walletQuery = `SELECT w.id, w.user_id, w.user_name, w.wallet_name, w.wallet_type, w.balance, w.created_at, w.status, w.held,
+	c.asset, c.address, c.decimals, c.amount
+	FROM user_wallet w LEFT JOIN crypto_wallet c ON c.wallet_id = w.id`
+
+func scanWalletWithCrypto(row scanner) (*wallet.Wallet, error) {
+	var w wallet.Wallet
+	var asset, address sql.NullString
+	var decimals sql.NullInt32
+	var amount wallet.Amount
+	var held float64
+	err := row.Scan(&w.ID,
+		&w.UserID, &w.UserName,
+		&w.WalletName, &w.WalletType,
+		&w.Balance, &w.CreatedAt,
+		&w.Status, &held,
+		&asset, &address, &decimals, &amount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	w.AvailableBalance = available(w.Balance, held)
+	if asset.Valid {
+		w.Crypto = &wallet.Crypto{
+			Asset:    asset.String,
+			Address:  address.String,
+			Decimals: int(decimals.Int32),
+			Amount:   amount,
+		}
+	}
+	return &w, nil
+}
+
+func saveCrypto(tx *sql.Tx, walletID int, c wallet.Crypto) error {
+	stmt := `INSERT INTO crypto_wallet (wallet_id, asset, address, decimals, amount) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (wallet_id) DO UPDATE SET asset = $2, address = $3, decimals = $4, amount = $5`
+	_, err := tx.Exec(stmt, walletID, c.Asset, c.Address, c.Decimals, c.Amount)
+	return err
+}
+
+func deleteCrypto(tx *sql.Tx, walletID int) error {
+	_, err := tx.Exec("DELETE FROM crypto_wallet WHERE wallet_id = $1", walletID)
+	return err
+}
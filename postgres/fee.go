@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/fee"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+)
+
+const feeColumns = "wallet_type, operation, flat, percent, tiers, min_fee, max_fee"
+
+func scanFeeRule(row scanner) (*fee.Rule, error) {
+	var r fee.Rule
+	var tiers []byte
+	if err := row.Scan(&r.WalletType, &r.Operation, &r.Flat, &r.Percent, &tiers, &r.Min, &r.Max); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tiers, &r.Tiers); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (p *Postgres) TypeFees(key string) ([]fee.Rule, error) {
+	var exists bool
+	if err := p.Db.QueryRow("SELECT EXISTS (SELECT 1 FROM wallet_types WHERE key = $1)", key).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fee.ErrTypeNotFound
+	}
+	rows, err := p.Db.Query("SELECT "+feeColumns+" FROM fee_rule WHERE wallet_type = $1 ORDER BY operation", key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []fee.Rule{}
+	for rows.Next() {
+		r, err := scanFeeRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *r)
+	}
+	return rules, rows.Err()
+}
+
+func (p *Postgres) SaveTypeFee(r fee.Rule) (*fee.Rule, error) {
+	if _, err := p.TypeFees(r.WalletType); err != nil {
+		return nil, err
+	}
+	if r.Tiers == nil {
+		r.Tiers = []fee.Tier{}
+	}
+	tiers, err := json.Marshal(r.Tiers)
+	if err != nil {
+		return nil, err
+	}
+	stmt := `INSERT INTO fee_rule (` + feeColumns + `, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (wallet_type, operation) DO UPDATE SET flat = $3, percent = $4, tiers = $5, min_fee = $6, max_fee = $7, updated_at = $8
+		RETURNING ` + feeColumns
+	return scanFeeRule(p.Db.QueryRow(stmt, r.WalletType, r.Operation, r.Flat, r.Percent, string(tiers), r.Min, r.Max, time.Now()))
+}
+
+func (p *Postgres) DeleteTypeFee(key, operation string) error {
+	res, err := p.Db.Exec("DELETE FROM fee_rule WHERE wallet_type = $1 AND operation = $2", key, operation)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fee.ErrNotFound
+	}
+	return nil
+}
+
+// feeFor returns the fee of moving amount out of a wallet by operation,
+// zero when its type charges none.
+func feeFor(q queryer, walletID int, operation string, amount float64) (float64, error) {
+	query := `SELECT ` + feeColumns + ` FROM fee_rule
+		WHERE operation = $2 AND wallet_type = (SELECT t.key FROM user_wallet w JOIN wallet_types t ON t.name = w.wallet_type WHERE w.id = $1)`
+	r, err := scanFeeRule(q.QueryRow(query, walletID, operation))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return r.Compute(amount), nil
+}
+
+// chargeFee posts the fee of an operation to the wallet money left, booked
+// to the house fee account, and returns the fee transaction, or nil when
+// there is no fee.
+func chargeFee(tx *sql.Tx, walletID int, operation string, amount float64, description string) (*int64, float64, error) {
+	charged, err := feeFor(tx, walletID, operation, amount)
+	if err != nil || charged <= 0 {
+		return nil, 0, err
+	}
+	t, err := postTransaction(tx, walletID, transaction.KindFee, -charged, description)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &t.ID, charged, nil
+}
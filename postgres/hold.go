@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/hold"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+const holdColumns = "id, wallet_id, amount, description, status, captured_amount, transaction_id, expires_at, created_at, resolved_at"
+
+// sweepBatch bounds the holds expired per sweep.
+const sweepBatch = 500
+
+func scanHold(row scanner) (*hold.Hold, error) {
+	var h hold.Hold
+	err := row.Scan(&h.ID, &h.WalletID, &h.Amount, &h.Description, &h.Status, &h.CapturedAmount, &h.TransactionID, &h.ExpiresAt, &h.CreatedAt, &h.ResolvedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hold.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// CreateHold reserves funds under the wallet row lock: the available balance
+// must still satisfy the rules of the wallet type, and the hold counts
+// against the spending limits as if it were spent.
+func (p *Postgres) CreateHold(walletID int, r hold.Request) (*hold.Hold, error) {
+	var h *hold.Hold
+	err := p.withTx(func(tx *sql.Tx) error {
+		var balance, held float64
+		var status, walletType string
+		row := tx.QueryRow("UPDATE user_wallet SET held = held + $1 WHERE id = $2 RETURNING balance, held, status, wallet_type", r.Amount, walletID)
+		err := row.Scan(&balance, &held, &status, &walletType)
+		if errors.Is(err, sql.ErrNoRows) {
+			return wallet.ErrWalletNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if status != wallet.StatusActive {
+			return &wallet.StatusError{WalletID: walletID, Status: status}
+		}
+		if walletType == wallet.TypeCreditCard {
+			return transaction.ErrCreditCard
+		}
+		wt, err := walletTypeFor(tx, walletID)
+		if err != nil {
+			return err
+		}
+		if err := wt.CheckBalance(available(balance, held)); err != nil {
+			return err
+		}
+		if err := checkLimits(tx, walletID, wt.Key, transaction.KindCapture, r.Amount); err != nil {
+			return err
+		}
+
+		stmt := "INSERT INTO wallet_hold (wallet_id, amount, description, expires_at) VALUES ($1, $2, $3, $4) RETURNING " + holdColumns
+		h, err = scanHold(tx.QueryRow(stmt, walletID, r.Amount, r.Description, r.ExpiresAt))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (p *Postgres) Holds(walletID int) ([]hold.Hold, error) {
+	rows, err := p.Db.Query("SELECT "+holdColumns+" FROM wallet_hold WHERE wallet_id = $1 ORDER BY id DESC", walletID)
+	if err != nil {
+		return nil, errors.New("failed to get holds")
+	}
+	defer rows.Close()
+
+	holds := []hold.Hold{}
+	for rows.Next() {
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, *h)
+	}
+	return holds, rows.Err()
+}
+
+// activeHold locks the wallet and then the hold, the order every change of
+// a hold takes, and checks the hold can still be resolved at now.
+func activeHold(tx *sql.Tx, walletID int, id int64, now time.Time) (*hold.Hold, error) {
+	if _, err := lockWallets(tx, walletID); err != nil {
+		return nil, err
+	}
+	h, err := scanHold(tx.QueryRow("SELECT "+holdColumns+" FROM wallet_hold WHERE id = $1 AND wallet_id = $2 FOR UPDATE", id, walletID))
+	if err != nil {
+		return nil, err
+	}
+	if h.Status != hold.StatusActive {
+		return nil, &hold.StateError{HoldID: id, Status: h.Status}
+	}
+	if !h.ExpiresAt.After(now) {
+		return nil, &hold.StateError{HoldID: id, Status: hold.StatusExpired}
+	}
+	return h, nil
+}
+
+// releaseHold gives the funds of an active hold back to the available
+// balance.
+func releaseHold(tx *sql.Tx, h *hold.Hold) error {
+	_, err := tx.Exec("UPDATE user_wallet SET held = held - $1 WHERE id = $2", h.Amount, h.WalletID)
+	return err
+}
+
+func resolveHold(tx *sql.Tx, id int64, status string, captured *float64, transactionID *int64, now time.Time) (*hold.Hold, error) {
+	stmt := "UPDATE wallet_hold SET status = $2, captured_amount = $3, transaction_id = $4, resolved_at = $5 WHERE id = $1 RETURNING " + holdColumns
+	return scanHold(tx.QueryRow(stmt, id, status, captured, transactionID, now))
+}
+
+func (p *Postgres) CaptureHold(walletID int, id int64, c hold.Capture) (*hold.Hold, error) {
+	now := time.Now().UTC()
+	var captured *hold.Hold
+	err := p.withTx(func(tx *sql.Tx) error {
+		h, err := activeHold(tx, walletID, id, now)
+		if err != nil {
+			return err
+		}
+		amount := h.Amount
+		if c.Amount != nil {
+			amount = *c.Amount
+		}
+		if amount > h.Amount {
+			return hold.ErrOverCapture
+		}
+		description := c.Description
+		if description == "" {
+			description = h.Description
+		}
+
+		// Release first so the capture is taken from the funds it held.
+		if err := releaseHold(tx, h); err != nil {
+			return err
+		}
+		t, err := postTransaction(tx, walletID, transaction.KindCapture, -amount, description)
+		if err != nil {
+			return err
+		}
+		captured, err = resolveHold(tx, id, hold.StatusCaptured, &amount, &t.ID, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return captured, nil
+}
+
+func (p *Postgres) VoidHold(walletID int, id int64) (*hold.Hold, error) {
+	now := time.Now().UTC()
+	var voided *hold.Hold
+	err := p.withTx(func(tx *sql.Tx) error {
+		h, err := activeHold(tx, walletID, id, now)
+		if err != nil {
+			return err
+		}
+		if err := releaseHold(tx, h); err != nil {
+			return err
+		}
+		voided, err = resolveHold(tx, id, hold.StatusVoided, nil, nil, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return voided, nil
+}
+
+// ExpireHolds releases the holds expired at now, each in its own
+// transaction taking the wallet lock first like every other change of a
+// hold. A hold resolved meanwhile is left alone.
+func (p *Postgres) ExpireHolds(now time.Time) (int, error) {
+	rows, err := p.Db.Query("SELECT id, wallet_id FROM wallet_hold WHERE status = $1 AND expires_at <= $2 ORDER BY expires_at LIMIT $3", hold.StatusActive, now, sweepBatch)
+	if err != nil {
+		return 0, err
+	}
+	type due struct {
+		id       int64
+		walletID int
+	}
+	var expired []due
+	for rows.Next() {
+		var d due
+		if err := rows.Scan(&d.id, &d.walletID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		expired = append(expired, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, d := range expired {
+		err := p.withTx(func(tx *sql.Tx) error {
+			if _, err := lockWallets(tx, d.walletID); err != nil {
+				return err
+			}
+			h, err := scanHold(tx.QueryRow("SELECT "+holdColumns+" FROM wallet_hold WHERE id = $1 AND status = $2 FOR UPDATE", d.id, hold.StatusActive))
+			if errors.Is(err, hold.ErrNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := releaseHold(tx, h); err != nil {
+				return err
+			}
+			if _, err := resolveHold(tx, h.ID, hold.StatusExpired, nil, nil, now); err != nil {
+				return err
+			}
+			released++
+			return nil
+		})
+		if err != nil {
+			return released, err
+		}
+	}
+	return released, nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"database/sql"
+	"slices"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/event"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/lib/pq"
+)
+
+// ImportWallets inserts all wallets in one transaction. Rows are streamed
+// with COPY into a staging table and moved into user_wallet from there, which
+// keeps COPY's speed while still getting the new ids back for the outbox. The
+// import is one audit record, and its events one statement, in the same
+// transaction.
+func (p *Postgres) ImportWallets(wallets []wallet.Wallet, actor audit.Actor) (int, error) {
+	inserted := 0
+	err := p.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TEMP TABLE wallet_import (
+			ord INT NOT NULL,
+			user_id INT NOT NULL,
+			user_name VARCHAR(255) NOT NULL,
+			wallet_name VARCHAR(255) NOT NULL,
+			wallet_type VARCHAR(64) NOT NULL,
+			balance DECIMAL(10, 2) NOT NULL
+		) ON COMMIT DROP`)
+		if err != nil {
+			return err
+		}
+
+		stmt, err := tx.Prepare(pq.CopyIn("wallet_import", "ord", "user_id", "user_name", "wallet_name", "wallet_type", "balance"))
+		if err != nil {
+			return err
+		}
+		for i, w := range wallets {
+			if _, err := stmt.Exec(i, w.UserID, w.UserName, w.WalletName, w.WalletType, w.Balance); err != nil {
+				stmt.Close()
+				return err
+			}
+		}
+		if _, err := stmt.Exec(); err != nil {
+			stmt.Close()
+			return err
+		}
+		if err := stmt.Close(); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(`INSERT INTO user_wallet (user_id, user_name, wallet_name, wallet_type, balance)
+			SELECT user_id, user_name, wallet_name, wallet_type, balance FROM wallet_import ORDER BY ord
+			RETURNING *`)
+		if err != nil {
+			return err
+		}
+		var created []wallet.Wallet
+		for rows.Next() {
+			w, err := scanWallet(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			created = append(created, *w)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		// Ids are drawn in the order of ord, so by id created[i] is wallets[i].
+		slices.SortFunc(created, func(a, b wallet.Wallet) int { return a.ID - b.ID })
+		for i := range created {
+			if c := wallets[i].Crypto; c != nil {
+				if err := saveCrypto(tx, created[i].ID, *c); err != nil {
+					return err
+				}
+				created[i].Crypto = c
+			}
+			if err := recordOpening(tx, &created[i]); err != nil {
+				return err
+			}
+		}
+		imported := audit.Import{Count: len(created), WalletIDs: make([]int, len(created))}
+		for i, w := range created {
+			imported.WalletIDs[i] = w.ID
+		}
+		if err := insertAudit(tx, actor, audit.ActionImport, 0, nil, imported); err != nil {
+			return err
+		}
+		if err := insertEvents(tx, event.WalletCreated, created); err != nil {
+			return err
+		}
+		inserted = len(created)
+		return nil
+	})
+	return inserted, err
+}
@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/interest"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+// effectiveRateQuery joins each wallet with its own rate, falling back to
+// the rate of its product. Credit cards never earn interest.
+const effectiveRateQuery = `SELECT w.id, w.balance, COALESCE(r.annual_rate, p.annual_rate), COALESCE(r.day_count, p.day_count)
+	FROM user_wallet w
+	LEFT JOIN wallet_interest_rate r ON r.wallet_id = w.id
+	LEFT JOIN interest_product p ON p.wallet_type = w.wallet_type
+	WHERE (r.wallet_id IS NOT NULL OR p.wallet_type IS NOT NULL) AND w.wallet_type <> $1`
+
+// interestTargetsQuery is effectiveRateQuery with the balance at the end of
+// a day instead of now. Like the opening balance of Statement, it is the sum
+// of the transactions until then, so it includes interest capitalized later
+// but dated back into the day.
+const interestTargetsQuery = `SELECT w.id, b.balance, COALESCE(r.annual_rate, p.annual_rate), COALESCE(r.day_count, p.day_count)
+	FROM user_wallet w
+	CROSS JOIN LATERAL (SELECT COALESCE(SUM(t.amount), 0) AS balance FROM wallet_transaction t WHERE t.wallet_id = w.id AND t.created_at < $3) b
+	LEFT JOIN wallet_interest_rate r ON r.wallet_id = w.id
+	LEFT JOIN interest_product p ON p.wallet_type = w.wallet_type
+	WHERE (r.wallet_id IS NOT NULL OR p.wallet_type IS NOT NULL) AND w.wallet_type <> $1
+		AND w.status = $2 AND w.created_at < $3 AND b.balance > 0
+	ORDER BY w.id`
+
+func (p *Postgres) InterestTargets(day time.Time) ([]interest.Target, error) {
+	end := day.AddDate(0, 0, 1)
+	rows, err := p.Db.Query(interestTargetsQuery, wallet.TypeCreditCard, wallet.StatusActive, end)
+	if err != nil {
+		return nil, errors.New("failed to get interest targets")
+	}
+	defer rows.Close()
+
+	var targets []interest.Target
+	for rows.Next() {
+		var t interest.Target
+		if err := rows.Scan(&t.WalletID, &t.Balance, &t.AnnualRate, &t.DayCount); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+func (p *Postgres) LastInterestDay() (time.Time, error) {
+	var day sql.NullTime
+	if err := p.Db.QueryRow("SELECT MAX(day) FROM interest_run").Scan(&day); err != nil {
+		return time.Time{}, err
+	}
+	return day.Time, nil
+}
+
+func (p *Postgres) SaveInterestDay(day time.Time) error {
+	_, err := p.Db.Exec("INSERT INTO interest_run (day) VALUES ($1) ON CONFLICT (day) DO UPDATE SET ran_at = CURRENT_TIMESTAMP", day)
+	return err
+}
+
+func (p *Postgres) SaveAccruals(accruals []interest.Accrual) error {
+	return p.withTx(func(tx *sql.Tx) error {
+		stmt := `INSERT INTO interest_accrual (wallet_id, accrual_date, balance, annual_rate, day_count, amount) VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (wallet_id, accrual_date) DO NOTHING`
+		for _, a := range accruals {
+			if _, err := tx.Exec(stmt, a.WalletID, a.Date, a.Balance, a.AnnualRate, a.DayCount, a.Amount); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CapitalizeInterest posts the accrued interest of each wallet for [from, to)
+// as one transaction dated at the end of the period. Accruals are marked
+// posted in the same transaction, so running it twice posts nothing more.
+// Sub-cent remainders are dropped. Wallets that are not active are skipped
+// and keep their accruals unposted until the period is run again.
+func (p *Postgres) CapitalizeInterest(from, to time.Time) error {
+	rows, err := p.Db.Query("SELECT DISTINCT wallet_id FROM interest_accrual WHERE posted_at IS NULL AND accrual_date >= $1 AND accrual_date < $2 ORDER BY wallet_id", from, to)
+	if err != nil {
+		return errors.New("failed to get interest accruals")
+	}
+	var walletIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		walletIDs = append(walletIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range walletIDs {
+		err := p.withTx(func(tx *sql.Tx) error {
+			var total float64
+			var n int
+			row := tx.QueryRow(`WITH posted AS (
+					UPDATE interest_accrual SET posted_at = CURRENT_TIMESTAMP
+					WHERE wallet_id = $1 AND posted_at IS NULL AND accrual_date >= $2 AND accrual_date < $3
+					RETURNING amount
+				) SELECT COALESCE(SUM(amount), 0), COUNT(*) FROM posted`, id, from, to)
+			if err := row.Scan(&total, &n); err != nil {
+				return err
+			}
+			amount := math.Round(total*100) / 100
+			if n == 0 || amount == 0 {
+				return nil
+			}
+
+			t, err := postTransactionAt(tx, id, transaction.KindInterest, amount, "Interest "+from.Format("2006-01"), to.Add(-time.Microsecond))
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec("UPDATE interest_accrual SET transaction_id = $1 WHERE wallet_id = $2 AND accrual_date >= $3 AND accrual_date < $4", t.ID, id, from, to)
+			return err
+		})
+		var se *wallet.StatusError
+		if errors.As(err, &se) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Postgres) InterestHistory(walletID int) (*interest.History, error) {
+	h := &interest.History{WalletID: walletID, Accruals: []interest.Accrual{}, Postings: []interest.Posting{}}
+
+	var r interest.Rate
+	row := p.Db.QueryRow(effectiveRateQuery+" AND w.id = $2", wallet.TypeCreditCard, walletID)
+	var balance float64
+	err := row.Scan(&r.WalletID, &balance, &r.AnnualRate, &r.DayCount)
+	switch {
+	case err == nil:
+		h.Rate = &r
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	rows, err := p.Db.Query("SELECT wallet_id, accrual_date, balance, annual_rate, day_count, amount, posted_at FROM interest_accrual WHERE wallet_id = $1 ORDER BY accrual_date DESC", walletID)
+	if err != nil {
+		return nil, errors.New("failed to get interest accruals")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a interest.Accrual
+		if err := rows.Scan(&a.WalletID, &a.Date, &a.Balance, &a.AnnualRate, &a.DayCount, &a.Amount, &a.PostedAt); err != nil {
+			return nil, err
+		}
+		if a.PostedAt == nil {
+			h.Accrued += a.Amount
+		}
+		h.Accruals = append(h.Accruals, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	h.Accrued = math.Round(h.Accrued*100) / 100
+
+	postings, err := p.Db.Query(`SELECT t.id, to_char(MIN(a.accrual_date), 'YYYY-MM'), t.amount, t.created_at
+		FROM interest_accrual a JOIN wallet_transaction t ON t.id = a.transaction_id
+		WHERE a.wallet_id = $1
+		GROUP BY t.id, t.amount, t.created_at
+		ORDER BY t.id DESC`, walletID)
+	if err != nil {
+		return nil, errors.New("failed to get interest postings")
+	}
+	defer postings.Close()
+	for postings.Next() {
+		var ps interest.Posting
+		if err := postings.Scan(&ps.TransactionID, &ps.Month, &ps.Amount, &ps.PostedAt); err != nil {
+			return nil, err
+		}
+		h.Postings = append(h.Postings, ps)
+	}
+	return h, postings.Err()
+}
+
+func (p *Postgres) SaveWalletRate(r interest.Rate) (*interest.Rate, error) {
+	var exists bool
+	if err := p.Db.QueryRow("SELECT EXISTS (SELECT 1 FROM user_wallet WHERE id = $1)", r.WalletID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, interest.ErrNotFound
+	}
+
+	stmt := `INSERT INTO wallet_interest_rate (wallet_id, annual_rate, day_count) VALUES ($1, $2, $3)
+		ON CONFLICT (wallet_id) DO UPDATE SET annual_rate = $2, day_count = $3
+		RETURNING wallet_id, annual_rate, day_count`
+	var saved interest.Rate
+	err := p.Db.QueryRow(stmt, r.WalletID, r.AnnualRate, r.DayCount).Scan(&saved.WalletID, &saved.AnnualRate, &saved.DayCount)
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (p *Postgres) ProductRates() ([]interest.Rate, error) {
+	rows, err := p.Db.Query("SELECT wallet_type, annual_rate, day_count FROM interest_product ORDER BY wallet_type")
+	if err != nil {
+		return nil, errors.New("failed to get interest products")
+	}
+	defer rows.Close()
+
+	rates := []interest.Rate{}
+	for rows.Next() {
+		var r interest.Rate
+		if err := rows.Scan(&r.WalletType, &r.AnnualRate, &r.DayCount); err != nil {
+			return nil, err
+		}
+		rates = append(rates, r)
+	}
+	return rates, rows.Err()
+}
+
+func (p *Postgres) SaveProductRate(r interest.Rate) (*interest.Rate, error) {
+	stmt := `INSERT INTO interest_product (wallet_type, annual_rate, day_count) VALUES ($1, $2, $3)
+		ON CONFLICT (wallet_type) DO UPDATE SET annual_rate = $2, day_count = $3
+		RETURNING wallet_type, annual_rate, day_count`
+	var saved interest.Rate
+	err := p.Db.QueryRow(stmt, r.WalletType, r.AnnualRate, r.DayCount).Scan(&saved.WalletType, &saved.AnnualRate, &saved.DayCount)
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
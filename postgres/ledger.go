@@ -0,0 +1,233 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/event"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/ledger"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+// posting is a change of the balance of a wallet.
+type posting struct {
+	walletID    int
+	kind        string
+	amount      float64
+	description string
+	// at dates the transaction, now when zero.
+	at time.Time
+	// contraKind is the kind whose system account takes the other side of
+	// the entry, kind unless set. A reversal books against the account of
+	// what it reverses.
+	contraKind string
+}
+
+// post is the only way the balance of an existing wallet changes. It updates
+// the balance, records the transaction and books a balanced entry between
+// the account of the wallet and a system account, so the balance is always
+// the sum of the postings to that account. See postTransaction for the
+// rules it enforces.
+func post(tx *sql.Tx, p posting) (*transaction.Transaction, error) {
+	amount := math.Round(p.amount*100) / 100
+
+	var before, after, held float64
+	var status string
+	row := tx.QueryRow("UPDATE user_wallet SET balance = balance + $1 WHERE id = $2 RETURNING balance - $1, balance, held, status", amount, p.walletID)
+	err := row.Scan(&before, &after, &held, &status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, wallet.ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != wallet.StatusActive {
+		return nil, &wallet.StatusError{WalletID: p.walletID, Status: status}
+	}
+	wt, err := walletTypeFor(tx, p.walletID)
+	if err != nil {
+		return nil, err
+	}
+	checked := after
+	if amount < 0 {
+		checked = available(after, held)
+	}
+	if err := wt.CheckBalance(checked); err != nil {
+		return nil, err
+	}
+	if outflow(wt, p.kind, amount) {
+		if err := checkLimits(tx, p.walletID, wt.Key, p.kind, amount); err != nil {
+			return nil, err
+		}
+	}
+
+	t, err := insertTransaction(tx, p.walletID, p.kind, amount, after, p.description, p.at)
+	if err != nil {
+		return nil, err
+	}
+	contraKind := p.contraKind
+	if contraKind == "" {
+		contraKind = p.kind
+	}
+	if err := bookWallet(tx, &t.ID, p.walletID, contraKind, amount, p.description); err != nil {
+		return nil, err
+	}
+	err = insertEvent(tx, event.BalanceChanged, p.walletID, event.Balance{WalletID: p.walletID, From: before, To: after})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// recordOpening opens the ledger account of a new wallet and books the
+// balance it was created with, which the INSERT has already set.
+func recordOpening(tx *sql.Tx, w *wallet.Wallet) error {
+	account := ledger.WalletAccount(w.ID, w.WalletName, w.WalletType == wallet.TypeCreditCard)
+	stmt := "INSERT INTO ledger_account (code, name, type, control, wallet_id) VALUES ($1, $2, $3, $4, $5)"
+	if _, err := tx.Exec(stmt, account.Code, account.Name, account.Type, account.Control(), w.ID); err != nil {
+		return err
+	}
+	t, err := insertTransaction(tx, w.ID, transaction.KindOpening, w.Balance, w.Balance, "Opening balance", time.Time{})
+	if err != nil {
+		return err
+	}
+	return bookWallet(tx, &t.ID, w.ID, transaction.KindOpening, w.Balance, "Opening balance")
+}
+
+// closeAccount pays out what is left on the account of a wallet being
+// deleted, so the account ends at zero.
+func closeAccount(tx *sql.Tx, w *wallet.Wallet) error {
+	return bookWallet(tx, nil, w.ID, transaction.KindAdjustment, -w.Balance, "Wallet deleted")
+}
+
+// bookWallet books an entry changing the account of a wallet by amount
+// against the system account for contraKind. Nothing is booked for a zero
+// amount.
+func bookWallet(tx *sql.Tx, transactionID *int64, walletID int, contraKind string, amount float64, description string) error {
+	if math.Round(amount*100) == 0 {
+		return nil
+	}
+	var account ledger.Account
+	err := tx.QueryRow("SELECT code, name, type FROM ledger_account WHERE wallet_id = $1", walletID).Scan(&account.Code, &account.Name, &account.Type)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("wallet %d has no ledger account", walletID)
+	}
+	if err != nil {
+		return err
+	}
+	contra := ledger.Contra(contraKind, account.Type == ledger.TypeAsset)
+	return insertEntry(tx, transactionID, ledger.Move(account, contra, amount, description))
+}
+
+// insertEntry books e, refusing it unless it balances.
+func insertEntry(tx *sql.Tx, transactionID *int64, e ledger.Entry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	var entryID int64
+	err := tx.QueryRow("INSERT INTO ledger_entry (transaction_id, description) VALUES ($1, $2) RETURNING id", transactionID, e.Description).Scan(&entryID)
+	if err != nil {
+		return err
+	}
+	for _, l := range e.Lines {
+		res, err := tx.Exec("INSERT INTO ledger_line (entry_id, account_id, amount) SELECT $1::bigint, id, $2::numeric FROM ledger_account WHERE code = $3", entryID, l.Amount, l.Account)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n != 1 {
+			return fmt.Errorf("unknown ledger account %q", l.Account)
+		}
+	}
+	return nil
+}
+
+func (p *Postgres) TrialBalance() (*ledger.TrialBalance, error) {
+	query := `SELECT COALESCE(a.control, a.code), MIN(a.name), MIN(a.type), COALESCE(SUM(l.amount), 0)
+		FROM ledger_account a
+		LEFT JOIN ledger_line l ON l.account_id = a.id
+		GROUP BY COALESCE(a.control, a.code)
+		ORDER BY 1`
+	rows, err := p.Db.Query(query)
+	if err != nil {
+		return nil, errors.New("failed to get trial balance")
+	}
+	defer rows.Close()
+
+	var accounts []ledger.Account
+	var nets []float64
+	for rows.Next() {
+		var a ledger.Account
+		var net float64
+		if err := rows.Scan(&a.Code, &a.Name, &a.Type, &net); err != nil {
+			return nil, err
+		}
+		if control, ok := ledger.Controls[a.Code]; ok {
+			a = control
+		}
+		accounts = append(accounts, a)
+		nets = append(nets, net)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	tb := ledger.NewTrialBalance(time.Now().UTC(), accounts, nets)
+	return &tb, nil
+}
+
+// CheckInvariant reads in one repeatable read snapshot, so postings made
+// meanwhile cannot show up as divergences.
+func (p *Postgres) CheckInvariant() (*ledger.Report, error) {
+	tx, err := p.Db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY"); err != nil {
+		return nil, err
+	}
+
+	r := &ledger.Report{CheckedAt: time.Now().UTC(), Divergences: []ledger.Divergence{}, UnbalancedEntries: []int64{}}
+	query := `SELECT w.id, w.balance, COALESCE(SUM(CASE WHEN a.type = $1 THEN l.amount ELSE -l.amount END), 0) AS posted
+		FROM user_wallet w
+		LEFT JOIN ledger_account a ON a.wallet_id = w.id
+		LEFT JOIN ledger_line l ON l.account_id = a.id
+		GROUP BY w.id
+		HAVING w.balance <> COALESCE(SUM(CASE WHEN a.type = $1 THEN l.amount ELSE -l.amount END), 0)
+		ORDER BY w.id`
+	rows, err := tx.Query(query, ledger.TypeAsset)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var d ledger.Divergence
+		if err := rows.Scan(&d.WalletID, &d.Balance, &d.Ledger); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		r.Divergences = append(r.Divergences, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query("SELECT entry_id FROM ledger_line GROUP BY entry_id HAVING SUM(amount) <> 0 ORDER BY entry_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		r.UnbalancedEntries = append(r.UnbalancedEntries, id)
+	}
+	return r, rows.Err()
+}
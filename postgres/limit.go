@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/hold"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/lib/pq"
+)
+
+// queryer is what the limit queries need from a *sql.DB or a *sql.Tx.
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+const limitColumns = "max_withdrawal, daily_outflow, monthly_outflow, max_transfers_per_hour"
+
+func scanLimits(row scanner) (limit.Limits, error) {
+	var l limit.Limits
+	var maxWithdrawal, daily, monthly sql.NullFloat64
+	var transfers sql.NullInt32
+	if err := row.Scan(&maxWithdrawal, &daily, &monthly, &transfers); err != nil {
+		return l, err
+	}
+	if maxWithdrawal.Valid {
+		l.MaxWithdrawal = &maxWithdrawal.Float64
+	}
+	if daily.Valid {
+		l.DailyOutflow = &daily.Float64
+	}
+	if monthly.Valid {
+		l.MonthlyOutflow = &monthly.Float64
+	}
+	if transfers.Valid {
+		n := int(transfers.Int32)
+		l.MaxTransfersPerHour = &n
+	}
+	return l, nil
+}
+
+// limitsOf returns the limits set on a wallet and on its type, missing
+// rows being no limits.
+func limitsOf(q queryer, walletID int, typeKey string) (own, ofType limit.Limits, err error) {
+	own, err = scanLimits(q.QueryRow("SELECT "+limitColumns+" FROM wallet_limit WHERE wallet_id = $1", walletID))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return own, ofType, err
+	}
+	ofType, err = scanLimits(q.QueryRow("SELECT "+limitColumns+" FROM wallet_type_limit WHERE wallet_type = $1", typeKey))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return own, ofType, err
+	}
+	return own, ofType, nil
+}
+
+// usageOf sums the outflows of a wallet in the current day and month, funds
+// held by active holds and reversals taking money out included, and counts
+// its transfers in the last hour. It goes by the clock of the database
+// through now(), whose offset the times it returns keep, so the days and
+// months it resets on are those the sums were taken over.
+func usageOf(q queryer, walletID int) (limit.Usage, error) {
+	var u limit.Usage
+	var oldest sql.NullTime
+	row := q.QueryRow(`SELECT
+			COALESCE(SUM(ABS(amount)) FILTER (WHERE created_at >= date_trunc('day', now())), 0),
+			COALESCE(SUM(ABS(amount)) FILTER (WHERE created_at >= date_trunc('month', now())), 0),
+			COUNT(*) FILTER (WHERE kind = $3 AND created_at > now() - INTERVAL '1 hour'),
+			(MIN(created_at) FILTER (WHERE kind = $3 AND created_at > now() - INTERVAL '1 hour'))::timestamptz,
+			now()
+		FROM wallet_transaction
+		WHERE wallet_id = $1
+			AND (kind = ANY($2) OR kind = $4 AND amount < 0 AND NOT EXISTS (SELECT 1 FROM user_wallet WHERE id = $1 AND wallet_type = $5))
+			AND created_at >= LEAST(date_trunc('month', now()), now() - INTERVAL '1 hour')`,
+		walletID, pq.Array(transaction.OutflowKinds), transaction.KindTransferOut, transaction.KindReversal, wallet.TypeCreditCard)
+	if err := row.Scan(&u.Daily, &u.Monthly, &u.TransfersHour, &oldest, &u.Now); err != nil {
+		return u, err
+	}
+	if oldest.Valid {
+		u.OldestTransfer = &oldest.Time
+	}
+
+	var heldDaily, heldMonthly float64
+	row = q.QueryRow(`SELECT
+			COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('day', now())), 0),
+			COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('month', now())), 0)
+		FROM wallet_hold WHERE wallet_id = $1 AND status = $2`, walletID, hold.StatusActive)
+	if err := row.Scan(&heldDaily, &heldMonthly); err != nil {
+		return u, err
+	}
+	u.Daily += heldDaily
+	u.Monthly += heldMonthly
+	return u, nil
+}
+
+// checkLimits refuses an outflow breaking the effective limits of a wallet.
+// It runs after the wallet row is locked, so concurrent outflows of the same
+// wallet are checked one after the other.
+func checkLimits(tx *sql.Tx, walletID int, typeKey, kind string, amount float64) error {
+	own, ofType, err := limitsOf(tx, walletID, typeKey)
+	if err != nil {
+		return err
+	}
+	effective := limit.Effective(own, ofType)
+	if effective.Empty() {
+		return nil
+	}
+	usage, err := usageOf(tx, walletID)
+	if err != nil {
+		return err
+	}
+	if amount < 0 {
+		amount = -amount
+	}
+	return limit.Check(effective, usage, limit.Outflow{Amount: amount, Transfer: kind == transaction.KindTransferOut})
+}
+
+func (p *Postgres) walletTypeKey(walletID int) (string, error) {
+	var key string
+	err := p.Db.QueryRow("SELECT t.key FROM user_wallet w JOIN wallet_types t ON t.name = w.wallet_type WHERE w.id = $1", walletID).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", limit.ErrNotFound
+	}
+	return key, err
+}
+
+func (p *Postgres) WalletLimits(walletID int) (*limit.WalletLimits, error) {
+	key, err := p.walletTypeKey(walletID)
+	if err != nil {
+		return nil, err
+	}
+	own, ofType, err := limitsOf(p.Db, walletID, key)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := usageOf(p.Db, walletID)
+	if err != nil {
+		return nil, err
+	}
+	return &limit.WalletLimits{
+		WalletID:  walletID,
+		Wallet:    own,
+		Type:      ofType,
+		Effective: limit.Effective(own, ofType),
+		Usage:     usage,
+	}, nil
+}
+
+func (p *Postgres) SaveWalletLimits(walletID int, l limit.Limits) (*limit.WalletLimits, error) {
+	if _, err := p.walletTypeKey(walletID); err != nil {
+		return nil, err
+	}
+	stmt := `INSERT INTO wallet_limit (wallet_id, ` + limitColumns + `, updated_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (wallet_id) DO UPDATE SET max_withdrawal = $2, daily_outflow = $3, monthly_outflow = $4, max_transfers_per_hour = $5, updated_at = $6`
+	if _, err := p.Db.Exec(stmt, walletID, l.MaxWithdrawal, l.DailyOutflow, l.MonthlyOutflow, l.MaxTransfersPerHour, time.Now()); err != nil {
+		return nil, err
+	}
+	return p.WalletLimits(walletID)
+}
+
+func (p *Postgres) TypeLimits(key string) (*limit.Limits, error) {
+	var exists bool
+	if err := p.Db.QueryRow("SELECT EXISTS (SELECT 1 FROM wallet_types WHERE key = $1)", key).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, limit.ErrTypeNotFound
+	}
+	l, err := scanLimits(p.Db.QueryRow("SELECT "+limitColumns+" FROM wallet_type_limit WHERE wallet_type = $1", key))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (p *Postgres) SaveTypeLimits(key string, l limit.Limits) (*limit.Limits, error) {
+	if _, err := p.TypeLimits(key); err != nil {
+		return nil, err
+	}
+	stmt := `INSERT INTO wallet_type_limit (wallet_type, ` + limitColumns + `, updated_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (wallet_type) DO UPDATE SET max_withdrawal = $2, daily_outflow = $3, monthly_outflow = $4, max_transfers_per_hour = $5, updated_at = $6`
+	if _, err := p.Db.Exec(stmt, key, l.MaxWithdrawal, l.DailyOutflow, l.MonthlyOutflow, l.MaxTransfersPerHour, time.Now()); err != nil {
+		return nil, err
+	}
+	return p.TypeLimits(key)
+}
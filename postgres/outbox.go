@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/event"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/lib/pq"
+)
+
+// outboxLockKey is the advisory lock held by the replica relaying the
+// outbox.
+const outboxLockKey int64 = 0x6f7574626f78
+
+// insertEvent writes a domain event to the outbox in the caller's
+// transaction, so it is published if and only if the mutation commits.
+func insertEvent(tx *sql.Tx, eventType string, walletID int, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	stmt := "INSERT INTO outbox (event_type, wallet_id, payload) VALUES ($1, $2, $3)"
+	_, err = tx.Exec(stmt, eventType, walletID, body)
+	return err
+}
+
+// insertEvents adds an event of eventType for each wallet, in one statement.
+func insertEvents(tx *sql.Tx, eventType string, wallets []wallet.Wallet) error {
+	ids := make([]int, len(wallets))
+	payloads := make([]string, len(wallets))
+	for i, w := range wallets {
+		body, err := json.Marshal(w)
+		if err != nil {
+			return err
+		}
+		ids[i], payloads[i] = w.ID, string(body)
+	}
+	stmt := "INSERT INTO outbox (event_type, wallet_id, payload) SELECT $1, * FROM unnest($2::int[], $3::jsonb[])"
+	_, err := tx.Exec(stmt, eventType, pq.Array(ids), pq.Array(payloads))
+	return err
+}
+
+func (p *Postgres) PendingEvents(limit int) ([]event.Event, error) {
+	rows, err := p.Db.Query("SELECT id, event_type, wallet_id, payload, created_at FROM outbox WHERE published_at IS NULL AND dead_at IS NULL ORDER BY id LIMIT $1", limit)
+	if err != nil {
+		return nil, errors.New("failed to get pending events")
+	}
+	defer rows.Close()
+
+	var events []event.Event
+	for rows.Next() {
+		var e event.Event
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Type, &e.WalletID, &payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (p *Postgres) MarkPublished(id int64) error {
+	_, err := p.Db.Exec("UPDATE outbox SET published_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+	return err
+}
+
+func (p *Postgres) MarkFailed(id int64, reason string, maxAttempts int) (bool, error) {
+	stmt := `UPDATE outbox SET attempts = attempts + 1, last_error = $2,
+		dead_at = CASE WHEN attempts + 1 >= $3 THEN CURRENT_TIMESTAMP END
+		WHERE id = $1 RETURNING dead_at IS NOT NULL`
+	var dead bool
+	err := p.Db.QueryRow(stmt, id, reason, maxAttempts).Scan(&dead)
+	return dead, err
+}
+
+// OutboxLock is the lock electing the replica relaying the outbox.
+func (p *Postgres) OutboxLock() *AdvisoryLock {
+	return &AdvisoryLock{db: p.Db, key: outboxLockKey}
+}
@@ -10,7 +10,8 @@ import (
 )
 
 type Postgres struct {
-	Db *sql.DB
+	Db  *sql.DB
+	dsn string
 }
 
 func New() (*Postgres, error) {
@@ -36,5 +37,24 @@ func New() (*Postgres, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	return &Postgres{Db: db}, nil
+	return &Postgres{Db: db, dsn: databaseSource}, nil
+}
+
+// withTx runs fn inside a transaction, committing when fn returns nil and
+// rolling back otherwise.
+func (p *Postgres) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := p.Db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
 }
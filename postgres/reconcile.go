@@ -0,0 +1,243 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/ledger"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/reconcile"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/lib/pq"
+)
+
+// reconcileLockKey serializes imports, so two files cannot both match the
+// same posting.
+const reconcileLockKey int64 = 0x7265636f6e
+
+const settlementItemColumns = "id, file_id, line_no, reference, amount, booked_on, description, status, entry_id, reason, note, resolved_by, resolved_at"
+
+type rowsQueryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// ImportFile matches the lines of f against the open settlement postings
+// and stores the file with its items.
+func (p *Postgres) ImportFile(f reconcile.File, lines []reconcile.Line) (*reconcile.Report, error) {
+	err := p.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", reconcileLockKey); err != nil {
+			return err
+		}
+		row := tx.QueryRow(`INSERT INTO settlement_file (name, format, checksum, period_from, period_to) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (checksum) DO NOTHING
+			RETURNING id`, f.Name, f.Format, f.Checksum, f.From, f.To)
+		err := row.Scan(&f.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return reconcile.ErrDuplicateFile
+		}
+		if err != nil {
+			return err
+		}
+
+		var referenced []int64
+		for _, l := range lines {
+			if id, ok := reconcile.ParseReference(l.Reference); ok {
+				referenced = append(referenced, id)
+			}
+		}
+		candidates, err := openEntries(tx, f.From, f.To, referenced)
+		if err != nil {
+			return err
+		}
+
+		stmt := `INSERT INTO settlement_item (file_id, line_no, reference, amount, booked_on, description, status, entry_id, reason)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+		for _, r := range reconcile.Match(lines, candidates) {
+			_, err := tx.Exec(stmt, f.ID, r.LineNo, r.Reference, r.Amount, r.BookedOn, r.Description, r.Status, r.EntryID, r.Reason)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.Report(f.ID)
+}
+
+// openEntries returns the settlement postings no item matches, made from a
+// Tolerance before from to a Tolerance after the day to, or made by one of
+// the transactions in referenced whenever that was. Openings and
+// adjustments never go through the bank.
+func openEntries(q rowsQueryer, from, to time.Time, referenced []int64) ([]reconcile.Candidate, error) {
+	query := `SELECT e.id, t.id, t.wallet_id, l.amount, t.created_at, e.description
+		FROM ledger_entry e
+		JOIN ledger_line l ON l.entry_id = e.id
+		JOIN ledger_account a ON a.id = l.account_id AND a.code = $1
+		JOIN wallet_transaction t ON t.id = e.transaction_id
+		WHERE t.kind NOT IN ($2, $3)
+		AND NOT EXISTS (SELECT 1 FROM settlement_item i WHERE i.entry_id = e.id)
+		AND ((t.created_at >= $4 AND t.created_at < $5) OR t.id = ANY($6))
+		ORDER BY e.id`
+	start := from.Add(-reconcile.Tolerance)
+	end := to.Add(24*time.Hour + reconcile.Tolerance)
+	rows, err := q.Query(query, ledger.AccountSettlement, transaction.KindOpening, transaction.KindAdjustment, start, end, pq.Array(referenced))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []reconcile.Candidate{}
+	for rows.Next() {
+		var c reconcile.Candidate
+		if err := rows.Scan(&c.EntryID, &c.TransactionID, &c.WalletID, &c.Amount, &c.PostedAt, &c.Description); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+const settlementFileQuery = `SELECT f.id, f.name, f.format, f.checksum, f.period_from, f.period_to, f.imported_at,
+	COUNT(i.id) FILTER (WHERE i.status = 'matched'),
+	COUNT(i.id) FILTER (WHERE i.status = 'unmatched'),
+	COUNT(i.id) FILTER (WHERE i.status = 'mismatched'),
+	COUNT(i.id) FILTER (WHERE i.status = 'resolved')
+	FROM settlement_file f
+	LEFT JOIN settlement_item i ON i.file_id = f.id`
+
+func scanSettlementFile(row scanner) (*reconcile.File, error) {
+	var f reconcile.File
+	err := row.Scan(&f.ID, &f.Name, &f.Format, &f.Checksum, &f.From, &f.To, &f.ImportedAt, &f.Matched, &f.Unmatched, &f.Mismatched, &f.Resolved)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (p *Postgres) Files() ([]reconcile.File, error) {
+	rows, err := p.Db.Query(settlementFileQuery + " GROUP BY f.id ORDER BY f.id DESC")
+	if err != nil {
+		return nil, errors.New("failed to get settlement files")
+	}
+	defer rows.Close()
+
+	files := []reconcile.File{}
+	for rows.Next() {
+		f, err := scanSettlementFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *f)
+	}
+	return files, rows.Err()
+}
+
+// Report reads a file with its items and the postings of its period still
+// unmatched, which may since have been matched by a later file.
+func (p *Postgres) Report(id int64) (*reconcile.Report, error) {
+	f, err := scanSettlementFile(p.Db.QueryRow(settlementFileQuery+" WHERE f.id = $1 GROUP BY f.id", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, reconcile.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := &reconcile.Report{File: *f, Items: []reconcile.Item{}}
+
+	rows, err := p.Db.Query("SELECT "+settlementItemColumns+" FROM settlement_item WHERE file_id = $1 ORDER BY line_no, id", id)
+	if err != nil {
+		return nil, errors.New("failed to get settlement items")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		it, err := scanSettlementItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		r.Items = append(r.Items, *it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if r.UnmatchedEntries, err = openEntries(p.Db, f.From, f.To, nil); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func scanSettlementItem(row scanner) (*reconcile.Item, error) {
+	var it reconcile.Item
+	var entryID sql.NullInt64
+	var resolvedBy sql.NullString
+	var resolvedAt sql.NullTime
+	err := row.Scan(&it.ID, &it.FileID, &it.LineNo, &it.Reference, &it.Amount, &it.BookedOn, &it.Description,
+		&it.Status, &entryID, &it.Reason, &it.Note, &resolvedBy, &resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	if entryID.Valid {
+		it.EntryID = &entryID.Int64
+	}
+	if resolvedBy.Valid {
+		it.ResolvedBy = &resolvedBy.String
+	}
+	if resolvedAt.Valid {
+		it.ResolvedAt = &resolvedAt.Time
+	}
+	return &it, nil
+}
+
+// Resolve settles an unmatched or mismatched item by hand. Without an entry
+// a mismatched item keeps the posting its reference named, accepting the
+// difference.
+func (p *Postgres) Resolve(fileID, itemID int64, r reconcile.Resolution, actor audit.Actor) (*reconcile.Item, error) {
+	var resolved *reconcile.Item
+	err := p.withTx(func(tx *sql.Tx) error {
+		row := tx.QueryRow("SELECT "+settlementItemColumns+" FROM settlement_item WHERE id = $1 AND file_id = $2 FOR UPDATE", itemID, fileID)
+		it, err := scanSettlementItem(row)
+		if errors.Is(err, sql.ErrNoRows) {
+			return reconcile.ErrItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if it.Status != reconcile.StatusUnmatched && it.Status != reconcile.StatusMismatched {
+			return reconcile.ErrSettled
+		}
+
+		entryID := it.EntryID
+		if r.EntryID != nil {
+			var open bool
+			query := `SELECT EXISTS (
+				SELECT 1 FROM ledger_line l
+				JOIN ledger_account a ON a.id = l.account_id AND a.code = $1
+				WHERE l.entry_id = $2
+				AND NOT EXISTS (SELECT 1 FROM settlement_item i WHERE i.entry_id = $2 AND i.id <> $3))`
+			if err := tx.QueryRow(query, ledger.AccountSettlement, *r.EntryID, itemID).Scan(&open); err != nil {
+				return err
+			}
+			if !open {
+				return reconcile.ErrEntryTaken
+			}
+			entryID = r.EntryID
+		}
+
+		row = tx.QueryRow(`UPDATE settlement_item SET status = $1, entry_id = $2, note = $3, resolved_by = $4, resolved_at = $5
+			WHERE id = $6
+			RETURNING `+settlementItemColumns, reconcile.StatusResolved, entryID, r.Note, actor.ID, time.Now().UTC(), itemID)
+		resolved, err = scanSettlementItem(row)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return reconcile.ErrEntryTaken
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
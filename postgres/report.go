@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/report"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/user"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+// SummaryByUserID aggregates a user's wallets per type, with the grand total
+// as the row where wallet_type is NULL. Credit card balances are money owed,
+// so they count against net worth.
+func (p *Postgres) SummaryByUserID(userID int) (*user.Summary, error) {
+	query := `SELECT wallet_type, COUNT(*), COALESCE(SUM(balance), 0),
+			COALESCE(SUM(CASE WHEN wallet_type = $2 THEN -balance ELSE balance END), 0)
+		FROM user_wallet WHERE user_id = $1
+		GROUP BY GROUPING SETS ((wallet_type), ())
+		ORDER BY wallet_type NULLS FIRST`
+	rows, err := p.Db.Query(query, userID, wallet.TypeCreditCard)
+	if err != nil {
+		return nil, errors.New("failed to get summary")
+	}
+	defer rows.Close()
+
+	summary := &user.Summary{UserID: userID, ByType: []user.TypeBalance{}}
+	for rows.Next() {
+		var walletType sql.NullString
+		var count int
+		var balance, netWorth float64
+		if err := rows.Scan(&walletType, &count, &balance, &netWorth); err != nil {
+			return nil, err
+		}
+		if !walletType.Valid {
+			summary.WalletCount = count
+			summary.TotalBalance = balance
+			summary.NetWorth = netWorth
+			continue
+		}
+		summary.ByType = append(summary.ByType, user.TypeBalance{
+			WalletType:  walletType.String,
+			WalletCount: count,
+			Balance:     balance,
+		})
+	}
+	return summary, rows.Err()
+}
+
+func (p *Postgres) BalanceReport() ([]report.BalanceRow, error) {
+	query := `SELECT wallet_type, to_char(date_trunc('month', created_at), 'YYYY-MM') AS month, COUNT(*), SUM(balance)
+		FROM user_wallet
+		GROUP BY wallet_type, month
+		ORDER BY month, wallet_type`
+	rows, err := p.Db.Query(query)
+	if err != nil {
+		return nil, errors.New("failed to get balance report")
+	}
+	defer rows.Close()
+
+	result := []report.BalanceRow{}
+	for rows.Next() {
+		var r report.BalanceRow
+		if err := rows.Scan(&r.WalletType, &r.Month, &r.WalletCount, &r.TotalBalance); err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
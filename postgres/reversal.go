@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/lib/pq"
+)
+
+// transferLegs returns the ids of both legs when t is a leg of a transfer,
+// the credit leg first so money is taken back before it is refunded, and
+// just t otherwise.
+func transferLegs(tx *sql.Tx, t *transaction.Transaction) ([]int64, error) {
+	var debit, credit int64
+	err := tx.QueryRow("SELECT debit_transaction_id, credit_transaction_id FROM transfer WHERE debit_transaction_id = $1 OR credit_transaction_id = $1", t.ID).Scan(&debit, &credit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return []int64{t.ID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []int64{credit, debit}, nil
+}
+
+func (p *Postgres) Reverse(id int64, amount *float64, reason string, actor audit.Actor) (*transaction.Reversal, error) {
+	var reversal *transaction.Reversal
+	err := p.withTx(func(tx *sql.Tx) error {
+		original, err := scanTransaction(tx.QueryRow("SELECT "+transactionColumns+" FROM wallet_transaction WHERE id = $1", id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !transaction.Reversible(original.Kind) {
+			return transaction.ErrNotReversible
+		}
+		legIDs, err := transferLegs(tx, original)
+		if err != nil {
+			return err
+		}
+
+		// Wallets first, then the transactions, so what is left to reverse
+		// is read under lock.
+		legs, err := transactionsByID(tx, legIDs, false)
+		if err != nil {
+			return err
+		}
+		walletIDs := make([]int, 0, len(legs))
+		for _, l := range legs {
+			walletIDs = append(walletIDs, l.WalletID)
+		}
+		if _, err := lockWallets(tx, walletIDs...); err != nil {
+			return err
+		}
+		if legs, err = transactionsByID(tx, legIDs, true); err != nil {
+			return err
+		}
+
+		left := math.Round((math.Abs(legs[0].Amount)-legs[0].Reversed)*100) / 100
+		if left <= 0 {
+			return transaction.ErrAlreadyReversed
+		}
+		reverse := left
+		if amount != nil {
+			if *amount > left {
+				return transaction.ErrReversalExceeds
+			}
+			reverse = *amount
+		}
+
+		reversal = &transaction.Reversal{}
+		for _, l := range legs {
+			entry, err := post(tx, posting{
+				walletID:    l.WalletID,
+				kind:        transaction.KindReversal,
+				amount:      -math.Copysign(reverse, l.Amount),
+				description: fmt.Sprintf("Reversal of #%d: %s", l.ID, reason),
+				contraKind:  l.Kind,
+			})
+			if err != nil {
+				return err
+			}
+			entry, err = scanTransaction(tx.QueryRow("UPDATE wallet_transaction SET reversal_of = $1 WHERE id = $2 RETURNING "+transactionColumns, l.ID, entry.ID))
+			if err != nil {
+				return err
+			}
+			reversed, err := scanTransaction(tx.QueryRow("UPDATE wallet_transaction SET reversed = reversed + $1 WHERE id = $2 RETURNING "+transactionColumns, reverse, l.ID))
+			if err != nil {
+				return err
+			}
+			if err := insertAudit(tx, actor, audit.ActionReverse, l.WalletID, l, reversed); err != nil {
+				return err
+			}
+			reversal.Reversed = append(reversal.Reversed, *reversed)
+			reversal.Entries = append(reversal.Entries, *entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+// transactionsByID reads the transactions with ids, in the order of ids,
+// locking them for the rest of the transaction when lock is set.
+func transactionsByID(tx *sql.Tx, ids []int64, lock bool) ([]transaction.Transaction, error) {
+	query := "SELECT " + transactionColumns + " FROM wallet_transaction WHERE id = ANY($1) ORDER BY id"
+	if lock {
+		query += " FOR UPDATE"
+	}
+	rows, err := tx.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]transaction.Transaction, len(ids))
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		byID[t.ID] = *t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	legs := make([]transaction.Transaction, 0, len(ids))
+	for _, id := range ids {
+		t, ok := byID[id]
+		if !ok {
+			return nil, transaction.ErrNotFound
+		}
+		legs = append(legs, t)
+	}
+	return legs, nil
+}
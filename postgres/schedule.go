@@ -0,0 +1,285 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/schedule"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/lib/pq"
+)
+
+const scheduleColumns = "id, from_wallet_id, to_wallet_id, amount, description, recurrence, start_at, end_at, on_insufficient_funds, max_retries, retry_delay, status, occurrence_at, next_run_at, retries, created_at, updated_at"
+
+const executionColumns = "id, schedule_id, scheduled_for, attempt, status, transfer_id, error, executed_at"
+
+// schedulerLockKey is the advisory lock held by the replica running the
+// scheduled transfers.
+const schedulerLockKey int64 = 0x7363686564
+
+func scanSchedule(row scanner) (*schedule.Schedule, error) {
+	var s schedule.Schedule
+	err := row.Scan(&s.ID, &s.FromWalletID, &s.ToWalletID, &s.Amount, &s.Description, &s.Recurrence,
+		&s.StartAt, &s.EndAt, &s.OnInsufficientFunds, &s.MaxRetries, &s.RetryDelay, &s.Status,
+		&s.OccurrenceAt, &s.NextRunAt, &s.Retries, &s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, schedule.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func scanExecution(row scanner) (*schedule.Execution, error) {
+	var e schedule.Execution
+	var errMsg sql.NullString
+	err := row.Scan(&e.ID, &e.ScheduleID, &e.ScheduledFor, &e.Attempt, &e.Status, &e.TransferID, &errMsg, &e.ExecutedAt)
+	if err != nil {
+		return nil, err
+	}
+	e.Error = errMsg.String
+	return &e, nil
+}
+
+// scheduleError maps a missing wallet to wallet.ErrWalletNotFound.
+func scheduleError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return wallet.ErrWalletNotFound
+	}
+	return err
+}
+
+// checkScheduleSource refuses credit cards as the source of a standing
+// order up front, rather than on every run.
+func (p *Postgres) checkScheduleSource(walletID int) error {
+	var walletType string
+	err := p.Db.QueryRow("SELECT wallet_type FROM user_wallet WHERE id = $1", walletID).Scan(&walletType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return wallet.ErrWalletNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if walletType == wallet.TypeCreditCard {
+		return transaction.ErrCreditCard
+	}
+	return nil
+}
+
+func (p *Postgres) CreateSchedule(s schedule.Schedule) (*schedule.Schedule, error) {
+	if err := p.checkScheduleSource(s.FromWalletID); err != nil {
+		return nil, err
+	}
+	stmt := `INSERT INTO scheduled_transfer (from_wallet_id, to_wallet_id, amount, description, recurrence, start_at, end_at,
+		on_insufficient_funds, max_retries, retry_delay, status, occurrence_at, next_run_at, retries)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING ` + scheduleColumns
+	created, err := scanSchedule(p.Db.QueryRow(stmt, s.FromWalletID, s.ToWalletID, s.Amount, s.Description, s.Recurrence, s.StartAt, s.EndAt,
+		s.OnInsufficientFunds, s.MaxRetries, s.RetryDelay, s.Status, s.OccurrenceAt, s.NextRunAt, s.Retries))
+	return created, scheduleError(err)
+}
+
+func (p *Postgres) Schedule(id int64) (*schedule.Schedule, error) {
+	return scanSchedule(p.Db.QueryRow("SELECT "+scheduleColumns+" FROM scheduled_transfer WHERE id = $1", id))
+}
+
+func (p *Postgres) WalletSchedules(walletID int) ([]schedule.Schedule, error) {
+	rows, err := p.Db.Query("SELECT "+scheduleColumns+" FROM scheduled_transfer WHERE from_wallet_id = $1 OR to_wallet_id = $1 ORDER BY id", walletID)
+	if err != nil {
+		return nil, errors.New("failed to get scheduled transfers")
+	}
+	defer rows.Close()
+
+	schedules := []schedule.Schedule{}
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *s)
+	}
+	return schedules, rows.Err()
+}
+
+// UpdateSchedule only saves s when the row is unchanged since s was read,
+// so an edit cannot undo a run that happened in between.
+func (p *Postgres) UpdateSchedule(s schedule.Schedule) (*schedule.Schedule, error) {
+	updated, err := updateSchedule(p.Db, s)
+	if !errors.Is(err, schedule.ErrNotFound) {
+		return updated, scheduleError(err)
+	}
+	if _, err := p.Schedule(s.ID); err != nil {
+		return nil, err
+	}
+	return nil, schedule.ErrConflict
+}
+
+func updateSchedule(q queryer, s schedule.Schedule) (*schedule.Schedule, error) {
+	stmt := `UPDATE scheduled_transfer SET from_wallet_id = $2, to_wallet_id = $3, amount = $4, description = $5, recurrence = $6,
+		start_at = $7, end_at = $8, on_insufficient_funds = $9, max_retries = $10, retry_delay = $11, status = $12,
+		occurrence_at = $13, next_run_at = $14, retries = $15, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND updated_at = $16 RETURNING ` + scheduleColumns
+	return scanSchedule(q.QueryRow(stmt, s.ID, s.FromWalletID, s.ToWalletID, s.Amount, s.Description, s.Recurrence,
+		s.StartAt, s.EndAt, s.OnInsufficientFunds, s.MaxRetries, s.RetryDelay, s.Status,
+		s.OccurrenceAt, s.NextRunAt, s.Retries, s.UpdatedAt))
+}
+
+func (p *Postgres) DeleteSchedule(id int64) error {
+	res, err := p.Db.Exec("DELETE FROM scheduled_transfer WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return schedule.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Postgres) Executions(scheduleID int64) ([]schedule.Execution, error) {
+	if _, err := p.Schedule(scheduleID); err != nil {
+		return nil, err
+	}
+	rows, err := p.Db.Query("SELECT "+executionColumns+" FROM scheduled_transfer_execution WHERE schedule_id = $1 ORDER BY id DESC", scheduleID)
+	if err != nil {
+		return nil, errors.New("failed to get executions")
+	}
+	defer rows.Close()
+
+	executions := []schedule.Execution{}
+	for rows.Next() {
+		e, err := scanExecution(rows)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, *e)
+	}
+	return executions, rows.Err()
+}
+
+func (p *Postgres) DueSchedules(now time.Time, limit int) ([]int64, error) {
+	rows, err := p.Db.Query("SELECT id FROM scheduled_transfer WHERE status = $1 AND next_run_at <= $2 ORDER BY next_run_at LIMIT $3", schedule.StatusActive, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// runError reports whether err is the transfer being refused, which is
+// recorded as the outcome of the run, rather than a failure to run it.
+func runError(err error) bool {
+	var se *wallet.StatusError
+	return schedule.Declined(err) || errors.As(err, &se) ||
+		errors.Is(err, wallet.ErrWalletNotFound) || errors.Is(err, transaction.ErrCreditCard) ||
+		errors.Is(err, creditcard.ErrOverpayment)
+}
+
+// RunSchedule makes the transfer of a due schedule, records the execution
+// and moves the schedule on, all or nothing. The row lock skips schedules
+// another replica is running. A refused transfer is rolled back to a
+// savepoint so its outcome can still be recorded.
+func (p *Postgres) RunSchedule(id int64, now time.Time) (*schedule.Execution, error) {
+	now = now.UTC()
+	var e *schedule.Execution
+	err := p.withTx(func(tx *sql.Tx) error {
+		s, err := scanSchedule(tx.QueryRow("SELECT "+scheduleColumns+" FROM scheduled_transfer WHERE id = $1 AND status = $2 AND next_run_at <= $3 FOR UPDATE SKIP LOCKED", id, schedule.StatusActive, now))
+		if errors.Is(err, schedule.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("SAVEPOINT scheduled_run"); err != nil {
+			return err
+		}
+		t, runErr := postTransfer(tx, s.Transfer())
+		if runErr != nil {
+			if !runError(runErr) {
+				return runErr
+			}
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT scheduled_run"); err != nil {
+				return err
+			}
+		}
+
+		execution, next := s.Settle(now, t, runErr)
+		if _, err := updateSchedule(tx, next); err != nil {
+			return err
+		}
+		stmt := "INSERT INTO scheduled_transfer_execution (schedule_id, scheduled_for, attempt, status, transfer_id, error, executed_at) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7) RETURNING " + executionColumns
+		e, err = scanExecution(tx.QueryRow(stmt, execution.ScheduleID, execution.ScheduledFor, execution.Attempt, execution.Status, execution.TransferID, execution.Error, execution.ExecutedAt))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// AdvisoryLock elects a leader among replicas with a session level advisory
+// lock, held on a dedicated connection for as long as the replica leads. If
+// the connection drops Postgres releases the lock and another replica takes
+// over.
+type AdvisoryLock struct {
+	db   *sql.DB
+	key  int64
+	conn *sql.Conn
+}
+
+// SchedulerLock is the lock electing the replica running scheduled
+// transfers.
+func (p *Postgres) SchedulerLock() *AdvisoryLock {
+	return &AdvisoryLock{db: p.Db, key: schedulerLockKey}
+}
+
+func (l *AdvisoryLock) TryLead(ctx context.Context) (bool, error) {
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		// The connection is gone, and the lock with it.
+		l.conn.Close()
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&locked); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *AdvisoryLock) Resign() error {
+	if l.conn == nil {
+		return nil
+	}
+	defer func() { l.conn = nil }()
+	_, err := l.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", l.key)
+	l.conn.Close()
+	return err
+}
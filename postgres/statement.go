@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/statement"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+// Statement reads the wallet, its balance at start and the transactions of
+// the period. The opening balance is the sum of every earlier transaction,
+// which stays right when a transaction is dated back into a closed period.
+func (p *Postgres) Statement(walletID int, start, end time.Time) (*statement.Statement, error) {
+	w, err := scanWalletWithCrypto(p.Db.QueryRow(walletQuery+" WHERE w.id = $1", walletID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, wallet.ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := statement.Statement{
+		WalletID:    w.ID,
+		WalletName:  w.WalletName,
+		WalletType:  w.WalletType,
+		UserName:    w.UserName,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+
+	row := p.Db.QueryRow("SELECT COALESCE(SUM(amount), 0) FROM wallet_transaction WHERE wallet_id = $1 AND created_at < $2", walletID, start)
+	if err := row.Scan(&s.OpeningBalance); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.Db.Query("SELECT "+transactionColumns+" FROM wallet_transaction WHERE wallet_id = $1 AND created_at >= $2 AND created_at < $3 ORDER BY created_at, id", walletID, start, end)
+	if err != nil {
+		return nil, errors.New("failed to get transactions")
+	}
+	defer rows.Close()
+
+	var transactions []transaction.Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	s = statement.Build(s, transactions)
+	return &s, nil
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/event"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+var statusActions = map[string]string{
+	wallet.StatusFrozen: audit.ActionFreeze,
+	wallet.StatusActive: audit.ActionUnfreeze,
+	wallet.StatusClosed: audit.ActionClose,
+}
+
+// ChangeStatus moves a wallet to status, recording the reason and actor in
+// wallet_status_change next to the usual audit log entry and event.
+func (p *Postgres) ChangeStatus(id int, status, reason string, actor audit.Actor) (*wallet.Wallet, error) {
+	var updated *wallet.Wallet
+	err := p.withTx(func(tx *sql.Tx) error {
+		before, err := walletForUpdate(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := wallet.CheckTransition(before.Status, status); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("UPDATE user_wallet SET status = $1 WHERE id = $2", status, id); err != nil {
+			return err
+		}
+		stmt := "INSERT INTO wallet_status_change (wallet_id, from_status, to_status, reason, actor) VALUES ($1, $2, $3, $4, $5)"
+		if _, err := tx.Exec(stmt, id, before.Status, status, reason, actor.ID); err != nil {
+			return err
+		}
+
+		after := *before
+		after.Status = status
+		updated = &after
+		if err := insertAudit(tx, actor, statusActions[status], id, before, updated); err != nil {
+			return err
+		}
+		return insertEvent(tx, event.WalletUpdated, id, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (p *Postgres) StatusHistory(id int) ([]wallet.StatusChange, error) {
+	rows, err := p.Db.Query("SELECT id, wallet_id, from_status, to_status, reason, actor, created_at FROM wallet_status_change WHERE wallet_id = $1 ORDER BY id DESC", id)
+	if err != nil {
+		return nil, errors.New("failed to get status history")
+	}
+	defer rows.Close()
+
+	changes := []wallet.StatusChange{}
+	for rows.Next() {
+		var c wallet.StatusChange
+		if err := rows.Scan(&c.ID, &c.WalletID, &c.From, &c.To, &c.Reason, &c.Actor, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/stream"
+	"github.com/lib/pq"
+)
+
+const walletChangesChannel = "wallet_changes"
+
+func (p *Postgres) WalletChanges(userID int, afterID int64, limit int) ([]stream.Change, error) {
+	rows, err := p.Db.Query("SELECT id, user_id, change_type, wallet, created_at FROM wallet_change WHERE user_id = $1 AND id > $2 ORDER BY id LIMIT $3", userID, afterID, limit)
+	if err != nil {
+		return nil, errors.New("failed to get wallet changes")
+	}
+	defer rows.Close()
+
+	var changes []stream.Change
+	for rows.Next() {
+		var c stream.Change
+		var w []byte
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Type, &w, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		c.Wallet = w
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func (p *Postgres) PruneWalletChanges(before time.Time) (int64, error) {
+	res, err := p.Db.Exec("DELETE FROM wallet_change WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (p *Postgres) LatestWalletChangeID() (int64, error) {
+	var id int64
+	err := p.Db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM wallet_change").Scan(&id)
+	return id, err
+}
+
+// ListenWalletChanges forwards NOTIFYs from the user_wallet trigger to n
+// until ctx is done. It reconnects on its own; after a reconnect every user
+// is notified since notifications may have been missed meanwhile.
+func (p *Postgres) ListenWalletChanges(ctx context.Context, n stream.Notifier) error {
+	listener := pq.NewListener(p.dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("wallet changes listener: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(walletChangesChannel); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-listener.Notify:
+			if notification == nil {
+				n.NotifyAll()
+				continue
+			}
+			var payload struct {
+				UserID int `json:"user_id"`
+			}
+			if err := json.Unmarshal([]byte(notification.Extra), &payload); err != nil {
+				log.Printf("wallet changes listener: %v", err)
+				continue
+			}
+			n.Notify(payload.UserID)
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
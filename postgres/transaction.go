@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+const transactionColumns = "id, wallet_id, kind, amount, balance_after, description, reversal_of, reversed, created_at"
+
+// postTransaction changes the balance of a wallet by amount and records why
+// in wallet_transaction and in the ledger, in the caller's transaction.
+// Every balance change goes through here so the transactions of a wallet,
+// and the postings to its ledger account, always add up to its balance, and
+// so the status of the wallet, the rules of its wallet type and its
+// spending limits are always enforced. Money going out must come from the
+// available balance, leaving the held funds alone.
+func postTransaction(tx *sql.Tx, walletID int, kind string, amount float64, description string) (*transaction.Transaction, error) {
+	return post(tx, posting{walletID: walletID, kind: kind, amount: amount, description: description})
+}
+
+// postTransactionAt is postTransaction with the transaction dated at, for
+// postings that belong to a period that has just ended. A zero at means now.
+func postTransactionAt(tx *sql.Tx, walletID int, kind string, amount float64, description string, at time.Time) (*transaction.Transaction, error) {
+	return post(tx, posting{walletID: walletID, kind: kind, amount: amount, description: description, at: at})
+}
+
+// outflow reports whether a posting counts against the spending limits of
+// a wallet. A capture was checked when its hold was placed, and a reversal
+// counts when it takes money out of a wallet other than a credit card.
+func outflow(wt *wallet.Type, kind string, amount float64) bool {
+	switch kind {
+	case transaction.KindCapture:
+		return false
+	case transaction.KindReversal:
+		return amount < 0 && wt.Name != wallet.TypeCreditCard
+	}
+	return transaction.IsOutflow(kind)
+}
+
+func insertTransaction(tx *sql.Tx, walletID int, kind string, amount, balanceAfter float64, description string, at time.Time) (*transaction.Transaction, error) {
+	stmt := "INSERT INTO wallet_transaction (wallet_id, kind, amount, balance_after, description, created_at) VALUES ($1, $2, $3, $4, $5, COALESCE($6, CURRENT_TIMESTAMP)) RETURNING " + transactionColumns
+	createdAt := sql.NullTime{Time: at, Valid: !at.IsZero()}
+	return scanTransaction(tx.QueryRow(stmt, walletID, kind, amount, balanceAfter, description, createdAt))
+}
+
+func scanTransaction(row scanner) (*transaction.Transaction, error) {
+	var t transaction.Transaction
+	err := row.Scan(&t.ID, &t.WalletID, &t.Kind, &t.Amount, &t.BalanceAfter, &t.Description, &t.ReversalOf, &t.Reversed, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *Postgres) Transactions(walletID int) ([]transaction.Transaction, error) {
+	rows, err := p.Db.Query("SELECT "+transactionColumns+" FROM wallet_transaction WHERE wallet_id = $1 ORDER BY id DESC", walletID)
+	if err != nil {
+		return nil, errors.New("failed to get transactions")
+	}
+	defer rows.Close()
+
+	transactions := []transaction.Transaction{}
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, *t)
+	}
+	return transactions, rows.Err()
+}
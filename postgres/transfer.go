@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/fee"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transfer"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/lib/pq"
+)
+
+const transferColumns = "id, COALESCE(from_wallet_id, 0), COALESCE(to_wallet_id, 0), amount, description, debit_transaction_id, credit_transaction_id, fee, fee_transaction_id, created_at"
+
+// lockedWallet is a wallet row locked by lockWallets.
+type lockedWallet struct {
+	walletType string
+	balance    float64
+}
+
+// lockWallets locks the wallets in id order, so two transfers between the
+// same wallets in opposite directions cannot deadlock.
+func lockWallets(tx *sql.Tx, ids ...int) (map[int]lockedWallet, error) {
+	rows, err := tx.Query("SELECT id, wallet_type, balance FROM user_wallet WHERE id = ANY($1) ORDER BY id FOR UPDATE", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locked := make(map[int]lockedWallet, len(ids))
+	for rows.Next() {
+		var id int
+		var w lockedWallet
+		if err := rows.Scan(&id, &w.walletType, &w.balance); err != nil {
+			return nil, err
+		}
+		locked[id] = w
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(locked) != len(ids) {
+		return nil, wallet.ErrWalletNotFound
+	}
+	return locked, nil
+}
+
+func (p *Postgres) Deposit(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	return p.move(walletID, transaction.KindDeposit, amount, description)
+}
+
+func (p *Postgres) Withdraw(walletID int, amount float64, description string) (*transaction.Transaction, error) {
+	return p.move(walletID, transaction.KindWithdrawal, -amount, description)
+}
+
+func (p *Postgres) move(walletID int, kind string, amount float64, description string) (*transaction.Transaction, error) {
+	var t *transaction.Transaction
+	err := p.withTx(func(tx *sql.Tx) error {
+		locked, err := lockWallets(tx, walletID)
+		if err != nil {
+			return err
+		}
+		if locked[walletID].walletType == wallet.TypeCreditCard {
+			return transaction.ErrCreditCard
+		}
+		t, err = postTransaction(tx, walletID, kind, amount, description)
+		if err != nil || kind != transaction.KindWithdrawal {
+			return err
+		}
+		_, _, err = chargeFee(tx, walletID, fee.OperationWithdrawal, -t.Amount, "Withdrawal fee")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (p *Postgres) CreateTransfer(r transfer.Request) (*transfer.Transfer, error) {
+	var t *transfer.Transfer
+	err := p.withTx(func(tx *sql.Tx) error {
+		var err error
+		t, err = postTransfer(tx, r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// postTransfer posts both legs of a transfer and its fee, and records it,
+// in the caller's transaction. A transfer into a credit card pays it off,
+// so its leg is a payment and cannot exceed the outstanding balance.
+func postTransfer(tx *sql.Tx, r transfer.Request) (*transfer.Transfer, error) {
+	locked, err := lockWallets(tx, r.FromWalletID, r.ToWalletID)
+	if err != nil {
+		return nil, err
+	}
+	if locked[r.FromWalletID].walletType == wallet.TypeCreditCard {
+		return nil, transaction.ErrCreditCard
+	}
+	debit, err := postTransaction(tx, r.FromWalletID, transaction.KindTransferOut, -r.Amount, r.Description)
+	if err != nil {
+		return nil, err
+	}
+	feeID, charged, err := chargeFee(tx, r.FromWalletID, fee.OperationTransfer, -debit.Amount, "Transfer fee")
+	if err != nil {
+		return nil, err
+	}
+
+	var credit *transaction.Transaction
+	if to := locked[r.ToWalletID]; to.walletType == wallet.TypeCreditCard {
+		if -debit.Amount > to.balance {
+			return nil, creditcard.ErrOverpayment
+		}
+		credit, err = postTransaction(tx, r.ToWalletID, transaction.KindPayment, debit.Amount, r.Description)
+	} else {
+		credit, err = postTransaction(tx, r.ToWalletID, transaction.KindTransferIn, -debit.Amount, r.Description)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := "INSERT INTO transfer (from_wallet_id, to_wallet_id, amount, description, debit_transaction_id, credit_transaction_id, fee, fee_transaction_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING " + transferColumns
+	return scanTransfer(tx.QueryRow(stmt, r.FromWalletID, r.ToWalletID, -debit.Amount, r.Description, debit.ID, credit.ID, charged, feeID))
+}
+
+// QuoteTransfer computes the fee a transfer would be charged now, without
+// moving money.
+func (p *Postgres) QuoteTransfer(r transfer.Request) (*transfer.Quote, error) {
+	rows, err := p.Db.Query("SELECT id, wallet_type FROM user_wallet WHERE id = ANY($1)", pq.Array([]int{r.FromWalletID, r.ToWalletID}))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	types := make(map[int]string, 2)
+	for rows.Next() {
+		var id int
+		var walletType string
+		if err := rows.Scan(&id, &walletType); err != nil {
+			return nil, err
+		}
+		types[id] = walletType
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(types) != 2 {
+		return nil, wallet.ErrWalletNotFound
+	}
+	if types[r.FromWalletID] == wallet.TypeCreditCard {
+		return nil, transaction.ErrCreditCard
+	}
+
+	charged, err := feeFor(p.Db, r.FromWalletID, fee.OperationTransfer, r.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer.Quote{
+		FromWalletID: r.FromWalletID,
+		ToWalletID:   r.ToWalletID,
+		Amount:       r.Amount,
+		Fee:          charged,
+		Total:        math.Round((r.Amount+charged)*100) / 100,
+	}, nil
+}
+
+func scanTransfer(row scanner) (*transfer.Transfer, error) {
+	var t transfer.Transfer
+	err := row.Scan(&t.ID, &t.FromWalletID, &t.ToWalletID, &t.Amount, &t.Description, &t.DebitTransactionID, &t.CreditTransactionID, &t.Fee, &t.FeeTransactionID, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *Postgres) Transfer(id int64) (*transfer.Transfer, error) {
+	t, err := scanTransfer(p.Db.QueryRow("SELECT "+transferColumns+" FROM transfer WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, transfer.ErrNotFound
+	}
+	return t, err
+}
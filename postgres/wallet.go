@@ -3,9 +3,14 @@ package postgres
 import (
 	"database/sql"
 	"errors"
+	"math"
 	"time"
 
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/event"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
 	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/lib/pq"
 )
 
 type Wallet struct {
@@ -16,17 +21,81 @@ type Wallet struct {
 	WalletType string    `postgres:"wallet_type"`
 	Balance    float64   `postgres:"balance"`
 	CreatedAt  time.Time `postgres:"created_at"`
+	Status     string    `postgres:"status"`
+	Held       float64   `postgres:"held"`
 }
 
 func (p *Postgres) Wallets(walletType string) ([]wallet.Wallet, error) {
+	var wallets []wallet.Wallet
+	err := p.EachWallet(walletType, func(w wallet.Wallet) error {
+		wallets = append(wallets, w)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// EachWallet calls fn for every wallet as rows are read, so callers can
+// stream any number of wallets without holding them in memory.
+func (p *Postgres) EachWallet(walletType string, fn func(wallet.Wallet) error) error {
 	var rows *sql.Rows
 	var err error
 	if walletType == "" {
-		rows, err = p.Db.Query("SELECT * FROM user_wallet")
+		rows, err = p.Db.Query(walletQuery + " ORDER BY w.id")
 	} else {
-		rows, err = p.Db.Query("SELECT * FROM user_wallet WHERE wallet_type = $1", walletType)
+		rows, err = p.Db.Query(walletQuery+" WHERE w.wallet_type = $1 ORDER BY w.id", walletType)
 	}
 
+	if err != nil {
+		return errors.New("failed to get wallets")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		w, err := scanWalletWithCrypto(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(*w); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanWallet(row scanner) (*wallet.Wallet, error) {
+	var w Wallet
+	err := row.Scan(&w.ID,
+		&w.UserID, &w.UserName,
+		&w.WalletName, &w.WalletType,
+		&w.Balance, &w.CreatedAt,
+		&w.Status, &w.Held,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet.Wallet{
+		ID:               w.ID,
+		UserID:           w.UserID,
+		UserName:         w.UserName,
+		WalletName:       w.WalletName,
+		WalletType:       w.WalletType,
+		Balance:          w.Balance,
+		AvailableBalance: available(w.Balance, w.Held),
+		CreatedAt:        w.CreatedAt,
+		Status:           w.Status,
+	}, nil
+}
+
+// available is the ledger balance less the held funds, in cents.
+func available(balance, held float64) float64 {
+	return math.Round((balance-held)*100) / 100
+}
+
+func (p *Postgres) WalletsByUserID(userID int) ([]wallet.Wallet, error) {
+	rows, err := p.Db.Query(walletQuery+" WHERE w.user_id = $1", userID)
 	if err != nil {
 		return nil, errors.New("failed to get wallets")
 	}
@@ -34,117 +103,175 @@ func (p *Postgres) Wallets(walletType string) ([]wallet.Wallet, error) {
 
 	var wallets []wallet.Wallet
 	for rows.Next() {
-		var w Wallet
-		err := rows.Scan(&w.ID,
-			&w.UserID, &w.UserName,
-			&w.WalletName, &w.WalletType,
-			&w.Balance, &w.CreatedAt,
-		)
+		w, err := scanWalletWithCrypto(rows)
 		if err != nil {
 			return nil, err
 		}
-		wallets = append(wallets, wallet.Wallet{
-			ID:         w.ID,
-			UserID:     w.UserID,
-			UserName:   w.UserName,
-			WalletName: w.WalletName,
-			WalletType: w.WalletType,
-			Balance:    w.Balance,
-			CreatedAt:  w.CreatedAt,
-		})
+		wallets = append(wallets, *w)
 	}
 	return wallets, nil
 }
 
-func (p *Postgres) WalletsByUserID(userID int) ([]wallet.Wallet, error) {
-	var rows *sql.Rows
-	var err error
-	rows, err = p.Db.Query("SELECT * FROM user_wallet WHERE user_id = $1", userID)
+// WalletsByIDs reads the wallets of ids in one query, for the loaders of
+// the GraphQL API. Unknown ids are left out.
+func (p *Postgres) WalletsByIDs(ids []int) ([]wallet.Wallet, error) {
+	return p.walletsWhere("w.id = ANY($1)", pq.Array(ids))
+}
+
+// WalletsByUserIDs reads the wallets of many users in one query, for the
+// loaders of the GraphQL API.
+func (p *Postgres) WalletsByUserIDs(userIDs []int) ([]wallet.Wallet, error) {
+	return p.walletsWhere("w.user_id = ANY($1)", pq.Array(userIDs))
+}
+
+func (p *Postgres) walletsWhere(cond string, args ...any) ([]wallet.Wallet, error) {
+	rows, err := p.Db.Query(walletQuery+" WHERE "+cond+" ORDER BY w.id", args...)
 	if err != nil {
 		return nil, errors.New("failed to get wallets")
 	}
 	defer rows.Close()
 
-	var wallets []wallet.Wallet
+	wallets := []wallet.Wallet{}
 	for rows.Next() {
-		var w Wallet
-		err := rows.Scan(&w.ID,
-			&w.UserID, &w.UserName,
-			&w.WalletName, &w.WalletType,
-			&w.Balance, &w.CreatedAt,
-		)
+		w, err := scanWalletWithCrypto(rows)
 		if err != nil {
 			return nil, err
 		}
-		wallets = append(wallets, wallet.Wallet{
-			ID:         w.ID,
-			UserID:     w.UserID,
-			UserName:   w.UserName,
-			WalletName: w.WalletName,
-			WalletType: w.WalletType,
-			Balance:    w.Balance,
-			CreatedAt:  w.CreatedAt,
-		})
+		wallets = append(wallets, *w)
 	}
-	return wallets, nil
+	return wallets, rows.Err()
 }
 
-func (p *Postgres) CreateWallet(w wallet.Wallet) (*wallet.Wallet, error) {
+func (p *Postgres) CreateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
 
 	stmt := "INSERT INTO user_wallet (user_id, user_name, wallet_name, wallet_type, balance, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *"
 
-	row := p.Db.QueryRow(stmt,
-		w.UserID, w.UserName, w.WalletName, w.WalletType, w.Balance, time.Now())
-
-	var newWallet wallet.Wallet
-	err := row.Scan(&newWallet.ID, &newWallet.UserID, &newWallet.UserName, &newWallet.WalletName, &newWallet.WalletType, &newWallet.Balance, &newWallet.CreatedAt)
+	var newWallet *wallet.Wallet
+	err := p.withTx(func(tx *sql.Tx) error {
+		var err error
+		row := tx.QueryRow(stmt,
+			w.UserID, w.UserName, w.WalletName, w.WalletType, w.Balance, time.Now())
+		if newWallet, err = scanWallet(row); err != nil {
+			return err
+		}
+		if w.Crypto != nil {
+			if err := saveCrypto(tx, newWallet.ID, *w.Crypto); err != nil {
+				return err
+			}
+			newWallet.Crypto = w.Crypto
+		}
+		if err := recordOpening(tx, newWallet); err != nil {
+			return err
+		}
+		if err := insertAudit(tx, actor, audit.ActionCreate, newWallet.ID, nil, newWallet); err != nil {
+			return err
+		}
+		return insertEvent(tx, event.WalletCreated, newWallet.ID, newWallet)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &newWallet, nil
+	return newWallet, nil
 }
 
-func (p *Postgres) UpdateWallet(w wallet.Wallet) (*wallet.Wallet, error) {
+// UpdateWallet updates the fields of an active wallet and, when the balance
+// differs, posts an adjustment transaction for the difference. Crypto
+// details are kept unless new ones are given, and dropped when the wallet
+// stops being a crypto wallet; a wallet becoming one must be given them.
+func (p *Postgres) UpdateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
 
-	stmt := "UPDATE user_wallet SET user_id = $1, user_name = $2, wallet_name = $3, wallet_type = $4, balance = $5 WHERE id = $6 RETURNING *"
+	stmt := "UPDATE user_wallet SET user_id = $1, user_name = $2, wallet_name = $3, wallet_type = $4 WHERE id = $5 RETURNING *"
 
-	row := p.Db.QueryRow(
-		stmt,
-		w.UserID,
-		w.UserName,
-		w.WalletName,
-		w.WalletType,
-		w.Balance,
-		w.ID,
-	)
-	var updatedWallet wallet.Wallet
-	err := row.Scan(&updatedWallet.ID, &updatedWallet.UserID, &updatedWallet.UserName, &updatedWallet.WalletName, &updatedWallet.WalletType, &updatedWallet.Balance, &updatedWallet.CreatedAt)
+	var updatedWallet *wallet.Wallet
+	err := p.withTx(func(tx *sql.Tx) error {
+		before, err := walletForUpdate(tx, w.ID)
+		if err != nil {
+			return err
+		}
+		if before.Status != wallet.StatusActive {
+			return &wallet.StatusError{WalletID: w.ID, Status: before.Status}
+		}
 
+		row := tx.QueryRow(
+			stmt,
+			w.UserID,
+			w.UserName,
+			w.WalletName,
+			w.WalletType,
+			w.ID,
+		)
+		if updatedWallet, err = scanWallet(row); err != nil {
+			return err
+		}
+		switch {
+		case updatedWallet.WalletType != wallet.TypeCryptoWallet:
+			if err := deleteCrypto(tx, w.ID); err != nil {
+				return err
+			}
+		case w.Crypto != nil:
+			if err := saveCrypto(tx, w.ID, *w.Crypto); err != nil {
+				return err
+			}
+			updatedWallet.Crypto = w.Crypto
+		case before.Crypto == nil:
+			return wallet.ErrMissingCrypto
+		default:
+			updatedWallet.Crypto = before.Crypto
+		}
+		if w.Balance != before.Balance {
+			t, err := postTransaction(tx, w.ID, transaction.KindAdjustment, w.Balance-before.Balance, "Balance set by wallet update")
+			if err != nil {
+				return err
+			}
+			updatedWallet.Balance = t.BalanceAfter
+		}
+		if err := insertAudit(tx, actor, audit.ActionUpdate, updatedWallet.ID, before, updatedWallet); err != nil {
+			return err
+		}
+		return insertEvent(tx, event.WalletUpdated, updatedWallet.ID, updatedWallet)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &updatedWallet, nil
+	return updatedWallet, nil
 }
 
-func (p *Postgres) DeleteWallet(id int) error {
-	var rows *sql.Rows
-	var err error
-	rows, err = p.Db.Query("SELECT * FROM user_wallet WHERE id = $1", id)
-	if err != nil {
-		return errors.New("failed to get wallets")
-	}
-	defer rows.Close()
+// DeleteWallet deletes an active wallet; a frozen or closed one is kept.
+func (p *Postgres) DeleteWallet(id int, actor audit.Actor) error {
+	return p.withTx(func(tx *sql.Tx) error {
+		before, err := walletForUpdate(tx, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != wallet.StatusActive {
+			return &wallet.StatusError{WalletID: id, Status: before.Status}
+		}
 
-	if !rows.Next() {
-		return errors.New("wallet not found")
-	}
+		if err := closeAccount(tx, before); err != nil {
+			return err
+		}
+		stmt := "DELETE FROM user_wallet WHERE id = $1"
+		if _, err := tx.Exec(stmt, id); err != nil {
+			return err
+		}
+		if err := insertAudit(tx, actor, audit.ActionDelete, id, before, nil); err != nil {
+			return err
+		}
+		return insertEvent(tx, event.WalletDeleted, id, before)
+	})
+}
 
-	stmt := "DELETE FROM user_wallet WHERE id = $1"
-	_, err = p.Db.Exec(stmt, id)
+// walletForUpdate locks the wallet row for the rest of the transaction and
+// returns its current state.
+func walletForUpdate(tx *sql.Tx, id int) (*wallet.Wallet, error) {
+	w, err := scanWalletWithCrypto(tx.QueryRow(walletQuery+" WHERE w.id = $1 FOR UPDATE OF w", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, wallet.ErrWalletNotFound
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return w, nil
 }
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/lib/pq"
+)
+
+const walletTypeColumns = "key, name, allow_negative, max_balance"
+
+func scanWalletType(row scanner) (*wallet.Type, error) {
+	var t wallet.Type
+	var maxBalance sql.NullFloat64
+	if err := row.Scan(&t.Key, &t.Name, &t.AllowNegative, &maxBalance); err != nil {
+		return nil, err
+	}
+	if maxBalance.Valid {
+		t.MaxBalance = &maxBalance.Float64
+	}
+	return &t, nil
+}
+
+func (p *Postgres) WalletTypes() ([]wallet.Type, error) {
+	rows, err := p.Db.Query("SELECT " + walletTypeColumns + " FROM wallet_types ORDER BY key")
+	if err != nil {
+		return nil, errors.New("failed to get wallet types")
+	}
+	defer rows.Close()
+
+	types := []wallet.Type{}
+	for rows.Next() {
+		t, err := scanWalletType(rows)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, *t)
+	}
+	return types, rows.Err()
+}
+
+func (p *Postgres) CreateWalletType(t wallet.Type) (*wallet.Type, error) {
+	stmt := "INSERT INTO wallet_types (key, name, allow_negative, max_balance) VALUES ($1, $2, $3, $4) RETURNING " + walletTypeColumns
+	created, err := scanWalletType(p.Db.QueryRow(stmt, t.Key, t.Name, t.AllowNegative, t.MaxBalance))
+	return created, walletTypeError(err)
+}
+
+// UpdateWalletType changes the name and rules of a type. A new name is
+// cascaded to its wallets by the foreign key.
+func (p *Postgres) UpdateWalletType(t wallet.Type) (*wallet.Type, error) {
+	stmt := "UPDATE wallet_types SET name = $2, allow_negative = $3, max_balance = $4 WHERE key = $1 RETURNING " + walletTypeColumns
+	updated, err := scanWalletType(p.Db.QueryRow(stmt, t.Key, t.Name, t.AllowNegative, t.MaxBalance))
+	return updated, walletTypeError(err)
+}
+
+func (p *Postgres) DeleteWalletType(key string) error {
+	res, err := p.Db.Exec("DELETE FROM wallet_types WHERE key = $1", key)
+	if err != nil {
+		return walletTypeError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return wallet.ErrTypeNotFound
+	}
+	return nil
+}
+
+// walletTypeFor returns the type of a wallet, for checking a balance
+// against its rules.
+func walletTypeFor(tx *sql.Tx, walletID int) (*wallet.Type, error) {
+	return scanWalletType(tx.QueryRow(`SELECT t.key, t.name, t.allow_negative, t.max_balance
+		FROM wallet_types t JOIN user_wallet w ON w.wallet_type = t.name
+		WHERE w.id = $1`, walletID))
+}
+
+func walletTypeError(err error) error {
+	var pqErr *pq.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return wallet.ErrTypeNotFound
+	case errors.As(err, &pqErr) && pqErr.Code == "23505":
+		return wallet.ErrTypeExists
+	case errors.As(err, &pqErr) && pqErr.Code == "23503":
+		return wallet.ErrTypeInUse
+	}
+	return err
+}
@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/event"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/webhook"
+	"github.com/lib/pq"
+)
+
+const subscriptionColumns = "id, target_url, events, secret, active, created_at"
+
+const deliveryColumns = "id, subscription_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at, attempts_before"
+
+func scanSubscription(row scanner) (*webhook.Subscription, error) {
+	var s webhook.Subscription
+	err := row.Scan(&s.ID, &s.TargetURL, pq.Array(&s.Events), &s.Secret, &s.Active, &s.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, webhook.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func scanDelivery(row scanner, extra ...any) (*webhook.Delivery, error) {
+	var d webhook.Delivery
+	var payload []byte
+	var responseStatus sql.NullInt32
+	var lastError sql.NullString
+	dest := append([]any{&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload,
+		&d.Status, &d.Attempts, &responseStatus, &lastError,
+		&d.NextAttemptAt, &d.CreatedAt, &d.DeliveredAt, &d.AttemptsBefore,
+	}, extra...)
+	err := row.Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, webhook.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	d.ResponseStatus = int(responseStatus.Int32)
+	d.LastError = lastError.String
+	return &d, nil
+}
+
+func (p *Postgres) Subscriptions() ([]webhook.Subscription, error) {
+	rows, err := p.Db.Query("SELECT " + subscriptionColumns + " FROM webhook_subscription ORDER BY id")
+	if err != nil {
+		return nil, errors.New("failed to get webhook subscriptions")
+	}
+	defer rows.Close()
+
+	subs := []webhook.Subscription{}
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *s)
+	}
+	return subs, rows.Err()
+}
+
+func (p *Postgres) Subscription(id int) (*webhook.Subscription, error) {
+	row := p.Db.QueryRow("SELECT "+subscriptionColumns+" FROM webhook_subscription WHERE id = $1", id)
+	return scanSubscription(row)
+}
+
+func (p *Postgres) CreateSubscription(s webhook.Subscription) (*webhook.Subscription, error) {
+	stmt := "INSERT INTO webhook_subscription (target_url, events, secret, active) VALUES ($1, $2, $3, $4) RETURNING " + subscriptionColumns
+	row := p.Db.QueryRow(stmt, s.TargetURL, pq.Array(nonNil(s.Events)), s.Secret, s.Active)
+	return scanSubscription(row)
+}
+
+func (p *Postgres) UpdateSubscription(s webhook.Subscription) (*webhook.Subscription, error) {
+	stmt := "UPDATE webhook_subscription SET target_url = $1, events = $2, secret = COALESCE(NULLIF($3, ''), secret), active = $4 WHERE id = $5 RETURNING " + subscriptionColumns
+	row := p.Db.QueryRow(stmt, s.TargetURL, pq.Array(nonNil(s.Events)), s.Secret, s.Active, s.ID)
+	return scanSubscription(row)
+}
+
+func (p *Postgres) DeleteSubscription(id int) error {
+	res, err := p.Db.Exec("DELETE FROM webhook_subscription WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return webhook.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Postgres) Deliveries(subscriptionID int) ([]webhook.Delivery, error) {
+	rows, err := p.Db.Query("SELECT "+deliveryColumns+" FROM webhook_delivery WHERE subscription_id = $1 ORDER BY id DESC", subscriptionID)
+	if err != nil {
+		return nil, errors.New("failed to get webhook deliveries")
+	}
+	defer rows.Close()
+
+	deliveries := []webhook.Delivery{}
+	byID := map[int]int{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		byID[d.ID] = len(deliveries)
+		deliveries = append(deliveries, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = p.Db.Query(`SELECT a.delivery_id, a.attempt, a.status, a.response_status, a.error, a.created_at
+		FROM webhook_attempt a JOIN webhook_delivery d ON d.id = a.delivery_id
+		WHERE d.subscription_id = $1 ORDER BY a.id`, subscriptionID)
+	if err != nil {
+		return nil, errors.New("failed to get webhook attempts")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var deliveryID int
+		var a webhook.Attempt
+		var responseStatus sql.NullInt32
+		var attemptError sql.NullString
+		if err := rows.Scan(&deliveryID, &a.Attempt, &a.Status, &responseStatus, &attemptError, &a.At); err != nil {
+			return nil, err
+		}
+		a.ResponseStatus = int(responseStatus.Int32)
+		a.Error = attemptError.String
+		if i, ok := byID[deliveryID]; ok {
+			deliveries[i].History = append(deliveries[i].History, a)
+		}
+	}
+	return deliveries, rows.Err()
+}
+
+// Redeliver keeps the attempts made so far, in the count and the history,
+// and gives the delivery a new round of retries after them.
+func (p *Postgres) Redeliver(subscriptionID, deliveryID int) (*webhook.Delivery, error) {
+	stmt := "UPDATE webhook_delivery SET status = $1, attempts_before = attempts, next_attempt_at = CURRENT_TIMESTAMP WHERE id = $2 AND subscription_id = $3 RETURNING " + deliveryColumns
+	row := p.Db.QueryRow(stmt, webhook.StatusPending, deliveryID, subscriptionID)
+	return scanDelivery(row)
+}
+
+func (p *Postgres) EnqueueDeliveries(e event.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	stmt := `INSERT INTO webhook_delivery (subscription_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $3 FROM webhook_subscription
+		WHERE active AND (cardinality(events) = 0 OR $2 = ANY(events))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`
+	_, err = p.Db.Exec(stmt, e.ID, e.Type, payload)
+	return err
+}
+
+// DueDeliveries claims the due deliveries by moving their next attempt past
+// the lease in the same statement that selects them. SKIP LOCKED lets
+// senders claiming at once take different rows instead of waiting.
+func (p *Postgres) DueDeliveries(now time.Time, lease time.Duration, limit int) ([]webhook.Pending, error) {
+	query := `UPDATE webhook_delivery d SET next_attempt_at = $3
+		FROM webhook_subscription s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT id FROM webhook_delivery
+			WHERE status = $1 AND next_attempt_at <= $2
+			ORDER BY id LIMIT $4 FOR UPDATE SKIP LOCKED)
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.response_status, d.last_error, d.next_attempt_at, d.created_at, d.delivered_at, d.attempts_before, s.target_url, s.secret`
+	rows, err := p.Db.Query(query, webhook.StatusPending, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, errors.New("failed to get due webhook deliveries")
+	}
+	defer rows.Close()
+
+	var pending []webhook.Pending
+	for rows.Next() {
+		var pd webhook.Pending
+		d, err := scanDelivery(rows, &pd.TargetURL, &pd.Secret)
+		if err != nil {
+			return nil, err
+		}
+		pd.Delivery = *d
+		pending = append(pending, pd)
+	}
+	return pending, rows.Err()
+}
+
+func (p *Postgres) SaveAttempt(d webhook.Delivery) error {
+	status := webhook.StatusFailed
+	if d.Status == webhook.StatusSucceeded {
+		status = webhook.StatusSucceeded
+	}
+	return p.withTx(func(tx *sql.Tx) error {
+		stmt := "UPDATE webhook_delivery SET status = $1, attempts = $2, response_status = NULLIF($3, 0), last_error = NULLIF($4, ''), next_attempt_at = $5, delivered_at = $6 WHERE id = $7"
+		if _, err := tx.Exec(stmt, d.Status, d.Attempts, d.ResponseStatus, d.LastError, d.NextAttemptAt, d.DeliveredAt, d.ID); err != nil {
+			return err
+		}
+		stmt = "INSERT INTO webhook_attempt (delivery_id, attempt, status, response_status, error) VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, ''))"
+		_, err := tx.Exec(stmt, d.ID, d.Attempts, status, d.ResponseStatus, d.LastError)
+		return err
+	})
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,199 @@
+package reconcile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	ImportFile(f File, lines []Line) (*Report, error)
+	Files() ([]File, error)
+	Report(id int64) (*Report, error)
+	Resolve(fileID, itemID int64, r Resolution, actor audit.Actor) (*Item, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// Import reads a settlement file and stores it with its lines matched. It
+// is shared by the API and the reconcile command.
+func Import(store Storer, name, format string, content []byte) (*Report, error) {
+	lines, err := Parse(bytes.NewReader(content), format)
+	if err != nil {
+		return nil, &ParseError{Err: err}
+	}
+	if len(lines) == 0 {
+		return nil, &ParseError{Err: errors.New("file has no booked lines")}
+	}
+	sum := sha256.Sum256(content)
+	f := File{Name: name, Format: format, Checksum: hex.EncodeToString(sum[:])}
+	f.From, f.To = Period(lines)
+	return store.ImportFile(f, lines)
+}
+
+// ParseError is returned by Import for a file that cannot be read.
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ImportFile
+//
+//	@Summary		Import settlement file
+//	@Description	Import a settlement file of the bank, as CSV (with a header row of date, amount and optionally reference, description) or ISO 20022 camt.053 XML, and match its lines against the postings to the settlement account by reference, or by amount and date.
+//	@Tags			reconciliation
+//	@Accept			text/csv
+//	@Accept			application/xml
+//	@Produce		json
+//	@Success		201	{object}	Report
+//	@Router			/api/v1/reconciliations [post]
+//	@Failure		400	{object}	Err
+//	@Failure		409	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   format  query	string	false	"File format, defaults to the Content-Type"	Enums(csv, camt053)
+//	@Param   name  query	string	false	"File name"
+//	@Param   file  body		string	true	"Settlement file"
+//	@Security	AdminToken
+func (h *Handler) ImportFile(c echo.Context) error {
+	name := c.QueryParam("name")
+	if name == "" {
+		name = "settlement"
+	}
+	format := c.QueryParam("format")
+	if format == "" {
+		format = FormatOf(c.Request().Header.Get(echo.HeaderContentType), name)
+	}
+	content, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+
+	report, err := Import(h.store, name, format, content)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, report)
+}
+
+// GetFiles
+//
+//	@Summary		Get settlement files
+//	@Description	Get the imported settlement files with their item counts, newest first
+//	@Tags			reconciliation
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		File
+//	@Router			/api/v1/reconciliations [get]
+//	@Failure		500	{object}	Err
+//	@Security	AdminToken
+func (h *Handler) GetFiles(c echo.Context) error {
+	files, err := h.store.Files()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, files)
+}
+
+// GetReport
+//
+//	@Summary		Get reconciliation report
+//	@Description	Get the matched, unmatched and mismatched items of a settlement file, and the postings of its period that no item matches
+//	@Tags			reconciliation
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Report
+//	@Router			/api/v1/reconciliations/{id} [get]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Settlement file ID"
+//	@Security	AdminToken
+func (h *Handler) GetReport(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid settlement file ID"})
+	}
+	report, err := h.store.Report(id)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// ResolveItem
+//
+//	@Summary		Resolve settlement item
+//	@Description	Settle an unmatched or mismatched item by hand, against a posting to the settlement account or, without one, with a note. A mismatched item resolved without an entry keeps the posting its reference names.
+//	@Tags			reconciliation
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Item
+//	@Router			/api/v1/reconciliations/{id}/items/{itemId}/resolve [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Settlement file ID"
+//	@Param   itemId  path		int	true	"Settlement item ID"
+//	@Param   resolution  body		Resolution	true	"Resolution"
+//	@Security	AdminToken
+func (h *Handler) ResolveItem(c echo.Context) error {
+	fileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid settlement file ID"})
+	}
+	itemID, err := strconv.ParseInt(c.Param("itemId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid settlement item ID"})
+	}
+	var r Resolution
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	if r.EntryID == nil && r.Note == "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: "A note is required to resolve without an entry"})
+	}
+
+	item, err := h.store.Resolve(fileID, itemID, r, audit.ActorFrom(c))
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+func storeError(c echo.Context, err error) error {
+	var pe *ParseError
+	switch {
+	case errors.As(err, &pe):
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrItemNotFound):
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	case errors.Is(err, ErrDuplicateFile), errors.Is(err, ErrSettled), errors.Is(err, ErrEntryTaken):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
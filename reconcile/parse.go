@@ -0,0 +1,202 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse reads a settlement file in format.
+func Parse(r io.Reader, format string) ([]Line, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatCamt053:
+		return ParseCamt053(r)
+	}
+	return nil, fmt.Errorf("unsupported format %q, use csv or camt053", format)
+}
+
+// FormatOf returns the format of a file by its content type or name.
+func FormatOf(contentType, name string) string {
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"), strings.HasSuffix(strings.ToLower(name), ".csv"):
+		return FormatCSV
+	case strings.Contains(contentType, "xml"), strings.HasSuffix(strings.ToLower(name), ".xml"):
+		return FormatCamt053
+	}
+	return ""
+}
+
+// ParseCSV reads a CSV file with a header naming its columns: date
+// (YYYY-MM-DD) and signed amount are required, reference and description
+// optional.
+func ParseCSV(r io.Reader) ([]Line, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "amount"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("missing %s column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := col[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var lines []Line
+	for n := 2; ; n++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		day, err := time.Parse(time.DateOnly, field(record, "date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q", n, field(record, "date"))
+		}
+		amount, err := strconv.ParseFloat(field(record, "amount"), 64)
+		if err != nil || amount == 0 {
+			return nil, fmt.Errorf("line %d: invalid amount %q", n, field(record, "amount"))
+		}
+		lines = append(lines, Line{
+			LineNo:      n,
+			Reference:   field(record, "reference"),
+			Amount:      amount,
+			BookedOn:    day,
+			Description: field(record, "description"),
+		})
+	}
+	return lines, nil
+}
+
+type camtDocument struct {
+	Statements []struct {
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtEntry struct {
+	Amount      string        `xml:"Amt"`
+	CdtDbtInd   string        `xml:"CdtDbtInd"`
+	Status      camtStatus    `xml:"Sts"`
+	BookedOn    string        `xml:"BookgDt>Dt"`
+	BookedAt    string        `xml:"BookgDt>DtTm"`
+	AcctSvcrRef string        `xml:"AcctSvcrRef"`
+	Info        string        `xml:"AddtlNtryInf"`
+	Details     []camtDetails `xml:"NtryDtls>TxDtls"`
+}
+
+// camtStatus is BOOK in camt.053.001.02 and <Cd>BOOK</Cd> from .001.08 on.
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+type camtDetails struct {
+	EndToEndID  string   `xml:"Refs>EndToEndId"`
+	AcctSvcrRef string   `xml:"Refs>AcctSvcrRef"`
+	Amount      string   `xml:"AmtDtls>TxAmt>Amt"`
+	Unstructed  []string `xml:"RmtInf>Ustrd"`
+}
+
+// ParseCamt053 reads the booked entries of an ISO 20022 camt.053 bank to
+// customer statement. A batch entry gives a line per transaction detail.
+// The reference is the end-to-end id, or the reference of the bank when
+// the end-to-end id is missing.
+func ParseCamt053(r io.Reader) ([]Line, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid camt.053: %w", err)
+	}
+
+	var lines []Line
+	n := 0
+	for _, s := range doc.Statements {
+		for _, e := range s.Entries {
+			n++
+			status := strings.TrimSpace(e.Status.Code + e.Status.Text)
+			if status != "" && status != "BOOK" {
+				continue
+			}
+			day, err := camtDate(e)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", n, err)
+			}
+			sign := 1.0
+			switch e.CdtDbtInd {
+			case "CRDT":
+			case "DBIT":
+				sign = -1
+			default:
+				return nil, fmt.Errorf("entry %d: invalid CdtDbtInd %q", n, e.CdtDbtInd)
+			}
+
+			details := e.Details
+			if len(details) == 0 {
+				details = []camtDetails{{}}
+			}
+			for _, d := range details {
+				raw := d.Amount
+				if raw == "" || len(e.Details) == 1 {
+					raw = e.Amount
+				}
+				amount, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+				if err != nil || amount <= 0 {
+					return nil, fmt.Errorf("entry %d: invalid amount %q", n, raw)
+				}
+				ref := strings.TrimSpace(d.EndToEndID)
+				if ref == "" || ref == "NOTPROVIDED" {
+					ref = strings.TrimSpace(d.AcctSvcrRef)
+				}
+				if ref == "" {
+					ref = strings.TrimSpace(e.AcctSvcrRef)
+				}
+				description := strings.TrimSpace(strings.Join(d.Unstructed, " "))
+				if description == "" {
+					description = strings.TrimSpace(e.Info)
+				}
+				lines = append(lines, Line{
+					LineNo:      len(lines) + 1,
+					Reference:   ref,
+					Amount:      sign * amount,
+					BookedOn:    day,
+					Description: description,
+				})
+			}
+		}
+	}
+	return lines, nil
+}
+
+func camtDate(e camtEntry) (time.Time, error) {
+	if e.BookedOn != "" {
+		return time.Parse(time.DateOnly, strings.TrimSpace(e.BookedOn))
+	}
+	if e.BookedAt != "" {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(e.BookedAt))
+		if err != nil {
+			return t, err
+		}
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Time{}, errors.New("missing booking date")
+}
@@ -0,0 +1,257 @@
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Statuses of an item of a settlement file.
+const (
+	StatusMatched    = "matched"
+	StatusUnmatched  = "unmatched"
+	StatusMismatched = "mismatched"
+	// StatusResolved is an unmatched or mismatched item settled by hand.
+	StatusResolved = "resolved"
+)
+
+// Formats of settlement files.
+const (
+	FormatCSV     = "csv"
+	FormatCamt053 = "camt053"
+)
+
+// Tolerance is how far apart the bank booking date and the posting may
+// be, as banks book a day late around cut-off.
+const Tolerance = 24 * time.Hour
+
+var (
+	ErrNotFound      = errors.New("settlement file not found")
+	ErrItemNotFound  = errors.New("settlement item not found")
+	ErrDuplicateFile = errors.New("settlement file was already imported")
+	// ErrSettled is returned when resolving an item that is already matched
+	// or resolved.
+	ErrSettled = errors.New("settlement item is already matched or resolved")
+	// ErrEntryTaken is returned when resolving against an entry that is not
+	// a settlement posting or that another item already matches.
+	ErrEntryTaken = errors.New("ledger entry is not an open settlement posting")
+)
+
+// Line is a line of a settlement file. Amount is signed from the side of
+// the bank account: money coming in is positive.
+type Line struct {
+	LineNo      int       `json:"line_no" example:"1"`
+	Reference   string    `json:"reference" example:"TX42"`
+	Amount      float64   `json:"amount" example:"250.00"`
+	BookedOn    time.Time `json:"booked_on" example:"2026-09-01T00:00:00Z"`
+	Description string    `json:"description" example:"Top-up John Doe"`
+}
+
+// Candidate is a ledger entry posted to the settlement account that no
+// item matches yet. Amount is the settlement line of the entry, so it has
+// the same sign as the bank line for the same money.
+type Candidate struct {
+	EntryID       int64     `json:"entry_id" example:"17"`
+	TransactionID int64     `json:"transaction_id" example:"42"`
+	WalletID      int       `json:"wallet_id" example:"1"`
+	Amount        float64   `json:"amount" example:"250.00"`
+	PostedAt      time.Time `json:"posted_at" example:"2026-09-01T10:15:00Z"`
+	Description   string    `json:"description" example:"Top-up"`
+}
+
+// Result is the outcome of matching a line.
+type Result struct {
+	Line
+	Status  string `json:"status" example:"matched"`
+	EntryID *int64 `json:"entry_id,omitempty" example:"17"`
+	Reason  string `json:"reason,omitempty" example:"amount 250.00 but posted 205.00"`
+}
+
+// Item is a line of an imported file with its match.
+type Item struct {
+	ID     int64 `json:"id" example:"1"`
+	FileID int64 `json:"file_id" example:"1"`
+	Result
+	Note       string     `json:"note,omitempty" example:"Bank fee, booked by hand"`
+	ResolvedBy *string    `json:"resolved_by,omitempty" example:"ops@example.com"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty" example:"2026-09-02T08:00:00Z"`
+}
+
+type File struct {
+	ID         int64     `json:"id" example:"1"`
+	Name       string    `json:"name" example:"settlement-2026-09-01.xml"`
+	Format     string    `json:"format" example:"camt053"`
+	Checksum   string    `json:"checksum" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	From       time.Time `json:"from" example:"2026-09-01T00:00:00Z"`
+	To         time.Time `json:"to" example:"2026-09-01T00:00:00Z"`
+	Matched    int       `json:"matched" example:"41"`
+	Unmatched  int       `json:"unmatched" example:"1"`
+	Mismatched int       `json:"mismatched" example:"0"`
+	Resolved   int       `json:"resolved" example:"0"`
+	ImportedAt time.Time `json:"imported_at" example:"2026-09-02T06:00:00Z"`
+}
+
+// Report is a file with its items and the settlement postings of its
+// period, within Tolerance, that no item of any file matches.
+type Report struct {
+	File
+	Items            []Item      `json:"items"`
+	UnmatchedEntries []Candidate `json:"unmatched_entries"`
+}
+
+// Clean reports whether every item is matched or resolved and no posting
+// is left over.
+func (r *Report) Clean() bool {
+	return r.Unmatched == 0 && r.Mismatched == 0 && len(r.UnmatchedEntries) == 0
+}
+
+// Resolution settles an item by hand, against a ledger entry or, without
+// one, with a note saying why there is nothing to match.
+type Resolution struct {
+	EntryID *int64 `json:"entry_id,omitempty" example:"17"`
+	Note    string `json:"note" example:"Bank fee, booked by hand"`
+}
+
+// Reference is the reference under which a transaction is sent to the
+// bank, as end-to-end id of payouts and collections.
+func Reference(transactionID int64) string {
+	return fmt.Sprintf("TX%d", transactionID)
+}
+
+// ParseReference returns the transaction id of a reference made by
+// Reference, in any case and with surrounding space.
+func ParseReference(ref string) (int64, bool) {
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+	if !strings.HasPrefix(ref, "TX") {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(ref[2:], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// Period returns the first and last booking day of lines.
+func Period(lines []Line) (from, to time.Time) {
+	for i, l := range lines {
+		if i == 0 || l.BookedOn.Before(from) {
+			from = l.BookedOn
+		}
+		if i == 0 || l.BookedOn.After(to) {
+			to = l.BookedOn
+		}
+	}
+	return from, to
+}
+
+// Match pairs lines with candidates, each candidate with one line at most.
+// Lines with a reference are matched first, to the transaction it names;
+// a wrong amount or date then makes a mismatch rather than a fresh search.
+// The other lines take the unclaimed candidate of the same amount closest
+// in time within Tolerance, the oldest entry on a tie. Results are in the
+// order of lines.
+func Match(lines []Line, candidates []Candidate) []Result {
+	results := make([]Result, len(lines))
+	taken := make(map[int64]bool, len(candidates))
+	byTransaction := make(map[int64]Candidate, len(candidates))
+	for _, c := range candidates {
+		byTransaction[c.TransactionID] = c
+	}
+
+	for i, l := range lines {
+		results[i] = Result{Line: l, Status: StatusUnmatched}
+		id, ok := ParseReference(l.Reference)
+		if !ok {
+			continue
+		}
+		c, ok := byTransaction[id]
+		if !ok || taken[c.EntryID] {
+			results[i].Reason = fmt.Sprintf("no open settlement posting for reference %s", Reference(id))
+			continue
+		}
+		taken[c.EntryID] = true
+		entryID := c.EntryID
+		results[i].EntryID = &entryID
+		results[i].Status = StatusMatched
+		if reason := differ(l, c); reason != "" {
+			results[i].Status, results[i].Reason = StatusMismatched, reason
+		}
+	}
+
+	sorted := append([]Candidate(nil), candidates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].EntryID < sorted[j].EntryID })
+	for i, l := range lines {
+		if _, ok := ParseReference(l.Reference); ok {
+			continue
+		}
+		best := -1
+		for j, c := range sorted {
+			if taken[c.EntryID] || cents(c.Amount) != cents(l.Amount) || gap(l, c) > Tolerance {
+				continue
+			}
+			if best < 0 || gap(l, c) < gap(l, sorted[best]) {
+				best = j
+			}
+		}
+		if best < 0 {
+			results[i].Reason = "no settlement posting of the same amount and date"
+			continue
+		}
+		taken[sorted[best].EntryID] = true
+		entryID := sorted[best].EntryID
+		results[i].EntryID = &entryID
+		results[i].Status = StatusMatched
+	}
+	return results
+}
+
+// differ says how a line and the posting its reference names disagree.
+func differ(l Line, c Candidate) string {
+	var reasons []string
+	if cents(l.Amount) != cents(c.Amount) {
+		reasons = append(reasons, fmt.Sprintf("amount %.2f but posted %.2f", l.Amount, c.Amount))
+	}
+	if gap(l, c) > Tolerance {
+		reasons = append(reasons, fmt.Sprintf("booked %s but posted %s", l.BookedOn.Format(time.DateOnly), c.PostedAt.UTC().Format(time.DateOnly)))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// gap is how far the posting is from the booking day, zero on the day.
+func gap(l Line, c Candidate) time.Duration {
+	day := l.BookedOn.UTC()
+	posted := c.PostedAt.UTC()
+	switch {
+	case posted.Before(day):
+		return day.Sub(posted)
+	case posted.Sub(day) >= 24*time.Hour:
+		return posted.Sub(day) - 24*time.Hour
+	}
+	return 0
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// Count tallies the statuses of items on f.
+func (f *File) Count(items []Item) {
+	f.Matched, f.Unmatched, f.Mismatched, f.Resolved = 0, 0, 0, 0
+	for _, it := range items {
+		switch it.Status {
+		case StatusMatched:
+			f.Matched++
+		case StatusUnmatched:
+			f.Unmatched++
+		case StatusMismatched:
+			f.Mismatched++
+		case StatusResolved:
+			f.Resolved++
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package reconcile
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/labstack/echo/v4"
+)
+
+func day(d int) time.Time {
+	return time.Date(2026, 9, d, 0, 0, 0, 0, time.UTC)
+}
+
+func at(d, hour int) time.Time {
+	return time.Date(2026, 9, d, hour, 0, 0, 0, time.UTC)
+}
+
+func TestParseReference(t *testing.T) {
+	for ref, want := range map[string]int64{"TX42": 42, " tx7 ": 7, "TX": 0, "TX-1": 0, "B0901-1": 0, "": 0} {
+		id, ok := ParseReference(ref)
+		if id != want || ok != (want != 0) {
+			t.Errorf("expected %q to give %d but got %d, %v", ref, want, id, ok)
+		}
+	}
+	if id, _ := ParseReference(Reference(42)); id != 42 {
+		t.Errorf("expected Reference to round trip but got %d", id)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	candidates := []Candidate{
+		{EntryID: 1, TransactionID: 42, Amount: 250, PostedAt: at(1, 10)},
+		{EntryID: 2, TransactionID: 43, Amount: 100, PostedAt: at(1, 11)},
+		{EntryID: 3, TransactionID: 44, Amount: -80, PostedAt: at(1, 9)},
+		{EntryID: 4, TransactionID: 45, Amount: -80, PostedAt: at(1, 15)},
+		{EntryID: 5, TransactionID: 46, Amount: 30, PostedAt: at(5, 12)},
+	}
+	lines := []Line{
+		{LineNo: 1, Reference: "TX42", Amount: 250, BookedOn: day(1)},
+		{LineNo: 2, Reference: "TX43", Amount: 90, BookedOn: day(1)},
+		{LineNo: 3, Amount: -80, BookedOn: day(2)},
+		{LineNo: 4, Amount: -80, BookedOn: day(1)},
+		{LineNo: 5, Amount: 30, BookedOn: day(1)},
+		{LineNo: 6, Reference: "TX99", Amount: 10, BookedOn: day(1)},
+		{LineNo: 7, Amount: -80, BookedOn: day(1)},
+	}
+
+	results := Match(lines, candidates)
+
+	want := []struct {
+		status string
+		entry  int64
+	}{
+		{StatusMatched, 1},
+		{StatusMismatched, 2},
+		{StatusMatched, 4},
+		{StatusMatched, 3},
+		{StatusUnmatched, 0},
+		{StatusUnmatched, 0},
+		{StatusUnmatched, 0},
+	}
+	for i, w := range want {
+		r := results[i]
+		var entry int64
+		if r.EntryID != nil {
+			entry = *r.EntryID
+		}
+		if r.Status != w.status || entry != w.entry {
+			t.Errorf("line %d: expected %s entry %d but got %s entry %d (%s)", r.LineNo, w.status, w.entry, r.Status, entry, r.Reason)
+		}
+	}
+	if results[1].Reason != "amount 90.00 but posted 100.00" {
+		t.Errorf("unexpected mismatch reason %q", results[1].Reason)
+	}
+}
+
+func TestMatchReferenceOutsideTolerance(t *testing.T) {
+	candidates := []Candidate{{EntryID: 1, TransactionID: 42, Amount: 250, PostedAt: at(1, 10)}}
+
+	results := Match([]Line{{LineNo: 1, Reference: "TX42", Amount: 250, BookedOn: day(4)}}, candidates)
+
+	if results[0].Status != StatusMismatched || results[0].Reason != "booked 2026-09-04 but posted 2026-09-01" {
+		t.Errorf("expected a date mismatch but got %s (%s)", results[0].Status, results[0].Reason)
+	}
+}
+
+func TestParseCamt053(t *testing.T) {
+	f, err := os.Open("testdata/settlement.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	lines, err := Parse(f, FormatCamt053)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Line{
+		{LineNo: 1, Reference: "TX42", Amount: 250, BookedOn: day(1), Description: "Top-up John Doe"},
+		{LineNo: 2, Reference: "TX43", Amount: 100, BookedOn: day(1)},
+		{LineNo: 3, Reference: "B0901-2b", Amount: 50, BookedOn: day(1), Description: "Card top-up Jane"},
+		{LineNo: 4, Reference: "B0901-3", Amount: -80, BookedOn: day(1), Description: "Cash withdrawal"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines but got %d: %+v", len(want), len(lines), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("expected %+v but got %+v", want[i], lines[i])
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	f, err := os.Open("testdata/settlement.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	lines, err := Parse(f, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Line{LineNo: 3, Amount: -80, BookedOn: day(1), Description: "Cash withdrawal, branch 12"}
+	if len(lines) != 2 || lines[0].Reference != "TX42" || lines[1] != want {
+		t.Errorf("unexpected lines %+v", lines)
+	}
+
+	for _, content := range []string{"reference,amount\nTX1,10\n", "date,amount\n2026-09-01,abc\n", "date,amount\n01/09/2026,10\n"} {
+		if _, err := Parse(strings.NewReader(content), FormatCSV); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
+
+type StubReconcile struct {
+	imported []Line
+	report   *Report
+	item     *Item
+	err      error
+}
+
+func (s *StubReconcile) ImportFile(f File, lines []Line) (*Report, error) {
+	s.imported = lines
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Report{File: f}, nil
+}
+
+func (s *StubReconcile) Files() ([]File, error) {
+	return []File{}, s.err
+}
+
+func (s *StubReconcile) Report(id int64) (*Report, error) {
+	return s.report, s.err
+}
+
+func (s *StubReconcile) Resolve(fileID, itemID int64, r Resolution, actor audit.Actor) (*Item, error) {
+	return s.item, s.err
+}
+
+func request(method, target, contentType, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, contentType)
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(req, rec), rec
+}
+
+func TestImportFile(t *testing.T) {
+	csv := "date,reference,amount\n2026-09-01,TX42,250.00\n"
+
+	t.Run("given a csv file should match its lines", func(t *testing.T) {
+		stub := &StubReconcile{}
+		c, rec := request(http.MethodPost, "/?name=sept.csv", "text/csv", csv)
+
+		New(stub).ImportFile(c)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d but got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+		}
+		if len(stub.imported) != 1 || !strings.Contains(rec.Body.String(), `"checksum":"`) {
+			t.Errorf("unexpected import %+v: %s", stub.imported, rec.Body.String())
+		}
+	})
+
+	t.Run("given an unreadable or empty file should return 400", func(t *testing.T) {
+		for _, tc := range []struct{ target, contentType, body string }{
+			{"/?format=camt053", "application/octet-stream", "<Document>"},
+			{"/", "text/csv", "date,amount\n"},
+			{"/", "application/octet-stream", csv},
+		} {
+			c, rec := request(http.MethodPost, tc.target, tc.contentType, tc.body)
+
+			New(&StubReconcile{}).ImportFile(c)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status code %d for %q but got %d", http.StatusBadRequest, tc.body, rec.Code)
+			}
+		}
+	})
+
+	t.Run("given a file imported before should return 409", func(t *testing.T) {
+		c, rec := request(http.MethodPost, "/", "text/csv", csv)
+
+		New(&StubReconcile{err: ErrDuplicateFile}).ImportFile(c)
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected status code %d but got %d", http.StatusConflict, rec.Code)
+		}
+	})
+}
+
+func TestGetReport(t *testing.T) {
+	c, rec := request(http.MethodGet, "/", "", "")
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+
+	New(&StubReconcile{err: ErrNotFound}).GetReport(c)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestResolveItem(t *testing.T) {
+	resolve := func(stub *StubReconcile, body string) *httptest.ResponseRecorder {
+		c, rec := request(http.MethodPost, "/", echo.MIMEApplicationJSON, body)
+		c.SetParamNames("id", "itemId")
+		c.SetParamValues("1", "3")
+		New(stub).ResolveItem(c)
+		return rec
+	}
+
+	t.Run("given an entry should resolve the item", func(t *testing.T) {
+		rec := resolve(&StubReconcile{item: &Item{ID: 3, Result: Result{Status: StatusResolved}}}, `{"entry_id": 17}`)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("given neither entry nor note should return 400", func(t *testing.T) {
+		rec := resolve(&StubReconcile{}, `{}`)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given a settled item or taken entry should return 409", func(t *testing.T) {
+		for _, err := range []error{ErrSettled, ErrEntryTaken} {
+			rec := resolve(&StubReconcile{err: err}, `{"entry_id": 17}`)
+
+			if rec.Code != http.StatusConflict {
+				t.Errorf("expected status code %d for %v but got %d", http.StatusConflict, err, rec.Code)
+			}
+		}
+	})
+}
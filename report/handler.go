@@ -0,0 +1,42 @@
+package report
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	BalanceReport() ([]BalanceRow, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// Balances
+//
+//	@Summary		Get balance report
+//	@Description	Get wallet count and total balance grouped by wallet type and creation month
+//	@Tags			report
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		BalanceRow
+//	@Router			/api/v1/reports/balances [get]
+//	@Failure		500	{object}	Err
+//	@Security	AdminToken
+func (h *Handler) Balances(c echo.Context) error {
+	rows, err := h.store.BalanceReport()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, rows)
+}
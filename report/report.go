@@ -0,0 +1,8 @@
+package report
+
+type BalanceRow struct {
+	WalletType   string  `json:"wallet_type" example:"Savings"`
+	Month        string  `json:"month" example:"2024-03"`
+	WalletCount  int     `json:"wallet_count" example:"2"`
+	TotalBalance float64 `json:"total_balance" example:"3000.00"`
+}
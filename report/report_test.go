@@ -0,0 +1,57 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StubReportHandler struct {
+	rows []BalanceRow
+	err  error
+}
+
+func (s *StubReportHandler) BalanceReport() ([]BalanceRow, error) {
+	return s.rows, s.err
+}
+
+func TestReport(t *testing.T) {
+
+	t.Run("given balances should return report rows", func(t *testing.T) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		want := []BalanceRow{
+			{WalletType: "Savings", Month: "2024-03", WalletCount: 2, TotalBalance: 3000},
+			{WalletType: "Credit Card", Month: "2024-03", WalletCount: 2, TotalBalance: 1500},
+		}
+
+		New(&StubReportHandler{rows: want}).Balances(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+		var got []BalanceRow
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected rows %v but got %v", want, got)
+		}
+	})
+
+	t.Run("given store error should return 500", func(t *testing.T) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+		New(&StubReportHandler{err: errors.New("failed to get balance report")}).Balances(c)
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status code %d but got %d", http.StatusInternalServerError, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/walletpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type StubWallet struct {
+	wallets []wallet.Wallet
+	actor   audit.Actor
+	err     error
+}
+
+func (s *StubWallet) Wallets(walletType string) ([]wallet.Wallet, error) {
+	return s.wallets, s.err
+}
+
+func (s *StubWallet) WalletTypes() ([]wallet.Type, error) {
+	return []wallet.Type{{Key: "Savings", Name: "Savings"}, {Key: "CreditCard", Name: "Credit Card", AllowNegative: true}}, nil
+}
+
+func (s *StubWallet) CreateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	w.ID, w.Status, s.actor = 7, wallet.StatusActive, actor
+	return &w, s.err
+}
+
+func (s *StubWallet) UpdateWallet(w wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &w, nil
+}
+
+func (s *StubWallet) DeleteWallet(id int, actor audit.Actor) error {
+	return s.err
+}
+
+func (s *StubWallet) WalletsByUserID(userId int) ([]wallet.Wallet, error) {
+	return s.wallets, s.err
+}
+
+func (s *StubWallet) WalletsByIDs(ids []int) ([]wallet.Wallet, error) {
+	var found []wallet.Wallet
+	for _, w := range s.wallets {
+		if w.ID == ids[0] {
+			found = append(found, w)
+		}
+	}
+	return found, s.err
+}
+
+// dial serves stub in memory and returns a client of it.
+func dial(t *testing.T, stub *StubWallet) walletpb.WalletServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewGRPCServer(New(stub))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return walletpb.NewWalletServiceClient(conn)
+}
+
+func savings() []wallet.Wallet {
+	return []wallet.Wallet{{ID: 1, UserID: 1, UserName: "John Doe", WalletName: "John Savings", WalletType: "Savings",
+		Balance: 100, AvailableBalance: 75, Status: wallet.StatusActive, CreatedAt: time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)}}
+}
+
+func TestGetWallet(t *testing.T) {
+	client := dial(t, &StubWallet{wallets: savings()})
+
+	t.Run("given a wallet id should return the wallet", func(t *testing.T) {
+		res, err := client.GetWallet(context.Background(), &walletpb.GetWalletRequest{Id: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := res.GetWallet()
+		if w.GetWalletName() != "John Savings" || w.GetAvailableBalance() != 75 || !w.GetCreatedAt().AsTime().Equal(savings()[0].CreatedAt) {
+			t.Errorf("unexpected wallet %v", w)
+		}
+	})
+
+	t.Run("given an unknown id should return NOT_FOUND", func(t *testing.T) {
+		_, err := client.GetWallet(context.Background(), &walletpb.GetWalletRequest{Id: 9})
+
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("expected NotFound but got %v", err)
+		}
+	})
+}
+
+func TestListWallets(t *testing.T) {
+	client := dial(t, &StubWallet{wallets: savings()})
+
+	res, err := client.ListWallets(context.Background(), &walletpb.ListWalletsRequest{WalletType: "Savings"})
+	if err != nil || len(res.GetWallets()) != 1 {
+		t.Errorf("unexpected response %v, %v", res, err)
+	}
+
+	_, err = client.ListWallets(context.Background(), &walletpb.ListWalletsRequest{WalletType: "Gold"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument but got %v", err)
+	}
+}
+
+func TestCreateWallet(t *testing.T) {
+	t.Run("given a valid wallet should create it as anonymous claiming x-actor", func(t *testing.T) {
+		stub := &StubWallet{}
+		client := dial(t, stub)
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-actor", "admin")
+
+		res, err := client.CreateWallet(ctx, &walletpb.CreateWalletRequest{Wallet: &walletpb.WalletInput{
+			UserId: 2, UserName: "Jane Doe", WalletName: "Jane Card", WalletType: "CreditCard", Balance: -10,
+		}})
+
+		if err != nil || res.GetWallet().GetId() != 7 || res.GetWallet().GetWalletType() != "Credit Card" {
+			t.Fatalf("unexpected response %v, %v", res, err)
+		}
+		if stub.actor.ID != audit.AnonymousActor || stub.actor.Claimed != "admin" || stub.actor.IP == "" {
+			t.Errorf("unexpected actor %+v", stub.actor)
+		}
+	})
+
+	t.Run("given a balance the type forbids should return INVALID_ARGUMENT", func(t *testing.T) {
+		client := dial(t, &StubWallet{})
+
+		_, err := client.CreateWallet(context.Background(), &walletpb.CreateWalletRequest{Wallet: &walletpb.WalletInput{
+			UserId: 2, UserName: "Jane Doe", WalletName: "Jane", WalletType: "Savings", Balance: -10,
+		}})
+
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument but got %v", err)
+		}
+	})
+}
+
+func TestUpdateWallet(t *testing.T) {
+	client := dial(t, &StubWallet{err: &wallet.StatusError{WalletID: 1, Status: wallet.StatusFrozen}})
+
+	_, err := client.UpdateWallet(context.Background(), &walletpb.UpdateWalletRequest{Id: 1, Wallet: &walletpb.WalletInput{
+		UserId: 1, UserName: "John Doe", WalletName: "John", WalletType: "Savings", Balance: 5,
+	}})
+
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition || len(st.Details()) != 1 {
+		t.Fatalf("expected FailedPrecondition with details but got %v", err)
+	}
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	if !ok || info.GetReason() != wallet.CodeWalletFrozen || info.GetDomain() != ErrorDomain {
+		t.Errorf("unexpected details %v", st.Details())
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{&limit.Error{Code: limit.CodeLimitExceeded, Message: "Daily outflow limit reached"}, codes.FailedPrecondition, limit.CodeLimitExceeded},
+		{creditcard.ErrNotCreditCard, codes.FailedPrecondition, ReasonNotCreditCard},
+		{creditcard.ErrCreditLimitExceeded, codes.FailedPrecondition, ReasonCreditLimitExceeded},
+		{creditcard.ErrOverpayment, codes.FailedPrecondition, ReasonOverpayment},
+		{creditcard.ErrNotFound, codes.NotFound, ""},
+		{errors.New("connection refused"), codes.Internal, ""},
+	}
+	for _, tt := range tests {
+		st := status.Convert(statusError(tt.err))
+		if st.Code() != tt.code || st.Message() != tt.err.Error() {
+			t.Errorf("%v: expected %v but got %v", tt.err, tt.code, st)
+			continue
+		}
+		if tt.reason == "" {
+			continue
+		}
+		if len(st.Details()) != 1 {
+			t.Errorf("%v: expected details but got none", tt.err)
+			continue
+		}
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		if !ok || info.GetReason() != tt.reason || info.GetDomain() != ErrorDomain {
+			t.Errorf("%v: unexpected details %v", tt.err, st.Details())
+		}
+	}
+}
+
+func TestDeleteWallet(t *testing.T) {
+	client := dial(t, &StubWallet{err: wallet.ErrWalletNotFound})
+
+	_, err := client.DeleteWallet(context.Background(), &walletpb.DeleteWalletRequest{Id: 9})
+
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound but got %v", err)
+	}
+}
+
+func TestServe(t *testing.T) {
+	t.Run("should return nil once ctx is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- Serve(ctx, "127.0.0.1:0", &StubWallet{}) }()
+
+		cancel()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("expected nil but got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected Serve to return")
+		}
+	})
+
+	t.Run("given an address in use should return the error", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer lis.Close()
+
+		if err := Serve(context.Background(), lis.Addr().String(), &StubWallet{}); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestReflection(t *testing.T) {
+	info := NewGRPCServer(New(&StubWallet{})).GetServiceInfo()
+
+	for _, name := range []string{"wallet.v1.WalletService", "grpc.reflection.v1.ServerReflection"} {
+		if _, ok := info[name]; !ok {
+			t.Errorf("expected %s to be registered", name)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// Package rpc serves WalletService of proto/wallet/v1 over gRPC.
+package rpc
+
+//go:generate sh -c "cd .. && buf generate"
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/walletpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ErrorDomain is the domain of the ErrorInfo of errors.
+const ErrorDomain = "wallet"
+
+type Server struct {
+	walletpb.UnimplementedWalletServiceServer
+	store Storer
+}
+
+// Storer is the part of wallet.Storer and user.Storer the service uses,
+// and WalletsByIDs to read one wallet.
+type Storer interface {
+	Wallets(walletType string) ([]wallet.Wallet, error)
+	WalletTypes() ([]wallet.Type, error)
+	CreateWallet(wallet wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error)
+	UpdateWallet(wallet wallet.Wallet, actor audit.Actor) (*wallet.Wallet, error)
+	DeleteWallet(id int, actor audit.Actor) error
+	WalletsByUserID(userId int) ([]wallet.Wallet, error)
+	WalletsByIDs(ids []int) ([]wallet.Wallet, error)
+}
+
+func New(db Storer) *Server {
+	return &Server{store: db}
+}
+
+// NewGRPCServer returns a gRPC server with s and server reflection, so
+// tools like grpcurl can list and call the service.
+func NewGRPCServer(s *Server, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	walletpb.RegisterWalletServiceServer(srv, s)
+	reflection.Register(srv)
+	return srv
+}
+
+// Serve serves the service over db on addr until ctx is done, when it
+// stops gracefully and returns nil, or until the listener fails.
+func Serve(ctx context.Context, addr string, db Storer) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := NewGRPCServer(New(db))
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+	err = srv.Serve(lis)
+	if ctx.Err() != nil {
+		<-stopped
+		return nil
+	}
+	srv.Stop()
+	return err
+}
+
+func (s *Server) ListWallets(ctx context.Context, req *walletpb.ListWalletsRequest) (*walletpb.ListWalletsResponse, error) {
+	walletType := req.GetWalletType()
+	if walletType != "" {
+		t, err := s.resolveType(walletType)
+		if err != nil {
+			return nil, statusError(err)
+		}
+		walletType = t.Name
+	}
+	wallets, err := s.store.Wallets(walletType)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &walletpb.ListWalletsResponse{Wallets: toProtos(wallets)}, nil
+}
+
+func (s *Server) GetWallet(ctx context.Context, req *walletpb.GetWalletRequest) (*walletpb.GetWalletResponse, error) {
+	wallets, err := s.store.WalletsByIDs([]int{int(req.GetId())})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if len(wallets) == 0 {
+		return nil, statusError(wallet.ErrWalletNotFound)
+	}
+	return &walletpb.GetWalletResponse{Wallet: toProto(wallets[0])}, nil
+}
+
+func (s *Server) CreateWallet(ctx context.Context, req *walletpb.CreateWalletRequest) (*walletpb.CreateWalletResponse, error) {
+	w, err := s.fromInput(req.GetWallet(), true)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	created, err := s.store.CreateWallet(w, actorFrom(ctx))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &walletpb.CreateWalletResponse{Wallet: toProto(*created)}, nil
+}
+
+func (s *Server) UpdateWallet(ctx context.Context, req *walletpb.UpdateWalletRequest) (*walletpb.UpdateWalletResponse, error) {
+	w, err := s.fromInput(req.GetWallet(), false)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	w.ID = int(req.GetId())
+	updated, err := s.store.UpdateWallet(w, actorFrom(ctx))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &walletpb.UpdateWalletResponse{Wallet: toProto(*updated)}, nil
+}
+
+func (s *Server) DeleteWallet(ctx context.Context, req *walletpb.DeleteWalletRequest) (*walletpb.DeleteWalletResponse, error) {
+	if err := s.store.DeleteWallet(int(req.GetId()), actorFrom(ctx)); err != nil {
+		return nil, statusError(err)
+	}
+	return &walletpb.DeleteWalletResponse{}, nil
+}
+
+func (s *Server) ListUserWallets(ctx context.Context, req *walletpb.ListUserWalletsRequest) (*walletpb.ListUserWalletsResponse, error) {
+	wallets, err := s.store.WalletsByUserID(int(req.GetUserId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &walletpb.ListUserWalletsResponse{Wallets: toProtos(wallets)}, nil
+}
+
+func (s *Server) resolveType(name string) (wallet.Type, error) {
+	types, err := s.store.WalletTypes()
+	if err != nil {
+		return wallet.Type{}, err
+	}
+	t, ok := wallet.ResolveType(types, name)
+	if !ok {
+		return wallet.Type{}, wallet.ErrTypeNotFound
+	}
+	return t, nil
+}
+
+// inputError is a request the REST API answers with 400.
+type inputError struct {
+	err error
+}
+
+func (e *inputError) Error() string {
+	return e.err.Error()
+}
+
+// fromInput checks in the way the REST API checks a wallet body.
+func (s *Server) fromInput(in *walletpb.WalletInput, create bool) (wallet.Wallet, error) {
+	if in == nil {
+		return wallet.Wallet{}, &inputError{errors.New("wallet is required")}
+	}
+	w := wallet.Wallet{
+		UserID:     int(in.GetUserId()),
+		UserName:   in.GetUserName(),
+		WalletName: in.GetWalletName(),
+		Balance:    in.GetBalance(),
+	}
+	t, err := s.resolveType(in.GetWalletType())
+	if err != nil {
+		return w, err
+	}
+	w.WalletType = t.Name
+	if err := t.CheckBalance(w.Balance); err != nil {
+		return w, err
+	}
+	if c := in.GetCrypto(); c != nil {
+		w.Crypto = &wallet.Crypto{Asset: c.GetAsset(), Address: c.GetAddress(), Amount: wallet.Amount(c.GetAmount())}
+	}
+	if err := wallet.ValidateCrypto(&w, create); err != nil {
+		return w, &inputError{err}
+	}
+	return w, nil
+}
+
+// Reasons of the ErrorInfo of credit card errors, which have no code of
+// their own.
+const (
+	ReasonNotCreditCard       = "not_credit_card"
+	ReasonCreditLimitExceeded = "credit_limit_exceeded"
+	ReasonOverpayment         = "overpayment"
+)
+
+// statusError maps err to the status code of the failure.
+func statusError(err error) error {
+	var ie *inputError
+	var be *wallet.BalanceError
+	var se *wallet.StatusError
+	var le *limit.Error
+	switch {
+	case errors.As(err, &ie), errors.As(err, &be), errors.Is(err, wallet.ErrMissingCrypto):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, wallet.ErrTypeNotFound):
+		return status.Error(codes.InvalidArgument, "Invalid wallet type")
+	case errors.Is(err, wallet.ErrWalletNotFound), errors.Is(err, creditcard.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &se):
+		return failedPrecondition(err, se.Code())
+	case errors.As(err, &le):
+		return failedPrecondition(err, le.Code)
+	case errors.Is(err, creditcard.ErrNotCreditCard):
+		return failedPrecondition(err, ReasonNotCreditCard)
+	case errors.Is(err, creditcard.ErrCreditLimitExceeded):
+		return failedPrecondition(err, ReasonCreditLimitExceeded)
+	case errors.Is(err, creditcard.ErrOverpayment):
+		return failedPrecondition(err, ReasonOverpayment)
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// failedPrecondition is a FailedPrecondition status whose ErrorInfo gives
+// reason, for clients to branch on.
+func failedPrecondition(err error, reason string) error {
+	st, detailErr := status.New(codes.FailedPrecondition, err.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: ErrorDomain,
+	})
+	if detailErr != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return st.Err()
+}
+
+// actorFrom builds the actor of a call from the x-actor and x-request-id
+// metadata, as audit.ActorFrom does from headers. Nothing authenticates a
+// call, so its actor is anonymous and x-actor only what it claimed.
+func actorFrom(ctx context.Context) audit.Actor {
+	actor := audit.Actor{ID: audit.AnonymousActor}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(audit.HeaderActor); len(v) > 0 {
+			actor.Claimed = v[0]
+		}
+		if v := md.Get("x-request-id"); len(v) > 0 {
+			actor.RequestID = v[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			actor.IP = host
+		} else {
+			actor.IP = p.Addr.String()
+		}
+	}
+	return actor
+}
+
+func toProto(w wallet.Wallet) *walletpb.Wallet {
+	pw := &walletpb.Wallet{
+		Id:               int64(w.ID),
+		UserId:           int64(w.UserID),
+		UserName:         w.UserName,
+		WalletName:       w.WalletName,
+		WalletType:       w.WalletType,
+		Balance:          w.Balance,
+		AvailableBalance: w.AvailableBalance,
+		CreatedAt:        timestamppb.New(w.CreatedAt),
+		Status:           w.Status,
+	}
+	if c := w.Crypto; c != nil {
+		pw.Crypto = &walletpb.Crypto{Asset: c.Asset, Address: c.Address, Decimals: int32(c.Decimals), Amount: string(c.Amount)}
+	}
+	return pw
+}
+
+func toProtos(wallets []wallet.Wallet) []*walletpb.Wallet {
+	out := make([]*walletpb.Wallet, len(wallets))
+	for i, w := range wallets {
+		out[i] = toProto(w)
+	}
+	return out
+}
@@ -0,0 +1,340 @@
+package schedule
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+	now   func() time.Time
+}
+
+type Storer interface {
+	CreateSchedule(s Schedule) (*Schedule, error)
+	Schedule(id int64) (*Schedule, error)
+	WalletSchedules(walletID int) ([]Schedule, error)
+	// UpdateSchedule saves s unless it changed since s.UpdatedAt, in which
+	// case it returns ErrConflict.
+	UpdateSchedule(s Schedule) (*Schedule, error)
+	DeleteSchedule(id int64) error
+	Executions(scheduleID int64) ([]Execution, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db, now: time.Now}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// CreateSchedule
+//
+//	@Summary		Create scheduled transfer
+//	@Description	Create a standing order repeating a transfer on a cron expression ("0 9 1 * *") or an RRULE ("FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9"), in UTC
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	Schedule
+//	@Router			/api/v1/scheduled-transfers [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		422	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   schedule  body		Request	true	"Scheduled transfer"
+func (h *Handler) CreateSchedule(c echo.Context) error {
+	var r Request
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	now := h.now().UTC()
+	s, msg := r.schedule(Schedule{Status: StatusActive}, now)
+	if msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	created, err := h.store.CreateSchedule(s.Start(now))
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// GetSchedule
+//
+//	@Summary		Get scheduled transfer
+//	@Description	Get a scheduled transfer and its next run
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Schedule
+//	@Router			/api/v1/scheduled-transfers/{id} [get]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Scheduled transfer id"
+func (h *Handler) GetSchedule(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid scheduled transfer id"})
+	}
+
+	s, err := h.store.Schedule(id)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, s)
+}
+
+// GetWalletSchedules
+//
+//	@Summary		Get scheduled transfers of a wallet
+//	@Description	Get the scheduled transfers from or to a wallet
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Schedule
+//	@Router			/api/v1/wallets/{id}/scheduled-transfers [get]
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+func (h *Handler) GetWalletSchedules(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+
+	schedules, err := h.store.WalletSchedules(walletId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, schedules)
+}
+
+// UpdateSchedule
+//
+//	@Summary		Update scheduled transfer
+//	@Description	Replace a scheduled transfer. Its next run is computed again from now; a paused schedule stays paused.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Schedule
+//	@Router			/api/v1/scheduled-transfers/{id} [put]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err
+//	@Failure		422	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Scheduled transfer id"
+//	@Param   schedule  body		Request	true	"Scheduled transfer"
+func (h *Handler) UpdateSchedule(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid scheduled transfer id"})
+	}
+	var r Request
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+
+	before, err := h.store.Schedule(id)
+	if err != nil {
+		return storeError(c, err)
+	}
+	now := h.now().UTC()
+	s, msg := r.schedule(*before, now)
+	if msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+	if s.Status != StatusPaused {
+		s = s.Start(now)
+	}
+
+	updated, err := h.store.UpdateSchedule(s)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// PauseSchedule
+//
+//	@Summary		Pause scheduled transfer
+//	@Description	Stop running a scheduled transfer until it is resumed
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Schedule
+//	@Router			/api/v1/scheduled-transfers/{id}/pause [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Scheduled transfer id"
+func (h *Handler) PauseSchedule(c echo.Context) error {
+	return h.changeStatus(c, StatusActive, func(s Schedule) Schedule {
+		s.Status, s.OccurrenceAt, s.NextRunAt, s.Retries = StatusPaused, nil, nil, 0
+		return s
+	})
+}
+
+// ResumeSchedule
+//
+//	@Summary		Resume scheduled transfer
+//	@Description	Run a paused scheduled transfer again from its next occurrence. Occurrences missed while paused are not made up.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Schedule
+//	@Router			/api/v1/scheduled-transfers/{id}/resume [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Scheduled transfer id"
+func (h *Handler) ResumeSchedule(c echo.Context) error {
+	return h.changeStatus(c, StatusPaused, func(s Schedule) Schedule {
+		s.Status = StatusActive
+		return s.Start(h.now().UTC())
+	})
+}
+
+func (h *Handler) changeStatus(c echo.Context, from string, change func(Schedule) Schedule) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid scheduled transfer id"})
+	}
+
+	s, err := h.store.Schedule(id)
+	if err != nil {
+		return storeError(c, err)
+	}
+	if s.Status != from {
+		return c.JSON(http.StatusConflict, Err{Message: "Scheduled transfer is " + s.Status})
+	}
+
+	updated, err := h.store.UpdateSchedule(change(*s))
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteSchedule
+//
+//	@Summary		Delete scheduled transfer
+//	@Description	Delete a scheduled transfer and its execution history. Transfers it made are kept.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Router			/api/v1/scheduled-transfers/{id} [delete]
+//	@Success		204
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Scheduled transfer id"
+func (h *Handler) DeleteSchedule(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid scheduled transfer id"})
+	}
+
+	if err := h.store.DeleteSchedule(id); err != nil {
+		return storeError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetExecutions
+//
+//	@Summary		Get execution history
+//	@Description	Get every run of a scheduled transfer, newest first
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Execution
+//	@Router			/api/v1/scheduled-transfers/{id}/executions [get]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Scheduled transfer id"
+func (h *Handler) GetExecutions(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid scheduled transfer id"})
+	}
+
+	executions, err := h.store.Executions(id)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, executions)
+}
+
+// schedule applies r to s, returning a message when r is invalid.
+func (r Request) schedule(s Schedule, now time.Time) (Schedule, string) {
+	start := now.Truncate(time.Second)
+	if r.StartAt != nil {
+		start = r.StartAt.UTC()
+	}
+	amount := math.Round(r.Amount*100) / 100
+
+	switch {
+	case r.FromWalletID <= 0 || r.ToWalletID <= 0:
+		return s, "Invalid wallet id"
+	case r.FromWalletID == r.ToWalletID:
+		return s, "Cannot transfer to the same wallet"
+	case amount <= 0 || math.IsInf(amount, 0):
+		return s, "Invalid amount"
+	case r.EndAt != nil && !r.EndAt.After(start):
+		return s, "end_at must be after start_at"
+	}
+	if _, err := ParseRecurrence(r.Recurrence, start); err != nil {
+		return s, "Invalid recurrence: " + err.Error()
+	}
+
+	switch r.OnInsufficientFunds {
+	case "":
+		r.OnInsufficientFunds = PolicySkip
+	case PolicySkip, PolicyRetry:
+	default:
+		return s, "Invalid on_insufficient_funds, must be skip or retry"
+	}
+	if r.MaxRetries < 0 || r.MaxRetries > MaxRetries {
+		return s, "max_retries must be between 0 and " + strconv.Itoa(MaxRetries)
+	}
+	if r.OnInsufficientFunds == PolicyRetry {
+		if d, err := time.ParseDuration(r.RetryDelay); err != nil || d < time.Minute {
+			return s, "retry_delay must be a duration of at least 1m"
+		}
+	}
+
+	s.FromWalletID, s.ToWalletID, s.Amount, s.Description = r.FromWalletID, r.ToWalletID, amount, r.Description
+	s.Recurrence, s.StartAt, s.EndAt = r.Recurrence, start, r.EndAt
+	if s.EndAt != nil {
+		end := s.EndAt.UTC()
+		s.EndAt = &end
+	}
+	s.OnInsufficientFunds, s.MaxRetries, s.RetryDelay = r.OnInsufficientFunds, r.MaxRetries, r.RetryDelay
+	return s, ""
+}
+
+func storeError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, wallet.ErrWalletNotFound):
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	case errors.Is(err, ErrConflict):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error()})
+	case errors.Is(err, transaction.ErrCreditCard):
+		return c.JSON(http.StatusUnprocessableEntity, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
@@ -0,0 +1,416 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence generates the occurrences of a schedule, in UTC.
+type Recurrence interface {
+	// Next returns the first occurrence strictly after t, or the zero time
+	// when there is none.
+	Next(t time.Time) time.Time
+}
+
+// ParseRecurrence parses either a five field cron expression
+// ("0 9 1 * *", or a macro such as "@monthly") or an iCalendar RRULE
+// ("FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9"). An RRULE starts at start, which
+// also gives the time of day of its occurrences unless BYHOUR or BYMINUTE
+// say otherwise.
+func ParseRecurrence(expr string, start time.Time) (Recurrence, error) {
+	expr = strings.TrimSpace(expr)
+	if rule, ok := strings.CutPrefix(strings.ToUpper(expr), "RRULE:"); ok || strings.Contains(strings.ToUpper(expr), "FREQ=") {
+		if !ok {
+			rule = strings.ToUpper(expr)
+		}
+		return parseRRule(rule, start)
+	}
+	return parseCron(expr)
+}
+
+// cron is a standard five field cron expression: minute, hour, day of
+// month, month and day of week, each a set of allowed values.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// When both day fields are restricted a day matching either one is
+	// an occurrence, as in Vixie cron.
+	domStar, dowStar bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	dayNames   = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+)
+
+func parseCron(expr string) (*cron, error) {
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.New("cron expression must have 5 fields")
+	}
+
+	var c cron
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	// 7 is Sunday as well.
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	c.domStar = fields[2] == "*" || fields[2] == "?"
+	c.dowStar = fields[4] == "*" || fields[4] == "?"
+	return &c, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges ("1-5")
+// and steps ("*/15", "1-31/2") into a bit set.
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	value := func(s string) (int, error) {
+		if n, ok := names[strings.ToUpper(s)]; ok {
+			return n, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("invalid value %q", s)
+		}
+		return n, nil
+	}
+
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*" || rng == "?":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = value(b); err != nil {
+				return 0, err
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			n, err := value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo = n
+			if !hasStep {
+				hi = n
+			}
+		}
+		for n := lo; n <= hi; n += step {
+			bits |= 1 << n
+		}
+	}
+	return bits, nil
+}
+
+func has(bits uint64, n int) bool {
+	return bits&(1<<n) != 0
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+	dom, dow := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// cronHorizon bounds the search for expressions that never match, such as
+// the 30th of February.
+const cronHorizon = 5
+
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronHorizon, 0, 0)
+	for t.Before(limit) {
+		if !has(c.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !has(c.hour, t.Hour()) {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// Frequencies of an RRULE.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+// rrule is the subset of RFC 5545 recurrence rules a standing order needs:
+// FREQ (DAILY, WEEKLY or MONTHLY), INTERVAL, COUNT, UNTIL, BYDAY,
+// BYMONTHDAY (negative counts from the end of the month), BYHOUR and
+// BYMINUTE. Weeks start on Monday.
+type rrule struct {
+	start      time.Time
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []time.Weekday
+	byMonthDay []int
+	byHour     []int
+	byMinute   []int
+}
+
+var rruleDays = map[string]time.Weekday{"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday}
+
+func parseRRule(rule string, start time.Time) (*rrule, error) {
+	r := rrule{start: start.UTC().Truncate(time.Second), interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		var err error
+		switch key {
+		case "FREQ":
+			if val != FreqDaily && val != FreqWeekly && val != FreqMonthly {
+				return nil, fmt.Errorf("unsupported FREQ %q", val)
+			}
+			r.freq = val
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(val)
+			if err != nil || r.interval <= 0 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(val)
+			if err != nil || r.count <= 0 {
+				return nil, fmt.Errorf("invalid COUNT %q", val)
+			}
+		case "UNTIL":
+			r.until, err = parseUntil(val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", val)
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, ok := rruleDays[d]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY %q", d)
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "BYMONTHDAY":
+			if r.byMonthDay, err = parseInts(val, -31, 31); err != nil {
+				return nil, fmt.Errorf("invalid BYMONTHDAY: %w", err)
+			}
+		case "BYHOUR":
+			if r.byHour, err = parseInts(val, 0, 23); err != nil {
+				return nil, fmt.Errorf("invalid BYHOUR: %w", err)
+			}
+		case "BYMINUTE":
+			if r.byMinute, err = parseInts(val, 0, 59); err != nil {
+				return nil, fmt.Errorf("invalid BYMINUTE: %w", err)
+			}
+		case "WKST":
+			if val != "MO" {
+				return nil, errors.New("only WKST=MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+	if r.freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return nil, errors.New("COUNT and UNTIL cannot both be set")
+	}
+	if len(r.byHour) == 0 {
+		r.byHour = []int{r.start.Hour()}
+	}
+	if len(r.byMinute) == 0 {
+		r.byMinute = []int{r.start.Minute()}
+	}
+	sort.Ints(r.byHour)
+	sort.Ints(r.byMinute)
+	return &r, nil
+}
+
+func parseUntil(s string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("20060102", s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// A date only UNTIL includes the whole day.
+	return t.AddDate(0, 0, 1).Add(-time.Second), nil
+}
+
+func parseInts(s string, min, max int) ([]int, error) {
+	var ns []int
+	for _, v := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < min || n > max || n == 0 && min < 0 {
+			return nil, fmt.Errorf("invalid value %q", v)
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+// rrulePeriods bounds how many periods are searched, for rules that never
+// match such as BYMONTHDAY=31 with BYDAY=MO in a weekly rule.
+const rrulePeriods = 100000
+
+func (r *rrule) Next(t time.Time) time.Time {
+	seen := 0
+	for period := 0; period < rrulePeriods; period++ {
+		for _, occ := range r.occurrences(period) {
+			if occ.Before(r.start) {
+				continue
+			}
+			if !r.until.IsZero() && occ.After(r.until) {
+				return time.Time{}
+			}
+			seen++
+			if r.count > 0 && seen > r.count {
+				return time.Time{}
+			}
+			if occ.After(t) {
+				return occ
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// occurrences returns the occurrences in the nth period of the rule, in
+// order.
+func (r *rrule) occurrences(n int) []time.Time {
+	y, m, d := r.start.Date()
+	var days []time.Time
+	switch r.freq {
+	case FreqDaily:
+		day := time.Date(y, m, d+n*r.interval, 0, 0, 0, 0, time.UTC)
+		if r.dayMatches(day) {
+			days = append(days, day)
+		}
+	case FreqWeekly:
+		monday := time.Date(y, m, d-(int(r.start.Weekday())+6)%7+7*n*r.interval, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 7; i++ {
+			day := monday.AddDate(0, 0, i)
+			if len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+				if day.Weekday() == r.start.Weekday() {
+					days = append(days, day)
+				}
+			} else if r.dayMatches(day) {
+				days = append(days, day)
+			}
+		}
+	case FreqMonthly:
+		first := time.Date(y, m+time.Month(n*r.interval), 1, 0, 0, 0, 0, time.UTC)
+		for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+			if len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+				if day.Day() == d {
+					days = append(days, day)
+				}
+			} else if r.dayMatches(day) {
+				days = append(days, day)
+			}
+		}
+	}
+
+	var occs []time.Time
+	for _, day := range days {
+		for _, h := range r.byHour {
+			for _, min := range r.byMinute {
+				occs = append(occs, day.Add(time.Duration(h)*time.Hour+time.Duration(min)*time.Minute+time.Duration(r.start.Second())*time.Second))
+			}
+		}
+	}
+	return occs
+}
+
+func (r *rrule) dayMatches(day time.Time) bool {
+	if len(r.byDay) > 0 {
+		ok := false
+		for _, wd := range r.byDay {
+			ok = ok || day.Weekday() == wd
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(r.byMonthDay) > 0 {
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		ok := false
+		for _, md := range r.byMonthDay {
+			if md < 0 {
+				md = last + md + 1
+			}
+			ok = ok || day.Day() == md
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package schedule
+
+import (
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transfer"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+)
+
+// Statuses of a scheduled transfer.
+const (
+	StatusActive    = "active"
+	StatusPaused    = "paused"
+	StatusCompleted = "completed"
+)
+
+// Policies for an occurrence declined for insufficient funds or a spending
+// limit.
+const (
+	// PolicySkip gives up on the occurrence and waits for the next one.
+	PolicySkip = "skip"
+	// PolicyRetry tries again after RetryDelay, up to MaxRetries times, as
+	// long as the retry comes before the next occurrence.
+	PolicyRetry = "retry"
+)
+
+// Outcomes of an execution.
+const (
+	ExecutionSucceeded = "succeeded"
+	ExecutionRetrying  = "retrying"
+	ExecutionSkipped   = "skipped"
+	ExecutionFailed    = "failed"
+)
+
+// MaxRetries bounds the retries of one occurrence.
+const MaxRetries = 10
+
+var (
+	ErrNotFound = errors.New("scheduled transfer not found")
+	// ErrConflict is returned when a schedule changed since it was read,
+	// usually because it just ran.
+	ErrConflict = errors.New("scheduled transfer was changed concurrently, reload and try again")
+)
+
+// Schedule is a standing order: a transfer repeated on a recurrence.
+type Schedule struct {
+	ID                  int64      `json:"id" example:"1"`
+	FromWalletID        int        `json:"from_wallet_id" example:"1"`
+	ToWalletID          int        `json:"to_wallet_id" example:"2"`
+	Amount              float64    `json:"amount" example:"500.00"`
+	Description         string     `json:"description" example:"Credit card repayment"`
+	Recurrence          string     `json:"recurrence" example:"FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9;BYMINUTE=0"`
+	StartAt             time.Time  `json:"start_at" example:"2024-04-01T00:00:00Z"`
+	EndAt               *time.Time `json:"end_at,omitempty" example:"2025-04-01T00:00:00Z"`
+	OnInsufficientFunds string     `json:"on_insufficient_funds" example:"retry"`
+	MaxRetries          int        `json:"max_retries" example:"3"`
+	RetryDelay          string     `json:"retry_delay,omitempty" example:"6h"`
+	Status              string     `json:"status" example:"active"`
+	// OccurrenceAt is the occurrence being worked on and NextRunAt when it
+	// is next tried, later than OccurrenceAt while it is retried.
+	OccurrenceAt *time.Time `json:"occurrence_at,omitempty" example:"2024-04-01T09:00:00Z"`
+	NextRunAt    *time.Time `json:"next_run_at,omitempty" example:"2024-04-01T09:00:00Z"`
+	Retries      int        `json:"retries" example:"0"`
+	CreatedAt    time.Time  `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+	UpdatedAt    time.Time  `json:"updated_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+// Request creates or replaces a scheduled transfer.
+type Request struct {
+	FromWalletID        int        `json:"from_wallet_id" example:"1"`
+	ToWalletID          int        `json:"to_wallet_id" example:"2"`
+	Amount              float64    `json:"amount" example:"500.00"`
+	Description         string     `json:"description" example:"Credit card repayment"`
+	Recurrence          string     `json:"recurrence" example:"0 9 1 * *"`
+	StartAt             *time.Time `json:"start_at,omitempty" example:"2024-04-01T00:00:00Z"`
+	EndAt               *time.Time `json:"end_at,omitempty" example:"2025-04-01T00:00:00Z"`
+	OnInsufficientFunds string     `json:"on_insufficient_funds" example:"retry"`
+	MaxRetries          int        `json:"max_retries" example:"3"`
+	RetryDelay          string     `json:"retry_delay" example:"6h"`
+}
+
+// Execution is one attempt at an occurrence of a schedule.
+type Execution struct {
+	ID           int64     `json:"id" example:"1"`
+	ScheduleID   int64     `json:"schedule_id" example:"1"`
+	ScheduledFor time.Time `json:"scheduled_for" example:"2024-04-01T09:00:00Z"`
+	Attempt      int       `json:"attempt" example:"1"`
+	Status       string    `json:"status" example:"succeeded"`
+	TransferID   *int64    `json:"transfer_id,omitempty" example:"7"`
+	Error        string    `json:"error,omitempty" example:"balance cannot be negative for Savings"`
+	ExecutedAt   time.Time `json:"executed_at" example:"2024-04-01T09:00:03Z"`
+}
+
+// Transfer is the transfer one run of s makes.
+func (s Schedule) Transfer() transfer.Request {
+	return transfer.Request{FromWalletID: s.FromWalletID, ToWalletID: s.ToWalletID, Amount: s.Amount, Description: s.Description}
+}
+
+// Declined reports whether err means the source could not pay: its balance
+// or a spending limit refused the transfer. Only declined runs are retried.
+func Declined(err error) bool {
+	var be *wallet.BalanceError
+	var le *limit.Error
+	return errors.As(err, &be) || errors.As(err, &le)
+}
+
+// next returns the first occurrence of s after t, or nil when the schedule
+// is over.
+func (s Schedule) next(t time.Time) *time.Time {
+	rec, err := ParseRecurrence(s.Recurrence, s.StartAt)
+	if err != nil {
+		return nil
+	}
+	if t.Before(s.StartAt) {
+		t = s.StartAt.Add(-time.Nanosecond)
+	}
+	n := rec.Next(t)
+	if n.IsZero() || s.EndAt != nil && n.After(*s.EndAt) {
+		return nil
+	}
+	return &n
+}
+
+// Start schedules the first occurrence of s after now, skipping any that
+// were missed while it was paused, or completes it when there is none.
+func (s Schedule) Start(now time.Time) Schedule {
+	s.Retries = 0
+	s.OccurrenceAt = s.next(now)
+	s.NextRunAt = s.OccurrenceAt
+	switch {
+	case s.OccurrenceAt == nil:
+		s.Status = StatusCompleted
+	case s.Status == StatusCompleted:
+		s.Status = StatusActive
+	}
+	return s
+}
+
+// Settle decides what follows running s at now: the execution to record and
+// s with its next run. err is the error of the transfer, nil when t went
+// through. Occurrences missed while no replica was running are not made up.
+func (s Schedule) Settle(now time.Time, t *transfer.Transfer, err error) (Execution, Schedule) {
+	e := Execution{ScheduleID: s.ID, Attempt: s.Retries + 1, ExecutedAt: now}
+	if s.OccurrenceAt != nil {
+		e.ScheduledFor = *s.OccurrenceAt
+	}
+
+	switch {
+	case err == nil:
+		e.Status, e.TransferID = ExecutionSucceeded, &t.ID
+	case Declined(err):
+		e.Status, e.Error = ExecutionSkipped, err.Error()
+		delay, _ := time.ParseDuration(s.RetryDelay)
+		if s.OnInsufficientFunds == PolicyRetry && s.Retries < s.MaxRetries && delay > 0 {
+			retryAt := now.Add(delay)
+			if next := s.next(now); next == nil || retryAt.Before(*next) {
+				e.Status = ExecutionRetrying
+				s.Retries++
+				s.NextRunAt = &retryAt
+				return e, s
+			}
+		}
+	default:
+		e.Status, e.Error = ExecutionFailed, err.Error()
+	}
+	return e, s.Start(now)
+}
@@ -0,0 +1,408 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transfer"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCron(t *testing.T) {
+	tests := []struct {
+		expr  string
+		after string
+		want  []string
+	}{
+		{"0 9 1 * *", "2024-01-15T00:00:00Z", []string{"2024-02-01T09:00:00Z", "2024-03-01T09:00:00Z"}},
+		{"@monthly", "2024-01-31T23:59:00Z", []string{"2024-02-01T00:00:00Z"}},
+		{"*/15 8-9 * * MON-FRI", "2024-03-22T09:50:00Z", []string{"2024-03-25T08:00:00Z", "2024-03-25T08:15:00Z"}},
+		{"30 12 31 * *", "2024-01-31T13:00:00Z", []string{"2024-03-31T12:30:00Z", "2024-05-31T12:30:00Z"}},
+		// Both day fields restricted: the 1st or any Sunday.
+		{"0 0 1 * 7", "2024-03-01T00:00:00Z", []string{"2024-03-03T00:00:00Z", "2024-03-10T00:00:00Z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			rec, err := ParseRecurrence(tt.expr, time.Time{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := at(tt.after)
+			for _, want := range tt.want {
+				next = rec.Next(next)
+				if !next.Equal(at(want)) {
+					t.Fatalf("expected %s but got %s", want, next)
+				}
+			}
+		})
+	}
+
+	t.Run("never matching expression has no next occurrence", func(t *testing.T) {
+		rec, _ := ParseRecurrence("0 0 30 2 *", time.Time{})
+		if next := rec.Next(at("2024-01-01T00:00:00Z")); !next.IsZero() {
+			t.Errorf("expected no occurrence but got %s", next)
+		}
+	})
+
+	for _, expr := range []string{"0 9 1 *", "60 * * * *", "0 9 1 13 *", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := ParseRecurrence(expr, time.Time{}); err == nil {
+			t.Errorf("expected %q to be invalid", expr)
+		}
+	}
+}
+
+func TestRRule(t *testing.T) {
+	tests := []struct {
+		rule  string
+		start string
+		after string
+		want  []string
+	}{
+		{"FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9;BYMINUTE=0", "2024-01-15T00:00:00Z", "2024-01-15T00:00:00Z", []string{"2024-02-01T09:00:00Z", "2024-03-01T09:00:00Z"}},
+		{"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1", "2024-01-10T18:00:00Z", "2024-01-10T00:00:00Z", []string{"2024-01-31T18:00:00Z", "2024-02-29T18:00:00Z", "2024-03-31T18:00:00Z"}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", "2024-03-04T08:30:00Z", "2024-03-01T00:00:00Z", []string{"2024-03-04T08:30:00Z", "2024-03-07T08:30:00Z", "2024-03-18T08:30:00Z"}},
+		{"FREQ=DAILY;COUNT=2", "2024-03-04T08:00:00Z", "2024-03-01T00:00:00Z", []string{"2024-03-04T08:00:00Z", "2024-03-05T08:00:00Z", "0001-01-01T00:00:00Z"}},
+		{"FREQ=MONTHLY", "2024-01-31T10:00:00Z", "2024-01-31T10:00:00Z", []string{"2024-03-31T10:00:00Z"}},
+		{"FREQ=DAILY;UNTIL=20240305", "2024-03-04T08:00:00Z", "2024-03-04T08:00:00Z", []string{"2024-03-05T08:00:00Z", "0001-01-01T00:00:00Z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rec, err := ParseRecurrence(tt.rule, at(tt.start))
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := at(tt.after)
+			for _, want := range tt.want {
+				next = rec.Next(next)
+				if !next.Equal(at(want)) {
+					t.Fatalf("expected %s but got %s", want, next)
+				}
+			}
+		})
+	}
+
+	for _, rule := range []string{"FREQ=YEARLY", "INTERVAL=2", "FREQ=DAILY;COUNT=0", "FREQ=DAILY;BYDAY=XX", "FREQ=MONTHLY;BYMONTHDAY=0", "FREQ=DAILY;COUNT=2;UNTIL=20240101"} {
+		if _, err := ParseRecurrence(rule, time.Now()); err == nil {
+			t.Errorf("expected %q to be invalid", rule)
+		}
+	}
+}
+
+func monthly() Schedule {
+	occ := at("2024-04-01T09:00:00Z")
+	return Schedule{
+		ID: 1, FromWalletID: 1, ToWalletID: 2, Amount: 500, Recurrence: "0 9 1 * *",
+		StartAt: at("2024-03-25T00:00:00Z"), Status: StatusActive,
+		OnInsufficientFunds: PolicyRetry, MaxRetries: 2, RetryDelay: "6h",
+		OccurrenceAt: &occ, NextRunAt: &occ,
+	}
+}
+
+func TestSettle(t *testing.T) {
+	now := at("2024-04-01T09:00:05Z")
+	declined := &wallet.BalanceError{Message: "balance cannot be negative for Savings"}
+
+	t.Run("given a transfer went through should record it and move to the next occurrence", func(t *testing.T) {
+		e, next := monthly().Settle(now, &transfer.Transfer{ID: 7}, nil)
+
+		if e.Status != ExecutionSucceeded || *e.TransferID != 7 || !e.ScheduledFor.Equal(at("2024-04-01T09:00:00Z")) || e.Attempt != 1 {
+			t.Errorf("unexpected execution %+v", e)
+		}
+		if !next.NextRunAt.Equal(at("2024-05-01T09:00:00Z")) || !next.OccurrenceAt.Equal(*next.NextRunAt) {
+			t.Errorf("expected next run on 2024-05-01 but got %v", next.NextRunAt)
+		}
+	})
+
+	t.Run("given insufficient funds and retries left should retry after the delay", func(t *testing.T) {
+		e, next := monthly().Settle(now, nil, declined)
+
+		if e.Status != ExecutionRetrying || e.Error == "" {
+			t.Errorf("unexpected execution %+v", e)
+		}
+		if next.Retries != 1 || !next.NextRunAt.Equal(now.Add(6*time.Hour)) || !next.OccurrenceAt.Equal(at("2024-04-01T09:00:00Z")) {
+			t.Errorf("unexpected retry %+v", next)
+		}
+	})
+
+	t.Run("given the retries are used up should skip the occurrence", func(t *testing.T) {
+		s := monthly()
+		s.Retries = 2
+
+		e, next := s.Settle(now, nil, &limit.Error{Limit: limit.DailyOutflow})
+
+		if e.Status != ExecutionSkipped || e.Attempt != 3 {
+			t.Errorf("unexpected execution %+v", e)
+		}
+		if next.Retries != 0 || !next.NextRunAt.Equal(at("2024-05-01T09:00:00Z")) {
+			t.Errorf("unexpected next %+v", next)
+		}
+	})
+
+	t.Run("given the skip policy should not retry", func(t *testing.T) {
+		s := monthly()
+		s.OnInsufficientFunds = PolicySkip
+
+		e, _ := s.Settle(now, nil, declined)
+
+		if e.Status != ExecutionSkipped {
+			t.Errorf("expected %s but got %s", ExecutionSkipped, e.Status)
+		}
+	})
+
+	t.Run("given a retry would pass the next occurrence should skip instead", func(t *testing.T) {
+		s := monthly()
+		s.Recurrence, s.RetryDelay = "0 * * * *", "2h"
+
+		e, next := s.Settle(now, nil, declined)
+
+		if e.Status != ExecutionSkipped || !next.NextRunAt.Equal(at("2024-04-01T10:00:00Z")) {
+			t.Errorf("unexpected outcome %+v %+v", e, next)
+		}
+	})
+
+	t.Run("given a frozen wallet should fail without retrying", func(t *testing.T) {
+		e, next := monthly().Settle(now, nil, &wallet.StatusError{WalletID: 1, Status: wallet.StatusFrozen})
+
+		if e.Status != ExecutionFailed || next.Retries != 0 {
+			t.Errorf("unexpected outcome %+v %+v", e, next)
+		}
+	})
+
+	t.Run("given the last occurrence should complete the schedule", func(t *testing.T) {
+		s := monthly()
+		end := at("2024-04-15T00:00:00Z")
+		s.EndAt = &end
+
+		_, next := s.Settle(now, &transfer.Transfer{ID: 7}, nil)
+
+		if next.Status != StatusCompleted || next.NextRunAt != nil {
+			t.Errorf("expected completed schedule but got %+v", next)
+		}
+	})
+}
+
+type StubLeader struct {
+	lead     bool
+	resigned bool
+}
+
+func (l *StubLeader) TryLead(ctx context.Context) (bool, error) {
+	return l.lead, nil
+}
+
+func (l *StubLeader) Resign() error {
+	l.resigned = true
+	return nil
+}
+
+type StubScheduler struct {
+	due []int64
+	ran []int64
+	err map[int64]error
+}
+
+func (s *StubScheduler) DueSchedules(now time.Time, limit int) ([]int64, error) {
+	return s.due, nil
+}
+
+func (s *StubScheduler) RunSchedule(id int64, now time.Time) (*Execution, error) {
+	s.ran = append(s.ran, id)
+	if err := s.err[id]; err != nil {
+		return nil, err
+	}
+	return &Execution{ScheduleID: id, Status: ExecutionSucceeded}, nil
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("given a failing schedule should still run the others", func(t *testing.T) {
+		store := &StubScheduler{due: []int64{1, 2, 3}, err: map[int64]error{2: errors.New("connection reset")}}
+
+		if err := NewScheduler(store, &StubLeader{lead: true}).RunDue(at("2024-04-01T09:00:00Z")); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(store.ran) != 3 {
+			t.Errorf("expected 3 runs but got %v", store.ran)
+		}
+	})
+
+	t.Run("given another replica leads should not run anything", func(t *testing.T) {
+		store := &StubScheduler{due: []int64{1}}
+		leader := &StubLeader{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		NewScheduler(store, leader).Run(ctx, time.Hour)
+
+		if len(store.ran) != 0 || !leader.resigned {
+			t.Errorf("expected no runs and resignation but got %v %v", store.ran, leader.resigned)
+		}
+	})
+}
+
+type StubSchedule struct {
+	schedules map[int64]Schedule
+	err       error
+}
+
+func (s *StubSchedule) CreateSchedule(sc Schedule) (*Schedule, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	sc.ID = int64(len(s.schedules) + 1)
+	s.schedules[sc.ID] = sc
+	return &sc, nil
+}
+
+func (s *StubSchedule) Schedule(id int64) (*Schedule, error) {
+	sc, ok := s.schedules[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &sc, nil
+}
+
+func (s *StubSchedule) WalletSchedules(walletID int) ([]Schedule, error) {
+	var schedules []Schedule
+	for _, sc := range s.schedules {
+		if sc.FromWalletID == walletID || sc.ToWalletID == walletID {
+			schedules = append(schedules, sc)
+		}
+	}
+	return schedules, nil
+}
+
+func (s *StubSchedule) UpdateSchedule(sc Schedule) (*Schedule, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.schedules[sc.ID] = sc
+	return &sc, nil
+}
+
+func (s *StubSchedule) DeleteSchedule(id int64) error {
+	if _, ok := s.schedules[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.schedules, id)
+	return nil
+}
+
+func (s *StubSchedule) Executions(scheduleID int64) ([]Execution, error) {
+	return []Execution{}, nil
+}
+
+func request(body string, id string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if id != "" {
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+	}
+	return c, rec
+}
+
+func handler(store Storer) *Handler {
+	h := New(store)
+	h.now = func() time.Time { return at("2024-03-25T14:00:00Z") }
+	return h
+}
+
+func TestCreateSchedule(t *testing.T) {
+	t.Run("given a monthly standing order should schedule its first run", func(t *testing.T) {
+		c, rec := request(`{"from_wallet_id": 1, "to_wallet_id": 2, "amount": 500, "recurrence": "0 9 1 * *", "on_insufficient_funds": "retry", "max_retries": 3, "retry_delay": "6h"}`, "")
+
+		handler(&StubSchedule{schedules: map[int64]Schedule{}}).CreateSchedule(c)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d but got %d: %s", http.StatusCreated, rec.Code, rec.Body)
+		}
+		var resp Schedule
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if resp.Status != StatusActive || resp.NextRunAt == nil || !resp.NextRunAt.Equal(at("2024-04-01T09:00:00Z")) {
+			t.Errorf("unexpected schedule %+v", resp)
+		}
+	})
+
+	t.Run("given invalid requests should return 400", func(t *testing.T) {
+		for _, body := range []string{
+			`{"from_wallet_id": 1, "to_wallet_id": 1, "amount": 5, "recurrence": "@daily"}`,
+			`{"from_wallet_id": 1, "to_wallet_id": 2, "amount": 0, "recurrence": "@daily"}`,
+			`{"from_wallet_id": 1, "to_wallet_id": 2, "amount": 5, "recurrence": "every day"}`,
+			`{"from_wallet_id": 1, "to_wallet_id": 2, "amount": 5, "recurrence": "@daily", "on_insufficient_funds": "wait"}`,
+			`{"from_wallet_id": 1, "to_wallet_id": 2, "amount": 5, "recurrence": "@daily", "on_insufficient_funds": "retry", "max_retries": 3}`,
+			`{"from_wallet_id": 1, "to_wallet_id": 2, "amount": 5, "recurrence": "@daily", "start_at": "2024-04-01T00:00:00Z", "end_at": "2024-03-01T00:00:00Z"}`,
+		} {
+			c, rec := request(body, "")
+
+			handler(&StubSchedule{schedules: map[int64]Schedule{}}).CreateSchedule(c)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status code %d for %s but got %d", http.StatusBadRequest, body, rec.Code)
+			}
+		}
+	})
+
+	t.Run("given a missing wallet should return 404", func(t *testing.T) {
+		c, rec := request(`{"from_wallet_id": 1, "to_wallet_id": 99, "amount": 5, "recurrence": "@daily"}`, "")
+
+		handler(&StubSchedule{schedules: map[int64]Schedule{}, err: wallet.ErrWalletNotFound}).CreateSchedule(c)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestPauseAndResume(t *testing.T) {
+	store := &StubSchedule{schedules: map[int64]Schedule{1: monthly()}}
+
+	c, rec := request("", "1")
+	handler(store).PauseSchedule(c)
+	if rec.Code != http.StatusOK || store.schedules[1].Status != StatusPaused || store.schedules[1].NextRunAt != nil {
+		t.Fatalf("expected paused schedule but got %d %+v", rec.Code, store.schedules[1])
+	}
+
+	c, rec = request("", "1")
+	handler(store).PauseSchedule(c)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status code %d but got %d", http.StatusConflict, rec.Code)
+	}
+
+	c, rec = request("", "1")
+	handler(store).ResumeSchedule(c)
+	if rec.Code != http.StatusOK || store.schedules[1].Status != StatusActive || !store.schedules[1].NextRunAt.Equal(at("2024-04-01T09:00:00Z")) {
+		t.Errorf("expected resumed schedule but got %d %+v", rec.Code, store.schedules[1])
+	}
+}
+
+func TestUpdateSchedule(t *testing.T) {
+	t.Run("given the schedule ran meanwhile should return 409", func(t *testing.T) {
+		store := &StubSchedule{schedules: map[int64]Schedule{1: monthly()}, err: ErrConflict}
+		c, rec := request(`{"from_wallet_id": 1, "to_wallet_id": 2, "amount": 600, "recurrence": "0 9 1 * *"}`, "1")
+
+		handler(store).UpdateSchedule(c)
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected status code %d but got %d", http.StatusConflict, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Leader elects one replica to run the scheduler.
+type Leader interface {
+	// TryLead reports whether this replica leads, trying to become the
+	// leader when it does not. Leadership is kept until Resign or until the
+	// replica dies.
+	TryLead(ctx context.Context) (bool, error)
+	Resign() error
+}
+
+type SchedulerStorer interface {
+	// DueSchedules returns the ids of the active schedules whose next run is
+	// at or before now.
+	DueSchedules(now time.Time, limit int) ([]int64, error)
+	// RunSchedule runs one due schedule: the transfer, its execution and
+	// the next run in one transaction. It returns nil when the schedule is
+	// no longer due, for example because it is running elsewhere.
+	RunSchedule(id int64, now time.Time) (*Execution, error)
+}
+
+// batchSize bounds the schedules run per tick, so a backlog is worked off
+// over several ticks.
+const batchSize = 100
+
+// Scheduler runs the due scheduled transfers. Every replica runs one but
+// only the leader executes; the row lock taken by RunSchedule keeps a
+// schedule from running twice even across a change of leader.
+type Scheduler struct {
+	store  SchedulerStorer
+	leader Leader
+	now    func() time.Time
+}
+
+func NewScheduler(store SchedulerStorer, leader Leader) *Scheduler {
+	return &Scheduler{store: store, leader: leader, now: time.Now}
+}
+
+// Run runs the due schedules every interval while this replica leads, until
+// ctx is done.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer s.leader.Resign()
+	for {
+		lead, err := s.leader.TryLead(ctx)
+		if err != nil {
+			log.Printf("scheduler: %v", err)
+		}
+		if lead {
+			if err := s.RunDue(s.now()); err != nil {
+				log.Printf("scheduler: %v", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs every schedule due at now. A schedule failing to run is
+// logged and tried again on the next tick.
+func (s *Scheduler) RunDue(now time.Time) error {
+	now = now.UTC()
+	ids, err := s.store.DueSchedules(now, batchSize)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		e, err := s.store.RunSchedule(id, now)
+		if err != nil {
+			log.Printf("scheduler: scheduled transfer %d: %v", id, err)
+			continue
+		}
+		if e != nil && e.Status != ExecutionSucceeded {
+			log.Printf("scheduler: scheduled transfer %d %s: %s", id, e.Status, e.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+const dateTimeLayout = "2006-01-02 15:04:05"
+
+// WriteCSV writes the statement as one row per transaction, between an
+// opening and a closing balance row.
+func WriteCSV(w io.Writer, s Statement) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"date", "transaction_id", "kind", "description", "amount", "balance"},
+		{s.PeriodStart.Format("2006-01-02"), "", "", "Opening balance", "", money(s.OpeningBalance)},
+	}
+	for _, l := range s.Lines {
+		rows = append(rows, []string{
+			l.CreatedAt.UTC().Format(dateTimeLayout),
+			strconv.FormatInt(l.ID, 10),
+			l.Kind,
+			l.Description,
+			money(l.Amount),
+			money(l.Balance),
+		})
+	}
+	rows = append(rows, []string{s.LastDay().Format("2006-01-02"), "", "", "Closing balance", "", money(s.ClosingBalance)})
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func money(amount float64) string {
+	if amount == 0 {
+		amount = 0 // no negative zero
+	}
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
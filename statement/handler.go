@@ -0,0 +1,91 @@
+package statement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+	now   func() time.Time
+}
+
+type Storer interface {
+	// Statement returns the statement of a wallet for the period
+	// [start, end), its transactions ordered by date.
+	Statement(walletID int, start, end time.Time) (*Statement, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db, now: time.Now}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// GetStatement
+//
+//	@Summary		Get wallet statement
+//	@Description	Get the monthly statement of a wallet, with opening balance, itemized transactions and closing balance, as a PDF or CSV download. The current month gives a statement to date.
+//	@Tags			statement
+//	@Produce		application/pdf
+//	@Produce		text/csv
+//	@Produce		json
+//	@Success		200	{file}		file
+//	@Router			/api/v1/wallets/{id}/statements [get]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   month  query		string	true	"Month, YYYY-MM"
+//	@Param   format  query		string	false	"pdf (default) or csv"
+func (h *Handler) GetStatement(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+	month := c.QueryParam("month")
+	start, end, err := ParseMonth(month, h.now())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	format := c.QueryParam("format")
+	if format == "" {
+		format = FormatPDF
+	}
+	if format != FormatPDF && format != FormatCSV {
+		return c.JSON(http.StatusBadRequest, Err{Message: "format must be pdf or csv"})
+	}
+
+	s, err := h.store.Statement(walletId, start, end)
+	if errors.Is(err, wallet.ErrWalletNotFound) {
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+
+	// Render fully before answering, so a failure is still a 500.
+	var buf bytes.Buffer
+	contentType := "application/pdf"
+	if format == FormatCSV {
+		contentType = "text/csv"
+		err = WriteCSV(&buf, *s)
+	} else {
+		err = WritePDF(&buf, *s)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	filename := fmt.Sprintf("wallet-%d-%s.%s", walletId, month, format)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Blob(http.StatusOK, contentType, buf.Bytes())
+}
@@ -0,0 +1,120 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Widths of the transaction table columns, in mm.
+const (
+	dateWidth        = 36
+	descriptionWidth = 74
+	amountWidth      = 30
+	balanceWidth     = 30
+	rowHeight        = 7
+)
+
+// WritePDF writes the statement as an A4 document using the core fonts
+// only. Its dates are those of the period rather than the clock, so the
+// same statement always gives the same bytes.
+func WritePDF(w io.Writer, s Statement) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(s.PeriodEnd)
+	pdf.SetModificationDate(s.PeriodEnd)
+	title := fmt.Sprintf("Statement %s - %s", s.PeriodStart.Format("January 2006"), s.WalletName)
+	pdf.SetTitle(title, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetHeaderFuncMode(func() {
+		if pdf.PageNo() > 1 {
+			tableHeader(pdf)
+		}
+	}, true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Wallet %d - page %d of {nb}", s.WalletID, pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Statement", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, row := range [][2]string{
+		{"Wallet", tr(fmt.Sprintf("%s (#%d, %s)", s.WalletName, s.WalletID, s.WalletType))},
+		{"Holder", tr(s.UserName)},
+		{"Period", fmt.Sprintf("%s to %s", s.PeriodStart.Format("2 January 2006"), s.LastDay().Format("2 January 2006"))},
+	} {
+		pdf.CellFormat(30, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, row[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	tableHeader(pdf)
+	pdf.SetFont("Helvetica", "", 9)
+	balanceRow(pdf, s.PeriodStart.Format("2006-01-02"), "Opening balance", s.OpeningBalance)
+	for _, l := range s.Lines {
+		pdf.CellFormat(dateWidth, rowHeight, l.CreatedAt.UTC().Format("2006-01-02 15:04"), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(descriptionWidth, rowHeight, fit(pdf, tr(describe(l)), descriptionWidth-2), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(amountWidth, rowHeight, money(l.Amount), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(balanceWidth, rowHeight, money(l.Balance), "B", 1, "R", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "B", 9)
+	balanceRow(pdf, s.LastDay().Format("2006-01-02"), "Closing balance", s.ClosingBalance)
+
+	pdf.Ln(6)
+	pdf.SetFont("Helvetica", "", 10)
+	for _, row := range [][2]string{
+		{"Money in", money(s.TotalIn)},
+		{"Money out", money(s.TotalOut)},
+		{"Transactions", strconv.Itoa(len(s.Lines))},
+	} {
+		pdf.CellFormat(40, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 6, row[1], "", 1, "R", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
+
+func tableHeader(pdf *fpdf.Fpdf) {
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(dateWidth, rowHeight, "Date", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(descriptionWidth, rowHeight, "Description", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(amountWidth, rowHeight, "Amount", "B", 0, "R", true, 0, "")
+	pdf.CellFormat(balanceWidth, rowHeight, "Balance", "B", 1, "R", true, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+}
+
+func balanceRow(pdf *fpdf.Fpdf, date, label string, balance float64) {
+	pdf.CellFormat(dateWidth, rowHeight, date, "B", 0, "L", false, 0, "")
+	pdf.CellFormat(descriptionWidth+amountWidth, rowHeight, label, "B", 0, "L", false, 0, "")
+	pdf.CellFormat(balanceWidth, rowHeight, money(balance), "B", 1, "R", false, 0, "")
+}
+
+// describe is the description of a line, its kind when it has none.
+func describe(l Line) string {
+	if l.Description == "" {
+		return l.Kind
+	}
+	return l.Description
+}
+
+// fit cuts s to width with an ellipsis when it does not fit. s is already
+// translated to the single byte encoding of the core fonts.
+func fit(pdf *fpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	for len(s) > 0 && pdf.GetStringWidth(s+"...") > width {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
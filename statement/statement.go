@@ -0,0 +1,82 @@
+package statement
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+)
+
+// Formats a statement can be exported in.
+const (
+	FormatPDF = "pdf"
+	FormatCSV = "csv"
+)
+
+var ErrInvalidMonth = errors.New("month must be YYYY-MM and not in the future")
+
+// Statement is the activity of a wallet over a calendar month in UTC.
+type Statement struct {
+	WalletID       int
+	WalletName     string
+	WalletType     string
+	UserName       string
+	PeriodStart    time.Time
+	PeriodEnd      time.Time
+	OpeningBalance float64
+	Lines          []Line
+	TotalIn        float64
+	TotalOut       float64
+	ClosingBalance float64
+}
+
+// Line is a transaction of the statement with the balance after it, in the
+// order of the statement.
+type Line struct {
+	transaction.Transaction
+	Balance float64
+}
+
+// ParseMonth returns the first instant of month and of the month after. A
+// month after the one of now is invalid; the current month gives a
+// statement to date.
+func ParseMonth(month string, now time.Time) (start, end time.Time, err error) {
+	start, err = time.Parse("2006-01", month)
+	if err != nil {
+		return start, end, ErrInvalidMonth
+	}
+	now = now.UTC()
+	if start.After(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		return start, end, ErrInvalidMonth
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// Build fills in the lines and totals of s from its opening balance and the
+// transactions of the period, oldest first.
+func Build(s Statement, transactions []transaction.Transaction) Statement {
+	balance := s.OpeningBalance
+	s.Lines = make([]Line, 0, len(transactions))
+	s.TotalIn, s.TotalOut = 0, 0
+	for _, t := range transactions {
+		balance = cents(balance + t.Amount)
+		if t.Amount >= 0 {
+			s.TotalIn = cents(s.TotalIn + t.Amount)
+		} else {
+			s.TotalOut = cents(s.TotalOut - t.Amount)
+		}
+		s.Lines = append(s.Lines, Line{Transaction: t, Balance: balance})
+	}
+	s.ClosingBalance = balance
+	return s
+}
+
+// LastDay is the last day the statement covers.
+func (s Statement) LastDay() time.Time {
+	return s.PeriodEnd.AddDate(0, 0, -1)
+}
+
+func cents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,164 @@
+package statement
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/internal/testutil"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+var now = time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+func at(day, hour int) time.Time {
+	return time.Date(2026, 9, day, hour, 0, 0, 0, time.UTC)
+}
+
+func september() Statement {
+	return Build(Statement{
+		WalletID:       1,
+		WalletName:     "John Savings",
+		WalletType:     "Savings",
+		UserName:       "John Doe",
+		PeriodStart:    time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:      time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 1000,
+	}, []transaction.Transaction{
+		{ID: 11, Kind: transaction.KindDeposit, Amount: 2500, Description: "Salary", CreatedAt: at(1, 9)},
+		{ID: 12, Kind: transaction.KindTransferOut, Amount: -800, Description: "Rent share", CreatedAt: at(3, 10)},
+		{ID: 13, Kind: transaction.KindFee, Amount: -0.55, Description: "Transfer fee", CreatedAt: at(3, 10)},
+		{ID: 14, Kind: transaction.KindCapture, Amount: -42.3, Description: "Café Crème, Zürich", CreatedAt: at(14, 18)},
+		{ID: 15, Kind: transaction.KindWithdrawal, Amount: -200, Description: "Cash withdrawal at the machine in the lobby of the central station", CreatedAt: at(20, 12)},
+		{ID: 16, Kind: transaction.KindInterest, Amount: 1.87, CreatedAt: at(30, 23)},
+	})
+}
+
+func TestBuild(t *testing.T) {
+	s := september()
+
+	if s.TotalIn != 2501.87 || s.TotalOut != 1042.85 || s.ClosingBalance != 2459.02 {
+		t.Errorf("expected in 2501.87, out 1042.85, closing 2459.02 but got %v, %v, %v", s.TotalIn, s.TotalOut, s.ClosingBalance)
+	}
+	if s.Lines[1].Balance != 2700 {
+		t.Errorf("expected a running balance of 2700 after the rent but got %v", s.Lines[1].Balance)
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	start, end, err := ParseMonth("2026-09", now)
+	if err != nil || !start.Equal(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected period %v to %v, %v", start, end, err)
+	}
+	if _, _, err := ParseMonth("2026-10", now); err != nil {
+		t.Errorf("expected the current month to be valid but got %v", err)
+	}
+	for _, month := range []string{"", "2026-9", "2026-13", "2026-11"} {
+		if _, _, err := ParseMonth(month, now); err != ErrInvalidMonth {
+			t.Errorf("expected %q to be invalid but got %v", month, err)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, september()); err != nil {
+		t.Fatal(err)
+	}
+	testutil.Golden(t, "statement.csv", buf.Bytes())
+}
+
+func TestWritePDF(t *testing.T) {
+	var first, second bytes.Buffer
+	if err := WritePDF(&first, september()); err != nil {
+		t.Fatal(err)
+	}
+	if err := WritePDF(&second, september()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Fatal("expected the same statement to give the same bytes")
+	}
+	testutil.Golden(t, "statement.pdf", first.Bytes())
+}
+
+type StubStatement struct {
+	statement *Statement
+	err       error
+}
+
+func (s *StubStatement) Statement(walletID int, start, end time.Time) (*Statement, error) {
+	return s.statement, s.err
+}
+
+func request(query string) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/?"+query, nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	return c, rec
+}
+
+func handler(store Storer) *Handler {
+	h := New(store)
+	h.now = func() time.Time { return now }
+	return h
+}
+
+func TestGetStatement(t *testing.T) {
+	s := september()
+
+	t.Run("given csv should download the statement", func(t *testing.T) {
+		c, rec := request("month=2026-09&format=csv")
+
+		handler(&StubStatement{statement: &s}).GetStatement(c)
+
+		if rec.Code != http.StatusOK || rec.Header().Get(echo.HeaderContentType) != "text/csv" {
+			t.Fatalf("unexpected response %d %s", rec.Code, rec.Header().Get(echo.HeaderContentType))
+		}
+		if got := rec.Header().Get(echo.HeaderContentDisposition); got != `attachment; filename="wallet-1-2026-09.csv"` {
+			t.Errorf("unexpected disposition %s", got)
+		}
+		if !strings.Contains(rec.Body.String(), "Closing balance,,2459.02") {
+			t.Errorf("expected the closing balance in %s", rec.Body.String())
+		}
+	})
+
+	t.Run("given no format should return a pdf", func(t *testing.T) {
+		c, rec := request("month=2026-09")
+
+		handler(&StubStatement{statement: &s}).GetStatement(c)
+
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "%PDF-") {
+			t.Errorf("expected a pdf but got %d", rec.Code)
+		}
+	})
+
+	t.Run("given an invalid month or format should return 400", func(t *testing.T) {
+		for _, query := range []string{"", "month=2026-12", "month=2026-09&format=xlsx"} {
+			c, rec := request(query)
+
+			handler(&StubStatement{statement: &s}).GetStatement(c)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status code %d for %q but got %d", http.StatusBadRequest, query, rec.Code)
+			}
+		}
+	})
+
+	t.Run("given an unknown wallet should return 404", func(t *testing.T) {
+		c, rec := request("month=2026-09")
+
+		handler(&StubStatement{err: wallet.ErrWalletNotFound}).GetStatement(c)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package stream
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store     Storer
+	broker    *Broker
+	heartbeat time.Duration
+}
+
+type Storer interface {
+	// WalletChanges returns up to limit changes of userID after afterID,
+	// oldest first.
+	WalletChanges(userID int, afterID int64, limit int) ([]Change, error)
+	LatestWalletChangeID() (int64, error)
+}
+
+// pageSize bounds the changes read at once; a stream far behind catches up
+// page by page.
+const pageSize = 500
+
+func New(db Storer, broker *Broker, heartbeat time.Duration) *Handler {
+	return &Handler{store: db, broker: broker, heartbeat: heartbeat}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// WalletStream
+//
+//	@Summary		Stream wallet changes of a user
+//	@Description	Server-Sent Events stream of wallet and balance changes of a user. Send Last-Event-ID to resume after a disconnect, within the retention of the change log.
+//	@Router			/api/v1/users/{id}/wallets/stream [get]
+//	@Tags			users
+//	@Produce		text/event-stream
+//	@Success		200	{object}	Change
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path	int	true "User id"
+//	@Param   Last-Event-ID  header	int	false "Id of the last change received"
+func (h *Handler) WalletStream(c echo.Context) error {
+	userId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid user id"})
+	}
+
+	// Subscribe before reading the log so no change falls in between.
+	changed, unsubscribe := h.broker.Subscribe(userId)
+	defer unsubscribe()
+
+	var lastId int64
+	if pLastId := c.Request().Header.Get("Last-Event-ID"); pLastId != "" {
+		lastId, err = strconv.ParseInt(pLastId, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Err{Message: "Invalid Last-Event-ID"})
+		}
+	} else {
+		lastId, err = h.store.LatestWalletChangeID()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+		}
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	ctx := c.Request().Context()
+
+	for {
+		for {
+			changes, err := h.store.WalletChanges(userId, lastId, pageSize)
+			if err != nil {
+				return err
+			}
+			for _, ch := range changes {
+				if _, err := fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", ch.ID, ch.Type, ch.Wallet); err != nil {
+					return nil
+				}
+				lastId = ch.ID
+			}
+			res.Flush()
+			if len(changes) < pageSize {
+				break
+			}
+		}
+
+		// A heartbeat also rereads the log, covering a missed notification.
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
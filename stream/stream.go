@@ -0,0 +1,148 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Change is one row of the wallet change log, written by a trigger on
+// user_wallet. Wallet is the wallet as the API returns it after the change,
+// or before it for deletes. A wallet moved to another user is a
+// WalletDeleted for its old owner and a WalletCreated for the new one.
+type Change struct {
+	ID        int64           `json:"id" example:"1"`
+	UserID    int             `json:"user_id" example:"1"`
+	Type      string          `json:"type" example:"BalanceChanged"`
+	Wallet    json.RawMessage `json:"wallet" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+// PrunerStorer removes old changes from the log.
+type PrunerStorer interface {
+	// PruneWalletChanges deletes the changes logged before before and
+	// returns how many it deleted.
+	PruneWalletChanges(before time.Time) (int64, error)
+}
+
+// Pruner keeps the change log to its retention. A stream resuming from a
+// change older than that misses what was pruned. Deleting is idempotent, so
+// every replica can prune.
+type Pruner struct {
+	store     PrunerStorer
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewPruner(store PrunerStorer, retention time.Duration) *Pruner {
+	return &Pruner{store: store, retention: retention, now: time.Now}
+}
+
+// Run prunes every interval until ctx is done.
+func (p *Pruner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Prune(); err != nil {
+			log.Printf("wallet change pruner: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune deletes the changes older than the retention.
+func (p *Pruner) Prune() error {
+	n, err := p.store.PruneWalletChanges(p.now().UTC().Add(-p.retention))
+	if n > 0 {
+		log.Printf("wallet change pruner: deleted %d changes", n)
+	}
+	return err
+}
+
+// Notifier is told which user's wallets changed. Broker is one; a cache
+// invalidated on changes is another.
+type Notifier interface {
+	Notify(userID int)
+	NotifyAll()
+}
+
+// Notifiers tells each of its notifiers.
+type Notifiers []Notifier
+
+func (ns Notifiers) Notify(userID int) {
+	for _, n := range ns {
+		n.Notify(userID)
+	}
+}
+
+func (ns Notifiers) NotifyAll() {
+	for _, n := range ns {
+		n.NotifyAll()
+	}
+}
+
+// Broker fans change notifications out to the streams of a user. A
+// notification only says "something changed"; each stream then reads the
+// change log itself, which is what makes resuming from Last-Event-ID work.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[int]map[chan struct{}]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: map[int]map[chan struct{}]struct{}{}}
+}
+
+// Subscribe returns a channel signalled on every change of userID's wallets
+// and a function to unsubscribe.
+func (b *Broker) Subscribe(userID int) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	b.mu.Lock()
+	if b.subs[userID] == nil {
+		b.subs[userID] = map[chan struct{}]struct{}{}
+	}
+	b.subs[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subs[userID], ch)
+		if len(b.subs[userID]) == 0 {
+			delete(b.subs, userID)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// Notify signals every stream of userID. It never blocks: a stream that has
+// not caught up yet already has a signal pending.
+func (b *Broker) Notify(userID int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[userID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// NotifyAll signals every stream, for when notifications may have been lost.
+func (b *Broker) NotifyAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, subs := range b.subs {
+		for ch := range subs {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StubStreamHandler struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+func (s *StubStreamHandler) WalletChanges(userID int, afterID int64, limit int) ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var changes []Change
+	for _, c := range s.changes {
+		if c.UserID == userID && c.ID > afterID && len(changes) < limit {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+type StubPruner struct {
+	before time.Time
+}
+
+func (s *StubPruner) PruneWalletChanges(before time.Time) (int64, error) {
+	s.before = before
+	return 0, nil
+}
+
+func (s *StubStreamHandler) LatestWalletChangeID() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.changes) == 0 {
+		return 0, nil
+	}
+	return s.changes[len(s.changes)-1].ID, nil
+}
+
+func (s *StubStreamHandler) add(c Change) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes = append(s.changes, c)
+}
+
+func startServer(t *testing.T, store Storer, broker *Broker, heartbeat time.Duration) *httptest.Server {
+	e := echo.New()
+	e.GET("/users/:id/wallets/stream", New(store, broker, heartbeat).WalletStream)
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func connect(t *testing.T, url, lastEventId string) *bufio.Reader {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if lastEventId != "" {
+		req.Header.Set("Last-Event-ID", lastEventId)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if ct := resp.Header.Get(echo.HeaderContentType); ct != "text/event-stream" {
+		t.Fatalf("expected content type %s but got %s", "text/event-stream", ct)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+// readMessage reads one SSE message, returning its lines without the blank
+// line terminating it.
+func readMessage(t *testing.T, r *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestWalletStream(t *testing.T) {
+
+	t.Run("given Last-Event-ID should replay later changes of the user", func(t *testing.T) {
+		store := &StubStreamHandler{changes: []Change{
+			{ID: 1, UserID: 1, Type: "WalletCreated", Wallet: json.RawMessage(`{"id":1}`)},
+			{ID: 2, UserID: 2, Type: "WalletCreated", Wallet: json.RawMessage(`{"id":2}`)},
+			{ID: 3, UserID: 1, Type: "BalanceChanged", Wallet: json.RawMessage(`{"id":1}`)},
+		}}
+		srv := startServer(t, store, NewBroker(), time.Minute)
+
+		r := connect(t, srv.URL+"/users/1/wallets/stream", "1")
+
+		got := readMessage(t, r)
+		want := []string{"id: 3", "event: BalanceChanged", `data: {"id":1}`}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("expected message %q but got %q", want, got)
+		}
+	})
+
+	t.Run("given more changes than a page should replay them all", func(t *testing.T) {
+		store := &StubStreamHandler{}
+		for id := int64(1); id <= pageSize+2; id++ {
+			store.changes = append(store.changes, Change{ID: id, UserID: 1, Type: "BalanceChanged", Wallet: json.RawMessage(`{"id":1}`)})
+		}
+		srv := startServer(t, store, NewBroker(), time.Minute)
+
+		r := connect(t, srv.URL+"/users/1/wallets/stream", "0")
+
+		var got []string
+		for i := 0; i < pageSize+2; i++ {
+			got = readMessage(t, r)
+		}
+		if got[0] != fmt.Sprintf("id: %d", pageSize+2) {
+			t.Errorf("expected last message id %d but got %s", pageSize+2, got[0])
+		}
+	})
+
+	t.Run("given notification should push new changes only", func(t *testing.T) {
+		store := &StubStreamHandler{changes: []Change{
+			{ID: 1, UserID: 1, Type: "WalletCreated", Wallet: json.RawMessage(`{"id":1}`)},
+		}}
+		broker := NewBroker()
+		srv := startServer(t, store, broker, time.Minute)
+
+		r := connect(t, srv.URL+"/users/1/wallets/stream", "")
+
+		store.add(Change{ID: 2, UserID: 1, Type: "WalletUpdated", Wallet: json.RawMessage(`{"id":1}`)})
+		broker.Notify(1)
+
+		got := readMessage(t, r)
+		if got[0] != "id: 2" {
+			t.Errorf("expected message id %s but got %s", "id: 2", got[0])
+		}
+	})
+
+	t.Run("given no changes should send heartbeat comments", func(t *testing.T) {
+		srv := startServer(t, &StubStreamHandler{}, NewBroker(), 10*time.Millisecond)
+
+		r := connect(t, srv.URL+"/users/1/wallets/stream", "")
+
+		got := readMessage(t, r)
+		if len(got) != 1 || got[0] != ": heartbeat" {
+			t.Errorf("expected heartbeat but got %q", got)
+		}
+	})
+
+	t.Run("given invalid user id should return 400", func(t *testing.T) {
+		srv := startServer(t, &StubStreamHandler{}, NewBroker(), time.Minute)
+
+		resp, err := http.Get(srv.URL + "/users/abc/wallets/stream")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, resp.StatusCode)
+		}
+	})
+}
+
+func TestPruner(t *testing.T) {
+	now := time.Date(2024, 3, 25, 14, 19, 0, 0, time.UTC)
+	store := &StubPruner{}
+	p := NewPruner(store, 7*24*time.Hour)
+	p.now = func() time.Time { return now }
+
+	if err := p.Prune(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := now.AddDate(0, 0, -7); !store.before.Equal(want) {
+		t.Errorf("expected changes before %v pruned but got %v", want, store.before)
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+	ch, unsubscribe := b.Subscribe(1)
+
+	b.Notify(2)
+	select {
+	case <-ch:
+		t.Error("expected no signal for other user")
+	default:
+	}
+
+	b.Notify(1)
+	b.Notify(1)
+	select {
+	case <-ch:
+	default:
+		t.Error("expected signal")
+	}
+
+	unsubscribe()
+	b.Notify(1)
+	select {
+	case <-ch:
+		t.Error("expected no signal after unsubscribe")
+	default:
+	}
+}
@@ -0,0 +1,186 @@
+package transaction
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	Transactions(walletID int) ([]Transaction, error)
+	Deposit(walletID int, amount float64, description string) (*Transaction, error)
+	Withdraw(walletID int, amount float64, description string) (*Transaction, error)
+	// Reverse posts the compensating entries of a transaction, of both legs
+	// for a transfer, and links them to it. A nil amount reverses all that
+	// is left.
+	Reverse(id int64, amount *float64, reason string, actor audit.Actor) (*Reversal, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+}
+
+// GetTransactions
+//
+//	@Summary		Get wallet transactions
+//	@Description	Get every transaction posted to a wallet, newest first
+//	@Tags			transaction
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Transaction
+//	@Router			/api/v1/wallets/{id}/transactions [get]
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+func (h *Handler) GetTransactions(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+
+	transactions, err := h.store.Transactions(walletId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, transactions)
+}
+
+// Deposit
+//
+//	@Summary		Deposit into wallet
+//	@Description	Add money to a wallet
+//	@Tags			transaction
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	Transaction
+//	@Router			/api/v1/wallets/{id}/deposits [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code wallet_frozen or wallet_closed"
+//	@Failure		422	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   deposit  body		Movement	true	"Deposit"
+func (h *Handler) Deposit(c echo.Context) error {
+	return h.move(c, h.store.Deposit)
+}
+
+// Withdraw
+//
+//	@Summary		Withdraw from wallet
+//	@Description	Take money out of a wallet, within its balance and spending limits. The withdrawal fee of its wallet type is posted as a separate fee transaction.
+//	@Tags			transaction
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	Transaction
+//	@Router			/api/v1/wallets/{id}/withdrawals [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code wallet_frozen or wallet_closed"
+//	@Failure		422	{object}	limit.Error	"a spending limit was hit, or the balance would break the rules of the wallet type"
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Wallet id"
+//	@Param   withdrawal  body		Movement	true	"Withdrawal"
+func (h *Handler) Withdraw(c echo.Context) error {
+	return h.move(c, h.store.Withdraw)
+}
+
+func (h *Handler) move(c echo.Context, post func(walletID int, amount float64, description string) (*Transaction, error)) error {
+	var m Movement
+	if err := c.Bind(&m); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+	amount := math.Round(m.Amount*100) / 100
+	if amount <= 0 || math.IsInf(amount, 0) {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid amount"})
+	}
+
+	t, err := post(walletId, amount, m.Description)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, t)
+}
+
+// Reverse
+//
+//	@Summary		Reverse transaction
+//	@Description	Undo some or all of a posted transaction with compensating reversal entries, within the status, balance rules and spending limits of the wallets. Reversing a transfer reverses both legs. A transaction can be reversed in parts until nothing is left.
+//	@Tags			transaction
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	Reversal
+//	@Router			/api/v1/transactions/{id}/reverse [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code already_reversed, wallet_frozen or wallet_closed"
+//	@Failure		422	{object}	limit.Error	"not reversible, more than is left, a spending limit was hit, or the balance would break the rules of the wallet type"
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Transaction id"
+//	@Param   reversal  body		ReverseRequest	true	"Reversal"
+//	@Security	AdminToken
+func (h *Handler) Reverse(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid transaction id"})
+	}
+	var r ReverseRequest
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Reason is required"})
+	}
+	if r.Amount != nil {
+		amount := math.Round(*r.Amount*100) / 100
+		if amount <= 0 || math.IsInf(amount, 0) {
+			return c.JSON(http.StatusBadRequest, Err{Message: "Invalid amount"})
+		}
+		r.Amount = &amount
+	}
+
+	reversal, err := h.store.Reverse(id, r.Amount, r.Reason, audit.ActorFrom(c))
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, reversal)
+}
+
+func storeError(c echo.Context, err error) error {
+	var le *limit.Error
+	var be *wallet.BalanceError
+	var se *wallet.StatusError
+	switch {
+	case errors.As(err, &le):
+		return c.JSON(http.StatusUnprocessableEntity, le)
+	case errors.As(err, &be), errors.Is(err, ErrCreditCard), errors.Is(err, ErrNotReversible), errors.Is(err, ErrReversalExceeds):
+		return c.JSON(http.StatusUnprocessableEntity, Err{Message: err.Error()})
+	case errors.As(err, &se):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error(), Code: se.Code()})
+	case errors.Is(err, ErrAlreadyReversed):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error(), Code: CodeAlreadyReversed})
+	case errors.Is(err, ErrNotFound), errors.Is(err, wallet.ErrWalletNotFound):
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
@@ -0,0 +1,98 @@
+package transaction
+
+import (
+	"errors"
+	"time"
+)
+
+// Kinds of transaction. Amount is the signed change to the wallet balance,
+// so for a credit card a spend is positive (more is owed) and a payment is
+// negative.
+const (
+	KindOpening     = "opening"
+	KindAdjustment  = "adjustment"
+	KindSpend       = "spend"
+	KindPayment     = "payment"
+	KindInterest    = "interest"
+	KindDeposit     = "deposit"
+	KindWithdrawal  = "withdrawal"
+	KindTransferIn  = "transfer_in"
+	KindTransferOut = "transfer_out"
+	// KindCapture takes the funds reserved by a hold.
+	KindCapture = "capture"
+	// KindReversal undoes some or all of an earlier transaction.
+	KindReversal = "reversal"
+	// KindFee is charged on transfers and withdrawals on top of the amount.
+	KindFee = "fee"
+)
+
+// OutflowKinds are the kinds moving money out of a wallet, which count
+// against its spending limits.
+var OutflowKinds = []string{KindWithdrawal, KindTransferOut, KindSpend, KindCapture}
+
+// IsOutflow reports whether kind moves money out of a wallet.
+func IsOutflow(kind string) bool {
+	for _, k := range OutflowKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrCreditCard is returned for deposits, withdrawals and transfers out of
+// credit card wallets, which move money through their own endpoints.
+var ErrCreditCard = errors.New("credit card wallets only support spend and payments")
+
+var (
+	ErrNotFound = errors.New("transaction not found")
+	// ErrNotReversible is returned for opening balances and reversals,
+	// which cannot be reversed.
+	ErrNotReversible = errors.New("transaction cannot be reversed")
+	// ErrAlreadyReversed is returned once the whole amount is reversed.
+	ErrAlreadyReversed = errors.New("transaction is already reversed")
+	// ErrReversalExceeds is returned when reversing more than is left.
+	ErrReversalExceeds = errors.New("reversal exceeds the amount left to reverse")
+)
+
+// CodeAlreadyReversed is the error code of ErrAlreadyReversed.
+const CodeAlreadyReversed = "already_reversed"
+
+// Reversible reports whether transactions of kind can be reversed.
+func Reversible(kind string) bool {
+	return kind != KindOpening && kind != KindReversal
+}
+
+// Movement is money put into or taken out of a wallet.
+type Movement struct {
+	Amount      float64 `json:"amount" example:"25.50"`
+	Description string  `json:"description" example:"Cash"`
+}
+
+type Transaction struct {
+	ID           int64   `json:"id" example:"1"`
+	WalletID     int     `json:"wallet_id" example:"1"`
+	Kind         string  `json:"kind" example:"spend"`
+	Amount       float64 `json:"amount" example:"25.50"`
+	BalanceAfter float64 `json:"balance_after" example:"525.50"`
+	Description  string  `json:"description" example:"Coffee"`
+	// ReversalOf links a reversal to the transaction it undoes, and
+	// Reversed is how much of a transaction was reversed so far.
+	ReversalOf *int64    `json:"reversal_of,omitempty" example:"7"`
+	Reversed   float64   `json:"reversed,omitempty" example:"10.00"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+// ReverseRequest reverses amount of a transaction, or all that is left of
+// it when amount is missing.
+type ReverseRequest struct {
+	Amount *float64 `json:"amount,omitempty" example:"10.00"`
+	Reason string   `json:"reason" example:"Deposit posted to the wrong wallet"`
+}
+
+// Reversal is the outcome of a reversal: the reversed transactions, both
+// legs for a transfer, and the compensating entries posted for them.
+type Reversal struct {
+	Reversed []Transaction `json:"reversed"`
+	Entries  []Transaction `json:"entries"`
+}
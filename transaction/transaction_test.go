@@ -0,0 +1,258 @@
+package transaction
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type StubTransactionHandler struct {
+	transactions []Transaction
+	err          error
+}
+
+func (s *StubTransactionHandler) Transactions(walletID int) ([]Transaction, error) {
+	filtered := []Transaction{}
+	for _, t := range s.transactions {
+		if t.WalletID == walletID {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, s.err
+}
+
+func (s *StubTransactionHandler) Deposit(walletID int, amount float64, description string) (*Transaction, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Transaction{WalletID: walletID, Kind: KindDeposit, Amount: amount, Description: description}, nil
+}
+
+func (s *StubTransactionHandler) Withdraw(walletID int, amount float64, description string) (*Transaction, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Transaction{WalletID: walletID, Kind: KindWithdrawal, Amount: -amount, Description: description}, nil
+}
+
+func (s *StubTransactionHandler) Reverse(id int64, amount *float64, reason string, actor audit.Actor) (*Reversal, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	for i, t := range s.transactions {
+		if t.ID != id {
+			continue
+		}
+		if !Reversible(t.Kind) {
+			return nil, ErrNotReversible
+		}
+		left := math.Abs(t.Amount) - t.Reversed
+		if left <= 0 {
+			return nil, ErrAlreadyReversed
+		}
+		reverse := left
+		if amount != nil {
+			if *amount > left {
+				return nil, ErrReversalExceeds
+			}
+			reverse = *amount
+		}
+		s.transactions[i].Reversed += reverse
+		entry := Transaction{WalletID: t.WalletID, Kind: KindReversal, Amount: -math.Copysign(reverse, t.Amount), ReversalOf: &t.ID, Description: reason}
+		return &Reversal{Reversed: []Transaction{s.transactions[i]}, Entries: []Transaction{entry}}, nil
+	}
+	return nil, ErrNotFound
+}
+
+func TestTransaction(t *testing.T) {
+
+	t.Run("given wallet id should return its transactions", func(t *testing.T) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		New(&StubTransactionHandler{transactions: []Transaction{
+			{ID: 1, WalletID: 1, Kind: KindOpening, Amount: 100, BalanceAfter: 100},
+			{ID: 2, WalletID: 2, Kind: KindOpening, Amount: 50, BalanceAfter: 50},
+			{ID: 3, WalletID: 1, Kind: KindSpend, Amount: 25, BalanceAfter: 125},
+		}}).GetTransactions(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+		var resp []Transaction
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if len(resp) != 2 {
+			t.Errorf("expected transactions length %d but got %d", 2, len(resp))
+		}
+	})
+
+	t.Run("given invalid wallet id should return 400", func(t *testing.T) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues("abc")
+
+		New(&StubTransactionHandler{}).GetTransactions(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestMovements(t *testing.T) {
+	movement := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		return c, rec
+	}
+
+	t.Run("given a withdrawal should post a negative transaction", func(t *testing.T) {
+		c, rec := movement(`{"amount": 20.004, "description": "Cash"}`)
+
+		New(&StubTransactionHandler{}).Withdraw(c)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d but got %d", http.StatusCreated, rec.Code)
+		}
+		var resp Transaction
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if resp.Kind != KindWithdrawal || resp.Amount != -20 {
+			t.Errorf("unexpected transaction %+v", resp)
+		}
+	})
+
+	t.Run("given a non-positive amount should return 400", func(t *testing.T) {
+		c, rec := movement(`{"amount": 0}`)
+
+		New(&StubTransactionHandler{}).Deposit(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given a limit is hit should return 422 naming the limit and its reset", func(t *testing.T) {
+		c, rec := movement(`{"amount": 200}`)
+		resets := time.Date(2024, 3, 26, 0, 0, 0, 0, time.UTC)
+		stub := &StubTransactionHandler{err: &limit.Error{Code: limit.CodeLimitExceeded, Limit: limit.DailyOutflow, Message: "Daily outflow limit reached", Allowed: 1000, Used: 900, ResetsAt: &resets}}
+
+		New(stub).Withdraw(c)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+		var resp limit.Error
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if resp.Limit != limit.DailyOutflow || resp.ResetsAt == nil || !resp.ResetsAt.Equal(resets) {
+			t.Errorf("unexpected error %+v", resp)
+		}
+	})
+
+	t.Run("given a frozen wallet should return 409 with its code", func(t *testing.T) {
+		c, rec := movement(`{"amount": 10}`)
+
+		New(&StubTransactionHandler{err: &wallet.StatusError{WalletID: 1, Status: wallet.StatusFrozen}}).Deposit(c)
+
+		var resp Err
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusConflict || resp.Code != wallet.CodeWalletFrozen {
+			t.Errorf("expected %d %s but got %d %s", http.StatusConflict, wallet.CodeWalletFrozen, rec.Code, resp.Code)
+		}
+	})
+}
+
+func TestReverse(t *testing.T) {
+	reverse := func(stub *StubTransactionHandler, id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		New(stub).Reverse(c)
+		return rec
+	}
+	deposit := func() *StubTransactionHandler {
+		return &StubTransactionHandler{transactions: []Transaction{
+			{ID: 1, WalletID: 1, Kind: KindOpening, Amount: 100, BalanceAfter: 100},
+			{ID: 2, WalletID: 1, Kind: KindDeposit, Amount: 50, BalanceAfter: 150},
+		}}
+	}
+
+	t.Run("given a partial reversal should post the compensating entry", func(t *testing.T) {
+		rec := reverse(deposit(), "2", `{"amount": 20, "reason": "Wrong amount"}`)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d but got %d: %s", http.StatusCreated, rec.Code, rec.Body)
+		}
+		var resp Reversal
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if resp.Entries[0].Amount != -20 || *resp.Entries[0].ReversalOf != 2 || resp.Reversed[0].Reversed != 20 {
+			t.Errorf("unexpected reversal %+v", resp)
+		}
+	})
+
+	t.Run("given a transaction already reversed should return 409", func(t *testing.T) {
+		stub := deposit()
+		reverse(stub, "2", `{"reason": "Wrong wallet"}`)
+
+		rec := reverse(stub, "2", `{"reason": "Wrong wallet"}`)
+
+		var resp Err
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusConflict || resp.Code != CodeAlreadyReversed {
+			t.Errorf("expected %d %s but got %d %s", http.StatusConflict, CodeAlreadyReversed, rec.Code, resp.Code)
+		}
+	})
+
+	t.Run("given more than is left should return 422", func(t *testing.T) {
+		stub := deposit()
+		reverse(stub, "2", `{"amount": 40, "reason": "Wrong amount"}`)
+
+		rec := reverse(stub, "2", `{"amount": 20, "reason": "Wrong amount"}`)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+	})
+
+	t.Run("given an opening balance should return 422", func(t *testing.T) {
+		rec := reverse(deposit(), "1", `{"reason": "Wrong opening"}`)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+	})
+
+	t.Run("given no reason or an invalid amount should return 400", func(t *testing.T) {
+		for _, body := range []string{`{"amount": 10}`, `{"amount": -10, "reason": "Oops"}`} {
+			if rec := reverse(deposit(), "2", body); rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status code %d for %s but got %d", http.StatusBadRequest, body, rec.Code)
+			}
+		}
+	})
+
+	t.Run("given an unknown transaction should return 404", func(t *testing.T) {
+		if rec := reverse(deposit(), "9", `{"reason": "Oops"}`); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
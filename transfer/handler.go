@@ -0,0 +1,151 @@
+package transfer
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	CreateTransfer(r Request) (*Transfer, error)
+	Transfer(id int64) (*Transfer, error)
+	QuoteTransfer(r Request) (*Quote, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+}
+
+// CreateTransfer
+//
+//	@Summary		Transfer between wallets
+//	@Description	Move money from one wallet to another atomically, within the balance and spending limits of the source. The fee of the source wallet type is charged to the source on top of the amount. A transfer into a credit card pays it off.
+//	@Tags			transfer
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	Transfer
+//	@Router			/api/v1/transfers [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err	"code wallet_frozen or wallet_closed"
+//	@Failure		422	{object}	limit.Error	"a spending limit was hit, or the balance would break the rules of the wallet type"
+//	@Failure		500	{object}	Err
+//	@Param   transfer  body		Request	true	"Transfer"
+func (h *Handler) CreateTransfer(c echo.Context) error {
+	var r Request
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	r.Amount = math.Round(r.Amount*100) / 100
+	if msg := validate(r); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	t, err := h.store.CreateTransfer(r)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, t)
+}
+
+// QuoteTransfer
+//
+//	@Summary		Quote transfer
+//	@Description	Preview the fee a transfer would be charged now and the total leaving the source, without moving money
+//	@Tags			transfer
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Quote
+//	@Router			/api/v1/transfers/quote [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		422	{object}	Err	"the source is a credit card"
+//	@Failure		500	{object}	Err
+//	@Param   transfer  body		Request	true	"Transfer to quote"
+func (h *Handler) QuoteTransfer(c echo.Context) error {
+	var r Request
+	if err := c.Bind(&r); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	r.Amount = math.Round(r.Amount*100) / 100
+	if msg := validate(r); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	q, err := h.store.QuoteTransfer(r)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, q)
+}
+
+// GetTransfer
+//
+//	@Summary		Get transfer
+//	@Description	Get a transfer by id
+//	@Tags			transfer
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Transfer
+//	@Router			/api/v1/transfers/{id} [get]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Transfer id"
+func (h *Handler) GetTransfer(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid transfer id"})
+	}
+
+	t, err := h.store.Transfer(id)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, t)
+}
+
+func validate(r Request) string {
+	switch {
+	case r.FromWalletID <= 0 || r.ToWalletID <= 0:
+		return "Invalid wallet id"
+	case r.FromWalletID == r.ToWalletID:
+		return "Cannot transfer to the same wallet"
+	case r.Amount <= 0 || math.IsInf(r.Amount, 0):
+		return "Invalid amount"
+	}
+	return ""
+}
+
+func storeError(c echo.Context, err error) error {
+	var le *limit.Error
+	var be *wallet.BalanceError
+	var se *wallet.StatusError
+	switch {
+	case errors.As(err, &le):
+		return c.JSON(http.StatusUnprocessableEntity, le)
+	case errors.As(err, &be), errors.Is(err, transaction.ErrCreditCard), errors.Is(err, creditcard.ErrOverpayment):
+		return c.JSON(http.StatusUnprocessableEntity, Err{Message: err.Error()})
+	case errors.As(err, &se):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error(), Code: se.Code()})
+	case errors.Is(err, ErrNotFound), errors.Is(err, wallet.ErrWalletNotFound):
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
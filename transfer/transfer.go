@@ -0,0 +1,42 @@
+package transfer
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("transfer not found")
+
+// Transfer moves money between two wallets as a pair of transactions: a
+// transfer_out on the source and a transfer_in on the destination, or a
+// payment when the destination is a credit card. A fee, if any, is a third
+// transaction on the source. A wallet id is 0 once that wallet is deleted.
+type Transfer struct {
+	ID                  int64     `json:"id" example:"1"`
+	FromWalletID        int       `json:"from_wallet_id" example:"1"`
+	ToWalletID          int       `json:"to_wallet_id" example:"4"`
+	Amount              float64   `json:"amount" example:"50.00"`
+	Description         string    `json:"description" example:"Rent share"`
+	DebitTransactionID  int64     `json:"debit_transaction_id" example:"10"`
+	CreditTransactionID int64     `json:"credit_transaction_id" example:"11"`
+	Fee                 float64   `json:"fee" example:"0.55"`
+	FeeTransactionID    *int64    `json:"fee_transaction_id,omitempty" example:"12"`
+	CreatedAt           time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+type Request struct {
+	FromWalletID int     `json:"from_wallet_id" example:"1"`
+	ToWalletID   int     `json:"to_wallet_id" example:"4"`
+	Amount       float64 `json:"amount" example:"50.00"`
+	Description  string  `json:"description" example:"Rent share"`
+}
+
+// Quote previews a transfer: the fee it would be charged now and what
+// would leave the source in total.
+type Quote struct {
+	FromWalletID int     `json:"from_wallet_id" example:"1"`
+	ToWalletID   int     `json:"to_wallet_id" example:"4"`
+	Amount       float64 `json:"amount" example:"50.00"`
+	Fee          float64 `json:"fee" example:"0.55"`
+	Total        float64 `json:"total" example:"50.55"`
+}
@@ -0,0 +1,159 @@
+package transfer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/creditcard"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/limit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/transaction"
+	"github.com/labstack/echo/v4"
+)
+
+type StubTransferHandler struct {
+	transfers []Transfer
+	fee       float64
+	err       error
+}
+
+func (s *StubTransferHandler) CreateTransfer(r Request) (*Transfer, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	t := Transfer{ID: int64(len(s.transfers) + 1), FromWalletID: r.FromWalletID, ToWalletID: r.ToWalletID, Amount: r.Amount, Description: r.Description}
+	s.transfers = append(s.transfers, t)
+	return &t, nil
+}
+
+func (s *StubTransferHandler) Transfer(id int64) (*Transfer, error) {
+	for _, t := range s.transfers {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *StubTransferHandler) QuoteTransfer(r Request) (*Quote, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Quote{FromWalletID: r.FromWalletID, ToWalletID: r.ToWalletID, Amount: r.Amount, Fee: s.fee, Total: r.Amount + s.fee}, nil
+}
+
+func request(body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(req, rec), rec
+}
+
+func TestCreateTransfer(t *testing.T) {
+	t.Run("given a valid transfer should create it", func(t *testing.T) {
+		c, rec := request(`{"from_wallet_id": 1, "to_wallet_id": 4, "amount": 50.005, "description": "Rent share"}`)
+		stub := &StubTransferHandler{}
+
+		New(stub).CreateTransfer(c)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d but got %d", http.StatusCreated, rec.Code)
+		}
+		if stub.transfers[0].Amount != 50.01 {
+			t.Errorf("expected amount rounded to 50.01 but got %v", stub.transfers[0].Amount)
+		}
+	})
+
+	t.Run("given invalid transfers should return 400", func(t *testing.T) {
+		for _, body := range []string{
+			`{"from_wallet_id": 1, "to_wallet_id": 1, "amount": 5}`,
+			`{"from_wallet_id": 1, "to_wallet_id": 4, "amount": -5}`,
+			`{"from_wallet_id": 0, "to_wallet_id": 4, "amount": 5}`,
+		} {
+			c, rec := request(body)
+
+			New(&StubTransferHandler{}).CreateTransfer(c)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status code %d for %s but got %d", http.StatusBadRequest, body, rec.Code)
+			}
+		}
+	})
+
+	t.Run("given a transfer limit is hit should return 422 with the limit", func(t *testing.T) {
+		c, rec := request(`{"from_wallet_id": 1, "to_wallet_id": 4, "amount": 5}`)
+		stub := &StubTransferHandler{err: &limit.Error{Code: limit.CodeLimitExceeded, Limit: limit.MaxTransfersPerHour, Message: "Limit of 3 transfers per hour reached"}}
+
+		New(stub).CreateTransfer(c)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+		var resp limit.Error
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if resp.Limit != limit.MaxTransfersPerHour {
+			t.Errorf("expected limit %s but got %s", limit.MaxTransfersPerHour, resp.Limit)
+		}
+	})
+
+	t.Run("given a credit card source or an overpaid card should return 422", func(t *testing.T) {
+		for _, err := range []error{transaction.ErrCreditCard, creditcard.ErrOverpayment} {
+			c, rec := request(`{"from_wallet_id": 2, "to_wallet_id": 4, "amount": 5}`)
+
+			New(&StubTransferHandler{err: err}).CreateTransfer(c)
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Errorf("expected status code %d for %v but got %d", http.StatusUnprocessableEntity, err, rec.Code)
+			}
+		}
+	})
+}
+
+func TestGetTransfer(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+
+	New(&StubTransferHandler{}).GetTransfer(c)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestQuoteTransfer(t *testing.T) {
+	t.Run("given a valid transfer should return the fee and total", func(t *testing.T) {
+		c, rec := request(`{"from_wallet_id": 1, "to_wallet_id": 4, "amount": 50}`)
+
+		New(&StubTransferHandler{fee: 0.55}).QuoteTransfer(c)
+
+		var resp Quote
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusOK || resp.Fee != 0.55 || resp.Total != 50.55 {
+			t.Errorf("unexpected quote %d %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("given an invalid transfer should return 400", func(t *testing.T) {
+		c, rec := request(`{"from_wallet_id": 1, "to_wallet_id": 1, "amount": 50}`)
+
+		New(&StubTransferHandler{}).QuoteTransfer(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given a credit card source should return 422", func(t *testing.T) {
+		c, rec := request(`{"from_wallet_id": 2, "to_wallet_id": 4, "amount": 50}`)
+
+		New(&StubTransferHandler{err: transaction.ErrCreditCard}).QuoteTransfer(c)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+	})
+}
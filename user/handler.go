@@ -14,6 +14,7 @@ type Handler struct {
 
 type Storer interface {
 	WalletsByUserID(userId int) ([]wallet.Wallet, error)
+	SummaryByUserID(userId int) (*Summary, error)
 }
 
 func New(db Storer) *Handler {
@@ -28,14 +29,18 @@ type Err struct {
 //
 //	@Summary		Get all wallets by user id
 //	@Description	Get all wallets by user id
-//	@Router			/api/v1/users/{user_id}/wallets [get]
+//	@Router			/api/v1/users/{id}/wallets [get]
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	wallet.Wallet
+//	@Success		200	{array}	wallet.Wallet
+//	@Success		304	"If-None-Match names the current ETag"
+//	@Header			200	{string}	ETag	"Hash of the body"
+//	@Header			200	{string}	Last-Modified	"When the wallets last changed"
 //	@Failure		400	{object}	Err
 //	@Failure		500	{object}	Err
-//	@Param   user_id  path	string	true "User id"
+//	@Param   id  path	int	true "User id"
+//	@Param   If-None-Match  header	string	false	"ETag of an earlier answer"
 func (h *Handler) WalletByUserId(c echo.Context) error {
 	pUserId := c.Param("id")
 	userId, err := strconv.Atoi(pUserId)
@@ -49,3 +54,29 @@ func (h *Handler) WalletByUserId(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, wallets)
 }
+
+// Summary
+//
+//	@Summary		Get balance summary of a user
+//	@Description	Get total balance, wallet count and balance per wallet type of a user. Net worth counts credit card balances as liabilities.
+//	@Router			/api/v1/users/{id}/summary [get]
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Summary
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path	int	true "User id"
+func (h *Handler) Summary(c echo.Context) error {
+	pUserId := c.Param("id")
+	userId, err := strconv.Atoi(pUserId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid user id"})
+	}
+
+	summary, err := h.store.SummaryByUserID(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, summary)
+}
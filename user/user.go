@@ -0,0 +1,15 @@
+package user
+
+type Summary struct {
+	UserID       int           `json:"user_id" example:"1"`
+	WalletCount  int           `json:"wallet_count" example:"3"`
+	TotalBalance float64       `json:"total_balance" example:"1600.00"`
+	NetWorth     float64       `json:"net_worth" example:"600.00"`
+	ByType       []TypeBalance `json:"by_type"`
+}
+
+type TypeBalance struct {
+	WalletType  string  `json:"wallet_type" example:"Credit Card"`
+	WalletCount int     `json:"wallet_count" example:"1"`
+	Balance     float64 `json:"balance" example:"500.00"`
+}
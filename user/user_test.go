@@ -13,6 +13,7 @@ import (
 
 type StubUserHandler struct {
 	wallets []wallet.Wallet
+	summary *Summary
 	err     error
 }
 
@@ -27,6 +28,10 @@ func (w *StubUserHandler) WalletsByUserID(userId int) ([]wallet.Wallet, error) {
 	return filteredWallets, w.err
 }
 
+func (w *StubUserHandler) SummaryByUserID(userId int) (*Summary, error) {
+	return w.summary, w.err
+}
+
 func TestUser(t *testing.T) {
 
 	t.Run("given user id should return list of wallets", func(t *testing.T) {
@@ -89,4 +94,74 @@ func TestUser(t *testing.T) {
 
 	})
 
+	t.Run("given user id should return balance summary", func(t *testing.T) {
+		t.Parallel()
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/users/:id/summary")
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		handlers := New(&StubUserHandler{
+			summary: &Summary{
+				UserID:       1,
+				WalletCount:  2,
+				TotalBalance: 1500,
+				NetWorth:     500,
+				ByType: []TypeBalance{
+					{WalletType: "Credit Card", WalletCount: 1, Balance: 500},
+					{WalletType: "Savings", WalletCount: 1, Balance: 1000},
+				},
+			},
+		})
+		handlers.Summary(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+		resp := &Summary{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		if resp.NetWorth != 500 || len(resp.ByType) != 2 {
+			t.Errorf("expected net worth %v with 2 types but got %+v", 500, resp)
+		}
+	})
+
+	t.Run("given invalid user id should return 400", func(t *testing.T) {
+		t.Parallel()
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("abc")
+
+		New(&StubUserHandler{}).Summary(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+// TestContractV1 pins the bytes of /api/v1/users/:id/wallets, which
+// clients depend on while /api/v2 evolves.
+func TestContractV1(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/2/wallets", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+
+	New(&StubUserHandler{wallets: []wallet.Wallet{
+		{ID: 1, UserID: 1, UserName: "John Doe", WalletName: "John's Savings", WalletType: "Savings", Balance: 100},
+		{ID: 3, UserID: 2, UserName: "Jane Doe", WalletName: "Jane's Card", WalletType: "Credit Card", Balance: -20.25, AvailableBalance: -20.25,
+			CreatedAt: time.Date(2026, 9, 1, 10, 0, 0, 729237000, time.UTC), Status: wallet.StatusActive},
+	}}).WalletByUserId(c)
+
+	want := `[{"id":3,"user_id":2,"user_name":"Jane Doe","wallet_name":"Jane's Card","wallet_type":"Credit Card","balance":-20.25,"available_balance":-20.25,"created_at":"2026-09-01T10:00:00.729237Z","status":"active"}]` + "\n"
+	if rec.Body.String() != want {
+		t.Errorf("response differs from the v1 contract:\n%s\nwant:\n%s", rec.Body, want)
+	}
 }
@@ -0,0 +1,203 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	ChainBitcoin  = "bitcoin"
+	ChainEthereum = "ethereum"
+)
+
+var ErrInvalidAddress = errors.New("Invalid address")
+
+// ValidateAddress checks a mainnet deposit address of chain and returns it
+// in canonical form: lower-case for bech32 and EIP-55 checksummed for
+// Ethereum.
+func ValidateAddress(chain, address string) (string, error) {
+	address = strings.TrimSpace(address)
+	switch chain {
+	case ChainBitcoin:
+		if strings.HasPrefix(strings.ToLower(address), "bc1") {
+			return validateBech32(address)
+		}
+		return validateBase58(address)
+	case ChainEthereum:
+		return validateEIP55(address)
+	}
+	return "", ErrInvalidAddress
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// validateBech32 accepts segwit addresses (BIP 173): bech32 for witness
+// version 0 and bech32m (BIP 350) for versions 1 to 16, such as taproot.
+func validateBech32(address string) (string, error) {
+	if len(address) < 14 || len(address) > 90 {
+		return "", ErrInvalidAddress
+	}
+	lower := strings.ToLower(address)
+	if address != lower && address != strings.ToUpper(address) {
+		return "", ErrInvalidAddress
+	}
+	sep := strings.LastIndexByte(lower, '1')
+	hrp := lower[:sep]
+	if hrp != "bc" || len(lower)-sep-1 < 6 {
+		return "", ErrInvalidAddress
+	}
+
+	data := make([]byte, 0, len(lower)-sep-1)
+	for _, r := range lower[sep+1:] {
+		i := strings.IndexRune(bech32Charset, r)
+		if i < 0 {
+			return "", ErrInvalidAddress
+		}
+		data = append(data, byte(i))
+	}
+
+	values := append(bech32HRPExpand(hrp), data...)
+	checksum := bech32Polymod(values)
+	version := data[0]
+	switch {
+	case version == 0 && checksum != bech32Const,
+		version > 0 && checksum != bech32mConst,
+		version > 16:
+		return "", ErrInvalidAddress
+	}
+
+	program, ok := convertBits(data[1:len(data)-6], 5, 8)
+	if !ok || len(program) < 2 || len(program) > 40 {
+		return "", ErrInvalidAddress
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return "", ErrInvalidAddress
+	}
+	return lower, nil
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// convertBits regroups data from groups of from bits into groups of to
+// bits, rejecting non-zero padding.
+func convertBits(data []byte, from, to uint) ([]byte, bool) {
+	var acc, bits uint
+	var out []byte
+	maxv := uint(1)<<to - 1
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if bits >= from || acc<<(to-bits)&maxv != 0 {
+		return nil, false
+	}
+	return out, true
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// validateBase58 accepts legacy P2PKH and P2SH addresses with a valid
+// Base58Check checksum.
+func validateBase58(address string) (string, error) {
+	if len(address) < 26 || len(address) > 35 {
+		return "", ErrInvalidAddress
+	}
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, r := range address {
+		i := strings.IndexRune(base58Alphabet, r)
+		if i < 0 {
+			return "", ErrInvalidAddress
+		}
+		n.Mul(n, radix).Add(n, big.NewInt(int64(i)))
+	}
+	decoded := n.Bytes()
+	for i := 0; i < len(address) && address[i] == '1'; i++ {
+		decoded = append([]byte{0}, decoded...)
+	}
+	if len(decoded) != 25 || (decoded[0] != 0x00 && decoded[0] != 0x05) {
+		return "", ErrInvalidAddress
+	}
+
+	first := sha256.Sum256(decoded[:21])
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], decoded[21:]) {
+		return "", ErrInvalidAddress
+	}
+	return address, nil
+}
+
+// validateEIP55 accepts a 0x-prefixed Ethereum address. Mixed-case addresses
+// must carry a valid EIP-55 checksum; all lower or upper case ones carry none
+// and are checksummed.
+func validateEIP55(address string) (string, error) {
+	if len(address) != 42 || !strings.HasPrefix(address, "0x") {
+		return "", ErrInvalidAddress
+	}
+	hexPart := address[2:]
+	if _, err := hex.DecodeString(hexPart); err != nil {
+		return "", ErrInvalidAddress
+	}
+
+	checksummed := eip55(hexPart)
+	lower, upper := strings.ToLower(hexPart), strings.ToUpper(hexPart)
+	if hexPart != lower && hexPart != upper && "0x"+hexPart != checksummed {
+		return "", ErrInvalidAddress
+	}
+	return checksummed, nil
+}
+
+func eip55(hexPart string) string {
+	lower := strings.ToLower(hexPart)
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	out := []byte(lower)
+	for i, c := range out {
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
@@ -0,0 +1,207 @@
+package wallet
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+const (
+	// ImportAtomic inserts every row or none of them.
+	ImportAtomic = "atomic"
+	// ImportBestEffort inserts the valid rows and reports the others.
+	ImportBestEffort = "best_effort"
+)
+
+// csvHeader names the columns of an export. The asset, address and amount
+// of a crypto wallet are empty for the other wallets.
+var csvHeader = []string{"id", "user_id", "user_name", "wallet_name", "wallet_type", "balance", "created_at", "asset", "address", "amount"}
+
+type RowError struct {
+	Row     int    `json:"row" example:"2"`
+	Message string `json:"message" example:"Invalid wallet type"`
+}
+
+type ImportReport struct {
+	Mode     string     `json:"mode" example:"atomic"`
+	Total    int        `json:"total" example:"3"`
+	Inserted int        `json:"inserted" example:"2"`
+	Errors   []RowError `json:"errors"`
+}
+
+// row is a parsed import row, numbered from 1 for the first data row.
+type row struct {
+	n      int
+	wallet Wallet
+	err    error
+}
+
+// parseImport reads every row of r, reporting unparsable rows in row.err
+// rather than failing so that the whole file can be validated at once.
+func parseImport(r io.Reader, format string) ([]row, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatNDJSON:
+		return parseNDJSON(r)
+	}
+	return nil, errors.New("Invalid format")
+}
+
+func parseCSV(r io.Reader) ([]row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, errors.New("Missing CSV header")
+	}
+	cols := map[string]int{}
+	for i, name := range header {
+		cols[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"user_id", "user_name", "wallet_name", "wallet_type", "balance"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("Missing CSV column %s", name)
+		}
+	}
+
+	var rows []row
+	for n := 1; ; n++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			rows = append(rows, row{n: n, err: err})
+			continue
+		}
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		var w Wallet
+		var perr error
+		if w.UserID, err = strconv.Atoi(field("user_id")); err != nil {
+			perr = errors.New("Invalid user id")
+		} else if w.Balance, err = strconv.ParseFloat(field("balance"), 64); err != nil {
+			perr = errors.New("Invalid balance")
+		}
+		w.UserName = field("user_name")
+		w.WalletName = field("wallet_name")
+		w.WalletType = field("wallet_type")
+		// The crypto columns are optional, and empty but for crypto wallets.
+		if asset, address, amount := field("asset"), field("address"), field("amount"); asset != "" || address != "" || amount != "" {
+			w.Crypto = &Crypto{Asset: asset, Address: address, Amount: Amount(amount)}
+		}
+		rows = append(rows, row{n: n, wallet: w, err: perr})
+	}
+}
+
+func parseNDJSON(r io.Reader) ([]row, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []row
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			n--
+			continue
+		}
+		var w Wallet
+		if err := json.Unmarshal([]byte(line), &w); err != nil {
+			rows = append(rows, row{n: n, err: errors.New("Invalid JSON")})
+			continue
+		}
+		rows = append(rows, row{n: n, wallet: w})
+	}
+	return rows, sc.Err()
+}
+
+// maxNameLength is the length in characters of the VARCHAR(255) user_name
+// and wallet_name columns.
+const maxNameLength = 255
+
+// validateImport checks a wallet to be imported against the wallet types
+// and the columns it is stored in, so that no row fails in the database,
+// and returns it with its wallet type normalized to the stored name.
+func validateImport(w Wallet, types []Type) (Wallet, error) {
+	switch {
+	case w.UserID <= 0 || w.UserID > math.MaxInt32:
+		return w, errors.New("Invalid user id")
+	case strings.TrimSpace(w.UserName) == "":
+		return w, errors.New("Missing user name")
+	case strings.TrimSpace(w.WalletName) == "":
+		return w, errors.New("Missing wallet name")
+	case math.IsNaN(w.Balance) || math.IsInf(w.Balance, 0) || math.Abs(w.Balance) >= 1e8:
+		return w, errors.New("Invalid balance")
+	}
+	if err := checkName("User name", w.UserName); err != nil {
+		return w, err
+	}
+	if err := checkName("Wallet name", w.WalletName); err != nil {
+		return w, err
+	}
+
+	t, ok := ResolveType(types, w.WalletType)
+	if !ok {
+		return w, errors.New("Invalid wallet type")
+	}
+	if err := t.CheckBalance(w.Balance); err != nil {
+		return w, err
+	}
+	w.WalletType = t.Name
+	if err := ValidateCrypto(&w, true); err != nil {
+		return w, err
+	}
+	w.ID = 0
+	return w, nil
+}
+
+// checkName refuses what a VARCHAR(255) column does not store: longer text,
+// invalid UTF-8 and NUL characters.
+func checkName(what, name string) error {
+	switch {
+	case !utf8.ValidString(name) || strings.ContainsRune(name, 0):
+		return fmt.Errorf("Invalid %s", strings.ToLower(what))
+	case utf8.RuneCountInString(name) > maxNameLength:
+		return fmt.Errorf("%s is longer than %d characters", what, maxNameLength)
+	}
+	return nil
+}
+
+func csvRecord(w Wallet) []string {
+	var asset, address, amount string
+	if c := w.Crypto; c != nil {
+		asset, address, amount = c.Asset, c.Address, string(c.Amount)
+	}
+	return []string{
+		strconv.Itoa(w.ID),
+		strconv.Itoa(w.UserID),
+		w.UserName,
+		w.WalletName,
+		w.WalletType,
+		strconv.FormatFloat(w.Balance, 'f', 2, 64),
+		w.CreatedAt.Format("2006-01-02T15:04:05.999999Z07:00"),
+		asset,
+		address,
+		amount,
+	}
+}
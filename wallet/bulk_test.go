@@ -0,0 +1,262 @@
+package wallet
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func bulkSetup(method, target, contentType, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, contentType)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestImportWallets(t *testing.T) {
+	const validCSV = `user_id,user_name,wallet_name,wallet_type,balance
+1,John Doe,John Savings,Savings,100.50
+2,Jane Doe,Jane Card,CreditCard,0
+`
+	const mixedNDJSON = `{"user_id": 1, "user_name": "John Doe", "wallet_name": "John Savings", "wallet_type": "Savings", "balance": 10}
+{"user_id": 0, "user_name": "Nobody", "wallet_name": "Nothing", "wallet_type": "Savings", "balance": 10}
+
+{"user_id": 2, "user_name": "Jane Doe", "wallet_name": "Jane Gold", "wallet_type": "Gold", "balance": 10}
+not json
+`
+
+	t.Run("given valid csv should insert every row", func(t *testing.T) {
+		c, rec := bulkSetup(http.MethodPost, "/", "text/csv", validCSV)
+		stub := &StubWalletHandler{}
+
+		New(stub).ImportWallets(c)
+
+		if rec.Code != http.StatusCreated {
+			t.Errorf("expected status code %d but got %d", http.StatusCreated, rec.Code)
+		}
+		resp := &ImportReport{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		if resp.Inserted != 2 || len(resp.Errors) != 0 {
+			t.Errorf("expected 2 inserted rows without errors but got %+v", resp)
+		}
+		if stub.wallets[1].WalletType != "Credit Card" {
+			t.Errorf("expected wallet type %s but got %s", "Credit Card", stub.wallets[1].WalletType)
+		}
+	})
+
+	t.Run("given invalid rows in atomic mode should insert nothing and report every row", func(t *testing.T) {
+		c, rec := bulkSetup(http.MethodPost, "/", "application/x-ndjson", mixedNDJSON)
+		stub := &StubWalletHandler{}
+
+		New(stub).ImportWallets(c)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status code %d but got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+		resp := &ImportReport{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		want := []RowError{
+			{Row: 2, Message: "Invalid user id"},
+			{Row: 3, Message: "Invalid wallet type"},
+			{Row: 4, Message: "Invalid JSON"},
+		}
+		if len(resp.Errors) != len(want) {
+			t.Fatalf("expected errors %v but got %v", want, resp.Errors)
+		}
+		for i := range want {
+			if resp.Errors[i] != want[i] {
+				t.Errorf("expected error %v but got %v", want[i], resp.Errors[i])
+			}
+		}
+		if len(stub.wallets) != 0 {
+			t.Errorf("expected no wallets but got %d", len(stub.wallets))
+		}
+	})
+
+	t.Run("given invalid rows in best effort mode should insert valid rows", func(t *testing.T) {
+		c, rec := bulkSetup(http.MethodPost, "/?mode=best_effort", "application/x-ndjson", mixedNDJSON)
+		stub := &StubWalletHandler{}
+
+		New(stub).ImportWallets(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+		resp := &ImportReport{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		if resp.Total != 4 || resp.Inserted != 1 || len(resp.Errors) != 3 {
+			t.Errorf("expected 1 of 4 rows inserted but got %+v", resp)
+		}
+	})
+
+	t.Run("given names longer than their columns in best effort mode should report the rows", func(t *testing.T) {
+		long := strings.Repeat("a", 256)
+		body := "user_id,user_name,wallet_name,wallet_type,balance\n" +
+			"1,John Doe,John Savings,Savings,10\n" +
+			"1," + long + ",John Spare,Savings,10\n" +
+			"1,John Doe," + long + ",Savings,10\n" +
+			"1,John Doe," + strings.Repeat("ก", 255) + ",Savings,10\n" +
+			"3000000000,John Doe,John Big,Savings,10\n"
+		c, rec := bulkSetup(http.MethodPost, "/?mode=best_effort", "text/csv", body)
+		stub := &StubWalletHandler{}
+
+		New(stub).ImportWallets(c)
+
+		resp := &ImportReport{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		want := []RowError{
+			{Row: 2, Message: "User name is longer than 255 characters"},
+			{Row: 3, Message: "Wallet name is longer than 255 characters"},
+			{Row: 5, Message: "Invalid user id"},
+		}
+		if rec.Code != http.StatusOK || resp.Inserted != 2 || !reflect.DeepEqual(resp.Errors, want) {
+			t.Errorf("expected 2 inserted and errors %v but got %d %+v", want, rec.Code, resp)
+		}
+	})
+
+	t.Run("given crypto wallets should validate their crypto details", func(t *testing.T) {
+		const crypto = `{"user_id": 1, "user_name": "John Doe", "wallet_name": "John Bitcoin", "wallet_type": "Crypto Wallet", "balance": 0, "crypto": {"asset": "btc", "address": "BC1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ", "amount": "0.5"}}
+{"user_id": 1, "user_name": "John Doe", "wallet_name": "John Ether", "wallet_type": "Crypto Wallet", "balance": 0, "crypto": {"asset": "ETH", "address": "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", "amount": "1"}}
+{"user_id": 1, "user_name": "John Doe", "wallet_name": "John Coins", "wallet_type": "Crypto Wallet", "balance": 0}
+{"user_id": 1, "user_name": "John Doe", "wallet_name": "John Savings", "wallet_type": "Savings", "balance": 0, "crypto": {"asset": "BTC", "address": "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", "amount": "1"}}
+`
+		c, rec := bulkSetup(http.MethodPost, "/?mode=best_effort", "application/x-ndjson", crypto)
+		stub := &StubWalletHandler{}
+
+		New(stub).ImportWallets(c)
+
+		resp := &ImportReport{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		want := []RowError{
+			{Row: 2, Message: ErrInvalidAddress.Error()},
+			{Row: 3, Message: ErrMissingCrypto.Error()},
+			{Row: 4, Message: "Only crypto wallets have crypto details"},
+		}
+		if resp.Inserted != 1 || len(resp.Errors) != len(want) {
+			t.Fatalf("expected 1 inserted row and errors %v but got %+v", want, resp)
+		}
+		for i := range want {
+			if resp.Errors[i] != want[i] {
+				t.Errorf("expected error %v but got %v", want[i], resp.Errors[i])
+			}
+		}
+		got := stub.wallets[0].Crypto
+		if got == nil || got.Asset != "BTC" || got.Address != "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq" || got.Decimals != 8 {
+			t.Errorf("expected normalized crypto details but got %+v", got)
+		}
+	})
+
+	t.Run("given csv without required column should return 400", func(t *testing.T) {
+		c, rec := bulkSetup(http.MethodPost, "/?format=csv", "text/plain", "user_id,user_name\n1,John\n")
+
+		New(&StubWalletHandler{}).ImportWallets(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestExportWallets(t *testing.T) {
+	createdAt := time.Date(2024, 3, 25, 14, 19, 0, 0, time.UTC)
+	wallets := []Wallet{
+		{ID: 1, UserID: 1, UserName: "John Doe", WalletName: "John, Savings", WalletType: "Savings", Balance: 100.5, CreatedAt: createdAt},
+		{ID: 2, UserID: 2, UserName: "Jane Doe", WalletName: "Jane Card", WalletType: "Credit Card", Balance: 20, CreatedAt: createdAt},
+	}
+
+	t.Run("given csv format should stream header and rows", func(t *testing.T) {
+		c, rec := bulkSetup(http.MethodGet, "/?format=csv", "", "")
+
+		New(&StubWalletHandler{wallets: wallets}).ExportWallets(c)
+
+		want := `id,user_id,user_name,wallet_name,wallet_type,balance,created_at,asset,address,amount
+1,1,John Doe,"John, Savings",Savings,100.50,2024-03-25T14:19:00Z,,,
+2,2,Jane Doe,Jane Card,Credit Card,20.00,2024-03-25T14:19:00Z,,,
+`
+		if rec.Body.String() != want {
+			t.Errorf("expected body %q but got %q", want, rec.Body.String())
+		}
+	})
+
+	t.Run("given ndjson format and wallet type should stream matching wallets", func(t *testing.T) {
+		c, rec := bulkSetup(http.MethodGet, "/?format=ndjson&wallet_type=Savings", "", "")
+
+		New(&StubWalletHandler{wallets: wallets}).ExportWallets(c)
+
+		if ct := rec.Header().Get(echo.HeaderContentType); ct != "application/x-ndjson" {
+			t.Errorf("expected content type %s but got %s", "application/x-ndjson", ct)
+		}
+		var got []Wallet
+		sc := bufio.NewScanner(rec.Body)
+		for sc.Scan() {
+			var w Wallet
+			json.Unmarshal(sc.Bytes(), &w)
+			got = append(got, w)
+		}
+		if len(got) != 1 || got[0].ID != 1 {
+			t.Errorf("expected only wallet 1 but got %v", got)
+		}
+	})
+
+	t.Run("given no wallets should stream the csv header only", func(t *testing.T) {
+		c, rec := bulkSetup(http.MethodGet, "/?format=csv", "", "")
+
+		New(&StubWalletHandler{}).ExportWallets(c)
+
+		if rec.Code != http.StatusOK || rec.Body.String() != "id,user_id,user_name,wallet_name,wallet_type,balance,created_at,asset,address,amount\n" {
+			t.Errorf("expected the header only but got %d %q", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("given the store fails before the first wallet should return 500", func(t *testing.T) {
+		c, rec := bulkSetup(http.MethodGet, "/?format=csv", "", "")
+
+		New(&StubWalletHandler{err: errors.New("failed to get wallets")}).ExportWallets(c)
+
+		if rec.Code != http.StatusInternalServerError || !strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+			t.Errorf("expected status code %d but got %d %s", http.StatusInternalServerError, rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("given a crypto wallet should export csv that imports back", func(t *testing.T) {
+		crypto := Wallet{ID: 3, UserID: 1, UserName: "John Doe", WalletName: "John Bitcoin", WalletType: "Crypto Wallet", Balance: 10, CreatedAt: createdAt,
+			Crypto: &Crypto{Asset: "BTC", Address: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", Decimals: 8, Amount: "0.5"}}
+		c, rec := bulkSetup(http.MethodGet, "/?format=csv", "", "")
+		New(&StubWalletHandler{wallets: []Wallet{wallets[0], crypto}}).ExportWallets(c)
+
+		c, imported := bulkSetup(http.MethodPost, "/", "text/csv", rec.Body.String())
+		stub := &StubWalletHandler{}
+		New(stub).ImportWallets(c)
+
+		report := &ImportReport{}
+		json.Unmarshal(imported.Body.Bytes(), report)
+		if imported.Code != http.StatusCreated || report.Inserted != 2 || len(stub.wallets) != 2 {
+			t.Fatalf("expected both wallets imported but got %d %s", imported.Code, imported.Body)
+		}
+		if got := stub.wallets[1].Crypto; got == nil || *got != *crypto.Crypto {
+			t.Errorf("expected crypto details %+v but got %+v", crypto.Crypto, got)
+		}
+		if stub.wallets[0].Crypto != nil {
+			t.Errorf("expected no crypto details but got %+v", stub.wallets[0].Crypto)
+		}
+	})
+
+	t.Run("given unknown format should return 400", func(t *testing.T) {
+		c, rec := bulkSetup(http.MethodGet, "/?format=xml", "", "")
+
+		New(&StubWalletHandler{}).ExportWallets(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package wallet
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/internal/testutil"
+	"github.com/labstack/echo/v4"
+)
+
+// The tests here pin the bytes /api/v1 answers with, which clients depend
+// on while /api/v2 evolves. A golden file only changes with a new version.
+
+func contractWallets() []Wallet {
+	created := time.Date(2026, 9, 1, 10, 0, 0, 729237000, time.UTC)
+	return []Wallet{
+		{ID: 1, UserID: 1, UserName: "John Doe", WalletName: "John's Savings", WalletType: TypeSavings,
+			Balance: 100.5, AvailableBalance: 75, CreatedAt: created, Status: StatusActive},
+		{ID: 2, UserID: 1, UserName: "John Doe", WalletName: "John's Card", WalletType: TypeCreditCard,
+			Balance: -20.25, AvailableBalance: -20.25, CreatedAt: created, Status: StatusFrozen},
+		{ID: 3, UserID: 2, UserName: "Jane <Doe> & Co", WalletName: "Jane's Bitcoin", WalletType: TypeCryptoWallet,
+			Balance: 0, AvailableBalance: 0, CreatedAt: created.In(time.FixedZone("ICT", 7*60*60)), Status: StatusActive,
+			Crypto: &Crypto{Asset: "BTC", Address: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", Decimals: 8, Amount: "0.00000001"}},
+	}
+}
+
+func TestContractV1(t *testing.T) {
+	t.Run("GET /api/v1/wallets", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil)
+		rec := httptest.NewRecorder()
+
+		New(&StubWalletHandler{wallets: contractWallets(), types: builtinTypes}).GetWallet(echo.New().NewContext(req, rec))
+
+		if rec.Code != http.StatusOK || rec.Header().Get(echo.HeaderContentType) != "application/json; charset=UTF-8" {
+			t.Fatalf("unexpected response %d %s", rec.Code, rec.Header())
+		}
+		testutil.Golden(t, "v1_wallets.json", rec.Body.Bytes())
+	})
+
+	t.Run("PUT /api/v1/wallets/:id", func(t *testing.T) {
+		body := `{"user_id": 1, "user_name": "John Doe", "wallet_name": "John's Savings", "wallet_type": "Savings", "balance": 150.5}`
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/wallets/1", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		New(&StubWalletHandler{wallets: contractWallets(), types: builtinTypes}).UpdateWallet(c)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected response %d %s", rec.Code, rec.Body)
+		}
+		testutil.Golden(t, "v1_wallet.json", rec.Body.Bytes())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets?wallet_type=Gold", nil)
+		rec := httptest.NewRecorder()
+
+		New(&StubWalletHandler{types: builtinTypes}).GetWallet(echo.New().NewContext(req, rec))
+
+		testutil.Golden(t, "v1_error.json", rec.Body.Bytes())
+	})
+}
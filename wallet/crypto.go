@@ -0,0 +1,164 @@
+package wallet
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxDecimals is the finest precision an asset can have, matching the scale
+// of the stored amount.
+const MaxDecimals = 18
+
+// maxIntegerDigits keeps amounts within NUMERIC(38, 18).
+const maxIntegerDigits = 38 - MaxDecimals
+
+// Asset is a crypto currency a wallet can hold, with the chain deciding its
+// address format and the number of decimals of its smallest unit.
+type Asset struct {
+	Symbol   string
+	Chain    string
+	Decimals int
+}
+
+var Assets = map[string]Asset{
+	"BTC":  {Symbol: "BTC", Chain: ChainBitcoin, Decimals: 8},
+	"ETH":  {Symbol: "ETH", Chain: ChainEthereum, Decimals: 18},
+	"USDC": {Symbol: "USDC", Chain: ChainEthereum, Decimals: 6},
+	"USDT": {Symbol: "USDT", Chain: ChainEthereum, Decimals: 6},
+}
+
+// Crypto holds the on-chain side of a crypto wallet. Amount is the quantity
+// of the asset, kept exactly, while the wallet balance stays its book value.
+type Crypto struct {
+	Asset    string `json:"asset" example:"BTC"`
+	Address  string `json:"address" example:"bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"`
+	Decimals int    `json:"decimals" example:"8"`
+	Amount   Amount `json:"amount" swaggertype:"string" example:"0.00000001"`
+}
+
+// Amount is a non-negative decimal kept as text so that no precision is
+// lost between JSON, Go and Postgres. It is serialized as a JSON string and
+// accepts a JSON string or number.
+type Amount string
+
+var amountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ParseAmount validates s as an amount with at most decimals decimals and
+// returns it in canonical form, without leading or trailing zeros.
+func ParseAmount(s string, decimals int) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if !amountPattern.MatchString(s) {
+		return "", errors.New("Invalid amount")
+	}
+	a := canonical(s)
+	whole, frac, _ := strings.Cut(string(a), ".")
+	if len(frac) > decimals {
+		return "", fmt.Errorf("Amount has more than %d decimals", decimals)
+	}
+	if len(whole) > maxIntegerDigits {
+		return "", errors.New("Amount is too large")
+	}
+	return a, nil
+}
+
+func canonical(s string) Amount {
+	whole, frac, _ := strings.Cut(s, ".")
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+	frac = strings.TrimRight(frac, "0")
+	if frac == "" {
+		return Amount(whole)
+	}
+	return Amount(whole + "." + frac)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if a == "" {
+		return []byte(`"0"`), nil
+	}
+	return json.Marshal(string(a))
+}
+
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if strings.HasPrefix(s, `"`) {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return errors.New("Invalid amount")
+		}
+	}
+	*a = Amount(s)
+	return nil
+}
+
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		*a = canonical(string(v))
+	case string:
+		*a = canonical(v)
+	case nil:
+		*a = ""
+	default:
+		return fmt.Errorf("cannot scan %T into Amount", src)
+	}
+	return nil
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	if a == "" {
+		return "0", nil
+	}
+	return string(a), nil
+}
+
+// ErrMissingCrypto is returned for a crypto wallet without crypto details,
+// by ValidateCrypto and by stores asked to make a wallet a crypto wallet
+// without giving them.
+var ErrMissingCrypto = errors.New("Missing crypto details")
+
+// ValidateCrypto checks the crypto details of w against its wallet type and
+// normalizes them: the symbol is upper-cased, the address put in its
+// canonical form and the decimals taken from the asset. Crypto wallets must
+// carry them when required is true.
+func ValidateCrypto(w *Wallet, required bool) error {
+	if w.WalletType != TypeCryptoWallet {
+		if w.Crypto != nil {
+			return errors.New("Only crypto wallets have crypto details")
+		}
+		return nil
+	}
+	if w.Crypto == nil {
+		if required {
+			return ErrMissingCrypto
+		}
+		return nil
+	}
+
+	c := w.Crypto
+	asset, ok := Assets[strings.ToUpper(strings.TrimSpace(c.Asset))]
+	if !ok {
+		return errors.New("Unsupported crypto asset")
+	}
+	address, err := ValidateAddress(asset.Chain, c.Address)
+	if err != nil {
+		return err
+	}
+	amount := string(c.Amount)
+	if amount == "" {
+		amount = "0"
+	}
+	parsed, err := ParseAmount(amount, asset.Decimals)
+	if err != nil {
+		return err
+	}
+	*c = Crypto{Asset: asset.Symbol, Address: address, Decimals: asset.Decimals, Amount: parsed}
+	return nil
+}
@@ -0,0 +1,189 @@
+package wallet
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals int
+		want     Amount
+		wantErr  bool
+	}{
+		{"0.00000001", 8, "0.00000001", false},
+		{"000.10000000", 8, "0.1", false},
+		{"12", 8, "12", false},
+		{"0.000000000000000001", 18, "0.000000000000000001", false},
+		{"0.000000001", 8, "", true},
+		{"-1", 8, "", true},
+		{"1e-8", 8, "", true},
+		{"123456789012345678901", 18, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in, tt.decimals)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAmount(%q, %d) = %q, %v", tt.in, tt.decimals, got, err)
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	t.Run("given a number should keep every digit", func(t *testing.T) {
+		var c Crypto
+		if err := json.Unmarshal([]byte(`{"amount": 0.123456789012345678}`), &c); err != nil {
+			t.Fatal(err)
+		}
+		if c.Amount != "0.123456789012345678" {
+			t.Errorf("expected 0.123456789012345678 but got %s", c.Amount)
+		}
+	})
+
+	t.Run("given an amount should serialize it as a string", func(t *testing.T) {
+		b, _ := json.Marshal(Crypto{Amount: "0.00000001"})
+		if !strings.Contains(string(b), `"amount":"0.00000001"`) {
+			t.Errorf("unexpected JSON %s", b)
+		}
+	})
+}
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		chain   string
+		address string
+		want    string
+	}{
+		{ChainBitcoin, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"},
+		{ChainBitcoin, "BC1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"},
+		{ChainBitcoin, "bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3", "bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3"},
+		{ChainBitcoin, "bc1p5d7rjq7g6rdk2yhzks9smlaqtedr4dekq08ge8ztwac72sfr9rusxg3297", "bc1p5d7rjq7g6rdk2yhzks9smlaqtedr4dekq08ge8ztwac72sfr9rusxg3297"},
+		{ChainBitcoin, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+		{ChainBitcoin, "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy"},
+		{ChainBitcoin, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdr", ""},
+		{ChainBitcoin, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzWf5mdq", ""},
+		{ChainBitcoin, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", ""},
+		{ChainBitcoin, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ""},
+		{ChainEthereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{ChainEthereum, "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"},
+		{ChainEthereum, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{ChainEthereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", ""},
+		{ChainEthereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAe", ""},
+	}
+	for _, tt := range tests {
+		got, err := ValidateAddress(tt.chain, tt.address)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("expected %s address %s to be invalid", tt.chain, tt.address)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ValidateAddress(%s, %s) = %s, %v, want %s", tt.chain, tt.address, got, err, tt.want)
+		}
+	}
+}
+
+func TestUpdateCryptoWallet(t *testing.T) {
+	update := func(t *testing.T, stub *StubWalletHandler, walletJSON string) (*httptest.ResponseRecorder, *Wallet) {
+		c, rec := setup(t, func() *http.Request {
+			return httptest.NewRequest(http.MethodPut, "/", strings.NewReader(walletJSON))
+		})
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		New(stub).UpdateWallet(c)
+
+		w := &Wallet{}
+		json.Unmarshal(rec.Body.Bytes(), w)
+		return rec, w
+	}
+	btc := &Crypto{Asset: "BTC", Address: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", Decimals: 8, Amount: "1"}
+
+	t.Run("given a wallet becoming a crypto wallet without crypto details should return 400", func(t *testing.T) {
+		stub := &StubWalletHandler{wallets: []Wallet{{ID: 1, UserID: 1, UserName: "John Doe", WalletName: "John Savings", WalletType: TypeSavings}}}
+
+		rec, _ := update(t, stub, `{"user_id": 1, "user_name": "John Doe", "wallet_name": "John Bitcoin", "wallet_type": "Crypto Wallet", "balance": 0}`)
+
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), ErrMissingCrypto.Error()) {
+			t.Errorf("expected status code %d but got %d %s", http.StatusBadRequest, rec.Code, rec.Body)
+		}
+		if stub.wallets[0].WalletType != TypeSavings {
+			t.Errorf("expected the wallet unchanged but got %+v", stub.wallets[0])
+		}
+	})
+
+	t.Run("given a crypto wallet without crypto details should keep them", func(t *testing.T) {
+		stub := &StubWalletHandler{wallets: []Wallet{{ID: 1, UserID: 1, UserName: "John Doe", WalletName: "John Bitcoin", WalletType: TypeCryptoWallet, Crypto: btc}}}
+
+		rec, w := update(t, stub, `{"user_id": 1, "user_name": "John Doe", "wallet_name": "John BTC", "wallet_type": "Crypto Wallet", "balance": 0}`)
+
+		if rec.Code != http.StatusOK || w.Crypto == nil || *w.Crypto != *btc {
+			t.Errorf("expected the crypto details kept but got %d %s", rec.Code, rec.Body)
+		}
+	})
+}
+
+func TestCreateCryptoWallet(t *testing.T) {
+	t.Run("given a crypto wallet without crypto details should return 400", func(t *testing.T) {
+		c, rec := setup(t, func() *http.Request {
+			walletJSON := `{"user_id": 1, "user_name": "John Doe", "wallet_name": "Coins", "wallet_type": "Crypto Wallet", "balance": 100}`
+			return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(walletJSON))
+		})
+
+		New(&StubWalletHandler{}).CreateWallet(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given too many decimals for the asset should return 400", func(t *testing.T) {
+		c, rec := setup(t, func() *http.Request {
+			walletJSON := `{"user_id": 1, "user_name": "John Doe", "wallet_name": "Coins", "wallet_type": "Crypto Wallet", "balance": 100,
+				"crypto": {"asset": "BTC", "address": "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", "amount": "0.000000001"}}`
+			return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(walletJSON))
+		})
+
+		New(&StubWalletHandler{}).CreateWallet(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given valid crypto details should create the wallet with them normalized", func(t *testing.T) {
+		c, rec := setup(t, func() *http.Request {
+			walletJSON := `{"user_id": 1, "user_name": "John Doe", "wallet_name": "Coins", "wallet_type": "Crypto Wallet", "balance": 100,
+				"crypto": {"asset": "eth", "address": "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "amount": 1.000000000000000001}}`
+			return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(walletJSON))
+		})
+
+		New(&StubWalletHandler{}).CreateWallet(c)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d but got %d", http.StatusCreated, rec.Code)
+		}
+		want := `"crypto":{"asset":"ETH","address":"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed","decimals":18,"amount":"1.000000000000000001"}`
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("expected %s in %s", want, rec.Body.String())
+		}
+	})
+
+	t.Run("given crypto details on another wallet type should return 400", func(t *testing.T) {
+		c, rec := setup(t, func() *http.Request {
+			walletJSON := `{"user_id": 1, "user_name": "John Doe", "wallet_name": "Savings", "wallet_type": "Savings", "balance": 100,
+				"crypto": {"asset": "BTC", "address": "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", "amount": "1"}}`
+			return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(walletJSON))
+		})
+
+		New(&StubWalletHandler{}).CreateWallet(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
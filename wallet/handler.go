@@ -1,27 +1,34 @@
 package wallet
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
 	"github.com/labstack/echo/v4"
 )
 
-var WalletType = map[string]string{
-	"Savings":      "Savings",
-	"CreditCard":   "Credit Card",
-	"CryptoWallet": "Crypto Wallet",
-}
-
 type Handler struct {
 	store Storer
 }
 
 type Storer interface {
 	Wallets(walletType string) ([]Wallet, error)
-	CreateWallet(wallet Wallet) (*Wallet, error)
-	UpdateWallet(wallet Wallet) (*Wallet, error)
-	DeleteWallet(id int) error
+	CreateWallet(wallet Wallet, actor audit.Actor) (*Wallet, error)
+	UpdateWallet(wallet Wallet, actor audit.Actor) (*Wallet, error)
+	DeleteWallet(id int, actor audit.Actor) error
+	ImportWallets(wallets []Wallet, actor audit.Actor) (int, error)
+	EachWallet(walletType string, fn func(Wallet) error) error
+	WalletTypes() ([]Type, error)
+	CreateWalletType(t Type) (*Type, error)
+	UpdateWalletType(t Type) (*Type, error)
+	DeleteWalletType(key string) error
+	ChangeStatus(id int, status, reason string, actor audit.Actor) (*Wallet, error)
+	StatusHistory(id int) ([]StatusChange, error)
 }
 
 func New(db Storer) *Handler {
@@ -30,6 +37,7 @@ func New(db Storer) *Handler {
 
 type Err struct {
 	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
 }
 
 // GetWallet
@@ -39,21 +47,27 @@ type Err struct {
 //	@Tags			wallet
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	Wallet
+//	@Success		200	{array}	Wallet
 //	@Router			/api/v1/wallets [get]
+//	@Success		304	"If-None-Match names the current ETag"
+//	@Header			200	{string}	ETag	"Hash of the body"
+//	@Header			200	{string}	Last-Modified	"When the wallets last changed"
 //	@Failure		500	{object}	Err
 //	@Failure		400	{object}	Err
-//	@Param   wallet_type  query	string	false	"Wallet type"	Enums(Savings, CreditCard, CryptoWallet)
+//	@Param   wallet_type  query	string	false	"Wallet type key, see /api/v1/wallet-types"
+//	@Param   If-None-Match  header	string	false	"ETag of an earlier answer"
 func (h *Handler) GetWallet(c echo.Context) error {
 	walletType := c.QueryParam("wallet_type")
 
 	if walletType != "" {
-		if _, ok := WalletType[walletType]; !ok {
-			return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet type"})
+		t, err := h.resolveType(walletType)
+		if err != nil {
+			return invalidType(c, err)
 		}
+		walletType = t.Name
 	}
 
-	wallets, err := h.store.Wallets(WalletType[walletType])
+	wallets, err := h.store.Wallets(walletType)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
 	}
@@ -63,13 +77,15 @@ func (h *Handler) GetWallet(c echo.Context) error {
 // Create new wallet
 //
 // @Summary		Create new wallet
-// @Description	Create new wallet
+// @Description	Create new wallet. Crypto wallets must carry crypto details: a supported asset, a deposit address valid for its chain
+// @Description	(Bitcoin bech32 or base58, Ethereum with an EIP-55 checksum) and an amount within the decimals of the asset.
 // @Tags			wallet
 // @Accept			json
 // @Produce		json
 // @Router			/api/v1/wallets [post]
-// @Success		200	{object}	Wallet
+// @Success		201	{object}	Wallet
 // @Failure		400	{object}	Err
+// @Failure		422	{object}	Err	"balance breaks the rules of the wallet type"
 // @Failure		500	{object}	Err
 // @Param   wallet  body		Wallet	true	"Wallet"
 func (h *Handler) CreateWallet(c echo.Context) error {
@@ -78,10 +94,21 @@ func (h *Handler) CreateWallet(c echo.Context) error {
 	if err := c.Bind(&w); err != nil {
 		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
 	}
+	t, err := h.resolveType(w.WalletType)
+	if err != nil {
+		return invalidType(c, err)
+	}
+	w.WalletType = t.Name
+	if err := t.CheckBalance(w.Balance); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, Err{Message: err.Error()})
+	}
+	if err := ValidateCrypto(&w, true); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
 
-	wallet, err := h.store.CreateWallet(w)
+	wallet, err := h.store.CreateWallet(w, audit.ActorFrom(c))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+		return storeError(c, err)
 	}
 	return c.JSON(http.StatusCreated, wallet)
 }
@@ -89,13 +116,16 @@ func (h *Handler) CreateWallet(c echo.Context) error {
 // UpdateWallet
 //
 // @Summary		Update wallet
-// @Description	Update wallet
+// @Description	Update wallet. The crypto details of a crypto wallet are kept when omitted, and required of a wallet becoming a crypto wallet.
 // @Tags			wallet
 // @Accept			json
 // @Produce		json
 // @Router			/api/v1/wallets/{id} [put]
 // @Success		200	{object}	Wallet
 // @Failure		400	{object}	Err
+// @Failure		404	{object}	Err
+// @Failure		409	{object}	Err	"code wallet_frozen or wallet_closed when the wallet is not active"
+// @Failure		422	{object}	Err	"balance breaks the rules of the wallet type"
 // @Failure		500	{object}	Err
 // @Param   id  path		int	true	"Wallet id"
 // @Param   wallet  body		Wallet	true	"Wallet"
@@ -111,10 +141,21 @@ func (h *Handler) UpdateWallet(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
 	}
 	w.ID = walletId
+	t, err := h.resolveType(w.WalletType)
+	if err != nil {
+		return invalidType(c, err)
+	}
+	w.WalletType = t.Name
+	if err := t.CheckBalance(w.Balance); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, Err{Message: err.Error()})
+	}
+	if err := ValidateCrypto(&w, false); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
 
-	wallet, err := h.store.UpdateWallet(w)
+	wallet, err := h.store.UpdateWallet(w, audit.ActorFrom(c))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+		return storeError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, wallet)
@@ -123,13 +164,15 @@ func (h *Handler) UpdateWallet(c echo.Context) error {
 // DeleteWallet
 //
 // @Summary		Delete wallet
-// @Description	Delete wallet
+// @Description	Delete wallet. Frozen and closed wallets cannot be deleted.
 // @Tags			wallet
 // @Accept			json
 // @Produce		json
 // @Router			/api/v1/wallets/{id} [delete]
 // @Success		204
 // @Failure		400	{object}	Err
+// @Failure		404	{object}	Err
+// @Failure		409	{object}	Err	"code wallet_frozen or wallet_closed when the wallet is not active"
 // @Failure		500	{object}	Err
 // @Param   id  path		int	true	"Wallet id"
 func (h *Handler) DeleteWallet(c echo.Context) error {
@@ -139,9 +182,185 @@ func (h *Handler) DeleteWallet(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
 	}
 
-	if err := h.store.DeleteWallet(walletId); err != nil {
-		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	if err := h.store.DeleteWallet(walletId, audit.ActorFrom(c)); err != nil {
+		return storeError(c, err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// ImportWallets
+//
+// @Summary		Import wallets
+// @Description	Import wallets from CSV (with a header row of user_id, user_name, wallet_name, wallet_type, balance, and asset, address, amount for crypto wallets) or NDJSON.
+// @Description	Crypto wallets must carry crypto details, checked as on create, so they can be imported from NDJSON only.
+// @Description	Every row is validated first. In atomic mode nothing is inserted unless every row is valid; in best_effort mode valid rows are inserted and the others reported.
+// @Tags			wallet
+// @Accept			text/csv
+// @Accept			application/x-ndjson
+// @Produce		json
+// @Router			/api/v1/wallets/import [post]
+// @Success		201	{object}	ImportReport
+// @Success		200	{object}	ImportReport	"best_effort with rejected rows"
+// @Failure		400	{object}	Err
+// @Failure		422	{object}	ImportReport
+// @Failure		500	{object}	Err
+// @Param   format  query	string	false	"Input format, defaults to the Content-Type"	Enums(csv, ndjson)
+// @Param   mode  query	string	false	"Import mode"	Enums(atomic, best_effort)	default(atomic)
+// @Param   wallets  body		string	true	"Rows of wallets in the format"
+func (h *Handler) ImportWallets(c echo.Context) error {
+	mode := c.QueryParam("mode")
+	if mode == "" {
+		mode = ImportAtomic
+	}
+	if mode != ImportAtomic && mode != ImportBestEffort {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid import mode"})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = formatOf(c.Request().Header.Get(echo.HeaderContentType))
+	}
+	rows, err := parseImport(c.Request().Body, format)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+
+	types, err := h.store.WalletTypes()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+
+	report := ImportReport{Mode: mode, Total: len(rows), Errors: []RowError{}}
+	var valid []Wallet
+	for _, r := range rows {
+		w, err := r.wallet, r.err
+		if err == nil {
+			w, err = validateImport(w, types)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, RowError{Row: r.n, Message: err.Error()})
+			continue
+		}
+		valid = append(valid, w)
+	}
+
+	if mode == ImportAtomic && len(report.Errors) > 0 {
+		return c.JSON(http.StatusUnprocessableEntity, report)
+	}
+	if len(valid) > 0 {
+		report.Inserted, err = h.store.ImportWallets(valid, audit.ActorFrom(c))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		return c.JSON(http.StatusOK, report)
+	}
+	return c.JSON(http.StatusCreated, report)
+}
+
+// ExportWallets
+//
+// @Summary		Export wallets
+// @Description	Stream all wallets as CSV or NDJSON
+// @Tags			wallet
+// @Produce		text/csv
+// @Produce		application/x-ndjson
+// @Produce		json
+// @Router			/api/v1/wallets/export [get]
+// @Success		200
+// @Failure		400	{object}	Err
+// @Failure		500	{object}	Err
+// @Param   format  query	string	false	"Output format"	Enums(csv, ndjson)	default(csv)
+// @Param   wallet_type  query	string	false	"Wallet type key, see /api/v1/wallet-types"
+func (h *Handler) ExportWallets(c echo.Context) error {
+	walletType := c.QueryParam("wallet_type")
+	if walletType != "" {
+		t, err := h.resolveType(walletType)
+		if err != nil {
+			return invalidType(c, err)
+		}
+		walletType = t.Name
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = FormatCSV
+	}
+
+	res := c.Response()
+	var begin, finish func()
+	var encode func(Wallet) error
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(res)
+		begin = func() { cw.Write(csvHeader) }
+		encode = func(w Wallet) error { return cw.Write(csvRecord(w)) }
+		finish = cw.Flush
+	case FormatNDJSON:
+		enc := json.NewEncoder(res)
+		begin, finish = func() {}, func() {}
+		encode = func(w Wallet) error { return enc.Encode(w) }
+	default:
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid format"})
+	}
+
+	// The status is committed with the first wallet, so that a store failing
+	// before it is still answered with an error.
+	commit := func() {
+		if res.Committed {
+			return
+		}
+		res.Header().Set(echo.HeaderContentType, exportTypes[format])
+		res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="wallets.`+format+`"`)
+		res.WriteHeader(http.StatusOK)
+		begin()
+	}
+	err := h.store.EachWallet(walletType, func(w Wallet) error {
+		commit()
+		return encode(w)
+	})
+	if err != nil && !res.Committed {
+		return storeError(c, err)
+	}
+	commit()
+	finish()
+	return err
+}
+
+// exportTypes are the content types of the export formats.
+var exportTypes = map[string]string{
+	FormatCSV:    "text/csv",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+func formatOf(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return FormatCSV
+	case strings.HasPrefix(contentType, "application/x-ndjson"), strings.HasPrefix(contentType, "application/ndjson"):
+		return FormatNDJSON
+	}
+	return ""
+}
+
+func storeError(c echo.Context, err error) error {
+	var be *BalanceError
+	var se *StatusError
+	var te *TransitionError
+	switch {
+	case errors.As(err, &be):
+		return c.JSON(http.StatusUnprocessableEntity, Err{Message: err.Error()})
+	case errors.As(err, &se):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error(), Code: se.Code()})
+	case errors.As(err, &te):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error(), Code: CodeInvalidTransition})
+	case errors.Is(err, ErrWalletNotFound):
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	case errors.Is(err, ErrMissingCrypto):
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
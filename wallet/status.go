@@ -0,0 +1,196 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/labstack/echo/v4"
+)
+
+// Wallet statuses. Only active wallets can have their balance changed;
+// frozen wallets can be unfrozen, closed ones never come back.
+const (
+	StatusActive = "active"
+	StatusFrozen = "frozen"
+	StatusClosed = "closed"
+)
+
+// Error codes returned in Err.Code for operations refused because of the
+// status of a wallet.
+const (
+	CodeWalletFrozen      = "wallet_frozen"
+	CodeWalletClosed      = "wallet_closed"
+	CodeInvalidTransition = "invalid_status_transition"
+)
+
+var ErrWalletNotFound = errors.New("wallet not found")
+
+// StatusError is an operation refused because the wallet is not active.
+type StatusError struct {
+	WalletID int
+	Status   string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("wallet %d is %s", e.WalletID, e.Status)
+}
+
+// Code is the error code clients can branch on.
+func (e *StatusError) Code() string {
+	if e.Status == StatusClosed {
+		return CodeWalletClosed
+	}
+	return CodeWalletFrozen
+}
+
+// TransitionError is a status change not allowed from the current status.
+type TransitionError struct {
+	From, To string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change wallet status from %s to %s", e.From, e.To)
+}
+
+var transitions = map[string][]string{
+	StatusActive: {StatusFrozen, StatusClosed},
+	StatusFrozen: {StatusActive, StatusClosed},
+}
+
+// CheckTransition returns a *TransitionError unless a wallet with status
+// from can move to status to.
+func CheckTransition(from, to string) error {
+	for _, s := range transitions[from] {
+		if s == to {
+			return nil
+		}
+	}
+	return &TransitionError{From: from, To: to}
+}
+
+// StatusChange records who changed the status of a wallet and why.
+type StatusChange struct {
+	ID        int       `json:"id" example:"1"`
+	WalletID  int       `json:"wallet_id" example:"1"`
+	From      string    `json:"from" example:"active"`
+	To        string    `json:"to" example:"frozen"`
+	Reason    string    `json:"reason" example:"Suspected card testing"`
+	Actor     string    `json:"actor" example:"ops@example.com"`
+	CreatedAt time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+type StatusRequest struct {
+	Reason string `json:"reason" example:"Suspected card testing"`
+}
+
+// FreezeWallet
+//
+// @Summary		Freeze wallet
+// @Description	Freeze an active wallet. Its balance cannot change until it is unfrozen.
+// @Tags			wallet
+// @Accept			json
+// @Produce		json
+// @Router			/api/v1/wallets/{id}/freeze [post]
+// @Success		200	{object}	Wallet
+// @Failure		400	{object}	Err
+// @Failure		404	{object}	Err
+// @Failure		409	{object}	Err	"code invalid_status_transition"
+// @Failure		500	{object}	Err
+// @Param   id  path		int	true	"Wallet id"
+// @Param   request  body		StatusRequest	true	"Reason"
+// @Security	AdminToken
+func (h *Handler) FreezeWallet(c echo.Context) error {
+	return h.changeStatus(c, StatusFrozen)
+}
+
+// UnfreezeWallet
+//
+// @Summary		Unfreeze wallet
+// @Description	Make a frozen wallet active again
+// @Tags			wallet
+// @Accept			json
+// @Produce		json
+// @Router			/api/v1/wallets/{id}/unfreeze [post]
+// @Success		200	{object}	Wallet
+// @Failure		400	{object}	Err
+// @Failure		404	{object}	Err
+// @Failure		409	{object}	Err	"code invalid_status_transition"
+// @Failure		500	{object}	Err
+// @Param   id  path		int	true	"Wallet id"
+// @Param   request  body		StatusRequest	true	"Reason"
+// @Security	AdminToken
+func (h *Handler) UnfreezeWallet(c echo.Context) error {
+	return h.changeStatus(c, StatusActive)
+}
+
+// CloseWallet
+//
+// @Summary		Close wallet
+// @Description	Close an active or frozen wallet for good
+// @Tags			wallet
+// @Accept			json
+// @Produce		json
+// @Router			/api/v1/wallets/{id}/close [post]
+// @Success		200	{object}	Wallet
+// @Failure		400	{object}	Err
+// @Failure		404	{object}	Err
+// @Failure		409	{object}	Err	"code invalid_status_transition"
+// @Failure		500	{object}	Err
+// @Param   id  path		int	true	"Wallet id"
+// @Param   request  body		StatusRequest	true	"Reason"
+// @Security	AdminToken
+func (h *Handler) CloseWallet(c echo.Context) error {
+	return h.changeStatus(c, StatusClosed)
+}
+
+func (h *Handler) changeStatus(c echo.Context, to string) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+	var req StatusRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Missing reason"})
+	}
+
+	wallet, err := h.store.ChangeStatus(walletId, to, req.Reason, audit.ActorFrom(c))
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, wallet)
+}
+
+// GetStatusHistory
+//
+// @Summary		Get wallet status history
+// @Description	Get every status change of a wallet with its reason and actor, latest first
+// @Tags			wallet
+// @Accept			json
+// @Produce		json
+// @Router			/api/v1/wallets/{id}/status-history [get]
+// @Success		200	{array}		StatusChange
+// @Failure		400	{object}	Err
+// @Failure		500	{object}	Err
+// @Param   id  path		int	true	"Wallet id"
+// @Security	AdminToken
+func (h *Handler) GetStatusHistory(c echo.Context) error {
+	walletId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet id"})
+	}
+
+	changes, err := h.store.StatusHistory(walletId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, changes)
+}
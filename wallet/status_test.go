@@ -0,0 +1,170 @@
+package wallet
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{StatusActive, StatusFrozen, true},
+		{StatusActive, StatusClosed, true},
+		{StatusFrozen, StatusActive, true},
+		{StatusFrozen, StatusClosed, true},
+		{StatusActive, StatusActive, false},
+		{StatusFrozen, StatusFrozen, false},
+		{StatusClosed, StatusActive, false},
+		{StatusClosed, StatusFrozen, false},
+	}
+	for _, tt := range tests {
+		if err := CheckTransition(tt.from, tt.to); (err == nil) != tt.ok {
+			t.Errorf("CheckTransition(%s, %s) = %v", tt.from, tt.to, err)
+		}
+	}
+}
+
+func TestChangeStatus(t *testing.T) {
+	statusRequest := func(t *testing.T, body, id string) (echo.Context, *httptest.ResponseRecorder) {
+		c, rec := setup(t, func() *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		})
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return c, rec
+	}
+
+	t.Run("given an active wallet should freeze it", func(t *testing.T) {
+		c, rec := statusRequest(t, `{"reason": "Suspected card testing"}`, "1")
+		stub := &StubWalletHandler{wallets: []Wallet{{ID: 1, Status: StatusActive}}}
+
+		New(stub).FreezeWallet(c)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+		if stub.wallets[0].Status != StatusFrozen {
+			t.Errorf("expected wallet to be %s but got %s", StatusFrozen, stub.wallets[0].Status)
+		}
+	})
+
+	t.Run("given no reason should return 400", func(t *testing.T) {
+		c, rec := statusRequest(t, `{"reason": " "}`, "1")
+
+		New(&StubWalletHandler{wallets: []Wallet{{ID: 1, Status: StatusActive}}}).FreezeWallet(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given an active wallet should not unfreeze it", func(t *testing.T) {
+		c, rec := statusRequest(t, `{"reason": "Cleared"}`, "1")
+
+		New(&StubWalletHandler{wallets: []Wallet{{ID: 1, Status: StatusActive}}}).UnfreezeWallet(c)
+
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("expected status code %d but got %d", http.StatusConflict, rec.Code)
+		}
+		var resp Err
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if resp.Code != CodeInvalidTransition {
+			t.Errorf("expected code %s but got %s", CodeInvalidTransition, resp.Code)
+		}
+	})
+
+	t.Run("given an unknown wallet should return 404", func(t *testing.T) {
+		c, rec := statusRequest(t, `{"reason": "Customer request"}`, "9")
+
+		New(&StubWalletHandler{}).CloseWallet(c)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestInactiveWallet(t *testing.T) {
+	stub := func() *StubWalletHandler {
+		return &StubWalletHandler{types: builtinTypes, wallets: []Wallet{
+			{ID: 1, UserID: 1, WalletName: "John Savings", WalletType: TypeSavings, Balance: 100, Status: StatusFrozen},
+			{ID: 2, UserID: 2, WalletName: "Jane Savings", WalletType: TypeSavings, Balance: 50, Status: StatusClosed},
+		}}
+	}
+	request := func(t *testing.T, method, body, id string) (echo.Context, *httptest.ResponseRecorder) {
+		c, rec := setup(t, func() *http.Request {
+			return httptest.NewRequest(method, "/", strings.NewReader(body))
+		})
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return c, rec
+	}
+	expectConflict := func(t *testing.T, rec *httptest.ResponseRecorder, code string) {
+		t.Helper()
+		var resp Err
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusConflict || resp.Code != code {
+			t.Errorf("expected %d %s but got %d %s", http.StatusConflict, code, rec.Code, rec.Body)
+		}
+	}
+
+	t.Run("given a frozen wallet should not change its owner, name or type", func(t *testing.T) {
+		c, rec := request(t, http.MethodPut, `{"user_id": 2, "user_name": "Jane Doe", "wallet_name": "Moved", "wallet_type": "CreditCard", "balance": 100}`, "1")
+		s := stub()
+
+		New(s).UpdateWallet(c)
+
+		expectConflict(t, rec, CodeWalletFrozen)
+		if s.wallets[0].UserID != 1 || s.wallets[0].WalletName != "John Savings" {
+			t.Errorf("expected the wallet unchanged but got %+v", s.wallets[0])
+		}
+	})
+
+	t.Run("given a frozen wallet should not delete it", func(t *testing.T) {
+		c, rec := request(t, http.MethodDelete, "", "1")
+		s := stub()
+
+		New(s).DeleteWallet(c)
+
+		expectConflict(t, rec, CodeWalletFrozen)
+		if len(s.wallets) != 2 {
+			t.Errorf("expected the wallet kept but got %v", s.wallets)
+		}
+	})
+
+	t.Run("given a closed wallet should not delete it", func(t *testing.T) {
+		c, rec := request(t, http.MethodDelete, "", "2")
+
+		New(stub()).DeleteWallet(c)
+
+		expectConflict(t, rec, CodeWalletClosed)
+	})
+}
+
+func TestStatusErrorResponse(t *testing.T) {
+	for _, tt := range []struct {
+		status string
+		code   string
+	}{
+		{StatusFrozen, CodeWalletFrozen},
+		{StatusClosed, CodeWalletClosed},
+	} {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodPut, "/", nil), rec)
+
+		storeError(c, &StatusError{WalletID: 1, Status: tt.status})
+
+		var resp Err
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusConflict || resp.Code != tt.code {
+			t.Errorf("expected %d %s for a %s wallet but got %d %s", http.StatusConflict, tt.code, tt.status, rec.Code, resp.Code)
+		}
+	}
+}
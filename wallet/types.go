@@ -0,0 +1,238 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Names of the wallet types the code gives extra behaviour to: credit card
+// terms and statements, and crypto details. They are seeded in init.sql and
+// cannot be renamed or deleted.
+const (
+	TypeSavings      = "Savings"
+	TypeCreditCard   = "Credit Card"
+	TypeCryptoWallet = "Crypto Wallet"
+)
+
+var (
+	ErrTypeNotFound = errors.New("wallet type not found")
+	ErrTypeExists   = errors.New("wallet type already exists")
+	ErrTypeInUse    = errors.New("wallet type is used by wallets")
+	ErrTypeBuiltin  = errors.New("built-in wallet types cannot be renamed or deleted")
+)
+
+// Type is a wallet type of the registry. Key is the identifier used in
+// query strings and paths, Name the value stored on wallets.
+type Type struct {
+	Key           string   `json:"key" example:"CreditCard"`
+	Name          string   `json:"name" example:"Credit Card"`
+	AllowNegative bool     `json:"allow_negative" example:"false"`
+	MaxBalance    *float64 `json:"max_balance,omitempty" example:"100000"`
+}
+
+// BalanceError is a balance breaking the rules of its wallet type.
+type BalanceError struct {
+	Type    string
+	Message string
+}
+
+func (e *BalanceError) Error() string {
+	return e.Message
+}
+
+// CheckBalance returns a *BalanceError when balance is not allowed for t.
+func (t Type) CheckBalance(balance float64) error {
+	if balance < 0 && !t.AllowNegative {
+		return &BalanceError{Type: t.Name, Message: fmt.Sprintf("%s wallets cannot have a negative balance", t.Name)}
+	}
+	if t.MaxBalance != nil && balance > *t.MaxBalance {
+		return &BalanceError{Type: t.Name, Message: fmt.Sprintf("%s wallets cannot hold more than %.2f", t.Name, *t.MaxBalance)}
+	}
+	return nil
+}
+
+func isBuiltin(name string) bool {
+	return name == TypeSavings || name == TypeCreditCard || name == TypeCryptoWallet
+}
+
+// ResolveType finds the type with the key or the name s.
+func ResolveType(types []Type, s string) (Type, bool) {
+	for _, t := range types {
+		if t.Key == s || t.Name == s {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// resolveType looks s up in the registry, so types added at runtime are
+// accepted without a redeploy.
+func (h *Handler) resolveType(s string) (Type, error) {
+	types, err := h.store.WalletTypes()
+	if err != nil {
+		return Type{}, err
+	}
+	t, ok := ResolveType(types, s)
+	if !ok {
+		return Type{}, ErrTypeNotFound
+	}
+	return t, nil
+}
+
+// invalidType answers a request naming a wallet type that is not in the
+// registry.
+func invalidType(c echo.Context, err error) error {
+	if errors.Is(err, ErrTypeNotFound) {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid wallet type"})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
+
+var typeKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{0,31}$`)
+
+func validateType(t Type) string {
+	switch {
+	case !typeKeyPattern.MatchString(t.Key):
+		return "Invalid wallet type key"
+	case strings.TrimSpace(t.Name) == "" || len(t.Name) > 64:
+		return "Invalid wallet type name"
+	case t.MaxBalance != nil && *t.MaxBalance < 0:
+		return "Invalid max balance"
+	}
+	return ""
+}
+
+// GetWalletTypes
+//
+//	@Summary		Get wallet types
+//	@Description	Get the wallet types of the registry and their balance rules
+//	@Tags			wallet type
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Type
+//	@Router			/api/v1/wallet-types [get]
+//	@Failure		500	{object}	Err
+func (h *Handler) GetWalletTypes(c echo.Context) error {
+	types, err := h.store.WalletTypes()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, types)
+}
+
+// CreateWalletType
+//
+//	@Summary		Create wallet type
+//	@Description	Add a wallet type to the registry. Wallets can use it right away.
+//	@Tags			wallet type
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	Type
+//	@Router			/api/v1/wallet-types [post]
+//	@Failure		400	{object}	Err
+//	@Failure		409	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   type  body		Type	true	"Wallet type"
+//	@Security	AdminToken
+func (h *Handler) CreateWalletType(c echo.Context) error {
+	var t Type
+	if err := c.Bind(&t); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	t.Name = strings.TrimSpace(t.Name)
+	if msg := validateType(t); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	created, err := h.store.CreateWalletType(t)
+	if err != nil {
+		return typeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateWalletType
+//
+//	@Summary		Update wallet type
+//	@Description	Update the name and balance rules of a wallet type. Renaming it renames it on its wallets.
+//	@Tags			wallet type
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Type
+//	@Router			/api/v1/wallet-types/{key} [put]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   key  path		string	true	"Wallet type key"
+//	@Param   type  body		Type	true	"Wallet type"
+//	@Security	AdminToken
+func (h *Handler) UpdateWalletType(c echo.Context) error {
+	var t Type
+	if err := c.Bind(&t); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	t.Key = c.Param("key")
+	t.Name = strings.TrimSpace(t.Name)
+	if msg := validateType(t); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	current, err := h.resolveType(t.Key)
+	if err != nil {
+		return typeError(c, err)
+	}
+	if isBuiltin(current.Name) && current.Name != t.Name {
+		return typeError(c, ErrTypeBuiltin)
+	}
+
+	updated, err := h.store.UpdateWalletType(t)
+	if err != nil {
+		return typeError(c, err)
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteWalletType
+//
+//	@Summary		Delete wallet type
+//	@Description	Delete a wallet type no wallet uses
+//	@Tags			wallet type
+//	@Accept			json
+//	@Produce		json
+//	@Success		204
+//	@Router			/api/v1/wallet-types/{key} [delete]
+//	@Failure		404	{object}	Err
+//	@Failure		409	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   key  path		string	true	"Wallet type key"
+//	@Security	AdminToken
+func (h *Handler) DeleteWalletType(c echo.Context) error {
+	current, err := h.resolveType(c.Param("key"))
+	if err != nil {
+		return typeError(c, err)
+	}
+	if isBuiltin(current.Name) {
+		return typeError(c, ErrTypeBuiltin)
+	}
+
+	if err := h.store.DeleteWalletType(current.Key); err != nil {
+		return typeError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func typeError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrTypeNotFound):
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	case errors.Is(err, ErrTypeExists), errors.Is(err, ErrTypeInUse), errors.Is(err, ErrTypeBuiltin):
+		return c.JSON(http.StatusConflict, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
@@ -0,0 +1,114 @@
+package wallet
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestCheckBalance(t *testing.T) {
+	max := 1000.0
+	tests := []struct {
+		t       Type
+		balance float64
+		wantErr bool
+	}{
+		{Type{Name: "Savings"}, 0, false},
+		{Type{Name: "Savings"}, -0.01, true},
+		{Type{Name: "Overdraft", AllowNegative: true}, -500, false},
+		{Type{Name: "Kids", MaxBalance: &max}, 1000, false},
+		{Type{Name: "Kids", MaxBalance: &max}, 1000.01, true},
+	}
+	for _, tt := range tests {
+		err := tt.t.CheckBalance(tt.balance)
+		var be *BalanceError
+		if tt.wantErr != errors.As(err, &be) {
+			t.Errorf("%s.CheckBalance(%v) = %v", tt.t.Name, tt.balance, err)
+		}
+	}
+}
+
+func TestWalletTypes(t *testing.T) {
+	t.Run("given a new wallet type should accept wallets of it without a redeploy", func(t *testing.T) {
+		stub := &StubWalletHandler{}
+		h := New(stub)
+
+		c, rec := setup(t, func() *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"key": "Kids", "name": "Kids Savings", "max_balance": 500}`))
+		})
+		h.CreateWalletType(c)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d but got %d", http.StatusCreated, rec.Code)
+		}
+
+		for _, tt := range []struct {
+			body string
+			want int
+		}{
+			{`{"user_id": 1, "user_name": "Jim", "wallet_name": "Piggy", "wallet_type": "Kids", "balance": 20}`, http.StatusCreated},
+			{`{"user_id": 1, "user_name": "Jim", "wallet_name": "Piggy", "wallet_type": "Kids", "balance": 501}`, http.StatusUnprocessableEntity},
+			{`{"user_id": 1, "user_name": "Jim", "wallet_name": "Piggy", "wallet_type": "Teens", "balance": 20}`, http.StatusBadRequest},
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			h.CreateWallet(echo.New().NewContext(req, rec))
+			if rec.Code != tt.want {
+				t.Errorf("expected status code %d for %s but got %d", tt.want, tt.body, rec.Code)
+			}
+		}
+		if stub.wallets[0].WalletType != "Kids Savings" {
+			t.Errorf("expected wallet type Kids Savings but got %s", stub.wallets[0].WalletType)
+		}
+	})
+
+	t.Run("given a built-in wallet type should not rename it", func(t *testing.T) {
+		c, rec := setup(t, func() *http.Request {
+			return httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name": "Card"}`))
+		})
+		c.SetParamNames("key")
+		c.SetParamValues("CreditCard")
+
+		New(&StubWalletHandler{}).UpdateWalletType(c)
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected status code %d but got %d", http.StatusConflict, rec.Code)
+		}
+	})
+
+	t.Run("given a wallet type in use should not delete it", func(t *testing.T) {
+		c, rec := setup(t, func() *http.Request {
+			return httptest.NewRequest(http.MethodDelete, "/", nil)
+		})
+		c.SetParamNames("key")
+		c.SetParamValues("Kids")
+		stub := &StubWalletHandler{
+			types:   append(append([]Type{}, builtinTypes...), Type{Key: "Kids", Name: "Kids Savings"}),
+			wallets: []Wallet{{ID: 1, WalletType: "Kids Savings"}},
+		}
+
+		New(stub).DeleteWalletType(c)
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected status code %d but got %d", http.StatusConflict, rec.Code)
+		}
+	})
+
+	t.Run("given an unknown wallet type should return 404", func(t *testing.T) {
+		c, rec := setup(t, func() *http.Request {
+			return httptest.NewRequest(http.MethodDelete, "/", nil)
+		})
+		c.SetParamNames("key")
+		c.SetParamValues("Nope")
+
+		New(&StubWalletHandler{}).DeleteWalletType(c)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
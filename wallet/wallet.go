@@ -2,12 +2,18 @@ package wallet
 
 import "time"
 
+// Wallet is a wallet of a user. Balance is the ledger balance, the sum of
+// its transactions; AvailableBalance is what can still be spent, the ledger
+// balance less the funds reserved by active holds.
 type Wallet struct {
-	ID         int       `json:"id" example:"1"`
-	UserID     int       `json:"user_id" example:"1"`
-	UserName   string    `json:"user_name" example:"John Doe"`
-	WalletName string    `json:"wallet_name" example:"John's Wallet"`
-	WalletType string    `json:"wallet_type" example:"CreditCard"`
-	Balance    float64   `json:"balance" example:"100.00"`
-	CreatedAt  time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+	ID               int       `json:"id" example:"1"`
+	UserID           int       `json:"user_id" example:"1"`
+	UserName         string    `json:"user_name" example:"John Doe"`
+	WalletName       string    `json:"wallet_name" example:"John's Wallet"`
+	WalletType       string    `json:"wallet_type" example:"CreditCard"`
+	Balance          float64   `json:"balance" example:"100.00"`
+	AvailableBalance float64   `json:"available_balance" example:"75.00"`
+	CreatedAt        time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+	Status           string    `json:"status" example:"active"`
+	Crypto           *Crypto   `json:"crypto,omitempty"`
 }
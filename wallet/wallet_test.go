@@ -11,17 +11,25 @@ import (
 	"testing"
 	"time"
 
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
 	"github.com/labstack/echo/v4"
 )
 
 type StubWalletHandler struct {
 	wallets []Wallet
+	types   []Type
 	err     error
 }
 
+var builtinTypes = []Type{
+	{Key: "Savings", Name: TypeSavings},
+	{Key: "CreditCard", Name: TypeCreditCard},
+	{Key: "CryptoWallet", Name: TypeCryptoWallet},
+}
+
 func (s *StubWalletHandler) Wallets(walletType string) ([]Wallet, error) {
 
-	if _, ok := WalletType[walletType]; ok {
+	if walletType != "" {
 		filteredWallets := []Wallet{}
 		for _, w := range s.wallets {
 			if w.WalletType == walletType {
@@ -33,7 +41,7 @@ func (s *StubWalletHandler) Wallets(walletType string) ([]Wallet, error) {
 
 	return s.wallets, s.err
 }
-func (w *StubWalletHandler) CreateWallet(wallet Wallet) (*Wallet, error) {
+func (w *StubWalletHandler) CreateWallet(wallet Wallet, actor audit.Actor) (*Wallet, error) {
 	lastWalletId := 0
 	if len(w.wallets) > 0 {
 		lastWalletId = w.wallets[len(w.wallets)-1].ID
@@ -45,10 +53,31 @@ func (w *StubWalletHandler) CreateWallet(wallet Wallet) (*Wallet, error) {
 	return &w.wallets[len(w.wallets)-1], nil
 }
 
-func (w *StubWalletHandler) UpdateWallet(wallet Wallet) (*Wallet, error) {
+// inactive refuses a change to wl like the store does, treating a wallet
+// without status as active.
+func inactive(wl Wallet) error {
+	if wl.Status != "" && wl.Status != StatusActive {
+		return &StatusError{WalletID: wl.ID, Status: wl.Status}
+	}
+	return nil
+}
+
+func (w *StubWalletHandler) UpdateWallet(wallet Wallet, actor audit.Actor) (*Wallet, error) {
 	for i, wl := range w.wallets {
 		if wl.ID == wallet.ID {
-			wl.Balance = wallet.Balance
+			if err := inactive(wl); err != nil {
+				return nil, err
+			}
+			// Like the store, keep what the request cannot change and
+			// what is held out of the balance.
+			held := wl.Balance - wl.AvailableBalance
+			wallet.Status, wallet.CreatedAt, wallet.AvailableBalance = wl.Status, wl.CreatedAt, wallet.Balance-held
+			if wallet.WalletType == TypeCryptoWallet && wallet.Crypto == nil {
+				if wl.Crypto == nil {
+					return nil, ErrMissingCrypto
+				}
+				wallet.Crypto = wl.Crypto
+			}
 			w.wallets[i] = wallet
 			return &w.wallets[i], nil
 		}
@@ -56,10 +85,13 @@ func (w *StubWalletHandler) UpdateWallet(wallet Wallet) (*Wallet, error) {
 	return nil, nil
 }
 
-func (w *StubWalletHandler) DeleteWallet(walletId int) error {
+func (w *StubWalletHandler) DeleteWallet(walletId int, actor audit.Actor) error {
 	removedIndex := -1
 	for i, wl := range w.wallets {
 		if wl.ID == walletId {
+			if err := inactive(wl); err != nil {
+				return err
+			}
 			removedIndex = i
 			w.wallets = append(w.wallets[:i], w.wallets[i+1:]...)
 			return nil
@@ -73,6 +105,85 @@ func (w *StubWalletHandler) DeleteWallet(walletId int) error {
 	return nil
 }
 
+func (w *StubWalletHandler) ImportWallets(wallets []Wallet, actor audit.Actor) (int, error) {
+	for _, wallet := range wallets {
+		w.CreateWallet(wallet, actor)
+	}
+	return len(wallets), w.err
+}
+
+func (w *StubWalletHandler) EachWallet(walletType string, fn func(Wallet) error) error {
+	wallets, err := w.Wallets(walletType)
+	if err != nil {
+		return err
+	}
+	for _, wallet := range wallets {
+		if err := fn(wallet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *StubWalletHandler) WalletTypes() ([]Type, error) {
+	if w.types == nil {
+		w.types = append([]Type{}, builtinTypes...)
+	}
+	return w.types, nil
+}
+
+func (w *StubWalletHandler) CreateWalletType(t Type) (*Type, error) {
+	types, _ := w.WalletTypes()
+	if _, ok := ResolveType(types, t.Key); ok {
+		return nil, ErrTypeExists
+	}
+	w.types = append(w.types, t)
+	return &t, nil
+}
+
+func (w *StubWalletHandler) UpdateWalletType(t Type) (*Type, error) {
+	types, _ := w.WalletTypes()
+	for i := range types {
+		if types[i].Key == t.Key {
+			types[i] = t
+			return &t, nil
+		}
+	}
+	return nil, ErrTypeNotFound
+}
+
+func (w *StubWalletHandler) DeleteWalletType(key string) error {
+	for _, wl := range w.wallets {
+		if t, _ := ResolveType(w.types, key); wl.WalletType == t.Name {
+			return ErrTypeInUse
+		}
+	}
+	for i, t := range w.types {
+		if t.Key == key {
+			w.types = append(w.types[:i], w.types[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTypeNotFound
+}
+
+func (w *StubWalletHandler) ChangeStatus(id int, status, reason string, actor audit.Actor) (*Wallet, error) {
+	for i := range w.wallets {
+		if w.wallets[i].ID == id {
+			if err := CheckTransition(w.wallets[i].Status, status); err != nil {
+				return nil, err
+			}
+			w.wallets[i].Status = status
+			return &w.wallets[i], nil
+		}
+	}
+	return nil, ErrWalletNotFound
+}
+
+func (w *StubWalletHandler) StatusHistory(id int) ([]StatusChange, error) {
+	return []StatusChange{}, w.err
+}
+
 func setup(t *testing.T, buildRequestFunc func() *http.Request) (echo.Context, *httptest.ResponseRecorder) {
 	t.Parallel()
 	e := echo.New()
@@ -250,22 +361,25 @@ func TestWallet(t *testing.T) {
 		c.SetParamNames("id")
 		c.SetParamValues("1")
 
+		created := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
 		wallets := []Wallet{
 			{
-				ID:         1,
-				UserName:   "John Doe",
-				WalletName: "John's Wallet",
-				WalletType: "CreditCard",
-				Balance:    100,
-				CreatedAt:  time.Now(),
+				ID:               1,
+				UserName:         "John Doe",
+				WalletName:       "John's Wallet",
+				WalletType:       "CreditCard",
+				Balance:          100,
+				AvailableBalance: 100,
+				CreatedAt:        created,
 			},
 			{
-				ID:         2,
-				UserName:   "John Doe",
-				WalletName: "John's Wallet",
-				WalletType: "CreditCard",
-				Balance:    100,
-				CreatedAt:  time.Now(),
+				ID:               2,
+				UserName:         "John Doe",
+				WalletName:       "John's Wallet",
+				WalletType:       "CreditCard",
+				Balance:          100,
+				AvailableBalance: 100,
+				CreatedAt:        created,
 			},
 		}
 
@@ -280,13 +394,14 @@ func TestWallet(t *testing.T) {
 		resp := &Wallet{}
 		json.Unmarshal(rec.Body.Bytes(), resp)
 		want := &Wallet{
-			ID:         1,
-			UserID:     2,
-			UserName:   "John Doe",
-			WalletName: "John's Wallet",
-			WalletType: "CreditCard",
-			Balance:    1000,
-			CreatedAt:  wallets[0].CreatedAt,
+			ID:               1,
+			UserID:           2,
+			UserName:         "John Doe",
+			WalletName:       "John's Wallet",
+			WalletType:       "Credit Card",
+			Balance:          1000,
+			AvailableBalance: 1000,
+			CreatedAt:        created,
 		}
 		if !reflect.DeepEqual(resp, want) {
 			t.Errorf("expected status code %v but got %v", want, resp)
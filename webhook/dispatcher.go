@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/event"
+)
+
+// Queue stores pending deliveries for the Dispatcher and the Sender.
+type Queue interface {
+	// EnqueueDeliveries creates one pending delivery per active subscription
+	// matching e. Enqueueing the same event twice must not duplicate them.
+	EnqueueDeliveries(e event.Event) error
+	// DueDeliveries claims up to limit deliveries due at now, hiding them
+	// from other senders until lease has passed, so that senders running
+	// side by side do not send the same delivery.
+	DueDeliveries(now time.Time, lease time.Duration, limit int) ([]Pending, error)
+	// SaveAttempt saves the outcome of the latest attempt at d and records
+	// the attempt in its history.
+	SaveAttempt(d Delivery) error
+}
+
+// Pending is a delivery together with where and how to send it.
+type Pending struct {
+	Delivery
+	TargetURL string
+	Secret    string
+}
+
+// Dispatcher is an event.Sink turning outbox events into webhook deliveries.
+type Dispatcher struct {
+	queue Queue
+}
+
+func NewDispatcher(queue Queue) *Dispatcher {
+	return &Dispatcher{queue: queue}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, e event.Event) error {
+	return d.queue.EnqueueDeliveries(e)
+}
+
+// claimLease is how long a claimed delivery stays hidden from other senders.
+// It outlasts a batch of timed out requests; a sender stopping mid-batch
+// leaves the rest to be sent once it has passed.
+const claimLease = 30 * time.Minute
+
+// Sender sends due deliveries, retrying failures with exponential backoff
+// (backoff, 2*backoff, 4*backoff, ...) until maxAttempts is reached and the
+// delivery is dead. A redelivery starts the count again.
+type Sender struct {
+	queue       Queue
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	now         func() time.Time
+}
+
+func NewSender(queue Queue, client *http.Client, maxAttempts int, backoff time.Duration) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sender{queue: queue, client: client, maxAttempts: maxAttempts, backoff: backoff, now: time.Now}
+}
+
+// Run sends due deliveries every interval until ctx is done.
+func (s *Sender) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Flush(ctx); err != nil {
+			log.Printf("webhook sender: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush sends one batch of due deliveries.
+func (s *Sender) Flush(ctx context.Context) error {
+	pending, err := s.queue.DueDeliveries(s.now(), claimLease, 100)
+	if err != nil {
+		return err
+	}
+	for _, p := range pending {
+		if err := s.queue.SaveAttempt(s.send(ctx, p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Sender) send(ctx context.Context, p Pending) Delivery {
+	d := p.Delivery
+	d.Attempts++
+
+	status, err := s.post(ctx, p)
+	d.ResponseStatus = status
+	if err == nil {
+		now := s.now()
+		d.Status = StatusSucceeded
+		d.LastError = ""
+		d.DeliveredAt = &now
+		return d
+	}
+
+	d.LastError = err.Error()
+	made := d.Attempts - d.AttemptsBefore
+	if made >= s.maxAttempts {
+		d.Status = StatusDead
+		return d
+	}
+	d.Status = StatusPending
+	d.NextAttemptAt = s.now().Add(s.backoff << (made - 1))
+	return d
+}
+
+func (s *Sender) post(ctx context.Context, p Pending) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TargetURL, bytes.NewReader(p.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, p.EventType)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(p.ID))
+	req.Header.Set(HeaderSignature, Sign(p.Secret, s.now(), p.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,269 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/event"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	store Storer
+}
+
+type Storer interface {
+	Subscriptions() ([]Subscription, error)
+	Subscription(id int) (*Subscription, error)
+	CreateSubscription(s Subscription) (*Subscription, error)
+	UpdateSubscription(s Subscription) (*Subscription, error)
+	DeleteSubscription(id int) error
+	Deliveries(subscriptionID int) ([]Delivery, error)
+	Redeliver(subscriptionID, deliveryID int) (*Delivery, error)
+}
+
+func New(db Storer) *Handler {
+	return &Handler{store: db}
+}
+
+type Err struct {
+	Message string `json:"message"`
+}
+
+// GetSubscriptions
+//
+//	@Summary		Get all webhook subscriptions
+//	@Description	Get all webhook subscriptions. Secrets are never returned.
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Subscription
+//	@Router			/api/v1/webhooks [get]
+//	@Failure		500	{object}	Err
+//	@Security	AdminToken
+func (h *Handler) GetSubscriptions(c echo.Context) error {
+	subs, err := h.store.Subscriptions()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return c.JSON(http.StatusOK, subs)
+}
+
+// GetSubscription
+//
+//	@Summary		Get webhook subscription
+//	@Description	Get webhook subscription. The secret is never returned.
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Subscription
+//	@Router			/api/v1/webhooks/{id} [get]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Subscription id"
+//	@Security	AdminToken
+func (h *Handler) GetSubscription(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid subscription id"})
+	}
+
+	sub, err := h.store.Subscription(id)
+	if err != nil {
+		return storeError(c, err)
+	}
+	sub.Secret = ""
+	return c.JSON(http.StatusOK, sub)
+}
+
+// CreateSubscription
+//
+//	@Summary		Create webhook subscription
+//	@Description	Create webhook subscription. A secret is generated when none is given and is only returned here.
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Router			/api/v1/webhooks [post]
+//	@Success		201	{object}	Subscription
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   subscription  body		Subscription	true	"Subscription"
+//	@Security	AdminToken
+func (h *Handler) CreateSubscription(c echo.Context) error {
+	s := Subscription{Active: true}
+	if err := c.Bind(&s); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	if msg := validate(s); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+	if s.Secret == "" {
+		secret, err := NewSecret()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+		}
+		s.Secret = secret
+	}
+
+	sub, err := h.store.CreateSubscription(s)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusCreated, sub)
+}
+
+// UpdateSubscription
+//
+//	@Summary		Update webhook subscription
+//	@Description	Update webhook subscription. An empty secret keeps the current one.
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Router			/api/v1/webhooks/{id} [put]
+//	@Success		200	{object}	Subscription
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Subscription id"
+//	@Param   subscription  body		Subscription	true	"Subscription"
+//	@Security	AdminToken
+func (h *Handler) UpdateSubscription(c echo.Context) error {
+	var s Subscription
+	if err := c.Bind(&s); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: err.Error()})
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid subscription id"})
+	}
+	s.ID = id
+	if msg := validate(s); msg != "" {
+		return c.JSON(http.StatusBadRequest, Err{Message: msg})
+	}
+
+	sub, err := h.store.UpdateSubscription(s)
+	if err != nil {
+		return storeError(c, err)
+	}
+	sub.Secret = ""
+	return c.JSON(http.StatusOK, sub)
+}
+
+// DeleteSubscription
+//
+//	@Summary		Delete webhook subscription
+//	@Description	Delete webhook subscription and its delivery history
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Router			/api/v1/webhooks/{id} [delete]
+//	@Success		204
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Subscription id"
+//	@Security	AdminToken
+func (h *Handler) DeleteSubscription(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid subscription id"})
+	}
+
+	if err := h.store.DeleteSubscription(id); err != nil {
+		return storeError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetDeliveries
+//
+//	@Summary		Get delivery history
+//	@Description	Get delivery history of a webhook subscription, newest first, each with its attempts
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		Delivery
+//	@Router			/api/v1/webhooks/{id}/deliveries [get]
+//	@Failure		400	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Subscription id"
+//	@Security	AdminToken
+func (h *Handler) GetDeliveries(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid subscription id"})
+	}
+
+	deliveries, err := h.store.Deliveries(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver
+//
+//	@Summary		Redeliver webhook
+//	@Description	Queue a delivery to be sent again right away, including dead ones, with a new round of retries. Earlier attempts stay in its history.
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Success		202	{object}	Delivery
+//	@Router			/api/v1/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+//	@Failure		400	{object}	Err
+//	@Failure		404	{object}	Err
+//	@Failure		500	{object}	Err
+//	@Param   id  path		int	true	"Subscription id"
+//	@Param   deliveryId  path		int	true	"Delivery id"
+//	@Security	AdminToken
+func (h *Handler) Redeliver(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid subscription id"})
+	}
+	deliveryId, err := strconv.Atoi(c.Param("deliveryId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: "Invalid delivery id"})
+	}
+
+	delivery, err := h.store.Redeliver(id, deliveryId)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
+
+func validate(s Subscription) string {
+	u, err := url.Parse(s.TargetURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "Invalid target url"
+	}
+	for _, e := range s.Events {
+		if !isEventType(e) {
+			return "Invalid event type: " + e
+		}
+	}
+	return ""
+}
+
+func isEventType(name string) bool {
+	for _, t := range event.Types {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func storeError(c echo.Context, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return c.JSON(http.StatusNotFound, Err{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, Err{Message: err.Error()})
+}
@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Statuses of a delivery. An attempt is succeeded or failed.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusDead      = "dead"
+)
+
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+var ErrNotFound = errors.New("webhook not found")
+
+type Subscription struct {
+	ID        int       `json:"id" example:"1"`
+	TargetURL string    `json:"target_url" example:"https://partner.example.com/hooks/wallet"`
+	Events    []string  `json:"events" example:"WalletCreated,BalanceChanged"`
+	Secret    string    `json:"secret,omitempty" example:"5f2b0c..."`
+	Active    bool      `json:"active" example:"true"`
+	CreatedAt time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+type Delivery struct {
+	ID             int             `json:"id" example:"1"`
+	SubscriptionID int             `json:"subscription_id" example:"1"`
+	EventID        int64           `json:"event_id" example:"1"`
+	EventType      string          `json:"event_type" example:"WalletCreated"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status" example:"pending"`
+	Attempts       int             `json:"attempts" example:"1"`
+	ResponseStatus int             `json:"response_status,omitempty" example:"500"`
+	LastError      string          `json:"last_error,omitempty" example:"unexpected status 500"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" example:"2024-03-25T14:19:00.729237Z"`
+	CreatedAt      time.Time       `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" example:"2024-03-25T14:19:00.729237Z"`
+	History        []Attempt       `json:"history,omitempty"`
+	// AttemptsBefore is Attempts when the delivery was last redelivered:
+	// a redelivery gets a new round of retries without losing count.
+	AttemptsBefore int `json:"-"`
+}
+
+// Attempt is one try at sending a delivery.
+type Attempt struct {
+	Attempt        int       `json:"attempt" example:"1"`
+	Status         string    `json:"status" example:"failed"`
+	ResponseStatus int       `json:"response_status,omitempty" example:"500"`
+	Error          string    `json:"error,omitempty" example:"unexpected status 500"`
+	At             time.Time `json:"at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the X-Webhook-Signature header value for body sent at t:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<body>">".
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, signature(secret, ts, body))
+}
+
+// Verify checks a signature header produced by Sign, rejecting signatures
+// older than tolerance. Receivers can use it as is.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return errors.New("malformed signature")
+	}
+	if now.Sub(time.Unix(unix, 0)) > tolerance {
+		return errors.New("signature expired")
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, ts, body))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+func signature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,299 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/event"
+	"github.com/labstack/echo/v4"
+)
+
+type StubWebhookHandler struct {
+	subs       []Subscription
+	deliveries []Delivery
+}
+
+func (s *StubWebhookHandler) Subscriptions() ([]Subscription, error) {
+	return s.subs, nil
+}
+
+func (s *StubWebhookHandler) Subscription(id int) (*Subscription, error) {
+	for i := range s.subs {
+		if s.subs[i].ID == id {
+			return &s.subs[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *StubWebhookHandler) CreateSubscription(sub Subscription) (*Subscription, error) {
+	sub.ID = len(s.subs) + 1
+	s.subs = append(s.subs, sub)
+	return &sub, nil
+}
+
+func (s *StubWebhookHandler) UpdateSubscription(sub Subscription) (*Subscription, error) {
+	for i := range s.subs {
+		if s.subs[i].ID == sub.ID {
+			s.subs[i] = sub
+			return &sub, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *StubWebhookHandler) DeleteSubscription(id int) error {
+	_, err := s.Subscription(id)
+	return err
+}
+
+func (s *StubWebhookHandler) Deliveries(subscriptionID int) ([]Delivery, error) {
+	return s.deliveries, nil
+}
+
+func (s *StubWebhookHandler) Redeliver(subscriptionID, deliveryID int) (*Delivery, error) {
+	for _, d := range s.deliveries {
+		if d.ID == deliveryID && d.SubscriptionID == subscriptionID {
+			d.Status = StatusPending
+			d.AttemptsBefore = d.Attempts
+			return &d, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+type StubQueue struct {
+	pending []Pending
+	saved   []Delivery
+}
+
+func (q *StubQueue) EnqueueDeliveries(e event.Event) error {
+	return nil
+}
+
+func (q *StubQueue) DueDeliveries(now time.Time, lease time.Duration, limit int) ([]Pending, error) {
+	return q.pending, nil
+}
+
+func (q *StubQueue) SaveAttempt(d Delivery) error {
+	q.saved = append(q.saved, d)
+	return nil
+}
+
+func newContext(method, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestWebhook(t *testing.T) {
+
+	t.Run("given subscription without secret should generate one", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, `{"target_url": "https://partner.example.com/hooks", "events": ["WalletCreated"]}`)
+
+		New(&StubWebhookHandler{}).CreateSubscription(c)
+
+		if rec.Code != http.StatusCreated {
+			t.Errorf("expected status code %d but got %d", http.StatusCreated, rec.Code)
+		}
+		resp := &Subscription{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		if resp.Secret == "" || !resp.Active {
+			t.Errorf("expected active subscription with secret but got %v", resp)
+		}
+	})
+
+	t.Run("given invalid target url should return 400", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, `{"target_url": "ftp://partner.example.com"}`)
+
+		New(&StubWebhookHandler{}).CreateSubscription(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given unknown event type should return 400", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, `{"target_url": "https://partner.example.com", "events": ["WalletStolen"]}`)
+
+		New(&StubWebhookHandler{}).CreateSubscription(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d but got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("given subscription id should not return secret", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, "")
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		New(&StubWebhookHandler{subs: []Subscription{{ID: 1, TargetURL: "https://partner.example.com", Secret: "s3cr3t"}}}).GetSubscription(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), "s3cr3t") {
+			t.Errorf("expected no secret but got %s", rec.Body.String())
+		}
+	})
+
+	t.Run("given unknown delivery should return 404 on redeliver", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "")
+		c.SetParamNames("id", "deliveryId")
+		c.SetParamValues("1", "9")
+
+		New(&StubWebhookHandler{}).Redeliver(c)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d but got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+
+	t.Run("given dead delivery should queue it again", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "")
+		c.SetParamNames("id", "deliveryId")
+		c.SetParamValues("1", "2")
+
+		New(&StubWebhookHandler{deliveries: []Delivery{{ID: 2, SubscriptionID: 1, Status: StatusDead}}}).Redeliver(c)
+
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("expected status code %d but got %d", http.StatusAccepted, rec.Code)
+		}
+		resp := &Delivery{}
+		json.Unmarshal(rec.Body.Bytes(), resp)
+		if resp.Status != StatusPending {
+			t.Errorf("expected status %s but got %s", StatusPending, resp.Status)
+		}
+	})
+}
+
+func TestSender(t *testing.T) {
+	now := time.Date(2024, 3, 25, 14, 19, 0, 0, time.UTC)
+	pending := Pending{
+		Delivery: Delivery{
+			ID:             1,
+			SubscriptionID: 1,
+			EventID:        7,
+			EventType:      event.WalletCreated,
+			Payload:        json.RawMessage(`{"id":7,"type":"WalletCreated"}`),
+			Status:         StatusPending,
+		},
+		Secret: "s3cr3t",
+	}
+
+	t.Run("given receiver accepts should send signed payload", func(t *testing.T) {
+		var mu sync.Mutex
+		var verifyErr error
+		var eventType string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			verifyErr = Verify("s3cr3t", r.Header.Get(HeaderSignature), body, 5*time.Minute, now)
+			eventType = r.Header.Get(HeaderEvent)
+		}))
+		defer srv.Close()
+
+		p := pending
+		p.TargetURL = srv.URL
+		queue := &StubQueue{pending: []Pending{p}}
+		sender := NewSender(queue, srv.Client(), 3, time.Minute)
+		sender.now = func() time.Time { return now }
+
+		if err := sender.Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if verifyErr != nil {
+			t.Errorf("expected valid signature but got %v", verifyErr)
+		}
+		if eventType != event.WalletCreated {
+			t.Errorf("expected event header %s but got %s", event.WalletCreated, eventType)
+		}
+		got := queue.saved[0]
+		if got.Status != StatusSucceeded || got.Attempts != 1 || got.DeliveredAt == nil {
+			t.Errorf("expected succeeded delivery but got %v", got)
+		}
+	})
+
+	t.Run("given receiver fails should back off exponentially then dead letter", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		p := pending
+		p.TargetURL = srv.URL
+		queue := &StubQueue{}
+		sender := NewSender(queue, srv.Client(), 3, time.Minute)
+		sender.now = func() time.Time { return now }
+
+		wantNext := []time.Duration{time.Minute, 2 * time.Minute}
+		for i := 0; i < 3; i++ {
+			queue.pending = []Pending{p}
+			sender.Flush(context.Background())
+			p.Delivery = queue.saved[len(queue.saved)-1]
+			if i < len(wantNext) {
+				if p.Status != StatusPending || !p.NextAttemptAt.Equal(now.Add(wantNext[i])) {
+					t.Errorf("attempt %d: expected retry at %v but got %v (%s)", i+1, now.Add(wantNext[i]), p.NextAttemptAt, p.Status)
+				}
+			}
+		}
+
+		if p.Status != StatusDead || p.Attempts != 3 || p.ResponseStatus != http.StatusInternalServerError {
+			t.Errorf("expected dead delivery after 3 attempts but got %v", p.Delivery)
+		}
+	})
+
+	t.Run("given a redelivered dead delivery should retry it again and keep counting", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		p := pending
+		p.TargetURL = srv.URL
+		p.Attempts, p.AttemptsBefore = 3, 3
+		queue := &StubQueue{pending: []Pending{p}}
+		sender := NewSender(queue, srv.Client(), 3, time.Minute)
+		sender.now = func() time.Time { return now }
+
+		sender.Flush(context.Background())
+
+		got := queue.saved[0]
+		if got.Status != StatusPending || got.Attempts != 4 || !got.NextAttemptAt.Equal(now.Add(time.Minute)) {
+			t.Errorf("expected a first retry after attempt 4 but got %v", got)
+		}
+	})
+}
+
+func TestSignature(t *testing.T) {
+	now := time.Date(2024, 3, 25, 14, 19, 0, 0, time.UTC)
+	body := []byte(`{"id":1}`)
+	header := Sign("s3cr3t", now, body)
+
+	if err := Verify("s3cr3t", header, body, time.Minute, now); err != nil {
+		t.Errorf("expected valid signature but got %v", err)
+	}
+	if err := Verify("other", header, body, time.Minute, now); err == nil {
+		t.Error("expected mismatch with other secret")
+	}
+	if err := Verify("s3cr3t", header, []byte(`{"id":2}`), time.Minute, now); err == nil {
+		t.Error("expected mismatch with tampered body")
+	}
+	if err := Verify("s3cr3t", header, body, time.Minute, now.Add(time.Hour)); err == nil {
+		t.Error("expected expired signature")
+	}
+}